@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/diag"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chargebackLabel         string
+	chargebackNamespace     string
+	chargebackIncludeSystem bool
+	chargebackCPUPrice      float64
+	chargebackMemPrice      float64
+	chargebackCSV           string
+)
+
+var chargebackCmd = &cobra.Command{
+	Use:   "chargeback",
+	Short: "Estimate monthly cost per team of requested vs used capacity",
+	Long: `Groups running pods by a label (default "team", read from the pod or else its
+namespace, like pods --group-by-label) and prices each group's capacity per
+month: the cost of what it requests, which is what it blocks on the nodes,
+against the cost of what it actually uses and the idle difference.
+
+Prices are per core and per GiB of memory per month; the defaults are rough
+on-demand cloud list prices, so pass your own rates for real chargeback.
+--csv also writes the same data as CSV to a file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if chargebackCPUPrice < 0 || chargebackMemPrice < 0 {
+			return fmt.Errorf("--cpu-price and --mem-price must not be negative")
+		}
+		if chargebackCSV == "-" {
+			return fmt.Errorf("--csv needs a file path: stdout already carries the table")
+		}
+
+		ctx := context.Background()
+		result, err := kube.FetchPods(ctx, clients, chargebackNamespace)
+		if err != nil {
+			return err
+		}
+		nsLabels, err := kube.FetchNamespaceLabels(ctx, clients, chargebackNamespace)
+		if err != nil {
+			diag.Warnf("%v; grouping by pod labels only", err)
+		}
+
+		includeSystem := chargebackIncludeSystem || chargebackNamespace != ""
+		var pods []kube.PodInfo
+		for _, p := range result.Pods {
+			if includeSystem || !kube.SystemNamespaces[p.Namespace] {
+				pods = append(pods, p)
+			}
+		}
+
+		prices := analysis.Prices{CPUPerCoreMonth: chargebackCPUPrice, MemPerGiBMonth: chargebackMemPrice}
+		lines := analysis.Chargeback(analysis.GroupByLabel(pods, nsLabels, chargebackLabel), prices)
+		output.RenderChargeback(lines, clients.ContextName, output.ChargebackOptions{
+			Label:            chargebackLabel,
+			Prices:           prices,
+			MetricsAvailable: result.MetricsAvailable,
+		})
+
+		if chargebackCSV == "" {
+			return nil
+		}
+		return writeChargebackCSV(chargebackCSV, lines, result.MetricsAvailable)
+	},
+}
+
+func writeChargebackCSV(path string, lines []analysis.ChargebackLine, metricsAvailable bool) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	defer f.Close()
+	if err := output.WriteChargebackCSV(f, lines, chargebackLabel, metricsAvailable); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	output.PrintSaved(path)
+	return nil
+}
+
+func init() {
+	chargebackCmd.Flags().StringVar(&chargebackLabel, "label", "team", "pod/namespace label to charge back by")
+	chargebackCmd.Flags().StringVar(&chargebackNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	chargebackCmd.Flags().BoolVar(&chargebackIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	chargebackCmd.Flags().Float64Var(&chargebackCPUPrice, "cpu-price", 24, "price of one CPU core per month")
+	chargebackCmd.Flags().Float64Var(&chargebackMemPrice, "mem-price", 3.2, "price of one GiB of memory per month")
+	chargebackCmd.Flags().StringVar(&chargebackCSV, "csv", "", "also write the report as CSV to this file")
+	_ = chargebackCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(chargebackCmd)
+}
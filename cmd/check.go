@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/diag"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkBaseline       string
+	checkUpdateBaseline bool
+	checkTolerance      float64
+	checkNamespace      string
+	checkIncludeSystem  bool
+	checkJUnit          string
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Fail when resource efficiency regressed against a baseline",
+	Long: `Compares current CPU and memory efficiency (actual usage / requests, summed
+over pods with metrics) against a committed baseline file and exits with
+status 3 when either dropped by more than --tolerance percentage points.
+
+Run with --update-baseline to (re)write the baseline from the current state.
+Together this gives a ratcheting CI gate for resource waste, like a coverage
+gate: commit the baseline, fail on regressions, update it on improvements.
+--junit also writes the result as JUnit XML, one test case per gated
+efficiency, for the CI system's test report view.`,
+	Example: `  kusa check --baseline baseline.json --update-baseline
+  kusa check --baseline baseline.json --tolerance 2
+  kusa check --baseline baseline.json --junit kusa-junit.xml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if checkJUnit != "" && checkUpdateBaseline {
+			return fmt.Errorf("--junit cannot be combined with --update-baseline")
+		}
+		if err := validateJUnitPath(checkJUnit); err != nil {
+			return err
+		}
+		result, err := kube.FetchPods(context.Background(), clients, checkNamespace)
+		if err != nil {
+			return err
+		}
+		if !result.MetricsAvailable {
+			return errors.New("efficiency check requires pod metrics (is metrics-server installed?)")
+		}
+
+		var pods []kube.PodInfo
+		for _, p := range result.Pods {
+			if checkNamespace == "" && !checkIncludeSystem && kube.SystemNamespaces[p.Namespace] {
+				continue
+			}
+			pods = append(pods, p)
+		}
+		current := analysis.MeasureEfficiency(pods)
+
+		if checkUpdateBaseline {
+			b := analysis.Baseline{
+				Context:    clients.ContextName,
+				Namespace:  checkNamespace,
+				CreatedAt:  time.Now().UTC(),
+				Efficiency: current,
+			}
+			if err := analysis.SaveBaseline(checkBaseline, b); err != nil {
+				return fmt.Errorf("failed to write baseline: %w", err)
+			}
+			fmt.Printf("Baseline written to %s: CPU efficiency %.1f%%, memory efficiency %.1f%% over %d pods.\n",
+				checkBaseline, current.CPUEfficiency(), current.MemEfficiency(), current.Pods)
+			return nil
+		}
+
+		baseline, err := analysis.LoadBaseline(checkBaseline)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("baseline %s not found; create it with --update-baseline", checkBaseline)
+		}
+		if err != nil {
+			return err
+		}
+		if baseline.Context != "" && baseline.Context != clients.ContextName {
+			diag.Warnf("baseline was taken on context %q, comparing against %q", baseline.Context, clients.ContextName)
+		}
+		if baseline.Namespace != checkNamespace {
+			diag.Warnf("baseline was taken for namespace %q, comparing against %q", baseline.Namespace, checkNamespace)
+		}
+
+		regressions := analysis.CompareEfficiency(baseline.Efficiency, current, checkTolerance)
+		output.RenderCheck(baseline, current, regressions, clients.ContextName, checkTolerance)
+		if checkJUnit != "" {
+			cases := output.CheckJUnitCases(baseline.Efficiency, current, regressions, checkTolerance)
+			if err := writeJUnitFile(checkJUnit, "kusa check", cases); err != nil {
+				return err
+			}
+		}
+		if len(regressions) > 0 {
+			return newExitError(cmd, exitFindings, "check: %d efficiency metrics regressed", len(regressions))
+		}
+		return nil
+	},
+}
+
+func init() {
+	checkCmd.Flags().StringVar(&checkBaseline, "baseline", "kusa-baseline.json", "baseline file to compare against")
+	checkCmd.Flags().BoolVar(&checkUpdateBaseline, "update-baseline", false, "write the current efficiency to the baseline file instead of comparing")
+	checkCmd.Flags().StringVar(&checkJUnit, "junit", "", "also write the result as JUnit XML to this file, one test case per gated efficiency")
+	checkCmd.Flags().Float64Var(&checkTolerance, "tolerance", 5, "allowed efficiency drop in percentage points")
+	checkCmd.Flags().StringVar(&checkNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	checkCmd.Flags().BoolVar(&checkIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	_ = checkCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(checkCmd)
+}
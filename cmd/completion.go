@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds live cluster lookups so a slow API server never hangs the shell.
+const completionTimeout = 3 * time.Second
+
+// completeContexts suggests context names from the kubeconfig selected via --kubeconfig.
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := kube.ContextNames(kubeconfig)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNamespaces suggests live namespace names from the cluster selected via --kubeconfig/--context.
+func completeNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	c, err := kube.NewClients(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	names, err := kube.ListNamespaceNames(ctx, c)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNodes suggests live node names for a command's first positional argument or a
+// --node flag.
+func completeNodes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	c, err := kube.NewClients(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	names, err := kube.ListNodeNames(ctx, c)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func filterPrefix(values []string, prefix string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// offlineAnnotation marks commands that never talk to the cluster (e.g. version).
+const offlineAnnotation = "kusa/offline"
+
+// needsCluster reports whether cmd talks to the cluster. Shell completion commands
+// (and their hidden __complete helper) and commands annotated offline must work
+// without a reachable cluster.
+func needsCluster(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[offlineAnnotation] == "true" {
+			return false
+		}
+		switch c.Name() {
+		case "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return false
+		}
+	}
+	return true
+}
@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/diag"
 	"github.com/amasotti/kusa/internal/kube"
 	"github.com/amasotti/kusa/internal/output"
 	"github.com/spf13/cobra"
@@ -13,6 +17,15 @@ var (
 	deploymentsIncludeSystem bool
 	deploymentsNamespace     string
 	deploymentsMinFactor     int
+	deploymentsNoLimits      string
+	deploymentsSort          string
+	deploymentsExcludeDS     bool
+	deploymentsName          string
+	deploymentsNameRegex     string
+	deploymentsByRelease     bool
+	deploymentsByArgoCDApp   bool
+	deploymentsVPA           bool
+	deploymentsExplain       bool
 )
 
 var deploymentsCmd = &cobra.Command{
@@ -24,14 +37,132 @@ Results are sorted by CPU over-request factor descending, so the biggest
 capacity offenders appear first.
 
 Pods owned by a ReplicaSet are resolved up to their parent Deployment.
-Standalone pods (no owner) are listed individually under kind "Pod".`,
+Standalone pods (no owner) are listed individually under kind "Pod", static
+pods (run by the kubelet from a manifest on the node) under kind "StaticPod".
+
+Deployments, StatefulSets and DaemonSets are also listed directly, so
+zero-replica, crash-looping or fully-Pending workloads still appear. The Pods
+column shows running/desired; workloads without running pods show the
+requests of their pod template × desired replicas and no usage.
+
+The Score column (0–100) weighs the over-request factor against the absolute
+capacity requested but unused and the pod count, so a large workload wasting
+whole cores outranks a tiny pod with an extreme ratio; --sort score orders by it.
+The Mem Over-req column is the memory request/actual factor; --sort mem-factor
+ranks by it, for clusters that run out of memory before CPU.
+The Overall column is the worse of the CPU and memory verdicts. --explain adds
+a Why column spelling out the numbers behind them, e.g. "CPU 4 req, 180m used
+(5%) → 22x; mem 2Gi req, 1.9Gi used (95%) → 1x", for readers of a report who
+should not have to trust the labels.
+
+--name 'checkout-*' (a shell glob matched against the whole name) or
+--name-regex '^(cart|checkout)-' keeps only workloads whose name matches,
+scoping the report to a product area across namespaces.
+
+--group-by-release totals the workloads per Helm release instead, since one
+chart often owns several Deployments and StatefulSets that are sized together.
+A workload's release is read from the meta.helm.sh/release-name annotation
+Helm puts on the objects it installs, or from the app.kubernetes.io/instance
+label next to app.kubernetes.io/managed-by=Helm or helm.sh/chart on the
+workload or its pods. Workloads outside a release are totalled per namespace
+under "none".
+
+--group-by-argocd-app totals them per Argo CD Application, read from the
+argocd.argoproj.io/instance label or argocd.argoproj.io/tracking-id annotation
+Argo CD sets on the objects it applies, so findings go to the Application (and
+its app-of-apps entry) that owns the manifests.
+
+--vpa adds a column with the VerticalPodAutoscaler (autoscaling.k8s.io/v1)
+targeting each workload: its update mode, and for Auto, Recreate and
+InPlaceOrRecreate how many pods it evicted or resized in place and when it last
+did, counted from the EvictedByVPA and InPlaceResizedByVPA events. The API
+server keeps events for an hour by default, so this is recent activity only.
+Workloads resized often have a VPA chasing a moving target; those it manages
+are right-sized through its resource policy, not their manifest.`,
+	Annotations: map[string]string{structuredOutputAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateNoLimits(deploymentsNoLimits); err != nil {
+			return err
+		}
+		if err := validateSort(deploymentsSort, "factor", "mem-factor"); err != nil {
+			return err
+		}
+		groupFlag := ""
+		switch {
+		case deploymentsByRelease && deploymentsByArgoCDApp:
+			return fmt.Errorf("--group-by-release cannot be combined with --group-by-argocd-app")
+		case deploymentsByRelease:
+			groupFlag = "--group-by-release"
+		case deploymentsByArgoCDApp:
+			groupFlag = "--group-by-argocd-app"
+		}
+		if groupFlag != "" && (deploymentsMinFactor != 0 || deploymentsNoLimits != "" || deploymentsExcludeDS || deploymentsSort != "factor") {
+			return fmt.Errorf("%s cannot be combined with --min-factor, --no-limits, --exclude-daemonsets or --sort", groupFlag)
+		}
+		if groupFlag != "" && (deploymentsVPA || deploymentsExplain) {
+			return fmt.Errorf("%s cannot be combined with --vpa or --explain", groupFlag)
+		}
+		if deploymentsVPA && outputFlag != output.FormatTable {
+			return fmt.Errorf("--vpa cannot be combined with --output %s", outputFlag)
+		}
+		if deploymentsExplain && outputFlag != output.FormatTable {
+			return fmt.Errorf("--explain cannot be combined with --output %s", outputFlag)
+		}
+		if groupFlag != "" && outputFlag != output.FormatTable {
+			return fmt.Errorf("%s cannot be combined with --output %s", groupFlag, outputFlag)
+		}
+		matchName, err := nameMatcher(deploymentsName, deploymentsNameRegex)
+		if err != nil {
+			return err
+		}
 		result, err := kube.FetchWorkloads(context.Background(), clients, deploymentsNamespace, deploymentsIncludeSystem)
 		if err != nil {
 			return err
 		}
-		output.RenderDeployments(result, clients.ContextName, deploymentsLimit, deploymentsMinFactor)
-		return nil
+		if matchName != nil {
+			result.Workloads = slices.DeleteFunc(result.Workloads, func(w kube.WorkloadInfo) bool {
+				return !matchName(w.Name)
+			})
+		}
+		result.Workloads = dropExceptedWorkloads(result.Workloads)
+		switch {
+		case deploymentsByRelease:
+			output.RenderReleases(analysis.GroupByRelease(result.Workloads), clients.ContextName, output.ReleasesOptions{
+				Limit:            deploymentsLimit,
+				MetricsAvailable: result.MetricsAvailable,
+			})
+			return nil
+		case deploymentsByArgoCDApp:
+			output.RenderArgoCDApps(analysis.GroupByArgoCDApp(result.Workloads), clients.ContextName, output.ArgoCDAppsOptions{
+				Limit:            deploymentsLimit,
+				MetricsAvailable: result.MetricsAvailable,
+			})
+			return nil
+		}
+		var (
+			vpas   []kube.VPAInfo
+			vpaErr error
+		)
+		if deploymentsVPA {
+			if vpas, vpaErr = kube.FetchVPAs(context.Background(), clients, deploymentsNamespace); vpaErr != nil {
+				diag.Warnf("VPA column unavailable: %v", vpaErr)
+			}
+		}
+		return output.RenderDeployments(result, clients.ContextName, output.DeploymentsOptions{
+			Limit:       deploymentsLimit,
+			MinFactor:   deploymentsMinFactor,
+			NoLimits:    deploymentsNoLimits,
+			SortByScore: deploymentsSort == "score",
+
+			SortByMemFactor: deploymentsSort == "mem-factor",
+
+			ExcludeDaemonSets: deploymentsExcludeDS,
+
+			ShowVPA: deploymentsVPA,
+			VPAs:    vpas,
+			VPAErr:  vpaErr,
+			Explain: deploymentsExplain,
+		})
 	},
 }
 
@@ -40,5 +171,15 @@ func init() {
 	deploymentsCmd.Flags().BoolVar(&deploymentsIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
 	deploymentsCmd.Flags().StringVar(&deploymentsNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
 	deploymentsCmd.Flags().IntVar(&deploymentsMinFactor, "min-factor", 0, "only show workloads where CPU req/actual >= N; negative N shows bursting workloads (actual > req); 0 disables filter")
+	deploymentsCmd.Flags().StringVar(&deploymentsName, "name", "", "only show workloads whose name matches this glob, e.g. 'checkout-*'")
+	deploymentsCmd.Flags().StringVar(&deploymentsNameRegex, "name-regex", "", "only show workloads whose name matches this regular expression")
+	addNoLimitsFlag(deploymentsCmd, &deploymentsNoLimits, "workloads")
+	addSortFlag(deploymentsCmd, &deploymentsSort, "factor", "mem-factor")
+	deploymentsCmd.Flags().BoolVar(&deploymentsExcludeDS, "exclude-daemonsets", false, "leave out DaemonSets (per-node overhead) to focus on scalable workloads")
+	deploymentsCmd.Flags().BoolVar(&deploymentsByRelease, "group-by-release", false, "total requests, usage and waste per Helm release instead of listing workloads")
+	deploymentsCmd.Flags().BoolVar(&deploymentsByArgoCDApp, "group-by-argocd-app", false, "total requests, usage and waste per Argo CD Application instead of listing workloads")
+	deploymentsCmd.Flags().BoolVar(&deploymentsVPA, "vpa", false, "add a column with each workload's VerticalPodAutoscaler and how often it recently resized its pods")
+	deploymentsCmd.Flags().BoolVar(&deploymentsExplain, "explain", false, "add a Why column with the requests, usage and factors behind each workload's verdicts")
+	_ = deploymentsCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
 	rootCmd.AddCommand(deploymentsCmd)
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Show everything kusa knows about one object",
+}
+
+var describeWorkloadCmd = &cobra.Command{
+	Use:   "workload <namespace>/<name>",
+	Short: "Show one workload's spec and its pods container by container",
+	Long: `Shows one workload in detail: the requests and limits its controller
+(Deployment, StatefulSet or DaemonSet) specifies per container, next to the
+highest usage of each container across the running pods, then every pod
+container by container with its node, QoS class, requests, limits, usage,
+verdicts and restarts (with the last OOM kill) — what a right-sizing change
+needs, on one screen.
+
+The workload is given as namespace/name or namespace/Kind/name, as for
+kusa whatif; the kind is case-insensitive and may be left out when the name
+is unambiguous. Containers whose resources differ from the spec
+(during a rollout, or changed by a VPA or admission webhook) are marked.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, kind, name, err := parseWorkloadRef(args[0])
+		if err != nil {
+			return err
+		}
+
+		var (
+			result *kube.FetchPodsResult
+			spec   *kube.WorkloadSpec
+		)
+		g, gctx := errgroup.WithContext(context.Background())
+		g.Go(func() error {
+			var err error
+			result, err = kube.FetchPods(gctx, clients, namespace)
+			return err
+		})
+		g.Go(func() error {
+			var err error
+			spec, err = kube.FetchWorkloadSpec(gctx, clients, namespace, kind, name)
+			return err
+		})
+		if err := g.Wait(); err != nil {
+			return err
+		}
+
+		if spec != nil {
+			kind = spec.Kind
+		}
+		pods := slices.DeleteFunc(result.Pods, func(p kube.PodInfo) bool {
+			return p.WorkloadName != name || (kind != "" && !strings.EqualFold(p.WorkloadKind, kind))
+		})
+		if spec == nil && len(pods) == 0 {
+			return fmt.Errorf("workload %s not found", args[0])
+		}
+		output.RenderDescribeWorkload(spec, pods, result.MetricsAvailable, args[0], clients.ContextName)
+		return nil
+	},
+}
+
+func init() {
+	describeCmd.AddCommand(describeWorkloadCmd)
+	rootCmd.AddCommand(describeCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	drainCheckMaxUtil  float64
+	drainCheckExitCode bool
+)
+
+var drainCheckCmd = &cobra.Command{
+	Use:   "drain-check <node>",
+	Short: "Simulate cordoning and draining a node",
+	Long: `Simulates cordoning and draining a node, by requests: which pods must move,
+whether the remaining schedulable nodes have request headroom for them (largest
+pods first, best fit, up to --max-util of allocatable, only on nodes the pod's
+tolerations, nodeSelector, node affinity and pod anti-affinity allow), and which
+PodDisruptionBudgets would block or slow the eviction.
+
+DaemonSet and mirror (static) pods stay with the node, as with kubectl drain.
+Nothing is changed in the cluster. Use --exit-code in scripts to fail when the
+drain is not feasible.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeNodes,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nodeName := args[0]
+
+		var (
+			result *kube.FetchNodesResult
+			pdbs   []kube.PDBInfo
+		)
+		g, gctx := errgroup.WithContext(context.Background())
+		g.Go(func() error {
+			var err error
+			result, err = kube.FetchNodes(gctx, clients, true)
+			return err
+		})
+		g.Go(func() error {
+			var err error
+			pdbs, err = kube.FetchPDBs(gctx, clients)
+			return err
+		})
+		if err := g.Wait(); err != nil {
+			return err
+		}
+
+		found := false
+		for _, n := range result.Nodes {
+			found = found || n.Name == nodeName
+		}
+		if !found {
+			return fmt.Errorf("node %q not found", nodeName)
+		}
+
+		check := analysis.CheckDrain(result.Nodes, nodeName, pdbs, drainCheckMaxUtil/100)
+		output.RenderDrainCheck(check, clients.ContextName)
+		if drainCheckExitCode && !check.Feasible() {
+			return newExitError(cmd, exitFindings, "drain-check: draining %s is not feasible", nodeName)
+		}
+		return nil
+	},
+}
+
+func init() {
+	drainCheckCmd.Flags().Float64Var(&drainCheckMaxUtil, "max-util", 100, "percent of node allocatable that requests may fill after moving pods")
+	drainCheckCmd.Flags().BoolVar(&drainCheckExitCode, "exit-code", false, "exit with status 3 when the drain is not feasible")
+	rootCmd.AddCommand(drainCheckCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/config"
+	"github.com/amasotti/kusa/internal/diag"
+	"github.com/amasotti/kusa/internal/email"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	emailToFlag []string
+
+	// smtpConfig is the smtp section of the configuration file, nil when there is none.
+	smtpConfig *config.SMTP
+)
+
+// validateEmailFlags checks that --email-to is given to a command whose reports are saved.
+// The smtp section it also needs is checked by checkEmailConfig once the config is loaded.
+func validateEmailFlags(cmd *cobra.Command) error {
+	if len(emailToFlag) == 0 {
+		return nil
+	}
+	switch {
+	case !needsCluster(cmd):
+		return fmt.Errorf("--email-to is not supported by %s", cmd.CommandPath())
+	case noSaveFlag:
+		return fmt.Errorf("--email-to cannot be combined with --no-save: the saved reports are what is sent")
+	case outputFlag != output.FormatTable:
+		return fmt.Errorf("--email-to cannot be combined with --output %s: no report is saved", outputFlag)
+	}
+	if _, err := email.ParseRecipients(emailToFlag); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkEmailConfig fails when --email-to is given without an smtp section in the config file.
+func checkEmailConfig() error {
+	if len(emailToFlag) > 0 && smtpConfig == nil {
+		return fmt.Errorf("--email-to needs an smtp section (host, from) in the config file")
+	}
+	return nil
+}
+
+// emailReports mails the reports saved by cmd to --email-to, one message with each report
+// attached.
+func emailReports(cmd *cobra.Command) error {
+	if len(emailToFlag) == 0 {
+		return nil
+	}
+	reports := output.SavedReports()
+	if len(reports) == 0 {
+		diag.Warnf("no report was saved; nothing to email")
+		return nil
+	}
+	to, err := email.ParseRecipients(emailToFlag)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	msg := email.Message{
+		From:    smtpConfig.From,
+		To:      to,
+		Subject: fmt.Sprintf("%s — %s", cmd.CommandPath(), clients.ContextName),
+	}
+	var names, list []string
+	for _, path := range reports {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read report: %w", err)
+		}
+		msg.Attachments = append(msg.Attachments, email.Attachment{Name: path, Content: content})
+		names = append(names, filepath.Base(path))
+		list = append(list, "- "+filepath.Base(path))
+	}
+	msg.Body = fmt.Sprintf("%s on context %s, run at %s.\n\nAttached:\n%s\n",
+		cmd.CommandPath(), clients.ContextName, now.UTC().Format("2006-01-02 15:04:05 UTC"), strings.Join(list, "\n"))
+
+	server := email.Server{Host: smtpConfig.Host, Port: smtpConfig.Port, Username: smtpConfig.Username}
+	if server.Username != "" {
+		server.Password = os.Getenv(smtpConfig.PasswordEnv)
+	}
+	if err := email.Send(server, msg, now); err != nil {
+		return err
+	}
+	diag.Infof("Emailed %s to %s", strings.Join(names, ", "), strings.Join(to, ", "))
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	evictionLimit         int
+	evictionIncludeSystem bool
+	evictionNamespace     string
+)
+
+var evictionCmd = &cobra.Command{
+	Use:   "eviction",
+	Short: "Rank pods by memory eviction risk",
+	Long: `Combines each pod's memory usage, request and limit, its QoS class and its
+node's MemoryPressure condition into an eviction risk verdict — who gets
+killed first when a node runs hot. This is the flip side of over-requesting:
+pods using more memory than they request are evicted first.
+
+  High    usage ≥ 90% of the memory limit (OOM kill), or above request on a
+          node that reports MemoryPressure
+  Medium  BestEffort, or usage above request
+  Low     usage within request`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := kube.FetchNodes(context.Background(), clients, true)
+		if err != nil {
+			return err
+		}
+		if !result.PodMetricsAvailable {
+			return errors.New("eviction risk requires pod metrics (is metrics-server installed?)")
+		}
+		output.RenderEviction(result, clients.ContextName, output.EvictionOptions{
+			Namespace:     evictionNamespace,
+			IncludeSystem: evictionIncludeSystem,
+			Limit:         evictionLimit,
+		})
+		return nil
+	},
+}
+
+func init() {
+	evictionCmd.Flags().IntVarP(&evictionLimit, "limit", "n", 25, "number of pods to show (0 = all)")
+	evictionCmd.Flags().BoolVar(&evictionIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	evictionCmd.Flags().StringVar(&evictionNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	_ = evictionCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(evictionCmd)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes beyond the generic failure (1), so automation can tell outcomes apart.
+const (
+	exitFindings  = 3 // the command ran successfully but reported findings (e.g. lint --exit-code)
+	exitNoMetrics = 4 // --require-metrics is set and node or pod metrics could not be read
+)
+
+// exitError carries a specific process exit code out of a command's RunE.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string { return e.msg }
+
+// newExitError returns an exitError for cmd. Usage and cobra's own error line are
+// silenced: the command ran fine, the outcome is just reported via the exit code.
+func newExitError(cmd *cobra.Command, code int, format string, args ...any) error {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return &exitError{code: code, msg: fmt.Sprintf(format, args...)}
+}
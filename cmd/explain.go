@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainHeadroom  float64
+	explainMinFactor float64
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Explain in plain language how kusa judges one object",
+}
+
+var explainPodCmd = &cobra.Command{
+	Use:   "pod <namespace>/<name>",
+	Short: "Explain the verdicts and suggested change for one pod",
+	Long: `Explains in plain language how kusa judges one running pod: its requests and
+limits per resource, the usage metrics-server last sampled, the over-request
+factor, the thresholds the CPU and memory verdicts are decided by, its
+eviction risk, and the change to its controller's pod template kusa suggests
+(the kusa recommend rule applied to this pod alone, with its confidence).
+
+The output is markdown, meant to be pasted into a ticket to the team that
+owns the pod.`,
+	Example: `  kusa explain pod shop/cart-7d9f8b-x2x4z
+  kusa explain pod shop/cart-7d9f8b-x2x4z --headroom 50`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, name, ok := strings.Cut(args[0], "/")
+		if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid pod %q: expected namespace/name", args[0])
+		}
+		if explainHeadroom < 0 {
+			return fmt.Errorf("--headroom must not be negative")
+		}
+
+		result, err := kube.FetchPods(context.Background(), clients, namespace)
+		if err != nil {
+			return err
+		}
+		var pod *kube.PodInfo
+		for i := range result.Pods {
+			if result.Pods[i].Name == name {
+				pod = &result.Pods[i]
+			}
+		}
+		if pod == nil {
+			return fmt.Errorf("pod %s not found or not running", args[0])
+		}
+
+		opts := output.ExplainOptions{Headroom: explainHeadroom / 100, MinFactor: explainMinFactor}
+		var rec *analysis.Recommendation
+		if recs := analysis.Recommend([]kube.PodInfo{*pod}, analysis.RecommendOptions{
+			Headroom:  opts.Headroom,
+			MinFactor: opts.MinFactor,
+			Samples:   clients.Sampling.Samples,
+		}); len(recs) > 0 {
+			rec = &recs[0]
+		}
+		output.RenderExplainPod(*pod, rec, result.MetricsAvailable, clients.ContextName, opts)
+		return nil
+	},
+}
+
+func init() {
+	explainPodCmd.Flags().Float64Var(&explainHeadroom, "headroom", 30, "percent added on top of usage for the suggested requests")
+	explainPodCmd.Flags().Float64Var(&explainMinFactor, "min-factor", 2, "only suggest lowering requests at least this many times the suggested value")
+	explainCmd.AddCommand(explainPodCmd)
+	rootCmd.AddCommand(explainCmd)
+}
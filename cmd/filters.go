@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/diag"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// addNoLimitsFlag registers --no-limits on cmd. A bare --no-limits means "memory",
+// the limit whose absence actually endangers neighbours (OOM on the node).
+func addNoLimitsFlag(cmd *cobra.Command, target *string, noun string) {
+	cmd.Flags().StringVar(target, "no-limits", "",
+		fmt.Sprintf("only show %s with a container lacking limits: %s (bare flag = memory)", noun, strings.Join(output.NoLimitsModes, "|")))
+	cmd.Flags().Lookup("no-limits").NoOptDefVal = "memory"
+	_ = cmd.RegisterFlagCompletionFunc("no-limits", cobra.FixedCompletions(output.NoLimitsModes, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func validateNoLimits(mode string) error {
+	if mode == "" || slices.Contains(output.NoLimitsModes, mode) {
+		return nil
+	}
+	return fmt.Errorf("invalid --no-limits %q: must be one of %s", mode, strings.Join(output.NoLimitsModes, ", "))
+}
+
+// addSortFlag registers --sort on cmd with byDefault (the table's natural order), the
+// command's other orders, or "score".
+func addSortFlag(cmd *cobra.Command, target *string, byDefault string, others ...string) {
+	modes := sortModes(byDefault, others)
+	cmd.Flags().StringVar(target, "sort", byDefault,
+		fmt.Sprintf("sort order: %s (score = 0–100 severity from factor, absolute waste and pod count)", strings.Join(modes, "|")))
+	_ = cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions(modes, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func validateSort(mode, byDefault string, others ...string) error {
+	modes := sortModes(byDefault, others)
+	if slices.Contains(modes, mode) {
+		return nil
+	}
+	return fmt.Errorf("invalid --sort %q: must be one of %s", mode, strings.Join(modes, ", "))
+}
+
+func sortModes(byDefault string, others []string) []string {
+	return append(append([]string{byDefault}, others...), "score")
+}
+
+// addCompatFlag registers --compat on cmd, which lays out the main table like kubectl top.
+func addCompatFlag(cmd *cobra.Command, target *string, noun string) {
+	cmd.Flags().StringVar(target, "compat", "",
+		fmt.Sprintf("lay %s out like kubectl top %s, plus request and verdict columns: %s", noun, noun, output.CompatTop))
+	_ = cmd.RegisterFlagCompletionFunc("compat", cobra.FixedCompletions([]string{output.CompatTop}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// validateCompat checks --compat; conflicting names the set flags that add to or replace
+// the main table, which kubectl top has no room for.
+func validateCompat(mode string, conflicting ...string) error {
+	switch {
+	case mode == "":
+		return nil
+	case mode != output.CompatTop:
+		return fmt.Errorf("invalid --compat %q: must be %s", mode, output.CompatTop)
+	case outputFlag != output.FormatTable:
+		return fmt.Errorf("--compat cannot be combined with --output %s", outputFlag)
+	case len(conflicting) > 0:
+		return fmt.Errorf("--compat cannot be combined with %s", strings.Join(conflicting, ", "))
+	}
+	return nil
+}
+
+// setFlags returns the names of the given flags of cmd that were set.
+func setFlags(cmd *cobra.Command, names ...string) []string {
+	var set []string
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			set = append(set, "--"+name)
+		}
+	}
+	return set
+}
+
+// nameMatcher builds the --name / --name-regex filter: a shell glob matched against the
+// whole name, or an unanchored regular expression. It returns nil when neither is set.
+func nameMatcher(glob, expr string) (func(string) bool, error) {
+	switch {
+	case glob != "" && expr != "":
+		return nil, fmt.Errorf("--name cannot be combined with --name-regex")
+	case glob != "":
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid --name %q: %w", glob, err)
+		}
+		return func(name string) bool {
+			ok, _ := path.Match(glob, name)
+			return ok
+		}, nil
+	case expr != "":
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --name-regex: %w", err)
+		}
+		return re.MatchString, nil
+	}
+	return nil, nil
+}
+
+// ignoredByConfig reports whether an ignore rule of the config file covers the workload.
+func ignoredByConfig(namespace, workload string) bool {
+	for _, r := range ignoreRules {
+		if r.Matches(namespace, workload) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropExceptedPods leaves out the pods ignored by the config file or annotated as accepted
+// exceptions, see analysis.Excepted, unless --include-excepted is set.
+func dropExceptedPods(pods []kube.PodInfo) []kube.PodInfo {
+	return dropPods(pods, func(p kube.PodInfo) bool {
+		return analysis.Excepted(p.Exception, p.CPURequest, p.CPUActual, p.MetricsAvailable)
+	})
+}
+
+// dropIgnoredPods leaves out the pods ignored by the config file or kusa.io/ignore, unless
+// --include-excepted is set. Unlike dropExceptedPods it keeps pods within their expected
+// over-request factor, for findings other than over-requests.
+func dropIgnoredPods(pods []kube.PodInfo) []kube.PodInfo {
+	return dropPods(pods, func(p kube.PodInfo) bool { return p.Exception.Ignore })
+}
+
+func dropPods(pods []kube.PodInfo, excepted func(kube.PodInfo) bool) []kube.PodInfo {
+	if includeExceptedFlag {
+		return pods
+	}
+	n := len(pods)
+	pods = slices.DeleteFunc(pods, func(p kube.PodInfo) bool {
+		return ignoredByConfig(p.Namespace, p.WorkloadName) || excepted(p)
+	})
+	noteExcepted(n-len(pods), "pod", "pods")
+	return pods
+}
+
+// dropExceptedWorkloads leaves out the workloads ignored by the config file or annotated as
+// accepted exceptions, see analysis.Excepted, unless --include-excepted is set.
+func dropExceptedWorkloads(workloads []kube.WorkloadInfo) []kube.WorkloadInfo {
+	if includeExceptedFlag {
+		return workloads
+	}
+	n := len(workloads)
+	workloads = slices.DeleteFunc(workloads, func(w kube.WorkloadInfo) bool {
+		return ignoredByConfig(w.Namespace, w.Name) ||
+			analysis.Excepted(w.Exception, w.CPURequest, w.CPUActual, w.MetricsAvailable && w.PodCount > 0)
+	})
+	noteExcepted(n-len(workloads), "workload", "workloads")
+	return workloads
+}
+
+func noteExcepted(n int, one, many string) {
+	if n == 0 {
+		return
+	}
+	diag.Infof("Left out %d %s ignored by the config file, annotated kusa.io/ignore or within their kusa.io/expected-factor; --include-excepted reports them.", n, kube.Plural(n, one, many))
+}
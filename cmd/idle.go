@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	idleLimit         int
+	idleIncludeSystem bool
+	idleNamespace     string
+	idleCPUThreshold  string
+	idleMemThreshold  string
+)
+
+var idleCmd = &cobra.Command{
+	Use:   "idle",
+	Short: "List idle workloads ranked by the capacity they hold",
+	Long: `Lists workloads whose actual CPU usage is ~0 (at or below --cpu-threshold per
+pod on average) and whose memory stays at its baseline (at or below
+--mem-threshold per pod), ranked by the CPU/memory requests they hold.
+
+Abandoned dev deployments and forgotten test environments are usually the
+cheapest capacity to reclaim. Usage is the metrics-server sample (its
+window is typically 15–60s), so check a few runs before deleting anything.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cpuThreshold, err := resource.ParseQuantity(idleCPUThreshold)
+		if err != nil {
+			return fmt.Errorf("invalid --cpu-threshold %q: %w", idleCPUThreshold, err)
+		}
+		var memThresholdMiB float64
+		if idleMemThreshold != "" {
+			q, err := resource.ParseQuantity(idleMemThreshold)
+			if err != nil {
+				return fmt.Errorf("invalid --mem-threshold %q: %w", idleMemThreshold, err)
+			}
+			memThresholdMiB = kube.MiBFromQuantity(q)
+		}
+
+		result, err := kube.FetchWorkloads(context.Background(), clients, idleNamespace, idleIncludeSystem)
+		if err != nil {
+			return err
+		}
+		if !result.MetricsAvailable {
+			return errors.New("idle detection requires pod metrics (is metrics-server installed?)")
+		}
+		result.Workloads = dropExceptedWorkloads(result.Workloads)
+		output.RenderIdle(result, clients.ContextName, output.IdleOptions{
+			Limit:           idleLimit,
+			MaxCPUPerPod:    kube.MillicoresFromQuantity(cpuThreshold),
+			MaxMemPerPodMiB: memThresholdMiB,
+		})
+		return nil
+	},
+}
+
+func init() {
+	idleCmd.Flags().IntVarP(&idleLimit, "limit", "n", 25, "number of idle workloads to show (0 = all)")
+	idleCmd.Flags().BoolVar(&idleIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	idleCmd.Flags().StringVar(&idleNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	idleCmd.Flags().StringVar(&idleCPUThreshold, "cpu-threshold", "5m", "max average CPU usage per pod to count as idle")
+	idleCmd.Flags().StringVar(&idleMemThreshold, "mem-threshold", "256Mi", "max average memory usage per pod to count as idle (empty = ignore memory)")
+	_ = idleCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(idleCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	limitRangeNamespace     string
+	limitRangeIncludeSystem bool
+	limitRangeLimitFactor   float64
+	limitRangeName          string
+	limitRangeYAML          string
+)
+
+var limitRangeSuggestCmd = &cobra.Command{
+	Use:   "limitrange-suggest",
+	Short: "Suggest LimitRange container defaults per namespace from median usage",
+	Long: `Derives per-namespace LimitRange defaults from the median actual usage of
+the namespace's running containers: defaultRequest.cpu and .memory are the
+median, rounded up, and default.memory (the limit) is --limit-factor times
+the memory request. No default CPU limit is suggested, since it would throttle
+every container that does not set its own.
+
+Defaults only apply to containers created without their own requests or
+limits, so new pods stop inheriting oversized org-wide defaults. Namespaces
+with fewer than three metered containers are flagged: their median says
+little.
+
+--yaml writes the LimitRange manifests to a file, or with - to stdout instead
+of the table, ready for kubectl apply -f -.`,
+	Example: `  kusa limitrange-suggest
+  kusa limitrange-suggest --namespace shop --limit-factor 1.5
+  kusa limitrange-suggest --yaml - | kubectl apply -f -`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if limitRangeLimitFactor < 1 {
+			return fmt.Errorf("--limit-factor must be at least 1 (a limit below the request is invalid)")
+		}
+
+		result, err := kube.FetchPods(context.Background(), clients, limitRangeNamespace)
+		if err != nil {
+			return err
+		}
+		if !result.MetricsAvailable {
+			return errors.New("LimitRange suggestions require pod metrics (is metrics-server installed?)")
+		}
+
+		includeSystem := limitRangeIncludeSystem || limitRangeNamespace != ""
+		var pods []kube.PodInfo
+		for _, p := range result.Pods {
+			if includeSystem || !kube.SystemNamespaces[p.Namespace] {
+				pods = append(pods, p)
+			}
+		}
+
+		suggestions := analysis.SuggestLimitRanges(pods, limitRangeLimitFactor)
+		if len(suggestions) == 0 {
+			return errors.New("no running containers with metrics found")
+		}
+		opts := output.LimitRangeOptions{Name: limitRangeName, MemLimitFactor: limitRangeLimitFactor}
+		if limitRangeYAML == "-" {
+			return output.WriteLimitRangeManifests(os.Stdout, suggestions, opts)
+		}
+		output.RenderLimitRangeSuggestions(suggestions, clients.ContextName, opts)
+		if limitRangeYAML == "" {
+			return nil
+		}
+		return writeManifestFile(limitRangeYAML, func(w io.Writer) error {
+			return output.WriteLimitRangeManifests(w, suggestions, opts)
+		})
+	},
+}
+
+func init() {
+	limitRangeSuggestCmd.Flags().StringVar(&limitRangeNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	limitRangeSuggestCmd.Flags().BoolVar(&limitRangeIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	limitRangeSuggestCmd.Flags().Float64Var(&limitRangeLimitFactor, "limit-factor", 2, "default memory limit as a multiple of the default memory request")
+	limitRangeSuggestCmd.Flags().StringVar(&limitRangeName, "name", "container-defaults", "name of the suggested LimitRange objects")
+	limitRangeSuggestCmd.Flags().StringVar(&limitRangeYAML, "yaml", "", "write the LimitRange manifests to this file (- for stdout instead of the table)")
+	_ = limitRangeSuggestCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(limitRangeSuggestCmd)
+}
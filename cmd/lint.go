@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintIncludeSystem bool
+	lintNamespace     string
+	lintExitCode      bool
+	lintLimits        bool
+	lintJUnit         string
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "List containers with no CPU or memory requests",
+	Long: `Lists every container that has no CPU and/or memory request, grouped by
+owning workload and namespace. Such containers are scheduling wildcards: the
+scheduler reserves nothing for them, so they can pile onto a node and starve
+their neighbours. Elsewhere they only show up implicitly as "no req".
+
+With --limits, containers without CPU or memory limits are reported too; a
+missing memory limit means a leaking container can take the whole node down.
+
+Use --exit-code in CI to fail when any finding is reported, and --junit to
+write the findings as JUnit XML, one failed test case per workload, for the
+CI system's test report view.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateJUnitPath(lintJUnit); err != nil {
+			return err
+		}
+		result, err := kube.FetchLint(context.Background(), clients, lintNamespace, lintIncludeSystem, lintLimits)
+		if err != nil {
+			return err
+		}
+		if !includeExceptedFlag {
+			n := len(result.Findings)
+			result.Findings = slices.DeleteFunc(result.Findings, func(f kube.LintFinding) bool {
+				return ignoredByConfig(f.Namespace, f.Workload)
+			})
+			noteExcepted(n-len(result.Findings), "finding", "findings")
+		}
+		output.RenderLint(result, clients.ContextName, lintLimits)
+		if lintJUnit != "" {
+			if err := writeJUnitFile(lintJUnit, "kusa lint", output.LintJUnitCases(result.Findings)); err != nil {
+				return err
+			}
+		}
+		if lintExitCode && len(result.Findings) > 0 {
+			return newExitError(cmd, exitFindings, "lint: %d findings", len(result.Findings))
+		}
+		return nil
+	},
+}
+
+func init() {
+	lintCmd.Flags().BoolVar(&lintIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	lintCmd.Flags().StringVar(&lintNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	lintCmd.Flags().BoolVar(&lintLimits, "limits", false, "also report containers without CPU or memory limits")
+	lintCmd.Flags().BoolVar(&lintExitCode, "exit-code", false, "exit with status 3 when any finding is reported")
+	lintCmd.Flags().StringVar(&lintJUnit, "junit", "", "also write the findings as JUnit XML to this file, one test case per workload")
+	_ = lintCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(lintCmd)
+}
+
+// validateJUnitPath rejects "-" for --junit: stdout carries the table, which the XML
+// would follow and corrupt.
+func validateJUnitPath(path string) error {
+	if path == "-" {
+		return fmt.Errorf("--junit needs a file path: stdout already carries the table")
+	}
+	return nil
+}
+
+// writeJUnitFile writes cases as a JUnit XML report to path.
+func writeJUnitFile(path, suite string, cases []output.JUnitCase) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	defer f.Close()
+	if err := output.WriteJUnit(f, suite, cases); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	output.PrintSaved(path)
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	namespacesLimit         int
+	namespacesIncludeSystem bool
+	namespacesSort          string
+)
+
+var namespacesCmd = &cobra.Command{
+	Use:   "namespaces",
+	Short: "Rank namespaces by CPU and memory requested but unused",
+	Long: `Sums requests and actual usage of the running pods per namespace and ranks
+namespaces by reclaimable capacity: request minus usage per pod, floored at 0,
+so a bursting pod does not hide an idle one. Each namespace's share of the
+cluster-wide total shows who to talk to first.
+
+--sort memory ranks by reclaimable memory instead of CPU. Pods without metrics
+count towards requests only.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if namespacesSort != "cpu" && namespacesSort != "memory" {
+			return fmt.Errorf("invalid --sort %q: must be cpu or memory", namespacesSort)
+		}
+		result, err := kube.FetchPods(context.Background(), clients, "")
+		if err != nil {
+			return err
+		}
+		if !result.MetricsAvailable {
+			return errors.New("ranking namespaces by waste requires pod metrics (is metrics-server installed?)")
+		}
+
+		var pods []kube.PodInfo
+		for _, p := range result.Pods {
+			if namespacesIncludeSystem || !kube.SystemNamespaces[p.Namespace] {
+				pods = append(pods, p)
+			}
+		}
+		pods = dropExceptedPods(pods)
+		output.RenderNamespaces(analysis.RankNamespacesByWaste(pods, namespacesSort == "memory"), clients.ContextName, output.NamespacesOptions{
+			Limit: namespacesLimit,
+		})
+		return nil
+	},
+}
+
+func init() {
+	namespacesCmd.Flags().IntVarP(&namespacesLimit, "limit", "n", 25, "number of top namespaces to show (0 = all)")
+	namespacesCmd.Flags().BoolVar(&namespacesIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	namespacesCmd.Flags().StringVar(&namespacesSort, "sort", "cpu", "rank by reclaimable cpu or memory")
+	_ = namespacesCmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{"cpu", "memory"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(namespacesCmd)
+}
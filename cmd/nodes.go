@@ -2,15 +2,32 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/diag"
 	"github.com/amasotti/kusa/internal/kube"
 	"github.com/amasotti/kusa/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
-	nodesPodOverview   bool
-	nodesIncludeSystem bool
+	nodesPodOverview      bool
+	nodesIncludeSystem    bool
+	nodesOvercommit       bool
+	nodesDaemonSets       bool
+	nodesReserved         bool
+	nodesEphemeral        bool
+	nodesDisk             bool
+	nodesMinFactor        int
+	nodesMaxCPULimitRatio float64
+	nodesMaxMemLimitRatio float64
+	nodesOS               string
+	nodesArch             string
+	nodesReclaimable      bool
+	nodesHeadroom         float64
+	nodesCompat           string
 )
 
 var nodesCmd = &cobra.Command{
@@ -18,19 +35,131 @@ var nodesCmd = &cobra.Command{
 	Short: "Compare actual vs requested resources per node",
 	Long: `Compares actual node CPU/memory usage (from metrics-server) against
 allocated (requested) resources. Surfaces nodes where pods are reserving
-far more than they consume.`,
+far more than they consume.
+
+With --overcommit, a second table shows requests/allocatable and
+limits/allocatable per node and cluster-wide. Limits above allocatable mean
+pods can collectively burst past what the node has; beyond the configured
+factor the node is flagged "Over budget". Containers without limits are not
+counted, so real overcommit may be higher.
+
+With --daemonsets, a table shows per node how much CPU/memory DaemonSet pods
+request and use: the fixed "tax" every node pays before running workloads,
+which weighs most on small nodes.
+
+Each node's OS/architecture is shown; in mixed clusters (amd64/arm64,
+Linux/Windows) a second table splits capacity by them, since pods only fit
+on nodes of their image's platform. --os and --arch restrict the view to one.
+
+With --reserved, a table shows per node the gap between capacity and
+allocatable, split into kube-reserved, system-reserved, the eviction threshold
+and hugepages as read from the kubelet config (this needs the nodes/proxy
+permission; without it only the total gap is shown).
+
+With --ephemeral, a table shows per node the ephemeral-storage (writable
+layers, logs, emptyDir) pods request and limit against allocatable, and what
+they write as read from the kubelet stats (nodes/proxy permission), with the
+same verdicts as CPU and memory.
+
+With --disk, a column shows how full each node's root filesystem (nodefs)
+and, when separate, image filesystem (imagefs) is, from the same kubelet
+stats: yellow within 10 points of the kubelet's hard eviction threshold, red
+past it or under DiskPressure. Nodes under DiskPressure are always named in
+the summary, since its evictions and unschedulable pods look like a shortage
+of CPU or memory.
+
+With --reclaimable, the summary estimates how many nodes' worth of capacity
+right-sizing every workload to its peak usage plus --headroom would free, bound
+by the scarcer of CPU and memory ("~6 of 40 nodes"). It ignores placement:
+kusa rebalance plans which nodes can actually be drained.
+
+With --compat top, the nodes are laid out like kubectl top nodes, with their
+requests as a share of allocatable and the worse of the CPU and memory
+verdicts appended; no report is saved.`,
+	Annotations: map[string]string{structuredOutputAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		result, err := kube.FetchNodes(context.Background(), clients, nodesPodOverview)
+		if err := validateCompat(nodesCompat, setFlags(cmd, "pod-overview", "overcommit", "daemonsets", "reserved", "ephemeral", "disk", "reclaimable")...); err != nil {
+			return err
+		}
+		if nodesReclaimable && outputFlag != output.FormatTable {
+			return fmt.Errorf("--reclaimable cannot be combined with --output %s", outputFlag)
+		}
+		result, err := kube.FetchNodes(context.Background(), clients, nodesPodOverview || nodesDaemonSets || nodesReclaimable)
 		if err != nil {
 			return err
 		}
-		output.RenderNodes(result, clients.ContextName, nodesIncludeSystem, nodesPodOverview)
-		return nil
+		if nodesOS != "" || nodesArch != "" {
+			result.Nodes = slices.DeleteFunc(result.Nodes, func(n kube.NodeInfo) bool {
+				return (nodesOS != "" && n.OS != nodesOS) || (nodesArch != "" && n.Arch != nodesArch)
+			})
+			if len(result.Nodes) == 0 {
+				return fmt.Errorf("no nodes match --os %q --arch %q", nodesOS, nodesArch)
+			}
+		}
+		var kubeletReserved map[string]kube.KubeletReserved
+		if nodesReserved {
+			kubeletReserved, err = kube.FetchKubeletReserved(context.Background(), clients, result.Nodes)
+			if err != nil {
+				diag.Warnf("%v; showing the total reserved capacity only", err)
+			}
+		}
+		var storage map[string]kube.NodeStorage
+		if nodesEphemeral || nodesDisk {
+			storage, err = kube.FetchNodeStorage(context.Background(), clients, result.Nodes)
+			if err != nil {
+				diag.Warnf("%v; showing no disk use", err)
+			}
+		}
+		var reclaim *analysis.ReclaimEstimate
+		if nodesReclaimable {
+			if result.PodMetricsAvailable {
+				var skip map[string]bool
+				if !nodesIncludeSystem {
+					skip = kube.SystemNamespaces
+				}
+				e := analysis.EstimateReclaimable(result.Nodes, nodesHeadroom/100, skip)
+				reclaim = &e
+			} else {
+				diag.Warnf("pod metrics unavailable; skipping the reclaimable capacity estimate")
+			}
+		}
+		return output.RenderNodes(result, clients.ContextName, output.NodesOptions{
+			IncludeSystem:    nodesIncludeSystem,
+			PodOverview:      nodesPodOverview,
+			MinFactor:        nodesMinFactor,
+			Overcommit:       nodesOvercommit,
+			DaemonSets:       nodesDaemonSets,
+			Reserved:         nodesReserved,
+			KubeletReserved:  kubeletReserved,
+			Ephemeral:        nodesEphemeral,
+			Disk:             nodesDisk,
+			Storage:          storage,
+			MaxCPULimitRatio: nodesMaxCPULimitRatio,
+			MaxMemLimitRatio: nodesMaxMemLimitRatio,
+			Reclaim:          reclaim,
+			ReclaimHeadroom:  nodesHeadroom / 100,
+			Top:              nodesCompat == output.CompatTop,
+		})
 	},
 }
 
 func init() {
 	nodesCmd.Flags().BoolVar(&nodesPodOverview, "pod-overview", false, "also output a per-node pod breakdown")
-	nodesCmd.Flags().BoolVar(&nodesIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.) in pod overview")
+	nodesCmd.Flags().BoolVar(&nodesIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.) in pod overview and --reclaimable")
+	nodesCmd.Flags().IntVar(&nodesMinFactor, "min-factor", 0, "only list pods in the pod overview where CPU req/actual >= N; negative N shows bursting pods (actual > req); 0 disables filter")
+	nodesCmd.Flags().BoolVar(&nodesOvercommit, "overcommit", false, "also output requests/allocatable and limits/allocatable ratios per node")
+	nodesCmd.Flags().BoolVar(&nodesDaemonSets, "daemonsets", false, "also output the CPU/memory DaemonSet pods take on each node")
+	nodesCmd.Flags().BoolVar(&nodesReserved, "reserved", false, "also output the capacity each node reserves for the kubelet, OS and eviction")
+	nodesCmd.Flags().BoolVar(&nodesEphemeral, "ephemeral", false, "also output ephemeral-storage requests, limits and use per node")
+	nodesCmd.Flags().BoolVar(&nodesDisk, "disk", false, "also show how full each node's nodefs and imagefs are against the kubelet's eviction threshold")
+	nodesCmd.Flags().Float64Var(&nodesMaxCPULimitRatio, "max-cpu-overcommit", 2.0, "CPU limits/allocatable ratio above which a node is over budget")
+	nodesCmd.Flags().Float64Var(&nodesMaxMemLimitRatio, "max-mem-overcommit", 1.0, "memory limits/allocatable ratio above which a node is over budget")
+	nodesCmd.Flags().BoolVar(&nodesReclaimable, "reclaimable", false, "estimate how many nodes right-sizing all workloads would free")
+	nodesCmd.Flags().Float64Var(&nodesHeadroom, "headroom", 30, "percent added on top of peak usage when right-sizing for --reclaimable")
+	nodesCmd.Flags().StringVar(&nodesOS, "os", "", "only nodes with this kubernetes.io/os (linux, windows)")
+	nodesCmd.Flags().StringVar(&nodesArch, "arch", "", "only nodes with this kubernetes.io/arch (amd64, arm64)")
+	addCompatFlag(nodesCmd, &nodesCompat, "nodes")
+	_ = nodesCmd.RegisterFlagCompletionFunc("os", cobra.FixedCompletions([]string{"linux", "windows"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = nodesCmd.RegisterFlagCompletionFunc("arch", cobra.FixedCompletions([]string{"amd64", "arm64"}, cobra.ShellCompDirectiveNoFileComp))
 	rootCmd.AddCommand(nodesCmd)
 }
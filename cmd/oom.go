@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	oomLimit         int
+	oomIncludeSystem bool
+	oomNamespace     string
+	oomMinScore      int
+	oomSince         time.Duration
+)
+
+var oomCmd = &cobra.Command{
+	Use:   "oom",
+	Short: "Rank containers by OOM-kill risk",
+	Long: `Scores each container's risk of being OOM-killed from 0 to 100, combining:
+
+  70  working set proximity to the memory limit, linear from 50% to 100%
+  20  an OOM kill within --since (current or last terminated state)
+  10  restarts, saturating at 5
+
+  High ≥ 60, Medium ≥ 30, Low below.
+
+This is the opposite question to the over-request verdicts, which only look at
+waste: a container can be massively over-requested and still sit at its limit.
+Containers without a memory limit are only killed when their node runs out of
+memory; the eviction command ranks that risk. Without metrics, containers are
+scored on their kill and restart history only.`,
+	Example: `  kusa oom
+  kusa oom --min-score 30 --since 168h
+  kusa oom --namespace shop -n 0`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if oomMinScore < 0 || oomMinScore > 100 {
+			return fmt.Errorf("--min-score must be between 0 and 100")
+		}
+
+		result, err := kube.FetchPods(context.Background(), clients, oomNamespace)
+		if err != nil {
+			return err
+		}
+		includeSystem := oomIncludeSystem || oomNamespace != ""
+		var pods []kube.PodInfo
+		for _, p := range result.Pods {
+			if includeSystem || !kube.SystemNamespaces[p.Namespace] {
+				pods = append(pods, p)
+			}
+		}
+		pods = dropIgnoredPods(pods)
+
+		risks := analysis.RankOOMRisk(pods, time.Now().Add(-oomSince))
+		output.RenderOOMRisk(risks, clients.ContextName, output.OOMOptions{
+			Limit:    oomLimit,
+			MinScore: oomMinScore,
+			Window:   oomSince,
+		})
+		return nil
+	},
+}
+
+func init() {
+	oomCmd.Flags().IntVarP(&oomLimit, "limit", "n", 25, "number of containers to show (0 = all)")
+	oomCmd.Flags().BoolVar(&oomIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	oomCmd.Flags().StringVar(&oomNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	oomCmd.Flags().IntVar(&oomMinScore, "min-score", 0, "only show containers with an OOM risk score of at least N (0–100)")
+	oomCmd.Flags().DurationVar(&oomSince, "since", 24*time.Hour, "OOM kills within this window count as recent")
+	_ = oomCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(oomCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	orphansLimit         int
+	orphansIncludeSystem bool
+	orphansNamespace     string
+	orphansMinAge        time.Duration
+)
+
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "List standalone pods without a controller owner",
+	Long: `Lists running pods that have no controller owner (no Deployment, StatefulSet,
+DaemonSet, Job or operator manages them), with their age and requests.
+
+These are typically forgotten debug pods ("kubectl run ...") that keep holding
+capacity; in the deployments table they are lost among real workloads under
+kind "Pod". Results are ranked by the CPU/memory requests they hold.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := kube.FetchPods(context.Background(), clients, orphansNamespace)
+		if err != nil {
+			return err
+		}
+		result.Pods = dropIgnoredPods(result.Pods)
+		output.RenderOrphans(result, clients.ContextName, output.OrphansOptions{
+			IncludeSystem: orphansIncludeSystem || orphansNamespace != "",
+			Limit:         orphansLimit,
+			MinAge:        orphansMinAge,
+		})
+		return nil
+	},
+}
+
+func init() {
+	orphansCmd.Flags().IntVarP(&orphansLimit, "limit", "n", 25, "number of pods to show (0 = all)")
+	orphansCmd.Flags().BoolVar(&orphansIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	orphansCmd.Flags().StringVar(&orphansNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	orphansCmd.Flags().DurationVar(&orphansMinAge, "min-age", 0, "only show pods at least this old (e.g. 24h)")
+	_ = orphansCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(orphansCmd)
+}
@@ -2,7 +2,12 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/diag"
 	"github.com/amasotti/kusa/internal/kube"
 	"github.com/amasotti/kusa/internal/output"
 	"github.com/spf13/cobra"
@@ -13,6 +18,16 @@ var (
 	podsIncludeSystem bool
 	podsNamespace     string
 	podsMinFactor     int
+	podsNoLimits      string
+	podsGroupByLabel  string
+	podsWorkload      string
+	podsSort          string
+	podsExcludeDS     bool
+	podsContainers    bool
+	podsNameRegex     string
+	podsCompat        string
+	podsNode          string
+	podsExplain       bool
 )
 
 var podsCmd = &cobra.Command{
@@ -20,23 +35,161 @@ var podsCmd = &cobra.Command{
 	Short: "List top pods by CPU request with actual usage",
 	Long: `Lists the top N pods cluster-wide by CPU request, cross-referenced with
 actual usage from metrics-server. Highlights pods with the highest
-over-request factor (CPU requested / CPU actual).`,
+over-request factor (CPU requested / CPU actual).
+
+With --group-by-label, pods are instead aggregated by the value of a label
+(e.g. team or app.kubernetes.io/part-of), taken from the pod or, when the pod
+does not carry it, from its namespace. Each group shows its requests, usage
+and waste (requested minus used), for ownership-based reporting when
+namespaces don't map 1:1 to teams.
+
+With --workload Kind/namespace/name (e.g. deployment/shop/web), only the pods
+of that controller are listed (matched by controller owner reference,
+ReplicaSets up to their Deployment): the drill-down after spotting an offender
+in deployments.
+
+With --containers, each container is listed on its own row and --min-factor,
+--no-limits and --sort apply per container: a pod can look reasonable in
+aggregate while one sidecar inside it requests 2 CPU and uses 5m. Init
+containers are not listed.
+
+With --name-regex, only pods whose name matches the regular expression are
+listed (e.g. '^payments-' for one service family); the match is unanchored
+unless the pattern says otherwise.
+
+With --node, only the pods scheduled on that node are listed: the follow-up
+when the nodes table flags one node, e.g. to see what fills it up.
+
+With --explain, a Why column spells out the request, usage, usage share and
+over-request factor behind each pod's verdicts.
+
+With --compat top, the pods are laid out like kubectl top pods -A, with their
+requests and the worse of the CPU and memory verdicts appended; no report is
+saved.`,
+	Annotations: map[string]string{structuredOutputAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		result, err := kube.FetchPods(context.Background(), clients, podsNamespace)
+		if err := validateNoLimits(podsNoLimits); err != nil {
+			return err
+		}
+		if err := validateSort(podsSort, "request"); err != nil {
+			return err
+		}
+		if podsGroupByLabel != "" && (podsMinFactor != 0 || podsNoLimits != "" || podsExcludeDS) {
+			return fmt.Errorf("--group-by-label cannot be combined with --min-factor, --no-limits or --exclude-daemonsets")
+		}
+		if podsGroupByLabel != "" && outputFlag != output.FormatTable {
+			return fmt.Errorf("--group-by-label cannot be combined with --output %s", outputFlag)
+		}
+		if podsContainers && podsGroupByLabel != "" {
+			return fmt.Errorf("--containers cannot be combined with --group-by-label")
+		}
+		if err := validateCompat(podsCompat, setFlags(cmd, "group-by-label", "containers")...); err != nil {
+			return err
+		}
+		if podsContainers && outputFlag != output.FormatTable {
+			return fmt.Errorf("--containers cannot be combined with --output %s", outputFlag)
+		}
+		if conflicting := setFlags(cmd, "group-by-label", "containers", "compat"); podsExplain && len(conflicting) > 0 {
+			return fmt.Errorf("--explain cannot be combined with %s", strings.Join(conflicting, ", "))
+		}
+		if podsExplain && outputFlag != output.FormatTable {
+			return fmt.Errorf("--explain cannot be combined with --output %s", outputFlag)
+		}
+		matchName, err := nameMatcher("", podsNameRegex)
 		if err != nil {
 			return err
 		}
+		namespace := podsNamespace
+		var workload kube.WorkloadRef
+		if podsWorkload != "" {
+			workload, err = kube.ParseWorkloadRef(podsWorkload)
+			if err != nil {
+				return err
+			}
+			if namespace != "" && namespace != workload.Namespace {
+				return fmt.Errorf("--workload %s is not in --namespace %s", podsWorkload, namespace)
+			}
+			namespace = workload.Namespace
+		}
+
+		result, err := kube.FetchNodePods(context.Background(), clients, namespace, podsNode)
+		if err != nil {
+			return err
+		}
+		if podsNode != "" && len(result.Pods) == 0 {
+			return fmt.Errorf("no running pods found on node %s", podsNode)
+		}
+		if podsWorkload != "" {
+			result.Pods = kube.WorkloadPods(result.Pods, workload)
+			if len(result.Pods) == 0 {
+				return fmt.Errorf("no running pods found for workload %s", podsWorkload)
+			}
+		}
+		if matchName != nil {
+			result.Pods = slices.DeleteFunc(result.Pods, func(p kube.PodInfo) bool {
+				return !matchName(p.Name)
+			})
+		}
+		result.Pods = dropExceptedPods(result.Pods)
 		// When scoped to a specific namespace, honour its pods regardless of system status.
-		includeSystem := podsIncludeSystem || podsNamespace != ""
-		output.RenderPods(result, clients.ContextName, includeSystem, podsLimit, podsMinFactor)
-		return nil
+		includeSystem := podsIncludeSystem || namespace != ""
+		if podsGroupByLabel != "" {
+			return renderPodsByLabel(result, includeSystem)
+		}
+		return output.RenderPods(result, clients.ContextName, output.PodsOptions{
+			IncludeSystem: includeSystem,
+			Limit:         podsLimit,
+			MinFactor:     podsMinFactor,
+			NoLimits:      podsNoLimits,
+			SortByScore:   podsSort == "score",
+
+			ExcludeDaemonSets: podsExcludeDS,
+			Containers:        podsContainers,
+			Top:               podsCompat == output.CompatTop,
+			Explain:           podsExplain,
+		})
 	},
 }
 
+// renderPodsByLabel aggregates pods by --group-by-label. Namespace labels are a fallback:
+// without permission to read them, grouping uses pod labels only.
+func renderPodsByLabel(result *kube.FetchPodsResult, includeSystem bool) error {
+	nsLabels, err := kube.FetchNamespaceLabels(context.Background(), clients, podsNamespace)
+	if err != nil {
+		diag.Warnf("%v; grouping by pod labels only", err)
+	}
+
+	var pods []kube.PodInfo
+	for _, p := range result.Pods {
+		if includeSystem || !kube.SystemNamespaces[p.Namespace] {
+			pods = append(pods, p)
+		}
+	}
+
+	output.RenderLabelGroups(analysis.GroupByLabel(pods, nsLabels, podsGroupByLabel), clients.ContextName, output.LabelGroupsOptions{
+		Label:            podsGroupByLabel,
+		Limit:            podsLimit,
+		MetricsAvailable: result.MetricsAvailable,
+	})
+	return nil
+}
+
 func init() {
 	podsCmd.Flags().IntVarP(&podsLimit, "limit", "n", 25, "number of top pods to show")
 	podsCmd.Flags().BoolVar(&podsIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
 	podsCmd.Flags().StringVar(&podsNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
 	podsCmd.Flags().IntVar(&podsMinFactor, "min-factor", 0, "only show pods where CPU req/actual >= N; negative N shows bursting pods (actual > req); 0 disables filter")
+	podsCmd.Flags().StringVar(&podsWorkload, "workload", "", "only show pods of this workload, as Kind/namespace/name, e.g. deployment/shop/web")
+	podsCmd.Flags().StringVar(&podsNameRegex, "name-regex", "", "only show pods whose name matches this regular expression")
+	podsCmd.Flags().StringVar(&podsNode, "node", "", "only show pods scheduled on this node")
+	podsCmd.Flags().StringVar(&podsGroupByLabel, "group-by-label", "", "aggregate requests, usage and waste by this pod/namespace label instead of listing pods")
+	addNoLimitsFlag(podsCmd, &podsNoLimits, "pods")
+	addSortFlag(podsCmd, &podsSort, "request")
+	podsCmd.Flags().BoolVar(&podsExcludeDS, "exclude-daemonsets", false, "leave out DaemonSet pods (per-node overhead) to focus on scalable workloads")
+	podsCmd.Flags().BoolVar(&podsContainers, "containers", false, "list one row per container; --min-factor, --no-limits and --sort then apply per container")
+	addCompatFlag(podsCmd, &podsCompat, "pods")
+	podsCmd.Flags().BoolVar(&podsExplain, "explain", false, "add a Why column with the request, usage and factor behind each pod's verdicts")
+	_ = podsCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	_ = podsCmd.RegisterFlagCompletionFunc("node", completeNodes)
 	rootCmd.AddCommand(podsCmd)
 }
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	quotaNamespace     string
+	quotaIncludeSystem bool
+	quotaBuffer        float64
+	quotaName          string
+	quotaYAML          string
+)
+
+var quotaSuggestCmd = &cobra.Command{
+	Use:   "quota-suggest",
+	Short: "Suggest ResourceQuotas per namespace from observed usage",
+	Long: `Sums the actual CPU/memory usage of each namespace's running pods, adds
+--buffer percent and suggests a ResourceQuota (requests.cpu, requests.memory
+and pods) per namespace: a data-backed starting point for introducing quotas
+in a cluster that has none.
+
+The buffer also has to cover rollouts, which briefly run surge pods next to the
+old ones. Namespaces already requesting more than the suggestion are flagged:
+a quota below current requests admits no new pods until they are right-sized.
+Usage is a point-in-time sample; --samples with --sample-aggregate max gives a
+safer basis.
+
+--yaml writes the ResourceQuota manifests to a file, or with - to stdout
+instead of the table, ready for kubectl apply -f -.`,
+	Example: `  kusa quota-suggest
+  kusa quota-suggest --buffer 50 --yaml quotas.yaml
+  kusa --samples 10 --sample-aggregate max quota-suggest --yaml - | kubectl apply -f -`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if quotaBuffer < 0 {
+			return fmt.Errorf("--buffer must not be negative")
+		}
+
+		result, err := kube.FetchPods(context.Background(), clients, quotaNamespace)
+		if err != nil {
+			return err
+		}
+		if !result.MetricsAvailable {
+			return errors.New("quota suggestions require pod metrics (is metrics-server installed?)")
+		}
+
+		includeSystem := quotaIncludeSystem || quotaNamespace != ""
+		var pods []kube.PodInfo
+		for _, p := range result.Pods {
+			if includeSystem || !kube.SystemNamespaces[p.Namespace] {
+				pods = append(pods, p)
+			}
+		}
+		if len(pods) == 0 {
+			return errors.New("no running pods found")
+		}
+
+		suggestions := analysis.SuggestQuotas(pods, quotaBuffer/100)
+		opts := output.QuotaOptions{Name: quotaName, Buffer: quotaBuffer / 100}
+		if quotaYAML == "-" {
+			return output.WriteQuotaManifests(os.Stdout, suggestions, opts)
+		}
+		output.RenderQuotaSuggestions(suggestions, clients.ContextName, opts)
+		if quotaYAML == "" {
+			return nil
+		}
+		return writeManifestFile(quotaYAML, func(w io.Writer) error {
+			return output.WriteQuotaManifests(w, suggestions, opts)
+		})
+	},
+}
+
+// writeManifestFile writes generated manifests to path.
+func writeManifestFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	defer f.Close()
+	if err := write(f); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	output.PrintSaved(path)
+	return nil
+}
+
+func init() {
+	quotaSuggestCmd.Flags().StringVar(&quotaNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	quotaSuggestCmd.Flags().BoolVar(&quotaIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	quotaSuggestCmd.Flags().Float64Var(&quotaBuffer, "buffer", 30, "percent added on top of observed usage")
+	quotaSuggestCmd.Flags().StringVar(&quotaName, "name", "compute-resources", "name of the suggested ResourceQuota objects")
+	quotaSuggestCmd.Flags().StringVar(&quotaYAML, "yaml", "", "write the ResourceQuota manifests to this file (- for stdout instead of the table)")
+	_ = quotaSuggestCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(quotaSuggestCmd)
+}
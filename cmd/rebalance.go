@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rebalanceHeadroom      float64
+	rebalanceMinFactor     float64
+	rebalanceMaxUtil       float64
+	rebalanceNoDownsize    bool
+	rebalanceIncludeSystem bool
+)
+
+var rebalanceCmd = &cobra.Command{
+	Use:   "rebalance",
+	Short: "Suggest downsizes and node drains that consolidate stranded capacity",
+	Long: `Simulates a rebalance of the cluster by requests:
+
+  1. Downsize: workloads whose requests are at least --min-factor times their
+     peak observed usage (+ --headroom) are right-sized.
+  2. Drain: the least requested node is drained when all of its pods fit on
+     the remaining nodes without filling them past --max-util (and only onto
+     nodes their placement constraints allow); repeated until no further node
+     can be freed.
+
+DaemonSet pods are not moved. Nothing is changed in the cluster: the output is
+a plan, e.g. "downsize these 4 deployments, then node X can be drained".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := kube.FetchNodes(context.Background(), clients, true)
+		if err != nil {
+			return err
+		}
+		if !rebalanceNoDownsize && !result.PodMetricsAvailable {
+			return errors.New("downsizing requires pod metrics (is metrics-server installed?); use --no-downsize to plan drains only")
+		}
+
+		var skip map[string]bool
+		if !rebalanceIncludeSystem {
+			skip = kube.SystemNamespaces
+		}
+		plan := analysis.PlanRebalance(result.Nodes, analysis.RebalanceOptions{
+			Headroom:       rebalanceHeadroom / 100,
+			MinFactor:      rebalanceMinFactor,
+			MaxUtilization: rebalanceMaxUtil / 100,
+			Downsize:       !rebalanceNoDownsize,
+			SkipNamespaces: skip,
+		})
+		output.RenderRebalance(plan, clients.ContextName)
+		return nil
+	},
+}
+
+func init() {
+	rebalanceCmd.Flags().Float64Var(&rebalanceHeadroom, "headroom", 30, "percent added on top of peak usage when right-sizing")
+	rebalanceCmd.Flags().Float64Var(&rebalanceMinFactor, "min-factor", 2, "only downsize requests at least this many times the right-sized value")
+	rebalanceCmd.Flags().Float64Var(&rebalanceMaxUtil, "max-util", 85, "percent of node allocatable that requests may fill after moving pods")
+	rebalanceCmd.Flags().BoolVar(&rebalanceNoDownsize, "no-downsize", false, "skip right-sizing and only plan node drains")
+	rebalanceCmd.Flags().BoolVar(&rebalanceIncludeSystem, "include-system", false, "also downsize workloads in system namespaces (kube-system etc.)")
+	rootCmd.AddCommand(rebalanceCmd)
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/diag"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recommendNamespace     string
+	recommendIncludeSystem bool
+	recommendHeadroom      float64
+	recommendMinFactor     float64
+	recommendGuaranteed    bool
+	recommendThrottling    bool
+	recommendMinThrottled  float64
+	recommendMaxNodeUtil   float64
+	recommendEmit          string
+	recommendEmitDir       string
+	recommendHelmValuePath string
+)
+
+// recommendEmitFormats lists the --emit formats.
+var recommendEmitFormats = []string{"kustomize", "helm"}
+
+var recommendCmd = &cobra.Command{
+	Use:   "recommend",
+	Short: "Recommend right-sized container requests and limits per workload",
+	Long: `Right-sizes the container requests of each workload to the peak usage
+observed across its pods plus --headroom, rounded up to 10m CPU and 16Mi
+memory. A request is only lowered when it is at least --min-factor times the
+recommended value.
+
+Workloads whose pods are all Guaranteed (requests == limits) get paired
+request and limit reductions: lowering the requests alone would demote them
+to Burstable. Their memory limit becomes a hard ceiling at peak + headroom,
+so prefer a sampled peak (--samples with --sample-aggregate max) as the basis.
+Limits of other workloads are left as they are. --guaranteed shows only the
+Guaranteed workloads.
+
+Containers without metrics, standalone and static pods are not right-sized.
+
+Each recommendation is rated High, Medium or Low confidence by the weakest of
+its signals: how many observations the peak rests on (pods × --samples), how
+long the pods have been running (a day, a week), how much usage varies across
+pods, and OOM kills, restarts or CPU throttling of the containers being cut.
+High confidence recommendations are safe to apply as is; the others are listed
+with what lowered them.
+
+--throttling also reads the CFS throttling counters of each node's kubelet
+(/metrics/cadvisor through the API server's node proxy, which needs the
+nodes/proxy permission). Containers throttled in at least --min-throttled
+percent of their CFS periods while none of their nodes uses more than
+--max-node-util percent of its CPU are listed with the advice to consider
+removing the CPU limit: the limit, not the node, is what slows them down.
+The counters cover each container's lifetime.
+
+--emit kustomize writes the recommendations to --emit-dir as strategic-merge
+patches, one per workload, plus a kustomization.yaml declaring them as a
+kustomize Component: add the directory to the components of the overlay that
+renders the workloads, and the right-sizing goes through GitOps review like
+any other change. Patches only set the values that change. Jobs and
+ReplicaSets are skipped, as their pod templates cannot be patched.
+
+--emit helm writes one values file per Helm release instead, for workloads
+whose pods carry the standard Helm labels (app.kubernetes.io/instance with
+app.kubernetes.io/managed-by=Helm or helm.sh/chart). Requests and limits are
+set below --helm-value-path, the chart's resources block: "resources" for
+most single-workload charts; {workload} (the workload name without the
+"<release>-" prefix) and {container} address charts that configure each
+component or container separately, e.g. "{workload}.resources". Apply a file
+with helm upgrade --reuse-values -f, so the release keeps its other values.`,
+	Example: `  kusa recommend
+  kusa recommend --guaranteed --headroom 50
+  kusa recommend --throttling --min-throttled 10
+  kusa --samples 10 --sample-aggregate max recommend --namespace shop
+  kusa recommend --emit kustomize --emit-dir overlays/prod/rightsizing
+  kusa recommend --emit helm --helm-value-path '{workload}.resources'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if recommendHeadroom < 0 {
+			return fmt.Errorf("--headroom must not be negative")
+		}
+		if recommendEmit != "" && !slices.Contains(recommendEmitFormats, recommendEmit) {
+			return fmt.Errorf("invalid --emit %q: must be one of %s", recommendEmit, strings.Join(recommendEmitFormats, ", "))
+		}
+
+		ctx := context.Background()
+		result, err := kube.FetchPods(ctx, clients, recommendNamespace)
+		if err != nil {
+			return err
+		}
+		if !result.MetricsAvailable {
+			return errors.New("recommendations require pod metrics (is metrics-server installed?)")
+		}
+
+		includeSystem := recommendIncludeSystem || recommendNamespace != ""
+		var pods []kube.PodInfo
+		for _, p := range result.Pods {
+			if !includeSystem && kube.SystemNamespaces[p.Namespace] {
+				continue
+			}
+			if recommendGuaranteed && p.QOSClass != "Guaranteed" {
+				continue
+			}
+			pods = append(pods, p)
+		}
+		pods = dropExceptedPods(pods)
+
+		var throttling map[kube.ContainerKey]kube.CPUThrottling
+		var nodes []kube.NodeInfo
+		if recommendThrottling {
+			throttling, nodes, err = fetchThrottling(ctx, pods)
+			if err != nil {
+				diag.Warnf("%v; skipping the CPU limit removal advice", err)
+			}
+		}
+
+		recs := analysis.Recommend(pods, analysis.RecommendOptions{
+			Headroom:   recommendHeadroom / 100,
+			MinFactor:  recommendMinFactor,
+			Samples:    clients.Sampling.Samples,
+			Throttling: throttling,
+		})
+		var removals []analysis.LimitRemoval
+		if throttling != nil {
+			removals = analysis.SuggestLimitRemovals(pods, throttling, nodes, analysis.ThrottlingOptions{
+				MinThrottled:       recommendMinThrottled / 100,
+				MaxNodeUtilization: recommendMaxNodeUtil / 100,
+			})
+		}
+		output.RenderRecommendations(recs, removals, clients.ContextName, output.RecommendOptions{
+			Headroom:       recommendHeadroom / 100,
+			GuaranteedOnly: recommendGuaranteed,
+			Throttling:     throttling != nil,
+			MinThrottled:   recommendMinThrottled / 100,
+		})
+		switch recommendEmit {
+		case "kustomize":
+			return emitKustomize(recommendEmitDir, recs)
+		case "helm":
+			return emitHelm(recommendEmitDir, recs, result.Pods)
+		}
+		return nil
+	},
+}
+
+// emitKustomize writes a patch per recommendation and the kustomization.yaml listing them
+// to dir.
+func emitKustomize(dir string, recs []analysis.Recommendation) error {
+	var patches []string
+	for _, r := range recs {
+		if !output.Patchable(r.Kind) {
+			diag.Warnf("%s/%s/%s: the pod template of a %s cannot be patched; skipping", r.Namespace, r.Kind, r.Name, r.Kind)
+			continue
+		}
+		patches = append(patches, output.KustomizePatchName(r))
+	}
+	if len(patches) == 0 {
+		diag.Warnf("no recommendations to emit")
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to write patches: %w", err)
+	}
+	for _, r := range recs {
+		if !output.Patchable(r.Kind) {
+			continue
+		}
+		if err := writeManifestFile(filepath.Join(dir, output.KustomizePatchName(r)), func(w io.Writer) error {
+			return output.WriteKustomizePatch(w, r, recommendHeadroom/100)
+		}); err != nil {
+			return err
+		}
+	}
+	return writeManifestFile(filepath.Join(dir, "kustomization.yaml"), func(w io.Writer) error {
+		return output.WriteKustomization(w, patches)
+	})
+}
+
+// emitHelm writes a values file per Helm release of recs to dir; pods tell which other
+// containers share the values keys.
+func emitHelm(dir string, recs []analysis.Recommendation, pods []kube.PodInfo) error {
+	for _, r := range recs {
+		if r.Release == "" {
+			diag.Warnf("%s/%s/%s: pods carry no Helm release labels; skipping", r.Namespace, r.Kind, r.Name)
+		}
+	}
+	overrides, conflicts := output.HelmOverrides(recs, pods, recommendHelmValuePath)
+	for _, c := range conflicts {
+		diag.Warnf("%s: several containers map to this key, keeping the largest value; add {workload} or {container} to --helm-value-path", c)
+	}
+	if len(overrides) == 0 {
+		diag.Warnf("no recommendations to emit")
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to write values: %w", err)
+	}
+	for _, o := range overrides {
+		if err := writeManifestFile(filepath.Join(dir, output.HelmValuesName(o)), func(w io.Writer) error {
+			return output.WriteHelmValues(w, o, recommendHeadroom/100)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchThrottling reads the CPU throttling of the containers of pods and the nodes they run
+// on, whose CPU usage decides whether removing a limit lets them burst.
+func fetchThrottling(ctx context.Context, pods []kube.PodInfo) (map[kube.ContainerKey]kube.CPUThrottling, []kube.NodeInfo, error) {
+	nodes, err := kube.FetchNodes(ctx, clients, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range pods {
+		if p.NodeName != "" && !seen[p.NodeName] {
+			seen[p.NodeName] = true
+			names = append(names, p.NodeName)
+		}
+	}
+	throttling, err := kube.FetchCPUThrottling(ctx, clients, names)
+	if err != nil {
+		return nil, nil, err
+	}
+	return throttling, nodes.Nodes, nil
+}
+
+func init() {
+	recommendCmd.Flags().StringVar(&recommendNamespace, "namespace", "", "filter by namespace (default: all namespaces)")
+	recommendCmd.Flags().BoolVar(&recommendIncludeSystem, "include-system", false, "include system namespaces (kube-system etc.)")
+	recommendCmd.Flags().Float64Var(&recommendHeadroom, "headroom", 30, "percent added on top of peak usage")
+	recommendCmd.Flags().Float64Var(&recommendMinFactor, "min-factor", 2, "only lower requests at least this many times the recommended value")
+	recommendCmd.Flags().BoolVar(&recommendGuaranteed, "guaranteed", false, "only Guaranteed workloads (requests == limits)")
+	recommendCmd.Flags().BoolVar(&recommendThrottling, "throttling", false, "read CPU throttling from the kubelets and advise on CPU limits worth removing")
+	recommendCmd.Flags().Float64Var(&recommendMinThrottled, "min-throttled", 25, "percent of CFS periods throttled from which a CPU limit counts as hurting")
+	recommendCmd.Flags().Float64Var(&recommendMaxNodeUtil, "max-node-util", 70, "percent of node CPU in use above which a node has no slack to burst into")
+	recommendCmd.Flags().StringVar(&recommendEmit, "emit", "", "also write the recommendations as files: "+strings.Join(recommendEmitFormats, "|"))
+	recommendCmd.Flags().StringVar(&recommendEmitDir, "emit-dir", "rightsizing", "directory --emit writes to")
+	recommendCmd.Flags().StringVar(&recommendHelmValuePath, "helm-value-path", output.DefaultHelmValuePath, "dotted values path of the chart's resources block for --emit helm; {workload} and {container} are replaced")
+	_ = recommendCmd.RegisterFlagCompletionFunc("emit", cobra.FixedCompletions(recommendEmitFormats, cobra.ShellCompDirectiveNoFileComp))
+	_ = recommendCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	rootCmd.AddCommand(recommendCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var reportsDryRun bool
+
+var reportsCmd = &cobra.Command{
+	Use:         "reports",
+	Short:       "Manage saved markdown reports",
+	Annotations: map[string]string{offlineAnnotation: "true"},
+}
+
+var reportsCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove old reports from the output directory",
+	Long: `Applies --keep-last and/or --max-age to the timestamped reports under
+output/, per command and context. Without --context every context directory
+is cleaned. Files that are not timestamped reports are left alone.
+
+The same flags can be passed to any command to prune its own reports right
+after saving, so scheduled runs need no external cleanup cron.`,
+	Example: `  kusa reports clean --keep-last 10
+  kusa reports clean --max-age 30d --dry-run`,
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := retentionFromFlags()
+		if err != nil {
+			return err
+		}
+		if !r.Enabled() {
+			return errors.New("nothing to clean: set --keep-last and/or --max-age")
+		}
+
+		removed, err := output.PruneReports(kubeContext, r, reportsDryRun)
+		verb := "Removed"
+		if reportsDryRun {
+			verb = "Would remove"
+		}
+		for _, p := range removed {
+			fmt.Printf("%s: %s\n", verb, p)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %d reports.\n", verb, len(removed))
+		return nil
+	},
+}
+
+// retentionFromFlags builds the report retention rules from --keep-last and --max-age.
+func retentionFromFlags() (output.Retention, error) {
+	if keepLast < 0 {
+		return output.Retention{}, fmt.Errorf("invalid --keep-last %d: must not be negative", keepLast)
+	}
+	r := output.Retention{KeepLast: keepLast}
+	if maxAgeFlag != "" {
+		age, err := parseAge(maxAgeFlag)
+		if err != nil {
+			return r, fmt.Errorf("invalid --max-age %q: %w", maxAgeFlag, err)
+		}
+		r.MaxAge = age
+	}
+	return r, nil
+}
+
+// parseAge parses a Go duration, additionally accepting whole days ("30d").
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, errors.New("expected a positive number of days, e.g. 30d")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err == nil && d <= 0 {
+		err = errors.New("must be positive")
+	}
+	return d, err
+}
+
+func init() {
+	reportsCleanCmd.Flags().BoolVar(&reportsDryRun, "dry-run", false, "only list the reports that would be removed")
+	reportsCmd.AddCommand(reportsCleanCmd)
+	rootCmd.AddCommand(reportsCmd)
+}
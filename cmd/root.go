@@ -1,19 +1,55 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/amasotti/kusa/internal/config"
+	"github.com/amasotti/kusa/internal/diag"
 	"github.com/amasotti/kusa/internal/kube"
 	"github.com/amasotti/kusa/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
-	kubeconfig  string
-	kubeContext string
-	noColorFlag bool
-	clients     *kube.Clients
+	kubeconfig      string
+	kubeContext     string
+	noColorFlag     bool
+	timingFlag      bool
+	keepLast        int
+	maxAgeFlag      string
+	latestFlag      bool
+	frontMatterFlag bool
+	mermaidFlag     bool
+	demoFlag        bool
+	snapshotFlag    string
+	outputFlag      string
+
+	samplesFlag         int
+	sampleIntervalFlag  time.Duration
+	sampleAggregateFlag string
+	maxMetricsAgeFlag   time.Duration
+	requireMetricsFlag  bool
+	concurrencyFlag     int
+	cacheFlag           time.Duration
+	quietFlag           bool
+	noSaveFlag          bool
+	decimalsFlag        int
+	pctDecimalsFlag     int
+	unitsFlag           string
+
+	contextNamespaceFlag bool
+	allNamespacesFlag    bool
+	includeExceptedFlag  bool
+	configFlag           string
+
+	// ignoreRules are the unexpired ignore rules of the configuration file.
+	ignoreRules []config.IgnoreRule
+
+	clients *kube.Clients
 )
 
 var rootCmd = &cobra.Command{
@@ -24,13 +60,83 @@ resources in your Kubernetes cluster. This gap is the root cause of
 "no resources available" errors on under-utilized clusters: pods reserve
 far more than they need, blocking scheduling for others.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		diag.SetQuiet(quietFlag)
 		_, noColorEnv := os.LookupEnv("NO_COLOR")
-		output.SetNoColor(noColorFlag || noColorEnv)
+		piped := !isTerminal(os.Stdout)
+		output.SetNoColor(noColorFlag || noColorEnv || piped)
+		output.SetPlain(piped)
+		output.SetNoSave(noSaveFlag)
+		if err := applyFormatting(cmd); err != nil {
+			return err
+		}
 
-		var err error
-		clients, err = kube.NewClients(kubeconfig, kubeContext)
+		r, err := retentionFromFlags()
 		if err != nil {
-			return fmt.Errorf("failed to connect to cluster: %w", err)
+			return err
+		}
+		output.SetRetention(r)
+		output.SetWriteLatest(latestFlag)
+		output.SetFrontMatter(frontMatterFlag)
+		output.SetMermaid(mermaidFlag)
+		if err := validateOutput(cmd); err != nil {
+			return err
+		}
+		if err := validateEmailFlags(cmd); err != nil {
+			return err
+		}
+
+		if !needsCluster(cmd) {
+			return nil
+		}
+		if err := loadConfig(); err != nil {
+			return err
+		}
+		if err := checkEmailConfig(); err != nil {
+			return err
+		}
+
+		sampling, err := samplingFromFlags()
+		if err != nil {
+			return err
+		}
+		if concurrencyFlag < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
+		}
+
+		switch {
+		case demoFlag && snapshotFlag != "":
+			return fmt.Errorf("--from-snapshot cannot be combined with --demo")
+		case snapshotFlag != "" && (samplesFlag > 1 || cacheFlag != 0):
+			return fmt.Errorf("--from-snapshot cannot be combined with --samples or --cache: the snapshot holds the metrics it was taken with")
+		case demoFlag:
+			clients = kube.NewDemoClients()
+		case snapshotFlag != "":
+			snapshot, err := kube.LoadSnapshot(snapshotFlag)
+			if err != nil {
+				return err
+			}
+			clients = kube.NewSnapshotClients(snapshot)
+			diag.Infof("Reading snapshot of %s taken %s", snapshot.Context, snapshot.TakenAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+		default:
+			cache, err := responseCacheFromFlags()
+			if err != nil {
+				return err
+			}
+			clients, err = kube.NewCachedClients(kubeconfig, kubeContext, cache)
+			if err != nil {
+				return fmt.Errorf("failed to connect to cluster: %w", err)
+			}
+		}
+		clients.Sampling = sampling
+		clients.MaxMetricsAge = maxMetricsAgeFlag
+		clients.RequireMetrics = requireMetricsFlag
+		clients.Concurrency = concurrencyFlag
+		if err := applyContextNamespace(cmd); err != nil {
+			return err
+		}
+		if sampling.Samples > 1 {
+			diag.Infof("Sampling metrics %d times, %s apart (using the %s)...",
+				sampling.Samples, sampling.Interval, map[bool]string{false: "average", true: "peak"}[sampling.Peak])
 		}
 		return nil
 	},
@@ -38,8 +144,29 @@ far more than they need, blocking scheduling for others.`,
 
 // Execute runs the root command.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	cmd, err := rootCmd.ExecuteC()
+	// Timings are rendered here rather than in a post-run hook, which cobra skips when the
+	// command fails: a slow or failing run is when they matter most.
+	if timingFlag && clients != nil {
+		output.RenderTimings(os.Stderr, clients.Timings.Calls())
+	}
+	// Reports are mailed after a failed run too: check regressions, lint findings and
+	// infeasible drains exit non-zero, and are what is most worth mailing.
+	if err == nil || len(output.SavedReports()) > 0 {
+		if mailErr := emailReports(cmd); mailErr != nil {
+			err = errors.Join(err, mailErr)
+		}
+	}
+	diag.Flush()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		if errors.Is(err, kube.ErrMetricsUnavailable) {
+			os.Exit(exitNoMetrics)
+		}
 		os.Exit(1)
 	}
 }
@@ -47,5 +174,159 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "path to kubeconfig file (default: ~/.kube/config)")
 	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "Kubernetes context to use (default: current context)")
-	rootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "disable ANSI color output (also honoured via NO_COLOR env var)")
+	rootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "disable ANSI color output (also honoured via NO_COLOR env var; automatic when stdout is not a terminal)")
+
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "suppress warnings and progress notices (errors are still reported)")
+	rootCmd.PersistentFlags().BoolVar(&timingFlag, "timing", false, "report duration, object and HTTP request counts and throttling for each API call (to stderr)")
+
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", output.FormatTable,
+		fmt.Sprintf("output format: %s (machine-readable rows on stdout instead of tables and saved reports)", strings.Join(output.Formats, "|")))
+	rootCmd.PersistentFlags().BoolVar(&demoFlag, "demo", false, "use a synthetic demo cluster instead of connecting to one")
+	rootCmd.PersistentFlags().StringVar(&snapshotFlag, "from-snapshot", "", "render from a file written by kusa snapshot instead of connecting to a cluster")
+	rootCmd.PersistentFlags().IntVar(&decimalsFlag, "decimals", kube.AutoDecimals, "show CPU cores and GiB with this many decimals, whole values included (default: 2 for cores, 1 for GiB, none when whole)")
+	rootCmd.PersistentFlags().IntVar(&pctDecimalsFlag, "pct-decimals", 0, "show percentages with this many decimals")
+	rootCmd.PersistentFlags().StringVar(&unitsFlag, "units", kube.UnitsBinary, "memory units: binary (Mi, Gi) or decimal (MB, GB, as in cloud billing)")
+	rootCmd.PersistentFlags().BoolVar(&frontMatterFlag, "front-matter", false, "start saved reports with YAML front matter (title, date, context, tags) for Obsidian/Hugo")
+	rootCmd.PersistentFlags().BoolVar(&mermaidFlag, "mermaid", false, "embed mermaid charts (requested vs actual, waste) in saved reports")
+	rootCmd.PersistentFlags().BoolVar(&noSaveFlag, "no-save", false, "do not save reports under output/, e.g. in scripts that only read stdout")
+	rootCmd.PersistentFlags().BoolVar(&latestFlag, "latest", false, "also overwrite output/<context>/<command>_latest.md with each report")
+	rootCmd.PersistentFlags().IntVar(&keepLast, "keep-last", 0, "keep only the N most recent reports per command and context (0 = all)")
+	rootCmd.PersistentFlags().StringSliceVar(&emailToFlag, "email-to", nil, "email the saved reports to these addresses, via the smtp section of the config file")
+	rootCmd.PersistentFlags().StringVar(&maxAgeFlag, "max-age", "", "remove reports older than this, e.g. 30d or 12h (default: keep)")
+
+	rootCmd.PersistentFlags().IntVar(&samplesFlag, "samples", 1, "poll the metrics API this many times and aggregate, smoothing a single scrape")
+	rootCmd.PersistentFlags().DurationVar(&sampleIntervalFlag, "sample-interval", 30*time.Second, "wait between metrics samples")
+	rootCmd.PersistentFlags().StringVar(&sampleAggregateFlag, "sample-aggregate", "avg", "how to combine metrics samples: avg or max")
+
+	rootCmd.PersistentFlags().DurationVar(&cacheFlag, "cache", 0, "reuse cluster-wide lists (pods, nodes, metrics) fetched by earlier runs within this long, e.g. 5m (0 = off); stored redacted in the user cache directory")
+	rootCmd.PersistentFlags().BoolVar(&requireMetricsFlag, "require-metrics", false, "fail with exit status 4 instead of reporting N/A when node or pod metrics cannot be read")
+	rootCmd.PersistentFlags().DurationVar(&maxMetricsAgeFlag, "max-metrics-age", kube.DefaultMaxMetricsAge, "warn about and mark metrics samples older than this (0 = never)")
+
+	rootCmd.PersistentFlags().IntVar(&concurrencyFlag, "concurrency", kube.DefaultConcurrency, "namespaces fetched in parallel when cluster-wide listing is forbidden; lower it to spare a shared API server")
+	rootCmd.PersistentFlags().BoolVar(&contextNamespaceFlag, "context-namespace", false, "default --namespace to the namespace of the kubeconfig context, like kubectl")
+	rootCmd.PersistentFlags().BoolVarP(&allNamespacesFlag, "all-namespaces", "A", false, "ignore the context namespace and analyze all namespaces")
+	rootCmd.PersistentFlags().BoolVar(&includeExceptedFlag, "include-excepted", false, "also report pods and workloads ignored by the config file or annotated as accepted exceptions (kusa.io/ignore, kusa.io/expected-factor)")
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "path to the config file (default: kusa/config.yaml in the user config directory, if present)")
+
+	_ = rootCmd.RegisterFlagCompletionFunc("context", completeContexts)
+	_ = rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(output.Formats, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("units", cobra.FixedCompletions([]string{kube.UnitsBinary, kube.UnitsDecimal}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("sample-aggregate", cobra.FixedCompletions([]string{"avg", "max"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// maxDecimals bounds --decimals and --pct-decimals; quantities carry no more precision.
+const maxDecimals = 3
+
+// applyFormatting validates --decimals, --pct-decimals and --units and applies them to the
+// formatters.
+func applyFormatting(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("decimals") && (decimalsFlag < 0 || decimalsFlag > maxDecimals) {
+		return fmt.Errorf("--decimals must be between 0 and %d", maxDecimals)
+	}
+	if pctDecimalsFlag < 0 || pctDecimalsFlag > maxDecimals {
+		return fmt.Errorf("--pct-decimals must be between 0 and %d", maxDecimals)
+	}
+	if unitsFlag != kube.UnitsBinary && unitsFlag != kube.UnitsDecimal {
+		return fmt.Errorf("--units must be %s or %s", kube.UnitsBinary, kube.UnitsDecimal)
+	}
+	kube.SetUnits(unitsFlag)
+	kube.SetDecimals(decimalsFlag)
+	output.SetPctDecimals(pctDecimalsFlag)
+	return nil
+}
+
+// isTerminal reports whether f is a character device (a terminal) rather than a pipe
+// or file, so colors are only written where they are rendered.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// structuredOutputAnnotation marks commands whose results can be written with --output
+// other than table.
+const structuredOutputAnnotation = "kusa/structured-output"
+
+// validateOutput parses --output and checks that cmd supports it.
+func validateOutput(cmd *cobra.Command) error {
+	if err := output.SetFormat(outputFlag); err != nil {
+		return err
+	}
+	if outputFlag != output.FormatTable && cmd.Annotations[structuredOutputAnnotation] != "true" {
+		name, _, _ := strings.Cut(outputFlag, "=")
+		return fmt.Errorf("--output %s is not supported by %s (only pods, deployments and nodes)", name, cmd.CommandPath())
+	}
+	return nil
+}
+
+// applyContextNamespace sets an unset --namespace of cmd to the kubeconfig context's
+// namespace when --context-namespace is given and -A is not.
+func applyContextNamespace(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("namespace")
+	if !contextNamespaceFlag || allNamespacesFlag || flag == nil || flag.Changed || clients.Namespace == "" {
+		return nil
+	}
+	if err := cmd.Flags().Set("namespace", clients.Namespace); err != nil {
+		return err
+	}
+	diag.Infof("Using namespace %q from context %s (-A for all namespaces).", clients.Namespace, clients.ContextName)
+	return nil
+}
+
+// loadConfig reads --config, or the default config file when it exists, and keeps its
+// smtp section and unexpired ignore rules. Expired rules are warned about: their matches are reported again.
+func loadConfig() error {
+	file, optional := configFlag, false
+	if file == "" {
+		var err error
+		if file, err = config.DefaultPath(); err != nil {
+			return nil // no home directory: no default config either
+		}
+		optional = true
+	}
+	cfg, err := config.Load(file, optional)
+	if err != nil {
+		return err
+	}
+	smtpConfig = cfg.SMTP
+	var expired []config.IgnoreRule
+	ignoreRules, expired = cfg.ActiveIgnores(time.Now())
+	for _, r := range expired {
+		reason := ""
+		if r.Reason != "" {
+			reason = " (" + r.Reason + ")"
+		}
+		diag.Warnf("ignore rule %s%s in %s expired on %s; its matches are reported again", r, reason, file, r.Expires)
+	}
+	return nil
+}
+
+// responseCacheFromFlags validates --cache.
+func responseCacheFromFlags() (kube.ResponseCache, error) {
+	switch {
+	case cacheFlag < 0:
+		return kube.ResponseCache{}, fmt.Errorf("--cache must not be negative")
+	case cacheFlag == 0:
+		return kube.ResponseCache{}, nil
+	case samplesFlag > 1:
+		return kube.ResponseCache{}, fmt.Errorf("--cache cannot be combined with --samples: cached metrics would repeat the same sample")
+	}
+	dir, err := kube.DefaultCacheDir()
+	if err != nil {
+		return kube.ResponseCache{}, fmt.Errorf("failed to locate the cache directory: %w", err)
+	}
+	return kube.ResponseCache{Dir: dir, TTL: cacheFlag}, nil
+}
+
+// samplingFromFlags validates --samples, --sample-interval and --sample-aggregate.
+func samplingFromFlags() (kube.Sampling, error) {
+	if samplesFlag < 1 {
+		return kube.Sampling{}, fmt.Errorf("--samples must be at least 1")
+	}
+	if samplesFlag > 1 && sampleIntervalFlag <= 0 {
+		return kube.Sampling{}, fmt.Errorf("--sample-interval must be positive")
+	}
+	if sampleAggregateFlag != "avg" && sampleAggregateFlag != "max" {
+		return kube.Sampling{}, fmt.Errorf("--sample-aggregate must be avg or max, got %q", sampleAggregateFlag)
+	}
+	return kube.Sampling{Samples: samplesFlag, Interval: sampleIntervalFlag, Peak: sampleAggregateFlag == "max"}, nil
 }
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the machine-readable output",
+	Long: `Prints the JSON Schema (draft 2020-12) of the rows written by pods,
+deployments and nodes with --output ndjson, go-template or jsonpath, to
+validate or generate code against. The schema is versioned: fields may be
+added within a version, while renames, removals and type changes bump it.`,
+	Example:     `  kusa schema > kusa-records.schema.json`,
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	Args:        cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _ = cmd.OutOrStdout().Write(output.Schema())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot <file>",
+	Short: "Save the cluster state for rendering reports offline",
+	Long: `Lists the nodes, pods, workload controllers, PodDisruptionBudgets, namespaces
+and metrics of the whole cluster once and saves them as JSON. Every command
+renders from the file with --from-snapshot, without connecting to the cluster:
+other views, filters and formats from one collection, or an investigation shared
+with someone who has no access to it.
+
+Kubelet data is not saved, so reserved resources and CPU throttling are
+reported as unavailable. Ages are computed against the time a report is
+rendered, not the time the snapshot was taken.
+
+Container commands, arguments, environment and volume mounts, pod volumes,
+managed fields and the last-applied-configuration annotation are stripped, as
+no report reads them and they may hold secrets. The file is readable by its
+owner only.`,
+	Example: `  kusa snapshot prod.json
+  kusa --from-snapshot prod.json deployments --min-factor 5`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshot, err := kube.TakeSnapshot(context.Background(), clients)
+		if err != nil {
+			return err
+		}
+		if err := kube.SaveSnapshot(args[0], snapshot); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		output.PrintSaved(fmt.Sprintf("%s (%d nodes, %d pods)", args[0], len(snapshot.Nodes), len(snapshot.Pods)))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// Build metadata, injected at build time via:
+//
+//	go build -ldflags "-X github.com/amasotti/kusa/cmd.version=v0.2.0 -X github.com/amasotti/kusa/cmd.commit=$(git rev-parse --short HEAD) -X github.com/amasotti/kusa/cmd.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version = "dev"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
+var versionCmd = &cobra.Command{
+	Use:         "version",
+	Short:       "Print version and build information",
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	Args:        cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprint(cmd.OutOrStdout(), versionInfo())
+	},
+}
+
+// versionInfo returns the multi-line build information shown by `kusa version` and `kusa --version`.
+func versionInfo() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "kusa %s\n", resolvedVersion())
+	fmt.Fprintf(&b, "  commit:      %s\n", commit)
+	fmt.Fprintf(&b, "  built:       %s\n", date)
+	fmt.Fprintf(&b, "  go:          %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "  client-go:   %s\n", depVersion("k8s.io/client-go"))
+	fmt.Fprintf(&b, "  metrics API: %s (k8s.io/metrics %s)\n", metricsv1beta1.SchemeGroupVersion, depVersion("k8s.io/metrics"))
+	return b.String()
+}
+
+// resolvedVersion prefers the ldflags-injected version and falls back to the module
+// version recorded by `go install module@version`.
+func resolvedVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
+
+// depVersion returns the version of a module dependency compiled into the binary.
+func depVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+func init() {
+	rootCmd.Version = resolvedVersion()
+	rootCmd.SetVersionTemplate(versionInfo())
+	rootCmd.AddCommand(versionCmd)
+}
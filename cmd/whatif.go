@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/amasotti/kusa/internal/output"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	whatIfWorkload   string
+	whatIfReplicas   int
+	whatIfCPURequest string
+	whatIfMemRequest string
+)
+
+var whatIfCmd = &cobra.Command{
+	Use:   "whatif",
+	Short: "Simulate scaling or resizing a workload",
+	Long: `Recomputes node and cluster request utilization under a hypothetical change
+to one workload — a new replica count and/or new per-pod requests — and reports
+whether it fits and which nodes absorb the pods.
+
+The workload is given as namespace/name, or namespace/Kind/name when the name is
+ambiguous. Its pods are removed and re-placed the way the default scheduler
+scores nodes (least allocated first), by requests, on schedulable nodes the
+pods' placement constraints allow: tolerated taints, nodeSelector, required node
+affinity and required pod anti-affinity (a workload without running pods has
+none).
+Unchanged values default to the workload's current ones; a workload without
+running pods needs --cpu-request and --mem-request.`,
+	Example: `  kusa whatif --workload shop/web --replicas 20
+  kusa whatif --workload shop/web --cpu-request 250m
+  kusa whatif --workload shop/StatefulSet/db --replicas 3 --mem-request 8Gi`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, kind, name, err := parseWorkloadRef(whatIfWorkload)
+		if err != nil {
+			return err
+		}
+
+		result, err := kube.FetchNodes(context.Background(), clients, true)
+		if err != nil {
+			return err
+		}
+
+		pods := analysis.WorkloadPods(result.Nodes, namespace, kind, name)
+		change := analysis.WhatIfChange{Namespace: namespace, Kind: kind, Name: name, Replicas: len(pods)}
+		for _, p := range pods {
+			if change.Kind != "" && change.Kind != p.WorkloadKind {
+				return fmt.Errorf("%s/%s matches both a %s and a %s; use namespace/Kind/name", namespace, name, change.Kind, p.WorkloadKind)
+			}
+			change.Kind = p.WorkloadKind
+			change.Labels = p.Labels
+			change.Tolerations = p.Tolerations
+			change.NodeSelector = p.NodeSelector
+			change.Affinity = p.Affinity
+			change.CPURequest = max(change.CPURequest, p.CPURequest)
+			change.MemRequest = max(change.MemRequest, p.MemRequest)
+		}
+
+		if cmd.Flags().Changed("cpu-request") {
+			q, err := resource.ParseQuantity(whatIfCPURequest)
+			if err != nil {
+				return fmt.Errorf("invalid --cpu-request %q: %w", whatIfCPURequest, err)
+			}
+			change.CPURequest = kube.MillicoresFromQuantity(q)
+		}
+		if cmd.Flags().Changed("mem-request") {
+			q, err := resource.ParseQuantity(whatIfMemRequest)
+			if err != nil {
+				return fmt.Errorf("invalid --mem-request %q: %w", whatIfMemRequest, err)
+			}
+			change.MemRequest = kube.MiBFromQuantity(q)
+		}
+		if len(pods) == 0 {
+			if !cmd.Flags().Changed("cpu-request") || !cmd.Flags().Changed("mem-request") {
+				return fmt.Errorf("no running pods found for %s; pass --cpu-request and --mem-request", whatIfWorkload)
+			}
+			if change.Kind == "" {
+				change.Kind = "Deployment"
+			}
+		}
+		if cmd.Flags().Changed("replicas") {
+			if change.Kind == "DaemonSet" {
+				return errors.New("--replicas does not apply to a DaemonSet (one pod per node)")
+			}
+			if whatIfReplicas < 0 {
+				return errors.New("--replicas must not be negative")
+			}
+			change.Replicas = whatIfReplicas
+		}
+
+		sim := analysis.SimulateWhatIf(result.Nodes, change)
+		output.RenderWhatIf(sim, change, clients.ContextName)
+		return nil
+	},
+}
+
+// parseWorkloadRef splits "namespace/name" or "namespace/Kind/name"; kind is "" when omitted.
+func parseWorkloadRef(ref string) (namespace, kind, name string, err error) {
+	parts := strings.Split(ref, "/")
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return parts[0], "", parts[1], nil
+	case len(parts) == 3 && parts[0] != "" && parts[1] != "" && parts[2] != "":
+		return parts[0], parts[1], parts[2], nil
+	default:
+		return "", "", "", fmt.Errorf("invalid workload %q: expected namespace/name or namespace/Kind/name", ref)
+	}
+}
+
+func init() {
+	whatIfCmd.Flags().StringVar(&whatIfWorkload, "workload", "", "workload to change, as namespace/name or namespace/Kind/name")
+	whatIfCmd.Flags().IntVar(&whatIfReplicas, "replicas", 0, "hypothetical replica count (default: current)")
+	whatIfCmd.Flags().StringVar(&whatIfCPURequest, "cpu-request", "", "hypothetical CPU request per pod, e.g. 250m (default: current)")
+	whatIfCmd.Flags().StringVar(&whatIfMemRequest, "mem-request", "", "hypothetical memory request per pod, e.g. 512Mi (default: current)")
+	_ = whatIfCmd.MarkFlagRequired("workload")
+	rootCmd.AddCommand(whatIfCmd)
+}
@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// ArgoCDAppGroup aggregates the workloads managed by one Argo CD Application.
+type ArgoCDAppGroup struct {
+	App        string   // "" for the workloads Argo CD does not manage
+	Namespaces []string // sorted
+	Workloads  []string // "namespace/Kind/name", sorted
+	WorkloadTotals
+}
+
+// GroupByArgoCDApp groups workloads by the Argo CD Application managing them, so findings
+// can be routed to the Application (or its app-of-apps entry) rather than to single
+// Deployments. An Application may deploy to several namespaces. Groups are sorted by CPU
+// waste, then CPU requests, descending; unmanaged workloads come last.
+func GroupByArgoCDApp(workloads []kube.WorkloadInfo) []ArgoCDAppGroup {
+	byApp := make(map[string]*ArgoCDAppGroup)
+	for _, w := range workloads {
+		g, ok := byApp[w.ArgoCDApp]
+		if !ok {
+			g = &ArgoCDAppGroup{App: w.ArgoCDApp}
+			byApp[w.ArgoCDApp] = g
+		}
+		if !slices.Contains(g.Namespaces, w.Namespace) {
+			g.Namespaces = append(g.Namespaces, w.Namespace)
+		}
+		g.Workloads = append(g.Workloads, w.Namespace+"/"+w.Kind+"/"+w.Name)
+		g.add(w)
+	}
+
+	groups := make([]ArgoCDAppGroup, 0, len(byApp))
+	for _, g := range byApp {
+		sort.Strings(g.Namespaces)
+		sort.Strings(g.Workloads)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.App == "") != (b.App == "") {
+			return b.App == ""
+		}
+		if more, ok := a.wastesMore(b.WorkloadTotals); ok {
+			return more
+		}
+		return a.App < b.App
+	})
+	return groups
+}
@@ -0,0 +1,43 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestGroupByArgoCDApp(t *testing.T) {
+	workload := func(ns, name, app string, pods int, cpuReq, cpuActual int64, metrics bool) kube.WorkloadInfo {
+		return kube.WorkloadInfo{Namespace: ns, Kind: "Deployment", Name: name, ArgoCDApp: app, PodCount: pods,
+			CPURequest: cpuReq, CPUActual: cpuActual, MetricsAvailable: metrics}
+	}
+	groups := GroupByArgoCDApp([]kube.WorkloadInfo{
+		workload("shop", "storefront", "shop", 3, 3000, 300, true),
+		workload("shop-canary", "storefront", "shop", 1, 1000, 100, true),
+		workload("payments", "checkout", "payments", 2, 2000, 1900, true),
+		workload("payments", "reports", "payments", 0, 4000, 0, false), // scaled to zero, no usage
+		workload("dev", "preview", "", 1, 8000, 10, true),
+	})
+
+	want := []struct {
+		app, namespaces, workloads string
+		pods                       int
+		cpuRequest, cpuWaste       int64
+	}{
+		{"shop", "shop,shop-canary", "shop-canary/Deployment/storefront,shop/Deployment/storefront", 4, 4000, 3600},
+		{"payments", "payments", "payments/Deployment/checkout,payments/Deployment/reports", 2, 6000, 100},
+		{"", "dev", "dev/Deployment/preview", 1, 8000, 7990},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d: %+v", len(groups), len(want), groups)
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.App != w.app || strings.Join(g.Namespaces, ",") != w.namespaces || strings.Join(g.Workloads, ",") != w.workloads ||
+			g.Pods != w.pods || g.CPURequest != w.cpuRequest || g.Metered.CPUWaste() != w.cpuWaste {
+			t.Errorf("group %d = %q %v %v pods %d req %d waste %d, want %+v",
+				i, g.App, g.Namespaces, g.Workloads, g.Pods, g.CPURequest, g.Metered.CPUWaste(), w)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// Efficiency sums requests and actual usage over the pods that report metrics.
+type Efficiency struct {
+	Pods         int     `json:"pods"`
+	CPURequested int64   `json:"cpuRequestedMillicores"`
+	CPUActual    int64   `json:"cpuActualMillicores"`
+	MemRequested float64 `json:"memRequestedMiB"`
+	MemActual    float64 `json:"memActualMiB"`
+}
+
+// MeasureEfficiency sums requests and usage of pods with metrics.
+func MeasureEfficiency(pods []kube.PodInfo) Efficiency {
+	var e Efficiency
+	for _, p := range pods {
+		if !p.MetricsAvailable {
+			continue
+		}
+		e.Pods++
+		e.CPURequested += p.CPURequest
+		e.CPUActual += p.CPUActual
+		e.MemRequested += p.MemRequest
+		e.MemActual += p.MemActual
+	}
+	return e
+}
+
+// CPUEfficiency returns actual/requested CPU in percent (0 without requests).
+func (e Efficiency) CPUEfficiency() float64 {
+	if e.CPURequested == 0 {
+		return 0
+	}
+	return float64(e.CPUActual) * 100 / float64(e.CPURequested)
+}
+
+// MemEfficiency returns actual/requested memory in percent (0 without requests).
+func (e Efficiency) MemEfficiency() float64 {
+	if e.MemRequested == 0 {
+		return 0
+	}
+	return e.MemActual * 100 / e.MemRequested
+}
+
+// CPUWaste returns requested minus used CPU in millicores, floored at 0.
+func (e Efficiency) CPUWaste() int64 {
+	return max(e.CPURequested-e.CPUActual, 0)
+}
+
+// MemWaste returns requested minus used memory in MiB, floored at 0.
+func (e Efficiency) MemWaste() float64 {
+	return max(e.MemRequested-e.MemActual, 0)
+}
+
+// Baseline is a committed efficiency snapshot that later runs are compared against.
+type Baseline struct {
+	Context    string     `json:"context"`
+	Namespace  string     `json:"namespace,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Efficiency Efficiency `json:"efficiency"`
+}
+
+// LoadBaseline reads a baseline file written by SaveBaseline.
+func LoadBaseline(path string) (Baseline, error) {
+	var b Baseline
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// SaveBaseline writes b to path as indented JSON, suitable for committing.
+func SaveBaseline(path string, b Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Regression is an efficiency metric that dropped by more than the tolerance.
+type Regression struct {
+	Metric            string
+	Baseline, Current float64 // percent
+}
+
+// CompareEfficiency returns the CPU/memory efficiencies that dropped by more than
+// tolerance percentage points below the baseline.
+func CompareEfficiency(baseline, current Efficiency, tolerance float64) []Regression {
+	var regressions []Regression
+	if b, c := baseline.CPUEfficiency(), current.CPUEfficiency(); b-c > tolerance {
+		regressions = append(regressions, Regression{Metric: "CPU efficiency", Baseline: b, Current: c})
+	}
+	if b, c := baseline.MemEfficiency(), current.MemEfficiency(); b-c > tolerance {
+		regressions = append(regressions, Regression{Metric: "Memory efficiency", Baseline: b, Current: c})
+	}
+	return regressions
+}
@@ -0,0 +1,35 @@
+package analysis
+
+import "testing"
+
+func TestCompareEfficiency(t *testing.T) {
+	baseline := Efficiency{CPURequested: 1000, CPUActual: 400, MemRequested: 1000, MemActual: 600} // 40% / 60%
+
+	tests := []struct {
+		name      string
+		current   Efficiency
+		tolerance float64
+		want      []string
+	}{
+		{"unchanged", baseline, 5, nil},
+		{"improved", Efficiency{CPURequested: 500, CPUActual: 400, MemRequested: 700, MemActual: 600}, 5, nil},
+		{"drop within tolerance", Efficiency{CPURequested: 1000, CPUActual: 360, MemRequested: 1000, MemActual: 600}, 5, nil},
+		{"cpu regressed", Efficiency{CPURequested: 2000, CPUActual: 400, MemRequested: 1000, MemActual: 600}, 5, []string{"CPU efficiency"}},
+		{"both regressed", Efficiency{CPURequested: 2000, CPUActual: 400, MemRequested: 2000, MemActual: 600}, 5, []string{"CPU efficiency", "Memory efficiency"}},
+		{"zero tolerance", Efficiency{CPURequested: 1000, CPUActual: 399, MemRequested: 1000, MemActual: 600}, 0, []string{"CPU efficiency"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CompareEfficiency(baseline, tc.current, tc.tolerance)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d regressions %+v, want %v", len(got), got, tc.want)
+			}
+			for i, r := range got {
+				if r.Metric != tc.want[i] {
+					t.Errorf("regression %d = %q, want %q", i, r.Metric, tc.want[i])
+				}
+			}
+		})
+	}
+}
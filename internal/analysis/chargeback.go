@@ -0,0 +1,45 @@
+package analysis
+
+import "sort"
+
+// Prices are unit prices of capacity per month.
+type Prices struct {
+	CPUPerCoreMonth float64
+	MemPerGiBMonth  float64
+}
+
+// Monthly returns the monthly cost of cpu millicores and mem MiB.
+func (p Prices) Monthly(cpu int64, mem float64) float64 {
+	return float64(cpu)/1000*p.CPUPerCoreMonth + mem/1024*p.MemPerGiBMonth
+}
+
+// ChargebackLine is the monthly cost attributed to one label value.
+type ChargebackLine struct {
+	Group         LabelGroup
+	RequestedCost float64 // requests of all the group's pods
+	UsedCost      float64 // actual usage of pods with metrics
+	IdleCost      float64 // requested but unused, over pods with metrics
+}
+
+// Chargeback prices label groups: teams pay for what they request (showback of
+// RequestedCost); UsedCost and IdleCost show how much of it does work.
+// Lines are sorted by requested cost, descending; the unlabelled group comes last.
+func Chargeback(groups []LabelGroup, prices Prices) []ChargebackLine {
+	lines := make([]ChargebackLine, 0, len(groups))
+	for _, g := range groups {
+		lines = append(lines, ChargebackLine{
+			Group:         g,
+			RequestedCost: prices.Monthly(g.CPURequest, g.MemRequest),
+			UsedCost:      prices.Monthly(g.Metered.CPUActual, g.Metered.MemActual),
+			IdleCost:      prices.Monthly(g.Metered.CPUWaste(), g.Metered.MemWaste()),
+		})
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if (a.Group.Value == "") != (b.Group.Value == "") {
+			return b.Group.Value == ""
+		}
+		return a.RequestedCost > b.RequestedCost
+	})
+	return lines
+}
@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChargeback(t *testing.T) {
+	prices := Prices{CPUPerCoreMonth: 20, MemPerGiBMonth: 2}
+	groups := []LabelGroup{
+		{Value: "", CPURequest: 4000, MemRequest: 4096},
+		{Value: "web", CPURequest: 1000, MemRequest: 1024,
+			Metered: Efficiency{Pods: 1, CPURequested: 1000, CPUActual: 250, MemRequested: 1024, MemActual: 512}},
+		{Value: "data", CPURequest: 2000, MemRequest: 8192},
+	}
+
+	lines := Chargeback(groups, prices)
+
+	want := []struct {
+		value                 string
+		requested, used, idle float64
+	}{
+		{"data", 56, 0, 0},
+		{"web", 22, 6, 16},
+		{"", 88, 0, 0},
+	}
+	for i, w := range want {
+		l := lines[i]
+		if l.Group.Value != w.value || !approx(l.RequestedCost, w.requested) || !approx(l.UsedCost, w.used) || !approx(l.IdleCost, w.idle) {
+			t.Errorf("line %d = %q %.2f/%.2f/%.2f, want %q %.2f/%.2f/%.2f",
+				i, l.Group.Value, l.RequestedCost, l.UsedCost, l.IdleCost, w.value, w.requested, w.used, w.idle)
+		}
+	}
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// DrainMove is one pod that has to leave a drained node.
+type DrainMove struct {
+	Namespace, Pod string
+	Kind, Workload string
+	CPU            int64   // millicores requested
+	Mem            float64 // MiB requested
+	Target         string  // node that can absorb it; "" when none has room
+}
+
+// PDBImpact describes how a PodDisruptionBudget constrains evicting a node's pods.
+type PDBImpact struct {
+	Namespace, Name    string
+	Pods               int // pods on the node covered by the budget
+	DisruptionsAllowed int32
+	Verdict            Verdict
+}
+
+// DrainCheck is the outcome of simulating `kubectl drain` on one node.
+type DrainCheck struct {
+	Node     string
+	Moves    []DrainMove
+	Pinned   int // DaemonSet and mirror pods, which stay with the node
+	Unplaced int // pods no remaining node has request headroom for
+	PDBs     []PDBImpact
+}
+
+// Feasible reports whether every pod fits elsewhere and no budget blocks eviction.
+func (d DrainCheck) Feasible() bool {
+	if d.Unplaced > 0 {
+		return false
+	}
+	for _, p := range d.PDBs {
+		if p.Verdict == VerdictPDBBlocks {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckDrain simulates cordoning and draining nodeName: its movable pods are placed,
+// largest CPU request first, onto the remaining schedulable nodes their placement
+// constraints allow, without filling their requests past maxUtil of allocatable. PDBs
+// covering the evicted pods are reported with VerdictPDBBlocks when they allow no
+// disruption at all and VerdictPDBSlows when they allow fewer disruptions than the node
+// holds (the drain has to wait for replacements).
+func CheckDrain(nodes []kube.NodeInfo, nodeName string, pdbs []kube.PDBInfo, maxUtil float64) DrainCheck {
+	check := DrainCheck{Node: nodeName}
+
+	sim := newSimNodes(nodes)
+	var (
+		target  *simNode
+		evicted []kube.PodInfo
+	)
+	for i, n := range nodes {
+		if n.Name != nodeName {
+			continue
+		}
+		target = sim[i]
+		for _, p := range n.Pods {
+			if p.WorkloadKind == "DaemonSet" || p.Mirror {
+				check.Pinned++
+				continue
+			}
+			evicted = append(evicted, p)
+		}
+	}
+	if target == nil {
+		return check
+	}
+
+	var movable []*simPod
+	for _, p := range target.pods {
+		if p.movable {
+			movable = append(movable, p)
+		}
+	}
+	sort.SliceStable(movable, func(i, j int) bool { return movable[i].cpu > movable[j].cpu })
+
+	placements, _ := placePods(movable, sim, target, maxUtil, bestFit)
+	for _, pl := range placements {
+		move := DrainMove{
+			Namespace: pl.pod.namespace,
+			Pod:       pl.pod.name,
+			Kind:      pl.pod.kind,
+			Workload:  pl.pod.workload,
+			CPU:       pl.pod.cpu,
+			Mem:       pl.pod.mem,
+		}
+		if pl.node != nil {
+			move.Target = pl.node.name
+		} else {
+			check.Unplaced++
+		}
+		check.Moves = append(check.Moves, move)
+	}
+
+	for _, pdb := range pdbs {
+		covered := 0
+		for _, p := range evicted {
+			if pdb.Matches(p) {
+				covered++
+			}
+		}
+		if covered == 0 {
+			continue
+		}
+		impact := PDBImpact{
+			Namespace:          pdb.Namespace,
+			Name:               pdb.Name,
+			Pods:               covered,
+			DisruptionsAllowed: pdb.DisruptionsAllowed,
+			Verdict:            VerdictOK,
+		}
+		switch {
+		case pdb.DisruptionsAllowed <= 0:
+			impact.Verdict = VerdictPDBBlocks
+		case int(pdb.DisruptionsAllowed) < covered:
+			impact.Verdict = VerdictPDBSlows
+		}
+		check.PDBs = append(check.PDBs, impact)
+	}
+	return check
+}
@@ -0,0 +1,102 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestCheckDrain(t *testing.T) {
+	web := rebalancePod("shop", "Deployment", "web", 500, 400, 1024, 900)
+	web.Labels = map[string]string{"app": "web"}
+	agent := rebalancePod("kube-system", "DaemonSet", "agent", 100, 50, 128, 100)
+	big := rebalancePod("shop", "Deployment", "big", 3900, 3000, 1024, 900)
+	gpuTaint := corev1.Taint{Key: "nvidia.com/gpu", Effect: corev1.TaintEffectNoSchedule}
+	gpuNode := rebalanceNode("b")
+	gpuNode.Taints = []corev1.Taint{gpuTaint}
+	gpuJob := rebalancePod("ml", "Deployment", "train", 500, 400, 1024, 900)
+	gpuJob.Tolerations = []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}}
+
+	webPDB := func(allowed int32) kube.PDBInfo {
+		return kube.PDBInfo{Namespace: "shop", Name: "web", Selector: labels.SelectorFromSet(labels.Set{"app": "web"}), DisruptionsAllowed: allowed}
+	}
+
+	tests := []struct {
+		name         string
+		nodes        []kube.NodeInfo
+		pdbs         []kube.PDBInfo
+		wantMoves    int
+		wantPinned   int
+		wantUnplaced int
+		wantPDB      *Verdict
+		wantFeasible bool
+	}{
+		{
+			name:         "pods fit elsewhere",
+			nodes:        []kube.NodeInfo{rebalanceNode("a", web, agent), rebalanceNode("b")},
+			wantMoves:    1,
+			wantPinned:   1,
+			wantFeasible: true,
+		},
+		{
+			name:         "no room on remaining nodes",
+			nodes:        []kube.NodeInfo{rebalanceNode("a", big), rebalanceNode("b", big)},
+			wantMoves:    1,
+			wantUnplaced: 1,
+		},
+		{
+			name: "cordoned node receives nothing",
+			nodes: []kube.NodeInfo{
+				rebalanceNode("a", web),
+				{Name: "b", AllocatableCPU: 4000, AllocatableMem: 16384, Unschedulable: true},
+			},
+			wantMoves:    1,
+			wantUnplaced: 1,
+		},
+		{
+			name:         "tainted node only takes tolerating pods",
+			nodes:        []kube.NodeInfo{rebalanceNode("a", web), gpuNode},
+			wantMoves:    1,
+			wantUnplaced: 1,
+		},
+		{
+			name:         "tolerating pod moves to tainted node",
+			nodes:        []kube.NodeInfo{rebalanceNode("a", gpuJob), gpuNode},
+			wantMoves:    1,
+			wantFeasible: true,
+		},
+		{
+			name:      "pdb allowing no disruption blocks",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web), rebalanceNode("b")},
+			pdbs:      []kube.PDBInfo{webPDB(0)},
+			wantMoves: 1,
+			wantPDB:   &VerdictPDBBlocks,
+		},
+		{
+			name:         "pdb with budget left is fine",
+			nodes:        []kube.NodeInfo{rebalanceNode("a", web), rebalanceNode("b")},
+			pdbs:         []kube.PDBInfo{webPDB(1)},
+			wantMoves:    1,
+			wantPDB:      &VerdictOK,
+			wantFeasible: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CheckDrain(tc.nodes, "a", tc.pdbs, 1)
+			if len(got.Moves) != tc.wantMoves || got.Pinned != tc.wantPinned || got.Unplaced != tc.wantUnplaced {
+				t.Errorf("moves=%d pinned=%d unplaced=%d, want %d/%d/%d",
+					len(got.Moves), got.Pinned, got.Unplaced, tc.wantMoves, tc.wantPinned, tc.wantUnplaced)
+			}
+			if tc.wantPDB != nil && (len(got.PDBs) != 1 || got.PDBs[0].Verdict != *tc.wantPDB) {
+				t.Errorf("PDBs = %+v, want one with verdict %q", got.PDBs, tc.wantPDB.Label)
+			}
+			if got.Feasible() != tc.wantFeasible {
+				t.Errorf("Feasible() = %v, want %v", got.Feasible(), tc.wantFeasible)
+			}
+		})
+	}
+}
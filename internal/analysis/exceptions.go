@@ -0,0 +1,16 @@
+package analysis
+
+import "github.com/amasotti/kusa/internal/kube"
+
+// Excepted reports whether a pod or workload is an accepted exception to leave out of the
+// findings: it is annotated kusa.io/ignore, or its CPU over-request factor (request /
+// actual) is within its kusa.io/expected-factor. Without metrics only the former applies.
+func Excepted(e kube.Exception, cpuRequest, cpuActual int64, metricsAvailable bool) bool {
+	if e.Ignore {
+		return true
+	}
+	if e.ExpectedFactor == 0 || !metricsAvailable || cpuActual <= 0 {
+		return false
+	}
+	return float64(cpuRequest) <= e.ExpectedFactor*float64(cpuActual)
+}
@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestExcepted(t *testing.T) {
+	tests := []struct {
+		name       string
+		exception  kube.Exception
+		cpuRequest int64
+		cpuActual  int64
+		metrics    bool
+		want       bool
+	}{
+		{"no exception", kube.Exception{}, 2000, 10, true, false},
+		{"ignored", kube.Exception{Ignore: true}, 2000, 10, true, true},
+		{"ignored without metrics", kube.Exception{Ignore: true}, 2000, 0, false, true},
+		{"within expected factor", kube.Exception{ExpectedFactor: 30}, 2000, 100, true, true},
+		{"at expected factor", kube.Exception{ExpectedFactor: 20}, 2000, 100, true, true},
+		{"beyond expected factor", kube.Exception{ExpectedFactor: 10}, 2000, 100, true, false},
+		{"expected factor without metrics", kube.Exception{ExpectedFactor: 30}, 2000, 0, false, false},
+		{"expected factor with zero usage", kube.Exception{ExpectedFactor: 30}, 2000, 0, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Excepted(tt.exception, tt.cpuRequest, tt.cpuActual, tt.metrics); got != tt.want {
+				t.Errorf("Excepted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
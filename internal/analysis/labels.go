@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// LabelGroup aggregates the pods sharing one value of a grouping label.
+type LabelGroup struct {
+	Value      string // "" for pods where neither the pod nor its namespace has the label
+	Namespaces []string
+	Pods       int
+	CPURequest int64      // millicores, all pods
+	MemRequest float64    // MiB, all pods
+	Metered    Efficiency // pods with metrics
+}
+
+// GroupByLabel groups pods by the value of label key, taken from the pod and, when the pod
+// does not carry it, from its namespace (nsLabels, keyed by namespace name). Groups are
+// sorted by CPU waste, then CPU requests, descending; the unlabelled group comes last.
+func GroupByLabel(pods []kube.PodInfo, nsLabels map[string]map[string]string, key string) []LabelGroup {
+	byValue := make(map[string]*LabelGroup)
+	members := make(map[string][]kube.PodInfo)
+	for _, p := range pods {
+		value, ok := p.Labels[key]
+		if !ok {
+			value = nsLabels[p.Namespace][key]
+		}
+		g, ok := byValue[value]
+		if !ok {
+			g = &LabelGroup{Value: value}
+			byValue[value] = g
+		}
+		if !slices.Contains(g.Namespaces, p.Namespace) {
+			g.Namespaces = append(g.Namespaces, p.Namespace)
+		}
+		g.Pods++
+		g.CPURequest += p.CPURequest
+		g.MemRequest += p.MemRequest
+		members[value] = append(members[value], p)
+	}
+
+	groups := make([]LabelGroup, 0, len(byValue))
+	for value, g := range byValue {
+		sort.Strings(g.Namespaces)
+		g.Metered = MeasureEfficiency(members[value])
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Value == "") != (b.Value == "") {
+			return b.Value == ""
+		}
+		if a.Metered.CPUWaste() != b.Metered.CPUWaste() {
+			return a.Metered.CPUWaste() > b.Metered.CPUWaste()
+		}
+		if a.CPURequest != b.CPURequest {
+			return a.CPURequest > b.CPURequest
+		}
+		return a.Value < b.Value
+	})
+	return groups
+}
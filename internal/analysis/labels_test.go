@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestGroupByLabel(t *testing.T) {
+	pod := func(ns string, labels map[string]string, cpuReq, cpuActual int64, metrics bool) kube.PodInfo {
+		return kube.PodInfo{Namespace: ns, Labels: labels, CPURequest: cpuReq, CPUActual: cpuActual, MetricsAvailable: metrics}
+	}
+	pods := []kube.PodInfo{
+		pod("shop", map[string]string{"team": "web"}, 1000, 100, true),
+		pod("shop-canary", map[string]string{"team": "web"}, 500, 50, true),
+		pod("billing", nil, 2000, 1900, true),                           // team from namespace
+		pod("billing", map[string]string{"team": "web"}, 200, 0, false), // pod label wins, no metrics
+		pod("scratch", nil, 4000, 0, true),                              // unlabelled
+	}
+	nsLabels := map[string]map[string]string{"billing": {"team": "payments"}}
+
+	groups := GroupByLabel(pods, nsLabels, "team")
+
+	want := []struct {
+		value      string
+		namespaces int
+		pods       int
+		cpuRequest int64
+		cpuWaste   int64
+	}{
+		{"web", 3, 3, 1700, 1350},
+		{"payments", 1, 1, 2000, 100},
+		{"", 1, 1, 4000, 4000},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups %+v, want %d", len(groups), groups, len(want))
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Value != w.value || len(g.Namespaces) != w.namespaces || g.Pods != w.pods || g.CPURequest != w.cpuRequest || g.Metered.CPUWaste() != w.cpuWaste {
+			t.Errorf("group %d = %q: %d namespaces, %d pods, %dm requested, %dm waste; want %q: %d, %d, %dm, %dm",
+				i, g.Value, len(g.Namespaces), g.Pods, g.CPURequest, g.Metered.CPUWaste(), w.value, w.namespaces, w.pods, w.cpuRequest, w.cpuWaste)
+		}
+	}
+}
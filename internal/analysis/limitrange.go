@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// minLimitRangeSamples is the number of metered containers below which a namespace's
+// median says little; such suggestions are flagged.
+const minLimitRangeSamples = 3
+
+// LimitRangeSuggestion is a suggested LimitRange for the containers of one namespace.
+type LimitRangeSuggestion struct {
+	Namespace  string
+	Containers int // running containers
+	Metered    int // containers with metrics, the basis of the medians
+	Unset      int // containers without CPU or memory request, which a default would apply to
+
+	MedianCPU int64   // millicores
+	MedianMem float64 // MiB
+
+	DefaultRequestCPU int64   // defaultRequest.cpu
+	DefaultRequestMem float64 // defaultRequest.memory
+	DefaultLimitMem   float64 // default.memory
+}
+
+// FewSamples reports whether too few containers were metered for the median to be a
+// reliable default.
+func (s LimitRangeSuggestion) FewSamples() bool {
+	return s.Metered < minLimitRangeSamples
+}
+
+// SuggestLimitRanges derives per-namespace container defaults from the median actual
+// usage of the namespace's containers: the default request is the median, rounded up,
+// and the default memory limit memLimitFactor times that. No default CPU limit is
+// suggested: it would throttle every container that does not set its own. Namespaces
+// without any metered container are left out. Sorted by namespace.
+func SuggestLimitRanges(pods []kube.PodInfo, memLimitFactor float64) []LimitRangeSuggestion {
+	type usage struct {
+		s    LimitRangeSuggestion
+		cpus []int64
+		mems []float64
+	}
+	byNS := make(map[string]*usage)
+	for _, p := range pods {
+		u, ok := byNS[p.Namespace]
+		if !ok {
+			u = &usage{s: LimitRangeSuggestion{Namespace: p.Namespace}}
+			byNS[p.Namespace] = u
+		}
+		for _, c := range p.Containers {
+			u.s.Containers++
+			if c.CPURequest == 0 || c.MemRequest == 0 {
+				u.s.Unset++
+			}
+			if c.MetricsAvailable {
+				u.s.Metered++
+				u.cpus = append(u.cpus, c.CPUActual)
+				u.mems = append(u.mems, c.MemActual)
+			}
+		}
+	}
+
+	var suggestions []LimitRangeSuggestion
+	for _, u := range byNS {
+		if u.s.Metered == 0 {
+			continue
+		}
+		s := u.s
+		s.MedianCPU = median(u.cpus)
+		s.MedianMem = median(u.mems)
+		s.DefaultRequestCPU = max(roundUpStep(float64(s.MedianCPU), containerCPUStep), minRightsizedCPU)
+		s.DefaultRequestMem = float64(max(roundUpStep(s.MedianMem, containerMemStep), minRightsizedMem))
+		s.DefaultLimitMem = float64(roundUpStep(s.DefaultRequestMem*max(memLimitFactor, 1), containerMemStep))
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Namespace < suggestions[j].Namespace })
+	return suggestions
+}
+
+// median returns the middle value of values (the mean of the two middle ones for an even
+// count); values is sorted in place.
+func median[T int64 | float64](values []T) T {
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func limitRangePod(namespace string, containers ...kube.ContainerInfo) kube.PodInfo {
+	return kube.PodInfo{Namespace: namespace, Containers: containers}
+}
+
+func meteredContainer(cpuReq int64, memReq float64, cpuActual int64, memActual float64) kube.ContainerInfo {
+	return kube.ContainerInfo{CPURequest: cpuReq, MemRequest: memReq, CPUActual: cpuActual, MemActual: memActual, MetricsAvailable: true}
+}
+
+func TestSuggestLimitRanges(t *testing.T) {
+	got := SuggestLimitRanges([]kube.PodInfo{
+		limitRangePod("shop",
+			meteredContainer(500, 512, 120, 300),
+			meteredContainer(0, 0, 45, 100),
+		),
+		limitRangePod("shop",
+			meteredContainer(500, 512, 200, 500),
+			meteredContainer(100, 0, 81, 200),
+		),
+		limitRangePod("dev", meteredContainer(100, 128, 2, 10)),
+		// No metered container: left out.
+		limitRangePod("idle", kube.ContainerInfo{CPURequest: 100, MemRequest: 128}),
+	}, 2)
+
+	tests := []struct {
+		namespace   string
+		wantMetered int
+		wantUnset   int
+		wantMedCPU  int64
+		wantMedMem  float64
+		wantCPU     int64
+		wantMem     float64
+		wantLimit   float64
+		wantFew     bool
+	}{
+		// Tiny usage is raised to the right-sizing floors.
+		{namespace: "dev", wantMetered: 1, wantMedCPU: 2, wantMedMem: 10, wantCPU: 10, wantMem: 32, wantLimit: 64, wantFew: true},
+		// Even count: mean of the middle two, (81+120)/2 and (200+300)/2.
+		{namespace: "shop", wantMetered: 4, wantUnset: 2, wantMedCPU: 100, wantMedMem: 250, wantCPU: 100, wantMem: 256, wantLimit: 512},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("got %d suggestions, want %d", len(got), len(tests))
+	}
+	for i, tc := range tests {
+		t.Run(tc.namespace, func(t *testing.T) {
+			s := got[i]
+			if s.Namespace != tc.namespace {
+				t.Fatalf("namespace = %q, want %q (sorted)", s.Namespace, tc.namespace)
+			}
+			if s.Metered != tc.wantMetered || s.Unset != tc.wantUnset {
+				t.Errorf("Metered/Unset = %d/%d, want %d/%d", s.Metered, s.Unset, tc.wantMetered, tc.wantUnset)
+			}
+			if s.MedianCPU != tc.wantMedCPU || s.MedianMem != tc.wantMedMem {
+				t.Errorf("median = %dm / %gMi, want %dm / %gMi", s.MedianCPU, s.MedianMem, tc.wantMedCPU, tc.wantMedMem)
+			}
+			if s.DefaultRequestCPU != tc.wantCPU || s.DefaultRequestMem != tc.wantMem || s.DefaultLimitMem != tc.wantLimit {
+				t.Errorf("defaults = %dm / %gMi / limit %gMi, want %dm / %gMi / limit %gMi",
+					s.DefaultRequestCPU, s.DefaultRequestMem, s.DefaultLimitMem, tc.wantCPU, tc.wantMem, tc.wantLimit)
+			}
+			if s.FewSamples() != tc.wantFew {
+				t.Errorf("FewSamples() = %v, want %v", s.FewSamples(), tc.wantFew)
+			}
+		})
+	}
+}
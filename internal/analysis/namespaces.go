@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// NamespaceWaste sums the requests, usage and reclaimable capacity of one namespace's pods.
+type NamespaceWaste struct {
+	Namespace  string
+	Pods       int
+	CPURequest int64   // millicores, all pods
+	MemRequest float64 // MiB, all pods
+	Metered    Efficiency
+
+	// Reclaimable is request minus usage summed per pod with metrics, each floored at 0:
+	// unlike Metered.CPUWaste, a bursting pod does not offset an idle one.
+	ReclaimableCPU int64
+	ReclaimableMem float64
+}
+
+// RankNamespacesByWaste groups pods by namespace, sorted by reclaimable CPU (or, with
+// byMemory, reclaimable memory) descending.
+func RankNamespacesByWaste(pods []kube.PodInfo, byMemory bool) []NamespaceWaste {
+	byName := make(map[string]*NamespaceWaste)
+	members := make(map[string][]kube.PodInfo)
+	for _, p := range pods {
+		ns, ok := byName[p.Namespace]
+		if !ok {
+			ns = &NamespaceWaste{Namespace: p.Namespace}
+			byName[p.Namespace] = ns
+		}
+		ns.Pods++
+		ns.CPURequest += p.CPURequest
+		ns.MemRequest += p.MemRequest
+		if p.MetricsAvailable {
+			ns.ReclaimableCPU += max(p.CPURequest-p.CPUActual, 0)
+			ns.ReclaimableMem += max(p.MemRequest-p.MemActual, 0)
+		}
+		members[p.Namespace] = append(members[p.Namespace], p)
+	}
+
+	namespaces := make([]NamespaceWaste, 0, len(byName))
+	for name, ns := range byName {
+		ns.Metered = MeasureEfficiency(members[name])
+		namespaces = append(namespaces, *ns)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		a, b := namespaces[i], namespaces[j]
+		if byMemory && a.ReclaimableMem != b.ReclaimableMem {
+			return a.ReclaimableMem > b.ReclaimableMem
+		}
+		if a.ReclaimableCPU != b.ReclaimableCPU {
+			return a.ReclaimableCPU > b.ReclaimableCPU
+		}
+		if a.ReclaimableMem != b.ReclaimableMem {
+			return a.ReclaimableMem > b.ReclaimableMem
+		}
+		return a.Namespace < b.Namespace
+	})
+	return namespaces
+}
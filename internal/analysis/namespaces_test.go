@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestRankNamespacesByWaste(t *testing.T) {
+	pod := func(ns string, cpuReq, cpuActual int64, memReq, memActual float64, metrics bool) kube.PodInfo {
+		return kube.PodInfo{Namespace: ns, CPURequest: cpuReq, CPUActual: cpuActual, MemRequest: memReq, MemActual: memActual, MetricsAvailable: metrics}
+	}
+	pods := []kube.PodInfo{
+		pod("shop", 1000, 100, 512, 500, true),
+		pod("shop", 500, 900, 512, 100, true), // bursting: no CPU reclaimable, does not offset the first pod
+		pod("data", 2000, 1500, 8192, 1024, true),
+		pod("data", 4000, 0, 0, 0, false), // no metrics: requested, not reclaimable
+		pod("dev", 200, 0, 256, 0, true),
+	}
+
+	tests := []struct {
+		name     string
+		byMemory bool
+		want     []string
+	}{
+		{"by cpu", false, []string{"shop", "data", "dev"}},
+		{"by memory", true, []string{"data", "shop", "dev"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := RankNamespacesByWaste(pods, tc.byMemory)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d namespaces %+v, want %d", len(got), got, len(tc.want))
+			}
+			for i, name := range tc.want {
+				if got[i].Namespace != name {
+					t.Errorf("namespace %d = %s, want %s", i, got[i].Namespace, name)
+				}
+			}
+		})
+	}
+
+	byName := make(map[string]NamespaceWaste)
+	for _, ns := range RankNamespacesByWaste(pods, false) {
+		byName[ns.Namespace] = ns
+	}
+	if shop := byName["shop"]; shop.ReclaimableCPU != 900 || shop.Metered.CPUWaste() != 500 || shop.ReclaimableMem != 424 {
+		t.Errorf("shop reclaimable %dm/%.0fMi (metered waste %dm), want 900m/424Mi (500m)", shop.ReclaimableCPU, shop.ReclaimableMem, shop.Metered.CPUWaste())
+	}
+	if data := byName["data"]; data.Pods != 2 || data.CPURequest != 6000 || data.ReclaimableCPU != 500 {
+		t.Errorf("data: %d pods, %dm requested, %dm reclaimable; want 2, 6000m, 500m", data.Pods, data.CPURequest, data.ReclaimableCPU)
+	}
+}
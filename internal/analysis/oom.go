@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// OOM risk score weights and saturation points. Proximity to the memory limit dominates:
+// it is the one signal about the next kill rather than past ones.
+const (
+	oomProximityWeight = 0.7
+	oomKilledWeight    = 0.2
+	oomRestartsWeight  = 0.1
+
+	oomProximityFloor = 0.5 // share of the memory limit below which proximity adds nothing
+	oomMaxRestarts    = 5   // restart count at which the restarts part saturates
+
+	oomHighScore   = 60
+	oomMediumScore = 30
+)
+
+// OOMRiskScore rates from 0 to 100 how likely a container is to be OOM-killed. It combines
+// the working set's proximity to the memory limit (linear from half the limit to the limit),
+// whether the container was OOM-killed recently and its restart count. Memory is in MiB;
+// memLimit 0 means no limit, so proximity adds nothing: such containers are only killed
+// when their node runs out of memory, which the eviction command covers.
+func OOMRiskScore(memLimit, memActual float64, recentlyOOMKilled bool, restarts int32) int {
+	var proximity float64
+	if memLimit > 0 {
+		proximity = (memActual/memLimit - oomProximityFloor) / (1 - oomProximityFloor)
+	}
+	var killed float64
+	if recentlyOOMKilled {
+		killed = 1
+	}
+	restartsPart := float64(restarts) / oomMaxRestarts
+
+	score := oomProximityWeight*clamp01(proximity) + oomKilledWeight*killed + oomRestartsWeight*clamp01(restartsPart)
+	return int(math.Round(score * 100))
+}
+
+// OOMRiskVerdict returns the verdict for an OOMRiskScore.
+func OOMRiskVerdict(score int) Verdict {
+	switch {
+	case score >= oomHighScore:
+		return VerdictOOMHigh
+	case score >= oomMediumScore:
+		return VerdictOOMMedium
+	default:
+		return VerdictOOMLow
+	}
+}
+
+// OOMRisk is the OOM-kill risk of one container.
+type OOMRisk struct {
+	Namespace    string
+	Pod          string
+	Container    string
+	WorkloadKind string
+	WorkloadName string
+
+	MemRequest       float64 // MiB
+	MemLimit         float64 // 0 = not set
+	MemActual        float64 // working set
+	MetricsAvailable bool
+
+	Restarts          int32
+	LastOOMKilled     time.Time // zero = not OOM-killed in its current or last termination
+	RecentlyOOMKilled bool      // LastOOMKilled falls within the window RankOOMRisk was given
+
+	Score int
+}
+
+// LimitShare returns the working set as a share of the memory limit (0 without a limit or
+// metrics).
+func (r OOMRisk) LimitShare() float64 {
+	if r.MemLimit == 0 || !r.MetricsAvailable {
+		return 0
+	}
+	return r.MemActual / r.MemLimit
+}
+
+// RankOOMRisk scores every container of pods, counting OOM kills after since as recent.
+// Containers without metrics are scored on their kill and restart history only. Sorted by
+// score, then by LimitShare, descending.
+func RankOOMRisk(pods []kube.PodInfo, since time.Time) []OOMRisk {
+	var risks []OOMRisk
+	for _, p := range pods {
+		for _, c := range p.Containers {
+			r := OOMRisk{
+				Namespace:         p.Namespace,
+				Pod:               p.Name,
+				Container:         c.Name,
+				WorkloadKind:      p.WorkloadKind,
+				WorkloadName:      p.WorkloadName,
+				MemRequest:        c.MemRequest,
+				MemLimit:          c.MemLimit,
+				MemActual:         c.MemActual,
+				MetricsAvailable:  c.MetricsAvailable,
+				Restarts:          c.Restarts,
+				LastOOMKilled:     c.LastOOMKilled,
+				RecentlyOOMKilled: !c.LastOOMKilled.IsZero() && c.LastOOMKilled.After(since),
+			}
+			var working float64
+			if c.MetricsAvailable {
+				working = c.MemActual
+			}
+			r.Score = OOMRiskScore(c.MemLimit, working, r.RecentlyOOMKilled, c.Restarts)
+			risks = append(risks, r)
+		}
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		if risks[i].Score != risks[j].Score {
+			return risks[i].Score > risks[j].Score
+		}
+		return risks[i].LimitShare() > risks[j].LimitShare()
+	})
+	return risks
+}
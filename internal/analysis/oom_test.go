@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestOOMRiskScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit, used float64
+		killed      bool
+		restarts    int32
+		want        int
+		wantVerdict Verdict
+	}{
+		{name: "half the limit", limit: 1024, used: 512, want: 0, wantVerdict: VerdictOOMLow},
+		{name: "at the limit", limit: 1024, used: 1024, want: 70, wantVerdict: VerdictOOMHigh},
+		{name: "above the limit saturates", limit: 1024, used: 2048, want: 70, wantVerdict: VerdictOOMHigh},
+		{name: "three quarters", limit: 1024, used: 768, want: 35, wantVerdict: VerdictOOMMedium},
+		{name: "no limit, no history", used: 4096, want: 0, wantVerdict: VerdictOOMLow},
+		{name: "no limit, recently killed", used: 4096, killed: true, restarts: 1, want: 22, wantVerdict: VerdictOOMLow},
+		{name: "everything", limit: 1024, used: 1000, killed: true, restarts: 9, want: 97, wantVerdict: VerdictOOMHigh},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := OOMRiskScore(tc.limit, tc.used, tc.killed, tc.restarts)
+			if got != tc.want {
+				t.Errorf("OOMRiskScore() = %d, want %d", got, tc.want)
+			}
+			if v := OOMRiskVerdict(got); v != tc.wantVerdict {
+				t.Errorf("OOMRiskVerdict(%d) = %s, want %s", got, v.Label, tc.wantVerdict.Label)
+			}
+		})
+	}
+}
+
+func TestRankOOMRisk(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	container := func(name string, limit, used float64, restarts int32, killedAgo time.Duration) kube.ContainerInfo {
+		c := kube.ContainerInfo{Name: name, MemLimit: limit, MemActual: used, MetricsAvailable: true, Restarts: restarts}
+		if killedAgo > 0 {
+			c.LastOOMKilled = now.Add(-killedAgo)
+		}
+		return c
+	}
+	unmetered := container("unmetered", 1024, 0, 0, time.Hour)
+	unmetered.MetricsAvailable = false
+
+	got := RankOOMRisk([]kube.PodInfo{
+		{Namespace: "shop", Name: "web", Containers: []kube.ContainerInfo{
+			container("app", 1024, 800, 0, 0),
+			container("sidecar", 256, 100, 0, 0),
+		}},
+		{Namespace: "data", Name: "db", Containers: []kube.ContainerInfo{
+			container("db", 4096, 3200, 1, 48*time.Hour), // killed outside the window
+		}},
+		{Namespace: "dev", Name: "job", Containers: []kube.ContainerInfo{unmetered}},
+	}, now.Add(-24*time.Hour))
+
+	want := []struct {
+		container string
+		recent    bool
+	}{
+		// app and db are equally close to their limits; db's restart puts it first.
+		{container: "db"},
+		{container: "app"},
+		{container: "unmetered", recent: true},
+		{container: "sidecar"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d risks, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Container != w.container || got[i].RecentlyOOMKilled != w.recent {
+			t.Errorf("rank %d = %s (recent %v, score %d), want %s (recent %v)", i+1, got[i].Container, got[i].RecentlyOOMKilled, got[i].Score, w.container, w.recent)
+		}
+	}
+}
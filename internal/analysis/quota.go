@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// Suggested quotas are rounded up to these steps, and never go below one step. Memory
+// above quotaMemLarge is rounded to whole GiB, keeping the quantities readable.
+const (
+	quotaCPUStep      = 100  // millicores
+	quotaMemStep      = 128  // MiB
+	quotaMemLarge     = 4096 // MiB
+	quotaMemLargeStep = 1024 // MiB
+)
+
+// QuotaSuggestion is a suggested ResourceQuota for one namespace, derived from the
+// observed usage of its running pods plus a buffer.
+type QuotaSuggestion struct {
+	Namespace string
+	Pods      int
+	Unmetered int // pods without metrics, missing from the usage
+
+	CPURequest int64 // millicores requested today
+	CPUActual  int64
+	MemRequest float64 // MiB
+	MemActual  float64
+
+	CPUQuota  int64   // requests.cpu, millicores
+	MemQuota  float64 // requests.memory, MiB
+	PodsQuota int
+}
+
+// ExceedsRequests reports whether the namespace already requests more than the
+// suggested quota: the quota admits no new pods (rollouts included) until requests
+// shrink, so right-size first.
+func (q QuotaSuggestion) ExceedsRequests() bool {
+	return q.CPURequest > q.CPUQuota || q.MemRequest > q.MemQuota
+}
+
+// SuggestQuotas sums usage per namespace and adds buffer (0.3 = +30%) to suggest
+// requests.cpu, requests.memory and pods quotas. The buffer also covers rollouts, which
+// briefly run surge pods next to the old ones. Sorted by namespace.
+func SuggestQuotas(pods []kube.PodInfo, buffer float64) []QuotaSuggestion {
+	byNS := make(map[string]*QuotaSuggestion)
+	for _, p := range pods {
+		s, ok := byNS[p.Namespace]
+		if !ok {
+			s = &QuotaSuggestion{Namespace: p.Namespace}
+			byNS[p.Namespace] = s
+		}
+		s.Pods++
+		s.CPURequest += p.CPURequest
+		s.MemRequest += p.MemRequest
+		if !p.MetricsAvailable {
+			s.Unmetered++
+			continue
+		}
+		s.CPUActual += p.CPUActual
+		s.MemActual += p.MemActual
+	}
+
+	suggestions := make([]QuotaSuggestion, 0, len(byNS))
+	for _, s := range byNS {
+		s.CPUQuota = roundUpStep(float64(s.CPUActual)*(1+buffer), quotaCPUStep)
+		mem := s.MemActual * (1 + buffer)
+		if mem > quotaMemLarge {
+			s.MemQuota = float64(roundUpStep(mem, quotaMemLargeStep))
+		} else {
+			s.MemQuota = float64(roundUpStep(mem, quotaMemStep))
+		}
+		s.PodsQuota = int(roundUpStep(float64(s.Pods)*(1+buffer), 1))
+		suggestions = append(suggestions, *s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Namespace < suggestions[j].Namespace })
+	return suggestions
+}
+
+// roundUpStep rounds v up to a multiple of step, at least one step. The epsilon keeps
+// float noise (1000 × 1.2 = 1200.0000000000002) from adding a step.
+func roundUpStep(v float64, step int64) int64 {
+	return max(int64(math.Ceil(v/float64(step)-1e-9))*step, step)
+}
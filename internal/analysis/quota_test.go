@@ -0,0 +1,55 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestSuggestQuotas(t *testing.T) {
+	unmetered := rebalancePod("dev", "Deployment", "debug", 500, 0, 1024, 0)
+	unmetered.MetricsAvailable = false
+
+	got := SuggestQuotas([]kube.PodInfo{
+		rebalancePod("shop", "Deployment", "web", 2000, 500, 4096, 1000),
+		rebalancePod("shop", "Deployment", "web", 2000, 500, 4096, 1000),
+		rebalancePod("data", "StatefulSet", "db", 1000, 1000, 8192, 6000),
+		unmetered,
+	}, 0.2)
+
+	tests := []struct {
+		namespace     string
+		wantCPU       int64
+		wantMem       float64
+		wantPods      int
+		wantUnmetered int
+		wantExceeds   bool
+	}{
+		// 6000Mi × 1.2 = 7200Mi is above 4Gi, so rounded to whole GiB.
+		{namespace: "data", wantCPU: 1200, wantMem: 8192, wantPods: 2},
+		// No usage at all still gets one step of each.
+		{namespace: "dev", wantCPU: 100, wantMem: 128, wantPods: 2, wantUnmetered: 1, wantExceeds: true},
+		{namespace: "shop", wantCPU: 1200, wantMem: 2432, wantPods: 3, wantExceeds: true},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("got %d suggestions, want %d", len(got), len(tests))
+	}
+	for i, tc := range tests {
+		t.Run(tc.namespace, func(t *testing.T) {
+			s := got[i]
+			if s.Namespace != tc.namespace {
+				t.Fatalf("namespace = %q, want %q (sorted)", s.Namespace, tc.namespace)
+			}
+			if s.CPUQuota != tc.wantCPU || s.MemQuota != tc.wantMem || s.PodsQuota != tc.wantPods {
+				t.Errorf("quota = %dm / %gMi / %d pods, want %dm / %gMi / %d pods",
+					s.CPUQuota, s.MemQuota, s.PodsQuota, tc.wantCPU, tc.wantMem, tc.wantPods)
+			}
+			if s.Unmetered != tc.wantUnmetered {
+				t.Errorf("Unmetered = %d, want %d", s.Unmetered, tc.wantUnmetered)
+			}
+			if s.ExceedsRequests() != tc.wantExceeds {
+				t.Errorf("ExceedsRequests() = %v, want %v", s.ExceedsRequests(), tc.wantExceeds)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Right-sized requests never go below these floors.
+const (
+	minRightsizedCPU = 10 // millicores
+	minRightsizedMem = 32 // MiB
+)
+
+// Rebalance action kinds.
+const (
+	ActionDownsize = "Downsize"
+	ActionDrain    = "Drain"
+)
+
+// RebalanceOptions tunes PlanRebalance.
+type RebalanceOptions struct {
+	Headroom       float64         // fraction added on top of observed usage when right-sizing (0.3 = +30%)
+	MinFactor      float64         // only downsize when the request is at least this multiple of the right-sized value
+	MaxUtilization float64         // fraction of allocatable receiving nodes may be filled to, by requests
+	Downsize       bool            // right-size workloads before trying to drain nodes
+	SkipNamespaces map[string]bool // never suggest downsizing workloads in these namespaces
+}
+
+// RebalanceAction is one step of a rebalance plan.
+type RebalanceAction struct {
+	Kind     string // ActionDownsize or ActionDrain
+	Target   string // "namespace/Kind/name" for downsizes, node name for drains
+	Detail   string
+	CPUFreed int64   // millicores: requests released (downsize) or allocatable removed (drain)
+	MemFreed float64 // MiB
+}
+
+// RebalancePlan is an ordered list of actions and their effect on the node count.
+type RebalancePlan struct {
+	Actions     []RebalanceAction
+	NodesBefore int
+	NodesAfter  int
+}
+
+type simPod struct {
+	namespace, name  string
+	kind, workload   string
+	cpu              int64
+	mem              float64
+	cpuActual        int64
+	memActual        float64
+	metricsAvailable bool
+	movable          bool
+	guaranteed       bool // Guaranteed QoS class: limits have to follow the requests
+
+	// Placement constraints, see kube.PodInfo.
+	labels       map[string]string
+	tolerations  []corev1.Toleration
+	nodeSelector map[string]string
+	affinity     *corev1.Affinity
+}
+
+// allowedOn reports whether the pod's tolerations, node selector and required node
+// affinity admit node n.
+func (p *simPod) allowedOn(n *simNode) bool {
+	return n.info.ToleratedBy(p.tolerations) && n.info.MatchesNodeSelector(p.nodeSelector, p.affinity)
+}
+
+// newSimNodes copies nodes and their pods into mutable simulation state.
+// DaemonSet and mirror pods are pinned to their node.
+func newSimNodes(nodes []kube.NodeInfo) []*simNode {
+	sim := make([]*simNode, 0, len(nodes))
+	for _, n := range nodes {
+		sn := &simNode{name: n.Name, allocCPU: n.AllocatableCPU, allocMem: n.AllocatableMem, cordoned: n.Unschedulable, info: n}
+		for _, p := range n.Pods {
+			sp := &simPod{
+				namespace:        p.Namespace,
+				name:             p.Name,
+				kind:             p.WorkloadKind,
+				workload:         p.WorkloadName,
+				cpu:              p.CPURequest,
+				mem:              p.MemRequest,
+				cpuActual:        p.CPUActual,
+				memActual:        p.MemActual,
+				metricsAvailable: p.MetricsAvailable,
+				movable:          p.WorkloadKind != "DaemonSet" && !p.Mirror,
+				guaranteed:       p.QOSClass == string(corev1.PodQOSGuaranteed),
+				labels:           p.Labels,
+				tolerations:      p.Tolerations,
+				nodeSelector:     p.NodeSelector,
+				affinity:         p.Affinity,
+			}
+			sn.addPod(sp)
+		}
+		sim = append(sim, sn)
+	}
+	return sim
+}
+
+type simNode struct {
+	name     string
+	allocCPU int64
+	allocMem float64
+	reqCPU   int64
+	reqMem   float64
+	pods     []*simPod
+	drained  bool
+	cordoned bool          // receives no pods
+	info     kube.NodeInfo // the source node: labels and taints for placement constraints
+}
+
+func (n *simNode) addPod(p *simPod) {
+	n.pods = append(n.pods, p)
+	n.reqCPU += p.cpu
+	n.reqMem += p.mem
+}
+
+func (n *simNode) removePod(p *simPod) {
+	n.pods = slices.DeleteFunc(n.pods, func(q *simPod) bool { return q == p })
+	n.reqCPU -= p.cpu
+	n.reqMem -= p.mem
+}
+
+func (n *simNode) utilization() float64 {
+	return requestUtilization(n.reqCPU, n.reqMem, n.allocCPU, n.allocMem)
+}
+
+// requestUtilization returns the larger of the CPU and memory requested/allocatable fractions.
+func requestUtilization(reqCPU int64, reqMem float64, allocCPU int64, allocMem float64) float64 {
+	cpu, mem := 0.0, 0.0
+	if allocCPU > 0 {
+		cpu = float64(reqCPU) / float64(allocCPU)
+	}
+	if allocMem > 0 {
+		mem = reqMem / allocMem
+	}
+	return math.Max(cpu, mem)
+}
+
+// PlanRebalance suggests how to consolidate stranded capacity: first right-size
+// over-requested workloads (when opts.Downsize), then repeatedly drain the least
+// requested node whose movable pods fit on the remaining nodes (best fit, by requests).
+// DaemonSet and mirror pods are not moved: they disappear with their node.
+func PlanRebalance(nodes []kube.NodeInfo, opts RebalanceOptions) RebalancePlan {
+	plan := RebalancePlan{NodesBefore: len(nodes)}
+
+	sim := newSimNodes(nodes)
+
+	if opts.Downsize {
+		plan.Actions = append(plan.Actions, planDownsizes(sim, opts)...)
+	}
+	plan.Actions = append(plan.Actions, planDrains(sim, opts.MaxUtilization)...)
+
+	plan.NodesAfter = plan.NodesBefore
+	for _, a := range plan.Actions {
+		if a.Kind == ActionDrain {
+			plan.NodesAfter--
+		}
+	}
+	return plan
+}
+
+// planDownsizes right-sizes each workload's per-pod requests to its peak observed usage
+// plus headroom, applying the new requests to the simulated nodes. Guaranteed workloads
+// are told to lower their limits with the requests, which keeps their QoS class.
+func planDownsizes(sim []*simNode, opts RebalanceOptions) []RebalanceAction {
+	type workload struct {
+		key       string
+		pods      []*simPod
+		podNodes  []*simNode
+		maxCPU    int64
+		maxMem    float64
+		maxCPUAct int64
+		maxMemAct float64
+		noMetrics bool
+		partial   bool // some pods are not Guaranteed
+	}
+
+	byKey := make(map[string]*workload)
+	var order []string
+	for _, n := range sim {
+		for _, p := range n.pods {
+			if p.kind == "Pod" || p.kind == "StaticPod" || opts.SkipNamespaces[p.namespace] {
+				continue // standalone and static pods have no template to change
+			}
+			key := p.namespace + "/" + p.kind + "/" + p.workload
+			w, ok := byKey[key]
+			if !ok {
+				w = &workload{key: key}
+				byKey[key] = w
+				order = append(order, key)
+			}
+			w.pods = append(w.pods, p)
+			w.podNodes = append(w.podNodes, n)
+			w.maxCPU = max(w.maxCPU, p.cpu)
+			w.maxMem = max(w.maxMem, p.mem)
+			w.maxCPUAct = max(w.maxCPUAct, p.cpuActual)
+			w.maxMemAct = max(w.maxMemAct, p.memActual)
+			w.noMetrics = w.noMetrics || !p.metricsAvailable
+			w.partial = w.partial || !p.guaranteed
+		}
+	}
+
+	var actions []RebalanceAction
+	for _, key := range order {
+		w := byKey[key]
+		if w.noMetrics {
+			continue
+		}
+
+		recCPU := max(int64(math.Ceil(float64(w.maxCPUAct)*(1+opts.Headroom))), minRightsizedCPU)
+		recMem := max(math.Ceil(w.maxMemAct*(1+opts.Headroom)), minRightsizedMem)
+
+		newCPU, newMem := w.maxCPU, w.maxMem
+		var changes []string
+		if w.maxCPU > 0 && float64(w.maxCPU) >= float64(recCPU)*opts.MinFactor && recCPU < w.maxCPU {
+			newCPU = recCPU
+			changes = append(changes, fmt.Sprintf("CPU %s → %s", kube.FormatCPU(w.maxCPU), kube.FormatCPU(recCPU)))
+		}
+		if w.maxMem > 0 && w.maxMem >= recMem*opts.MinFactor && recMem < w.maxMem {
+			newMem = recMem
+			changes = append(changes, fmt.Sprintf("mem %s → %s", kube.FormatMem(w.maxMem), kube.FormatMem(recMem)))
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		action := RebalanceAction{Kind: ActionDownsize, Target: key}
+		for i, p := range w.pods {
+			n := w.podNodes[i]
+			if p.cpu > newCPU {
+				action.CPUFreed += p.cpu - newCPU
+				n.reqCPU -= p.cpu - newCPU
+				p.cpu = newCPU
+			}
+			if p.mem > newMem {
+				action.MemFreed += p.mem - newMem
+				n.reqMem -= p.mem - newMem
+				p.mem = newMem
+			}
+		}
+		action.Detail = fmt.Sprintf("%s per pod (%d %s)", strings.Join(changes, ", "), len(w.pods), kube.Plural(len(w.pods), "pod", "pods"))
+		if !w.partial {
+			action.Detail += ", limits lowered too to keep Guaranteed QoS"
+		}
+		actions = append(actions, action)
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].CPUFreed > actions[j].CPUFreed
+	})
+	return actions
+}
+
+// planDrains repeatedly drains the least utilized node whose movable pods all fit elsewhere.
+func planDrains(sim []*simNode, maxUtil float64) []RebalanceAction {
+	var actions []RebalanceAction
+
+	for {
+		var active []*simNode
+		for _, n := range sim {
+			if !n.drained {
+				active = append(active, n)
+			}
+		}
+		if len(active) <= 1 {
+			return actions
+		}
+
+		sort.SliceStable(active, func(i, j int) bool {
+			return active[i].utilization() < active[j].utilization()
+		})
+
+		drained := false
+		for _, cand := range active {
+			if action, ok := tryDrain(cand, active, maxUtil); ok {
+				actions = append(actions, action)
+				drained = true
+				break
+			}
+		}
+		if !drained {
+			return actions
+		}
+	}
+}
+
+// tryDrain places cand's movable pods onto the other active nodes (largest pods first,
+// best fit) and commits the moves only if every pod fits.
+func tryDrain(cand *simNode, active []*simNode, maxUtil float64) (RebalanceAction, bool) {
+	var movable []*simPod
+	for _, p := range cand.pods {
+		if p.movable {
+			movable = append(movable, p)
+		}
+	}
+	sort.SliceStable(movable, func(i, j int) bool { return movable[i].cpu > movable[j].cpu })
+
+	placements, ok := placePods(movable, active, cand, maxUtil, bestFit)
+	if !ok {
+		return RebalanceAction{}, false
+	}
+
+	moved := make(map[string]int)
+	var targets []string
+	for _, pl := range placements {
+		pl.node.addPod(pl.pod)
+		if moved[pl.node.name] == 0 {
+			targets = append(targets, pl.node.name)
+		}
+		moved[pl.node.name]++
+	}
+	cand.drained = true
+
+	detail := "no movable pods"
+	if len(placements) > 0 {
+		parts := make([]string, len(targets))
+		for i, t := range targets {
+			parts[i] = fmt.Sprintf("%s (%d)", t, moved[t])
+		}
+		detail = fmt.Sprintf("move %d %s → %s", len(placements), kube.Plural(len(placements), "pod", "pods"), strings.Join(parts, ", "))
+	}
+
+	return RebalanceAction{
+		Kind:     ActionDrain,
+		Target:   cand.name,
+		Detail:   detail,
+		CPUFreed: cand.allocCPU,
+		MemFreed: cand.allocMem,
+	}, true
+}
+
+// placementStrategy picks among the nodes a pod fits on.
+type placementStrategy int
+
+const (
+	bestFit        placementStrategy = iota // the node left with the least spare capacity (bin packing)
+	leastAllocated                          // the node left with the most spare capacity, like the default scheduler scoring
+)
+
+type placement struct {
+	pod  *simPod
+	node *simNode // nil when no node has room
+}
+
+// placePods tentatively places pods (in the given order) onto targets, skipping exclude,
+// drained and cordoned nodes and nodes the pod's placement constraints rule out (taints,
+// node selector and affinity, pod anti-affinity), without filling any node's requests
+// past maxUtil of its allocatable, choosing among candidate nodes according to strategy.
+// Nodes are not modified; ok is false when at least one pod did not fit.
+func placePods(pods []*simPod, targets []*simNode, exclude *simNode, maxUtil float64, strategy placementStrategy) (placements []placement, ok bool) {
+	extraCPU := make(map[*simNode]int64)
+	extraMem := make(map[*simNode]float64)
+	extraPods := make(map[*simNode][]*simPod)
+	withTerms := antiAffinityPods(targets, exclude)
+	ok = true
+
+	for _, p := range pods {
+		var (
+			best     *simNode
+			bestUtil float64
+		)
+		for _, n := range targets {
+			if n == exclude || n.drained || n.cordoned || !p.allowedOn(n) || antiAffinityConflict(p, n, targets, exclude, extraPods, withTerms) {
+				continue
+			}
+			cpu := n.reqCPU + extraCPU[n] + p.cpu
+			mem := n.reqMem + extraMem[n] + p.mem
+			if float64(cpu) > float64(n.allocCPU)*maxUtil || mem > n.allocMem*maxUtil {
+				continue
+			}
+			u := requestUtilization(cpu, mem, n.allocCPU, n.allocMem)
+			if best == nil || (strategy == bestFit && u > bestUtil) || (strategy == leastAllocated && u < bestUtil) {
+				best, bestUtil = n, u
+			}
+		}
+		if best == nil {
+			ok = false
+		} else {
+			extraCPU[best] += p.cpu
+			extraMem[best] += p.mem
+			extraPods[best] = append(extraPods[best], p)
+			if len(kube.AntiAffinityTerms(p.affinity)) > 0 {
+				withTerms = append(withTerms, podOnNode{p, best})
+			}
+		}
+		placements = append(placements, placement{pod: p, node: best})
+	}
+	return placements, ok
+}
+
+// podOnNode is a pod and the (simulated) node it runs on.
+type podOnNode struct {
+	pod  *simPod
+	node *simNode
+}
+
+// antiAffinityPods returns the pods on targets (bar exclude and drained nodes) that have
+// required pod anti-affinity terms, which constrain where other pods may go.
+func antiAffinityPods(targets []*simNode, exclude *simNode) []podOnNode {
+	var pods []podOnNode
+	for _, n := range targets {
+		if n == exclude || n.drained {
+			continue
+		}
+		for _, p := range n.pods {
+			if len(kube.AntiAffinityTerms(p.affinity)) > 0 {
+				pods = append(pods, podOnNode{p, n})
+			}
+		}
+	}
+	return pods
+}
+
+// antiAffinityConflict reports whether placing p on n breaks a required pod anti-affinity
+// term: one of p's own, selecting a pod in the same topology domain, or one of the pods
+// in withTerms (see antiAffinityPods) selecting p. extra holds the pods tentatively placed
+// so far.
+func antiAffinityConflict(p *simPod, n *simNode, targets []*simNode, exclude *simNode, extra map[*simNode][]*simPod, withTerms []podOnNode) bool {
+	for _, q := range withTerms {
+		if q.pod == p {
+			continue
+		}
+		for _, term := range kube.AntiAffinityTerms(q.pod.affinity) {
+			if kube.SameTopology(n.info, q.node.info, term.TopologyKey) && kube.TermMatches(term, q.pod.namespace, p.namespace, p.labels) {
+				return true
+			}
+		}
+	}
+
+	own := kube.AntiAffinityTerms(p.affinity)
+	if len(own) == 0 {
+		return false
+	}
+	for _, m := range targets {
+		if m == exclude || m.drained {
+			continue
+		}
+		for _, q := range slices.Concat(m.pods, extra[m]) {
+			if q == p {
+				continue
+			}
+			for _, term := range own {
+				if kube.SameTopology(n.info, m.info, term.TopologyKey) && kube.TermMatches(term, p.namespace, q.namespace, q.labels) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func rebalancePod(ns, kind, workload string, cpuReq, cpuActual int64, memReq, memActual float64) kube.PodInfo {
+	return kube.PodInfo{
+		Namespace:        ns,
+		Name:             workload + "-pod",
+		WorkloadKind:     kind,
+		WorkloadName:     workload,
+		CPURequest:       cpuReq,
+		CPUActual:        cpuActual,
+		MemRequest:       memReq,
+		MemActual:        memActual,
+		MetricsAvailable: true,
+	}
+}
+
+func rebalanceNode(name string, pods ...kube.PodInfo) kube.NodeInfo {
+	return kube.NodeInfo{Name: name, AllocatableCPU: 4000, AllocatableMem: 16384, Pods: pods}
+}
+
+func TestPlanRebalance(t *testing.T) {
+	opts := RebalanceOptions{Headroom: 0.3, MinFactor: 2, MaxUtilization: 0.85}
+
+	tests := []struct {
+		name          string
+		nodes         []kube.NodeInfo
+		downsize      bool
+		wantDownsizes int
+		wantDrains    []string
+	}{
+		{
+			name: "underutilized node is drained",
+			nodes: []kube.NodeInfo{
+				rebalanceNode("busy", rebalancePod("shop", "Deployment", "api", 2000, 1800, 4096, 4000)),
+				rebalanceNode("quiet", rebalancePod("shop", "Deployment", "web", 500, 400, 1024, 900)),
+			},
+			wantDrains: []string{"quiet"},
+		},
+		{
+			name: "never drains the last node",
+			nodes: []kube.NodeInfo{
+				rebalanceNode("only", rebalancePod("shop", "Deployment", "web", 100, 90, 256, 200)),
+			},
+		},
+		{
+			name: "full nodes stay",
+			nodes: []kube.NodeInfo{
+				rebalanceNode("a", rebalancePod("shop", "Deployment", "api", 3000, 2900, 1024, 1000)),
+				rebalanceNode("b", rebalancePod("shop", "Deployment", "web", 3000, 2900, 1024, 1000)),
+			},
+		},
+		{
+			name: "downsizing frees room for a drain",
+			nodes: []kube.NodeInfo{
+				rebalanceNode("a", rebalancePod("shop", "Deployment", "api", 3000, 100, 1024, 500)),
+				rebalanceNode("b", rebalancePod("shop", "Deployment", "web", 3000, 2000, 1024, 1000)),
+			},
+			downsize:      true,
+			wantDownsizes: 1,
+			wantDrains:    []string{"a"},
+		},
+		{
+			name: "daemonset pods are not moved",
+			nodes: []kube.NodeInfo{
+				rebalanceNode("a",
+					rebalancePod("shop", "Deployment", "api", 3000, 2900, 1024, 1000),
+					rebalancePod("kube-system", "DaemonSet", "agent", 400, 300, 512, 400)),
+				rebalanceNode("b", rebalancePod("kube-system", "DaemonSet", "agent", 400, 300, 512, 400)),
+			},
+			wantDrains: []string{"b"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := opts
+			o.Downsize = tc.downsize
+			plan := PlanRebalance(tc.nodes, o)
+
+			var downsizes int
+			var drains []string
+			for _, a := range plan.Actions {
+				switch a.Kind {
+				case ActionDownsize:
+					downsizes++
+				case ActionDrain:
+					drains = append(drains, a.Target)
+				}
+			}
+			if downsizes != tc.wantDownsizes {
+				t.Errorf("got %d downsizes, want %d", downsizes, tc.wantDownsizes)
+			}
+			if len(drains) != len(tc.wantDrains) {
+				t.Fatalf("drained %v, want %v", drains, tc.wantDrains)
+			}
+			for i := range drains {
+				if drains[i] != tc.wantDrains[i] {
+					t.Errorf("drained %v, want %v", drains, tc.wantDrains)
+				}
+			}
+			if plan.NodesAfter != plan.NodesBefore-len(drains) {
+				t.Errorf("NodesAfter = %d, want %d", plan.NodesAfter, plan.NodesBefore-len(drains))
+			}
+		})
+	}
+}
+
+func TestPlanRebalanceGuaranteed(t *testing.T) {
+	guaranteed := rebalancePod("data", "StatefulSet", "db", 2000, 100, 4096, 1000)
+	guaranteed.QOSClass = "Guaranteed"
+	burstable := rebalancePod("shop", "Deployment", "web", 2000, 100, 4096, 1000)
+
+	plan := PlanRebalance([]kube.NodeInfo{rebalanceNode("a", guaranteed, burstable)},
+		RebalanceOptions{Headroom: 0.3, MinFactor: 2, MaxUtilization: 0.85, Downsize: true})
+	if len(plan.Actions) != 2 {
+		t.Fatalf("got %d actions, want 2 downsizes", len(plan.Actions))
+	}
+	for _, a := range plan.Actions {
+		keeps := strings.Contains(a.Detail, "Guaranteed")
+		if want := a.Target == "data/StatefulSet/db"; keeps != want {
+			t.Errorf("%s: %q mentions Guaranteed = %v, want %v", a.Target, a.Detail, keeps, want)
+		}
+	}
+}
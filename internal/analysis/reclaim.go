@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// ReclaimEstimate is the capacity that right-sizing every workload would release,
+// next to the average node it is measured in.
+type ReclaimEstimate struct {
+	Nodes      int
+	CPUFreed   int64   // millicores of requests released
+	MemFreed   float64 // MiB
+	AvgNodeCPU int64   // average allocatable CPU per node, millicores
+	AvgNodeMem float64 // MiB
+}
+
+// NodeEquivalents returns how many average nodes the released requests add up to, bound
+// by the scarcer resource: a node only goes away when both its CPU and memory do.
+func (e ReclaimEstimate) NodeEquivalents() float64 {
+	if e.AvgNodeCPU == 0 || e.AvgNodeMem == 0 {
+		return 0
+	}
+	return math.Min(float64(e.CPUFreed)/float64(e.AvgNodeCPU), e.MemFreed/e.AvgNodeMem)
+}
+
+// EstimateReclaimable right-sizes every workload to its peak observed usage plus headroom
+// (0.3 = +30%), as PlanRebalance does with a minimum factor of 1, and sums the requests
+// released. Unlike the rebalance plan it ignores placement: it answers "how many nodes'
+// worth of capacity is over-requested", not "which nodes can be drained".
+func EstimateReclaimable(nodes []kube.NodeInfo, headroom float64, skipNamespaces map[string]bool) ReclaimEstimate {
+	e := ReclaimEstimate{Nodes: len(nodes)}
+	if len(nodes) == 0 {
+		return e
+	}
+	var allocCPU int64
+	var allocMem float64
+	for _, n := range nodes {
+		allocCPU += n.AllocatableCPU
+		allocMem += n.AllocatableMem
+	}
+	e.AvgNodeCPU = allocCPU / int64(len(nodes))
+	e.AvgNodeMem = allocMem / float64(len(nodes))
+
+	actions := planDownsizes(newSimNodes(nodes), RebalanceOptions{Headroom: headroom, MinFactor: 1, SkipNamespaces: skipNamespaces})
+	for _, a := range actions {
+		e.CPUFreed += a.CPUFreed
+		e.MemFreed += a.MemFreed
+	}
+	return e
+}
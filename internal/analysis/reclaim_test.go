@@ -0,0 +1,37 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestEstimateReclaimable(t *testing.T) {
+	nodes := []kube.NodeInfo{
+		rebalanceNode("a",
+			rebalancePod("shop", "Deployment", "api", 3000, 100, 12288, 1000), // → 130m, 1300Mi
+			rebalancePod("kube-system", "Deployment", "coredns", 2000, 10, 4096, 100),
+		),
+		rebalanceNode("b", rebalancePod("shop", "Deployment", "web", 3000, 2900, 8192, 8000)), // already tight
+	}
+
+	e := EstimateReclaimable(nodes, 0.3, kube.SystemNamespaces)
+	if e.Nodes != 2 || e.AvgNodeCPU != 4000 || e.AvgNodeMem != 16384 {
+		t.Errorf("nodes/average = %d, %dm/%.0fMi; want 2, 4000m/16384Mi", e.Nodes, e.AvgNodeCPU, e.AvgNodeMem)
+	}
+	if e.CPUFreed != 2870 || e.MemFreed != 10988 {
+		t.Errorf("freed %dm/%.0fMi, want 2870m/10988Mi", e.CPUFreed, e.MemFreed)
+	}
+	// Memory is the scarcer resource: 10988/16384 of a node against 2870/4000.
+	if got, want := e.NodeEquivalents(), 10988.0/16384; math.Abs(got-want) > 1e-9 {
+		t.Errorf("NodeEquivalents() = %.3f, want %.3f", got, want)
+	}
+
+	if e := EstimateReclaimable(nodes, 0.3, nil); e.CPUFreed != 2870+1987 {
+		t.Errorf("with system namespaces freed %dm, want %dm", e.CPUFreed, 2870+1987)
+	}
+	if e := EstimateReclaimable(nil, 0.3, nil); e.NodeEquivalents() != 0 {
+		t.Errorf("no nodes: NodeEquivalents() = %f, want 0", e.NodeEquivalents())
+	}
+}
@@ -0,0 +1,322 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// Suggested container requests and limits are rounded up to these steps.
+const (
+	containerCPUStep = 10 // millicores
+	containerMemStep = 16 // MiB
+)
+
+// Thresholds of the confidence rating, see rateConfidence.
+const (
+	confidenceHighObservations   = 6 // pods × metrics samples
+	confidenceMediumObservations = 3
+	confidenceHighAge            = 7 * 24 * time.Hour
+	confidenceMediumAge          = 24 * time.Hour
+	confidenceHighSpread         = 0.5 // coefficient of variation of CPU usage across pods
+	confidenceMediumSpread       = 1.0
+	confidenceThrottled          = 0.1 // throttled share of CFS periods from which the CPU peak understates demand
+)
+
+// RecommendOptions tunes Recommend.
+type RecommendOptions struct {
+	Headroom  float64 // fraction added on top of peak usage (0.3 = +30%)
+	MinFactor float64 // only recommend when the request is at least this multiple of the recommended value
+
+	// Samples is the number of metrics samples each pod's usage is based on (0 or 1 = a
+	// single scrape); with the pod count it decides how well the peak is observed.
+	Samples int
+	// Now is the reference time for the age of a workload's pods (zero = time.Now()).
+	Now time.Time
+	// Throttling, when read, lowers the confidence in CPU cuts of throttled containers.
+	Throttling map[kube.ContainerKey]kube.CPUThrottling
+}
+
+// ContainerRecommendation holds the current and recommended resources of one container
+// of a workload. Current values are the largest across the workload's pods, Peak the
+// highest usage observed in any of them. New values equal the current ones when unchanged.
+type ContainerRecommendation struct {
+	Name string
+
+	CPUPeak    int64 // millicores
+	CPURequest int64
+	CPULimit   int64   // 0 = not set
+	MemPeak    float64 // MiB
+	MemRequest float64
+	MemLimit   float64 // 0 = not set
+
+	NewCPURequest int64
+	NewCPULimit   int64
+	NewMemRequest float64
+	NewMemLimit   float64
+}
+
+// CPUChanged reports whether the CPU request is lowered.
+func (c ContainerRecommendation) CPUChanged() bool { return c.NewCPURequest != c.CPURequest }
+
+// MemChanged reports whether the memory request is lowered.
+func (c ContainerRecommendation) MemChanged() bool { return c.NewMemRequest != c.MemRequest }
+
+// Recommendation lists the right-sized containers of one workload.
+type Recommendation struct {
+	Namespace string
+	Kind      string
+	Name      string
+	Pods      int
+	Release   string // Helm release the pods are labelled with, see kube.HelmRelease ("" = none)
+
+	// Guaranteed is set when every pod of the workload has the Guaranteed QoS class. Its
+	// limits are lowered together with the requests, keeping requests == limits: lowering
+	// the requests alone would silently demote the pods to Burstable.
+	Guaranteed bool
+
+	Containers []ContainerRecommendation // only the containers with a change
+
+	// Confidence rates how safely the recommendation can be applied as is:
+	// VerdictConfidenceHigh, Medium or Low. ConfidenceReasons say what lowered it.
+	Confidence        Verdict
+	ConfidenceReasons []string
+}
+
+// CPUFreed returns the CPU requests released across all pods of the workload, in millicores.
+func (r Recommendation) CPUFreed() int64 {
+	var freed int64
+	for _, c := range r.Containers {
+		freed += c.CPURequest - c.NewCPURequest
+	}
+	return freed * int64(r.Pods)
+}
+
+// MemFreed returns the memory requests released across all pods of the workload, in MiB.
+func (r Recommendation) MemFreed() float64 {
+	var freed float64
+	for _, c := range r.Containers {
+		freed += c.MemRequest - c.NewMemRequest
+	}
+	return freed * float64(r.Pods)
+}
+
+// Recommend right-sizes the container requests of each workload to the peak usage observed
+// across its pods plus headroom, rounded up to containerCPUStep / containerMemStep. A request
+// is only lowered when it is at least MinFactor times the recommended value. Containers
+// without metrics in any pod are left alone, as are standalone and static pods, which have no
+// template to change.
+//
+// Limits of Guaranteed workloads follow the new requests so the QoS class is preserved; other
+// limits stay as they are. Workloads without any change are left out. Sorted by CPU freed.
+// Each recommendation carries a confidence rating, see rateConfidence.
+func Recommend(pods []kube.PodInfo, opts RecommendOptions) []Recommendation {
+	type workload struct {
+		rec        Recommendation
+		containers map[string]*ContainerRecommendation
+		order      []string
+		unmetered  map[string]bool
+		signals    confidenceSignals
+	}
+
+	byKey := make(map[string]*workload)
+	var keys []string
+	for _, p := range pods {
+		if p.WorkloadKind == "Pod" || p.WorkloadKind == "StaticPod" {
+			continue
+		}
+		key := p.Namespace + "/" + p.WorkloadKind + "/" + p.WorkloadName
+		w, ok := byKey[key]
+		if !ok {
+			w = &workload{
+				rec:        Recommendation{Namespace: p.Namespace, Kind: p.WorkloadKind, Name: p.WorkloadName, Release: kube.HelmRelease(p.Labels, nil), Guaranteed: true},
+				containers: make(map[string]*ContainerRecommendation),
+				unmetered:  make(map[string]bool),
+				signals: confidenceSignals{
+					oomKilled: make(map[string]bool),
+					restarts:  make(map[string]int32),
+					throttled: make(map[string]float64),
+				},
+			}
+			byKey[key] = w
+			keys = append(keys, key)
+		}
+		w.rec.Pods++
+		w.rec.Guaranteed = w.rec.Guaranteed && p.QOSClass == string(corev1.PodQOSGuaranteed)
+		w.signals.observe(p, opts.Throttling)
+		for _, c := range p.Containers {
+			cr, ok := w.containers[c.Name]
+			if !ok {
+				cr = &ContainerRecommendation{Name: c.Name}
+				w.containers[c.Name] = cr
+				w.order = append(w.order, c.Name)
+			}
+			cr.CPURequest = max(cr.CPURequest, c.CPURequest)
+			cr.CPULimit = max(cr.CPULimit, c.CPULimit)
+			cr.MemRequest = max(cr.MemRequest, c.MemRequest)
+			cr.MemLimit = max(cr.MemLimit, c.MemLimit)
+			if !c.MetricsAvailable {
+				w.unmetered[c.Name] = true
+				continue
+			}
+			cr.CPUPeak = max(cr.CPUPeak, c.CPUActual)
+			cr.MemPeak = max(cr.MemPeak, c.MemActual)
+		}
+	}
+
+	var recs []Recommendation
+	for _, key := range keys {
+		w := byKey[key]
+		r := w.rec
+		for _, name := range w.order {
+			if w.unmetered[name] {
+				continue
+			}
+			c := *w.containers[name]
+			recCPU := max(roundUpStep(float64(c.CPUPeak)*(1+opts.Headroom), containerCPUStep), minRightsizedCPU)
+			recMem := float64(max(roundUpStep(c.MemPeak*(1+opts.Headroom), containerMemStep), minRightsizedMem))
+
+			c.NewCPURequest, c.NewCPULimit = c.CPURequest, c.CPULimit
+			c.NewMemRequest, c.NewMemLimit = c.MemRequest, c.MemLimit
+			if lowers(float64(c.CPURequest), float64(recCPU), opts.MinFactor) {
+				c.NewCPURequest = recCPU
+				if r.Guaranteed {
+					c.NewCPULimit = recCPU
+				}
+			}
+			if lowers(c.MemRequest, recMem, opts.MinFactor) {
+				c.NewMemRequest = recMem
+				if r.Guaranteed {
+					c.NewMemLimit = recMem
+				}
+			}
+			if c.CPUChanged() || c.MemChanged() {
+				r.Containers = append(r.Containers, c)
+			}
+		}
+		if len(r.Containers) > 0 {
+			r.Confidence, r.ConfidenceReasons = rateConfidence(r, w.signals, opts)
+			recs = append(recs, r)
+		}
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].CPUFreed() > recs[j].CPUFreed() })
+	return recs
+}
+
+// lowers reports whether a request should be lowered to rec: it is set, larger than rec and
+// at least minFactor times rec.
+func lowers(request, rec, minFactor float64) bool {
+	return request > 0 && request > rec && request >= rec*math.Max(minFactor, 1)
+}
+
+// confidenceSignals collects what a workload's pods tell about how well their usage is known.
+type confidenceSignals struct {
+	metered   int       // pods with metrics
+	oldest    time.Time // creation of the oldest pod
+	podCPU    []float64 // CPU usage of each pod with metrics
+	oomKilled map[string]bool
+	restarts  map[string]int32   // most restarts of a container in any pod
+	throttled map[string]float64 // largest throttled share of a container's CFS periods
+}
+
+func (s *confidenceSignals) observe(p kube.PodInfo, throttling map[kube.ContainerKey]kube.CPUThrottling) {
+	if !p.CreatedAt.IsZero() && (s.oldest.IsZero() || p.CreatedAt.Before(s.oldest)) {
+		s.oldest = p.CreatedAt
+	}
+	if p.MetricsAvailable {
+		s.metered++
+		s.podCPU = append(s.podCPU, float64(p.CPUActual))
+	}
+	for _, c := range p.Containers {
+		s.oomKilled[c.Name] = s.oomKilled[c.Name] || !c.LastOOMKilled.IsZero()
+		s.restarts[c.Name] = max(s.restarts[c.Name], c.Restarts)
+		if t, ok := throttling[kube.ContainerKey{Namespace: p.Namespace, Pod: p.Name, Container: c.Name}]; ok {
+			s.throttled[c.Name] = max(s.throttled[c.Name], t.Ratio())
+		}
+	}
+}
+
+// rateConfidence rates a recommendation by the weakest of its signals:
+//   - observations: pods × metrics samples the peak is taken from,
+//   - age: how long the oldest pod has been running, i.e. which traffic cycles it has seen,
+//   - spread: how much CPU usage varies across the pods,
+//   - OOM kills and restarts of containers whose memory is lowered, and CPU throttling of
+//     containers whose CPU is lowered: there the observed peak understates the real demand.
+func rateConfidence(r Recommendation, s confidenceSignals, opts RecommendOptions) (Verdict, []string) {
+	level := 2 // 2 = High, 1 = Medium, 0 = Low
+	var reasons []string
+	lower := func(to int, reason string) {
+		level = min(level, to)
+		reasons = append(reasons, reason)
+	}
+
+	observations := s.metered * max(opts.Samples, 1)
+	switch {
+	case observations < confidenceMediumObservations:
+		lower(0, fmt.Sprintf("%d %s", observations, kube.Plural(observations, "sample", "samples")))
+	case observations < confidenceHighObservations:
+		lower(1, fmt.Sprintf("%d samples", observations))
+	}
+
+	if !s.oldest.IsZero() {
+		now := opts.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		age := now.Sub(s.oldest)
+		switch {
+		case age < confidenceMediumAge:
+			lower(0, "running for "+duration.HumanDuration(age))
+		case age < confidenceHighAge:
+			lower(1, "running for "+duration.HumanDuration(age))
+		}
+	}
+
+	if spread := coefficientOfVariation(s.podCPU); spread > confidenceMediumSpread {
+		lower(0, fmt.Sprintf("CPU usage varies %.0f%% across pods", spread*100))
+	} else if spread > confidenceHighSpread {
+		lower(1, fmt.Sprintf("CPU usage varies %.0f%% across pods", spread*100))
+	}
+
+	for _, c := range r.Containers {
+		if c.MemChanged() {
+			if s.oomKilled[c.Name] {
+				lower(0, c.Name+" was OOM-killed")
+			} else if n := s.restarts[c.Name]; n > 0 {
+				lower(1, fmt.Sprintf("%s restarted %d %s", c.Name, n, kube.Plural(int(n), "time", "times")))
+			}
+		}
+		if c.CPUChanged() && s.throttled[c.Name] >= confidenceThrottled {
+			lower(0, fmt.Sprintf("%s CPU-throttled %.0f%%", c.Name, s.throttled[c.Name]*100))
+		}
+	}
+
+	return []Verdict{VerdictConfidenceLow, VerdictConfidenceMedium, VerdictConfidenceHigh}[level], reasons
+}
+
+// coefficientOfVariation returns the standard deviation of values relative to their mean
+// (0 for fewer than two values or a zero mean).
+func coefficientOfVariation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sq/float64(len(values))) / mean
+}
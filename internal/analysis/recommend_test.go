@@ -0,0 +1,156 @@
+package analysis
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func recommendPod(ns, kind, workload, qos string, containers ...kube.ContainerInfo) kube.PodInfo {
+	return kube.PodInfo{
+		Namespace:        ns,
+		WorkloadKind:     kind,
+		WorkloadName:     workload,
+		QOSClass:         qos,
+		MetricsAvailable: true,
+		Containers:       containers,
+	}
+}
+
+func recommendContainer(name string, cpuReq, cpuLimit, cpuActual int64, memReq, memLimit, memActual float64) kube.ContainerInfo {
+	return kube.ContainerInfo{
+		Name:             name,
+		CPURequest:       cpuReq,
+		CPULimit:         cpuLimit,
+		CPUActual:        cpuActual,
+		MemRequest:       memReq,
+		MemLimit:         memLimit,
+		MemActual:        memActual,
+		MetricsAvailable: true,
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	unmetered := recommendContainer("sidecar", 500, 0, 0, 512, 0, 0)
+	unmetered.MetricsAvailable = false
+
+	got := Recommend([]kube.PodInfo{
+		// Guaranteed: limits follow the requests.
+		recommendPod("data", "StatefulSet", "db", "Guaranteed", recommendContainer("db", 2000, 2000, 300, 4096, 4096, 1000)),
+		recommendPod("data", "StatefulSet", "db", "Guaranteed", recommendContainer("db", 2000, 2000, 500, 4096, 4096, 1500)),
+		// Burstable: limits stay; memory is below --min-factor and kept.
+		recommendPod("shop", "Deployment", "web", "Burstable",
+			recommendContainer("web", 1000, 2000, 100, 1024, 2048, 600),
+			unmetered),
+		// Standalone pods have no template to change.
+		recommendPod("dev", "Pod", "debug", "Burstable", recommendContainer("debug", 1000, 0, 1, 1024, 0, 10)),
+		// Nothing to lower.
+		recommendPod("shop", "Deployment", "api", "Burstable", recommendContainer("api", 200, 0, 180, 256, 0, 250)),
+	}, RecommendOptions{Headroom: 0.3, MinFactor: 2})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d recommendations, want 2: %+v", len(got), got)
+	}
+
+	db := got[0]
+	if db.Name != "db" || !db.Guaranteed || db.Pods != 2 || len(db.Containers) != 1 {
+		t.Fatalf("first = %+v, want Guaranteed db with 2 pods first (most CPU freed)", db)
+	}
+	// Peak 500m × 1.3 = 650m, 1500Mi × 1.3 = 1950Mi → 1952Mi.
+	if c := db.Containers[0]; c.NewCPURequest != 650 || c.NewCPULimit != 650 || c.NewMemRequest != 1952 || c.NewMemLimit != 1952 {
+		t.Errorf("db = %+v, want requests == limits at 650m / 1952Mi", c)
+	}
+	if db.CPUFreed() != 2*1350 || db.MemFreed() != 2*(4096-1952) {
+		t.Errorf("db freed %dm / %gMi, want %dm / %dMi", db.CPUFreed(), db.MemFreed(), 2*1350, 2*(4096-1952))
+	}
+
+	web := got[1]
+	if web.Name != "web" || web.Guaranteed || len(web.Containers) != 1 {
+		t.Fatalf("second = %+v, want Burstable web with only its metered container", web)
+	}
+	c := web.Containers[0]
+	if c.NewCPURequest != 130 || c.NewCPULimit != 2000 {
+		t.Errorf("web CPU = %dm request / %dm limit, want 130m / 2000m (limit kept)", c.NewCPURequest, c.NewCPULimit)
+	}
+	if c.MemChanged() || c.NewMemLimit != 2048 {
+		t.Errorf("web memory = %gMi / %gMi, want unchanged 1024Mi / 2048Mi", c.NewMemRequest, c.NewMemLimit)
+	}
+}
+
+func TestRecommendMixedQoS(t *testing.T) {
+	// A rollout between Burstable and Guaranteed templates: only a fully Guaranteed
+	// workload gets paired limits.
+	got := Recommend([]kube.PodInfo{
+		recommendPod("shop", "Deployment", "web", "Guaranteed", recommendContainer("web", 1000, 1000, 50, 1024, 1024, 100)),
+		recommendPod("shop", "Deployment", "web", "Burstable", recommendContainer("web", 1000, 2000, 50, 1024, 2048, 100)),
+	}, RecommendOptions{Headroom: 0.3, MinFactor: 2})
+	if len(got) != 1 || got[0].Guaranteed {
+		t.Fatalf("got %+v, want one non-Guaranteed recommendation", got)
+	}
+	if c := got[0].Containers[0]; c.NewCPULimit != 2000 || c.NewMemLimit != 2048 {
+		t.Errorf("limits = %dm / %gMi, want kept at 2000m / 2048Mi", c.NewCPULimit, c.NewMemLimit)
+	}
+}
+
+func TestRecommendConfidence(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	pods := func(n int, age time.Duration, podCPU ...int64) []kube.PodInfo {
+		var out []kube.PodInfo
+		for i := range n {
+			p := recommendPod("shop", "Deployment", "api", "Burstable", recommendContainer("api", 2000, 0, 100, 4096, 0, 500))
+			p.Name = fmt.Sprintf("api-%d", i)
+			p.CreatedAt = now.Add(-age)
+			if i < len(podCPU) {
+				p.CPUActual = podCPU[i]
+			}
+			out = append(out, p)
+		}
+		return out
+	}
+	oomKilled := pods(3, 10*24*time.Hour)
+	oomKilled[1].Containers[0].LastOOMKilled = now.Add(-time.Hour)
+	restarted := pods(3, 10*24*time.Hour)
+	restarted[2].Containers[0].Restarts = 2
+
+	tests := []struct {
+		name       string
+		pods       []kube.PodInfo
+		samples    int
+		throttling map[kube.ContainerKey]kube.CPUThrottling
+		want       Verdict
+		reason     string
+	}{
+		{"well observed", pods(3, 10*24*time.Hour), 2, nil, VerdictConfidenceHigh, ""},
+		{"single scrape of one pod", pods(1, 10*24*time.Hour), 1, nil, VerdictConfidenceLow, "1 sample"},
+		{"few samples", pods(3, 10*24*time.Hour), 1, nil, VerdictConfidenceMedium, "3 samples"},
+		{"young pods", pods(3, 5*time.Hour), 2, nil, VerdictConfidenceLow, "running for 5h"},
+		{"pods a few days old", pods(3, 3*24*time.Hour), 2, nil, VerdictConfidenceMedium, "running for 3d"},
+		{"usage varies across pods", pods(2, 10*24*time.Hour, 100, 400), 3, nil, VerdictConfidenceMedium, "CPU usage varies 60% across pods"},
+		{"OOM-killed", oomKilled, 2, nil, VerdictConfidenceLow, "api was OOM-killed"},
+		{"restarted", restarted, 2, nil, VerdictConfidenceMedium, "api restarted 2 times"},
+		{"throttled", pods(3, 10*24*time.Hour), 2, map[kube.ContainerKey]kube.CPUThrottling{
+			{Namespace: "shop", Pod: "api-0", Container: "api"}: {Periods: 100, ThrottledPeriods: 38},
+		}, VerdictConfidenceLow, "api CPU-throttled 38%"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recs := Recommend(tc.pods, RecommendOptions{Headroom: 0.3, MinFactor: 2, Samples: tc.samples, Now: now, Throttling: tc.throttling})
+			if len(recs) != 1 {
+				t.Fatalf("got %d recommendations, want 1", len(recs))
+			}
+			r := recs[0]
+			if r.Confidence != tc.want {
+				t.Errorf("confidence = %s (%v), want %s", r.Confidence.Label, r.ConfidenceReasons, tc.want.Label)
+			}
+			if tc.reason == "" && len(r.ConfidenceReasons) > 0 {
+				t.Errorf("reasons = %v, want none", r.ConfidenceReasons)
+			}
+			if tc.reason != "" && !slices.Contains(r.ConfidenceReasons, tc.reason) {
+				t.Errorf("reasons = %v, want %q", r.ConfidenceReasons, tc.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// WorkloadTotals sums the requests and usage of a group of workloads.
+type WorkloadTotals struct {
+	Pods       int
+	CPURequest int64      // millicores, all workloads
+	MemRequest float64    // MiB, all workloads
+	Metered    Efficiency // workloads with running, metered pods
+}
+
+func (t *WorkloadTotals) add(w kube.WorkloadInfo) {
+	t.Pods += w.PodCount
+	t.CPURequest += w.CPURequest
+	t.MemRequest += w.MemRequest
+	if w.MetricsAvailable && w.PodCount > 0 {
+		t.Metered.Pods += w.PodCount
+		t.Metered.CPURequested += w.CPURequest
+		t.Metered.CPUActual += w.CPUActual
+		t.Metered.MemRequested += w.MemRequest
+		t.Metered.MemActual += w.MemActual
+	}
+}
+
+// wastesMore orders totals by CPU waste, then CPU requests, descending; ok is false on a tie.
+func (t WorkloadTotals) wastesMore(o WorkloadTotals) (more, ok bool) {
+	if t.Metered.CPUWaste() != o.Metered.CPUWaste() {
+		return t.Metered.CPUWaste() > o.Metered.CPUWaste(), true
+	}
+	if t.CPURequest != o.CPURequest {
+		return t.CPURequest > o.CPURequest, true
+	}
+	return false, false
+}
+
+// ReleaseGroup aggregates the workloads installed by one Helm release.
+type ReleaseGroup struct {
+	Namespace string
+	Release   string   // "" for the workloads of the namespace not installed by Helm
+	Workloads []string // "Kind/name", sorted
+	WorkloadTotals
+}
+
+// GroupByRelease groups workloads by namespace and Helm release, since a chart usually
+// owns several Deployments and StatefulSets that are sized together. Groups are sorted by
+// CPU waste, then CPU requests, descending; workloads outside a release come last, grouped
+// per namespace.
+func GroupByRelease(workloads []kube.WorkloadInfo) []ReleaseGroup {
+	byKey := make(map[string]*ReleaseGroup)
+	for _, w := range workloads {
+		key := w.Namespace + "/" + w.Release
+		g, ok := byKey[key]
+		if !ok {
+			g = &ReleaseGroup{Namespace: w.Namespace, Release: w.Release}
+			byKey[key] = g
+		}
+		g.Workloads = append(g.Workloads, w.Kind+"/"+w.Name)
+		g.add(w)
+	}
+
+	groups := make([]ReleaseGroup, 0, len(byKey))
+	for _, g := range byKey {
+		sort.Strings(g.Workloads)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Release == "") != (b.Release == "") {
+			return b.Release == ""
+		}
+		if more, ok := a.wastesMore(b.WorkloadTotals); ok {
+			return more
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Release < b.Release
+	})
+	return groups
+}
@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestGroupByRelease(t *testing.T) {
+	workload := func(ns, kind, name, release string, pods int, cpuReq, cpuActual int64, metrics bool) kube.WorkloadInfo {
+		return kube.WorkloadInfo{Namespace: ns, Kind: kind, Name: name, Release: release, PodCount: pods,
+			CPURequest: cpuReq, CPUActual: cpuActual, MetricsAvailable: metrics}
+	}
+	groups := GroupByRelease([]kube.WorkloadInfo{
+		workload("monitoring", "StatefulSet", "prometheus", "monitoring", 1, 1000, 600, true),
+		workload("monitoring", "Deployment", "grafana", "monitoring", 1, 200, 50, true),
+		workload("shop", "StatefulSet", "redis", "redis", 3, 750, 100, true),
+		workload("shop", "Deployment", "cart", "", 3, 1500, 1200, true),
+		workload("data", "Deployment", "etl", "", 0, 3000, 0, false), // scaled to zero, no usage
+		workload("staging", "StatefulSet", "redis", "redis", 1, 250, 10, true),
+	})
+
+	want := []struct {
+		namespace, release, workloads string
+		pods                          int
+		cpuRequest, cpuWaste          int64
+	}{
+		{"shop", "redis", "StatefulSet/redis", 3, 750, 650},
+		{"monitoring", "monitoring", "Deployment/grafana,StatefulSet/prometheus", 2, 1200, 550},
+		{"staging", "redis", "StatefulSet/redis", 1, 250, 240},
+		{"shop", "", "Deployment/cart", 3, 1500, 300},
+		{"data", "", "Deployment/etl", 0, 3000, 0},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d: %+v", len(groups), len(want), groups)
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Namespace != w.namespace || g.Release != w.release || strings.Join(g.Workloads, ",") != w.workloads ||
+			g.Pods != w.pods || g.CPURequest != w.cpuRequest || g.Metered.CPUWaste() != w.cpuWaste {
+			t.Errorf("group %d = %s/%s %v pods %d req %d waste %d, want %+v",
+				i, g.Namespace, g.Release, g.Workloads, g.Pods, g.CPURequest, g.Metered.CPUWaste(), w)
+		}
+	}
+}
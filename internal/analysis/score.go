@@ -0,0 +1,48 @@
+package analysis
+
+import "math"
+
+// Severity score weights and saturation points. Absolute waste dominates so that a
+// tiny pod with an extreme ratio ranks below a large workload wasting whole cores.
+const (
+	scoreFactorWeight = 0.3
+	scoreWasteWeight  = 0.5
+	scorePodsWeight   = 0.2
+
+	scoreMaxFactor   = 100         // over-request factor at which the factor part saturates
+	scoreMaxCPUWaste = 4000        // millicores requested but unused at which the waste part saturates
+	scoreMaxMemWaste = 16 * 1024.0 // MiB requested but unused at which the waste part saturates
+	scoreMaxPods     = 16          // pod count at which the pods part saturates
+)
+
+// SeverityScore rates how much a row (pod or workload) is worth fixing, from 0 to 100.
+// It combines the CPU over-request factor (log-scaled), the absolute CPU or memory
+// requested but unused (whichever is larger relative to its saturation point, square
+// root scaled) and the number of pods (log-scaled). CPU is in millicores, memory in MiB.
+func SeverityScore(cpuReq, cpuActual int64, memReq, memActual float64, pods int) int {
+	var factor float64
+	switch {
+	case cpuReq == 0:
+		factor = 0
+	case cpuActual == 0:
+		factor = 1
+	default:
+		factor = math.Log10(float64(cpuReq)/float64(cpuActual)) / math.Log10(scoreMaxFactor)
+	}
+
+	cpuWaste := float64(max(cpuReq-cpuActual, 0)) / scoreMaxCPUWaste
+	memWaste := max(memReq-memActual, 0) / scoreMaxMemWaste
+	waste := math.Sqrt(clamp01(max(cpuWaste, memWaste)))
+
+	var podsPart float64
+	if pods > 1 {
+		podsPart = math.Log2(float64(pods)) / math.Log2(scoreMaxPods)
+	}
+
+	score := scoreFactorWeight*clamp01(factor) + scoreWasteWeight*waste + scorePodsWeight*clamp01(podsPart)
+	return int(math.Round(score * 100))
+}
+
+func clamp01(v float64) float64 {
+	return min(max(v, 0), 1)
+}
@@ -0,0 +1,41 @@
+package analysis
+
+import "testing"
+
+func TestSeverityScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		cpuReq    int64
+		cpuActual int64
+		memReq    float64
+		memActual float64
+		pods      int
+		want      int
+	}{
+		{"no requests scores zero", 0, 0, 0, 0, 1, 0},
+		{"right-sized single pod", 100, 100, 128, 128, 1, 0},
+		{"idle pod counts as maximum factor", 100, 0, 0, 0, 1, 38},
+		{"everything saturated", 8000, 0, 32768, 0, 32, 100},
+		{"memory waste counts when CPU is fine", 100, 100, 16384, 0, 1, 50},
+		{"100x factor on a tiny pod", 100, 1, 0, 0, 1, 38},
+		{"10x factor on a large workload", 40000, 4000, 0, 0, 16, 85},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := SeverityScore(tc.cpuReq, tc.cpuActual, tc.memReq, tc.memActual, tc.pods)
+			if got != tc.want {
+				t.Errorf("SeverityScore(%d, %d, %.0f, %.0f, %d) = %d, want %d",
+					tc.cpuReq, tc.cpuActual, tc.memReq, tc.memActual, tc.pods, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSeverityScoreRanksWasteAboveRatio(t *testing.T) {
+	tiny := SeverityScore(50, 1, 64, 8, 1)
+	large := SeverityScore(8000, 1000, 16384, 4096, 4)
+	if tiny >= large {
+		t.Errorf("tiny 50x pod scored %d, large 8x workload %d; want the workload ranked higher", tiny, large)
+	}
+}
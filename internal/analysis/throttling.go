@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ThrottlingOptions tunes SuggestLimitRemovals.
+type ThrottlingOptions struct {
+	MinThrottled       float64 // share of CFS periods throttled from which a limit hurts (0.25 = 25%)
+	MaxNodeUtilization float64 // nodes using more CPU than this fraction of allocatable have no slack
+}
+
+// LimitRemoval suggests removing the CPU limit of a container that is heavily throttled
+// while its nodes have CPU to spare: the limit, not the node, caps it.
+type LimitRemoval struct {
+	Namespace string
+	Kind      string
+	Name      string
+	Container string
+	Pods      int
+
+	// Guaranteed is set when the workload's pods are Guaranteed: without the limit they
+	// become Burstable.
+	Guaranteed bool
+
+	CPURequest int64 // millicores
+	CPULimit   int64
+	CPUPeak    int64
+
+	Throttled       float64 // highest share of CFS periods throttled across the pods
+	NodeUtilization float64 // highest actual CPU / allocatable of the nodes the pods run on
+}
+
+// SuggestLimitRemovals finds workload containers with a CPU limit that spend at least
+// MinThrottled of their CFS periods throttled while none of their nodes uses more than
+// MaxNodeUtilization of its allocatable CPU. Containers without throttling data and pods on
+// nodes without metrics are skipped, as are standalone and static pods. Sorted by Throttled,
+// descending.
+func SuggestLimitRemovals(pods []kube.PodInfo, throttling map[kube.ContainerKey]kube.CPUThrottling, nodes []kube.NodeInfo, opts ThrottlingOptions) []LimitRemoval {
+	nodeUtil := make(map[string]float64)
+	for _, n := range nodes {
+		if n.MetricsAvailable && n.AllocatableCPU > 0 {
+			nodeUtil[n.Name] = float64(n.ActualCPU) / float64(n.AllocatableCPU)
+		}
+	}
+
+	type container struct {
+		removal LimitRemoval
+		unknown bool // some pod lacks throttling data or node metrics
+	}
+	byKey := make(map[string]*container)
+	var keys []string
+	for _, p := range pods {
+		if p.WorkloadKind == "Pod" || p.WorkloadKind == "StaticPod" {
+			continue
+		}
+		util, nodeKnown := nodeUtil[p.NodeName]
+		for _, c := range p.Containers {
+			if c.CPULimit == 0 {
+				continue
+			}
+			key := p.Namespace + "/" + p.WorkloadKind + "/" + p.WorkloadName + "/" + c.Name
+			e, ok := byKey[key]
+			if !ok {
+				e = &container{removal: LimitRemoval{
+					Namespace:  p.Namespace,
+					Kind:       p.WorkloadKind,
+					Name:       p.WorkloadName,
+					Container:  c.Name,
+					Guaranteed: true,
+				}}
+				byKey[key] = e
+				keys = append(keys, key)
+			}
+			r := &e.removal
+			r.Pods++
+			r.Guaranteed = r.Guaranteed && p.QOSClass == string(corev1.PodQOSGuaranteed)
+			r.CPURequest = max(r.CPURequest, c.CPURequest)
+			r.CPULimit = max(r.CPULimit, c.CPULimit)
+			if c.MetricsAvailable {
+				r.CPUPeak = max(r.CPUPeak, c.CPUActual)
+			}
+			t, ok := throttling[kube.ContainerKey{Namespace: p.Namespace, Pod: p.Name, Container: c.Name}]
+			if !ok || !nodeKnown {
+				e.unknown = true
+				continue
+			}
+			r.Throttled = max(r.Throttled, t.Ratio())
+			r.NodeUtilization = max(r.NodeUtilization, util)
+		}
+	}
+
+	var removals []LimitRemoval
+	for _, key := range keys {
+		e := byKey[key]
+		if e.unknown || e.removal.Throttled < opts.MinThrottled || e.removal.NodeUtilization > opts.MaxNodeUtilization {
+			continue
+		}
+		removals = append(removals, e.removal)
+	}
+	sort.SliceStable(removals, func(i, j int) bool { return removals[i].Throttled > removals[j].Throttled })
+	return removals
+}
@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func throttledPod(workload, node, qos string, cpuLimit int64) kube.PodInfo {
+	p := recommendPod("shop", "Deployment", workload, qos, recommendContainer(workload, 500, cpuLimit, 100, 512, 0, 100))
+	p.Name = workload + "-" + node
+	p.NodeName = node
+	return p
+}
+
+func TestSuggestLimitRemovals(t *testing.T) {
+	nodes := []kube.NodeInfo{
+		{Name: "quiet", AllocatableCPU: 4000, ActualCPU: 1000, MetricsAvailable: true},
+		{Name: "busy", AllocatableCPU: 4000, ActualCPU: 3800, MetricsAvailable: true},
+		{Name: "unmetered", AllocatableCPU: 4000},
+	}
+	pods := []kube.PodInfo{
+		throttledPod("web", "quiet", "Burstable", 1000),
+		throttledPod("db", "quiet", "Guaranteed", 500),
+		// One replica on a node without slack rules the workload out.
+		throttledPod("api", "quiet", "Burstable", 1000),
+		throttledPod("api", "busy", "Burstable", 1000),
+		throttledPod("batch", "unmetered", "Burstable", 1000),
+		throttledPod("cron", "quiet", "Burstable", 1000), // barely throttled
+		throttledPod("nolimit", "quiet", "Burstable", 0),
+		throttledPod("unread", "quiet", "Burstable", 1000), // no throttling data
+	}
+	throttling := make(map[kube.ContainerKey]kube.CPUThrottling)
+	for _, p := range pods {
+		ratio := 0.5
+		switch p.WorkloadName {
+		case "db":
+			ratio = 0.3
+		case "cron":
+			ratio = 0.1
+		case "unread":
+			continue
+		}
+		throttling[kube.ContainerKey{Namespace: p.Namespace, Pod: p.Name, Container: p.WorkloadName}] = kube.CPUThrottling{Periods: 1000, ThrottledPeriods: 1000 * ratio}
+	}
+
+	got := SuggestLimitRemovals(pods, throttling, nodes, ThrottlingOptions{MinThrottled: 0.25, MaxNodeUtilization: 0.7})
+	want := []struct {
+		name       string
+		throttled  float64
+		guaranteed bool
+	}{
+		{name: "web", throttled: 0.5},
+		{name: "db", throttled: 0.3, guaranteed: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want web and db", got)
+	}
+	for i, w := range want {
+		r := got[i]
+		if r.Name != w.name || r.Throttled != w.throttled || r.Guaranteed != w.guaranteed || r.NodeUtilization != 0.25 {
+			t.Errorf("removal %d = %+v, want %s throttled %g, Guaranteed %v, node at 25%%", i, r, w.name, w.throttled, w.guaranteed)
+		}
+	}
+}
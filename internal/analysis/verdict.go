@@ -1,6 +1,11 @@
 package analysis
 
-import "github.com/jedib0t/go-pretty/v6/text"
+import (
+	"fmt"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
 
 // Verdict represents the health verdict for a resource dimension.
 type Verdict struct {
@@ -13,15 +18,44 @@ var (
 	VerdictOverRequested          = Verdict{"Over-requested", text.FgYellow}
 	VerdictBursting               = Verdict{"Bursting", text.FgMagenta}
 	VerdictOK                     = Verdict{"OK", text.FgGreen}
+
+	VerdictOverBudget    = Verdict{"Over budget", text.FgRed}
+	VerdictOvercommitted = Verdict{"Overcommitted", text.FgYellow}
+
+	VerdictEvictionHigh   = Verdict{"High", text.FgRed}
+	VerdictEvictionMedium = Verdict{"Medium", text.FgYellow}
+	VerdictEvictionLow    = Verdict{"Low", text.FgGreen}
+
+	VerdictPDBBlocks = Verdict{"Blocks", text.FgRed}
+	VerdictPDBSlows  = Verdict{"Slows", text.FgYellow}
+
+	VerdictOOMHigh   = Verdict{"High", text.FgRed}
+	VerdictOOMMedium = Verdict{"Medium", text.FgYellow}
+	VerdictOOMLow    = Verdict{"Low", text.FgGreen}
+
+	VerdictConfidenceHigh   = Verdict{"High", text.FgGreen}
+	VerdictConfidenceMedium = Verdict{"Medium", text.FgYellow}
+	VerdictConfidenceLow    = Verdict{"Low", text.FgRed}
+)
+
+// oomLimitProximity is the share of the memory limit above which a container is
+// considered about to be OOM-killed.
+const oomLimitProximity = 0.9
+
+// A resource is over-requested when its request exceeds its usage by more than
+// OverRequestedPoints percentage points, massively so beyond MassivelyOverRequestedPoints.
+const (
+	OverRequestedPoints          = 20
+	MassivelyOverRequestedPoints = 50
 )
 
 // ResourceVerdict returns the verdict given requested% and actual% usage.
 func ResourceVerdict(requestedPct, actualPct float64) Verdict {
 	diff := requestedPct - actualPct
 	switch {
-	case diff > 50:
+	case diff > MassivelyOverRequestedPoints:
 		return VerdictMassivelyOverRequested
-	case diff > 20:
+	case diff > OverRequestedPoints:
 		return VerdictOverRequested
 	case actualPct > requestedPct:
 		return VerdictBursting
@@ -30,13 +64,100 @@ func ResourceVerdict(requestedPct, actualPct float64) Verdict {
 	}
 }
 
-// FactorColors returns the display colors for a CPU over-request factor.
-// req and actual are in millicores.
-func FactorColors(req, actual int64) text.Colors {
+// resourceVerdictRank orders the ResourceVerdict results from harmless to worst.
+// Bursting ranks below over-requesting: it costs no capacity, only risks contention.
+var resourceVerdictRank = map[Verdict]int{
+	VerdictOK:                     0,
+	VerdictBursting:               1,
+	VerdictOverRequested:          2,
+	VerdictMassivelyOverRequested: 3,
+}
+
+// OverallVerdict combines the CPU and memory verdicts of a workload into the worse of
+// the two, so a workload right-sized on CPU but hoarding memory is not reported as OK.
+func OverallVerdict(cpu, mem Verdict) Verdict {
+	if resourceVerdictRank[mem] > resourceVerdictRank[cpu] {
+		return mem
+	}
+	return cpu
+}
+
+// OvercommitVerdict returns the verdict for a limits/allocatable ratio against the
+// maximum tolerated overcommit factor (e.g. 2.0 = limits may add up to twice the node).
+// Any ratio above 1 is overcommitted; above maxRatio it is over budget.
+func OvercommitVerdict(limitRatio, maxRatio float64) Verdict {
+	switch {
+	case limitRatio > maxRatio:
+		return VerdictOverBudget
+	case limitRatio > 1:
+		return VerdictOvercommitted
+	default:
+		return VerdictOK
+	}
+}
+
+// EvictionRisk estimates how early a pod is killed when its node runs out of memory,
+// following the kubelet's eviction order: pods using more memory than they requested
+// (always the case for BestEffort) go first, Guaranteed pods within requests go last.
+// Memory values are in MiB; memLimit 0 means no limit. It returns the verdict and a
+// short human-readable reason.
+func EvictionRisk(qosClass string, memReq, memLimit, memActual float64, nodePressure bool) (Verdict, string) {
+	if memLimit > 0 && memActual >= memLimit*oomLimitProximity {
+		return VerdictEvictionHigh, fmt.Sprintf("at %.0f%% of memory limit (OOM kill)", memActual*100/memLimit)
+	}
+
+	aboveRequest := memActual > memReq
+	switch {
+	case aboveRequest && nodePressure:
+		return VerdictEvictionHigh, "above request on node under memory pressure"
+	case qosClass == "BestEffort":
+		return VerdictEvictionMedium, "BestEffort: first to be evicted"
+	case aboveRequest:
+		return VerdictEvictionMedium, "usage above memory request"
+	case nodePressure && qosClass != "Guaranteed":
+		return VerdictEvictionLow, "within request, node under memory pressure"
+	default:
+		return VerdictEvictionLow, "within request"
+	}
+}
+
+// PodEvictionRisk is EvictionRisk for p. The pod memory limit only counts when every
+// container sets one: with a container unlimited, MemLimit sums the others and is no
+// bound on the pod, so usage near it does not mean an OOM kill.
+func PodEvictionRisk(p kube.PodInfo, nodePressure bool) (Verdict, string) {
+	memLimit := p.MemLimit
+	if p.MissingMemLimit {
+		memLimit = 0
+	}
+	return EvictionRisk(p.QOSClass, p.MemRequest, memLimit, p.MemActual, nodePressure)
+}
+
+// A node is pod-slot-bound when at least podSlotsFullPct of its pod slots are taken while
+// its CPU and memory requests each stay below podSlotsRoomPct of allocatable.
+const (
+	podSlotsFullPct = 90
+	podSlotsRoomPct = 80
+)
+
+// PodSlotsBound reports whether a node runs out of pod slots (max-pods, or its pod CIDR)
+// before CPU or memory: resources look available, but nothing more schedules there.
+func PodSlotsBound(n kube.NodeInfo) bool {
+	slots := n.PodSlots()
+	if slots == 0 || n.AllocatableCPU == 0 || n.AllocatableMem == 0 {
+		return false
+	}
+	return float64(n.ScheduledPods)*100 >= float64(slots)*podSlotsFullPct &&
+		float64(n.RequestedCPU)*100 < float64(n.AllocatableCPU)*podSlotsRoomPct &&
+		n.RequestedMem*100 < n.AllocatableMem*podSlotsRoomPct
+}
+
+// FactorColors returns the display colors for an over-request factor.
+// req and actual are in millicores (CPU) or MiB (memory).
+func FactorColors[T int64 | float64](req, actual T) text.Colors {
 	if req == 0 || actual == 0 {
 		return text.Colors{text.Faint}
 	}
-	factor := req / actual
+	factor := int64(req / actual)
 	switch {
 	case factor >= 50:
 		return text.Colors{text.Bold, text.FgRed}
@@ -3,6 +3,7 @@ package analysis
 import (
 	"testing"
 
+	"github.com/amasotti/kusa/internal/kube"
 	"github.com/jedib0t/go-pretty/v6/text"
 )
 
@@ -77,3 +78,132 @@ func TestFactorColors(t *testing.T) {
 		})
 	}
 }
+
+func TestOverallVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		cpu, mem Verdict
+		want     Verdict
+	}{
+		{"both OK", VerdictOK, VerdictOK, VerdictOK},
+		{"memory worse than CPU", VerdictOK, VerdictMassivelyOverRequested, VerdictMassivelyOverRequested},
+		{"CPU worse than memory", VerdictOverRequested, VerdictOK, VerdictOverRequested},
+		{"over-requested outranks bursting", VerdictBursting, VerdictOverRequested, VerdictOverRequested},
+		{"bursting outranks OK", VerdictOK, VerdictBursting, VerdictBursting},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := OverallVerdict(tc.cpu, tc.mem)
+			if got != tc.want {
+				t.Errorf("OverallVerdict(%q, %q) = %q, want %q", tc.cpu.Label, tc.mem.Label, got.Label, tc.want.Label)
+			}
+		})
+	}
+}
+
+func TestOvercommitVerdict(t *testing.T) {
+	tests := []struct {
+		name       string
+		limitRatio float64
+		maxRatio   float64
+		want       Verdict
+	}{
+		{"no limits at all", 0, 2, VerdictOK},
+		{"limits below allocatable", 0.8, 2, VerdictOK},
+		{"limits exactly allocatable", 1, 2, VerdictOK},
+		{"overcommitted within budget", 1.5, 2, VerdictOvercommitted},
+		{"exactly at budget", 2, 2, VerdictOvercommitted},
+		{"over budget", 2.1, 2, VerdictOverBudget},
+		{"budget of 1 flags any overcommit", 1.01, 1, VerdictOverBudget},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := OvercommitVerdict(tc.limitRatio, tc.maxRatio)
+			if got != tc.want {
+				t.Errorf("OvercommitVerdict(%.2f, %.2f) = %q, want %q", tc.limitRatio, tc.maxRatio, got.Label, tc.want.Label)
+			}
+		})
+	}
+}
+
+func TestPodSlotsBound(t *testing.T) {
+	node := func(pods int, allocatablePods, cidrAddresses int64, cpuReq int64, memReq float64) kube.NodeInfo {
+		return kube.NodeInfo{
+			AllocatableCPU: 4000, AllocatableMem: 16384, RequestedCPU: cpuReq, RequestedMem: memReq,
+			AllocatablePods: allocatablePods, PodCIDRAddresses: cidrAddresses, ScheduledPods: pods,
+		}
+	}
+	tests := []struct {
+		name string
+		node kube.NodeInfo
+		want bool
+	}{
+		{"slots full, resources free", node(110, 110, 0, 1000, 4096), true},
+		{"slots at 90%", node(99, 110, 0, 1000, 4096), true},
+		{"slots below 90%", node(98, 110, 0, 1000, 4096), false},
+		{"slots full, CPU full too", node(110, 110, 0, 3900, 4096), false},
+		{"slots full, memory full too", node(110, 110, 0, 1000, 15000), false},
+		{"pod CIDR caps below max-pods", node(60, 110, 62, 1000, 4096), true},
+		{"pod CIDR above max-pods", node(60, 110, 254, 1000, 4096), false},
+		{"host-network pods take no pod IP", func() kube.NodeInfo { n := node(60, 110, 62, 1000, 4096); n.HostNetworkPods = 10; return n }(), false},
+		{"no slots reported", node(60, 0, 0, 1000, 4096), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := PodSlotsBound(tc.node); got != tc.want {
+				t.Errorf("PodSlotsBound() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvictionRisk(t *testing.T) {
+	tests := []struct {
+		name                        string
+		qos                         string
+		memReq, memLimit, memActual float64
+		pressure                    bool
+		want                        Verdict
+	}{
+		{"near limit is high", "Burstable", 256, 1024, 950, false, VerdictEvictionHigh},
+		{"guaranteed near limit is high", "Guaranteed", 1024, 1024, 1000, false, VerdictEvictionHigh},
+		{"above request under pressure is high", "Burstable", 256, 0, 512, true, VerdictEvictionHigh},
+		{"best effort under pressure is high", "BestEffort", 0, 0, 10, true, VerdictEvictionHigh},
+		{"best effort without pressure is medium", "BestEffort", 0, 0, 10, false, VerdictEvictionMedium},
+		{"above request without pressure is medium", "Burstable", 256, 0, 300, false, VerdictEvictionMedium},
+		{"within request is low", "Burstable", 512, 1024, 300, false, VerdictEvictionLow},
+		{"within request under pressure is low", "Burstable", 512, 0, 300, true, VerdictEvictionLow},
+		{"guaranteed within request is low", "Guaranteed", 1024, 1024, 300, true, VerdictEvictionLow},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := EvictionRisk(tc.qos, tc.memReq, tc.memLimit, tc.memActual, tc.pressure)
+			if got != tc.want {
+				t.Errorf("EvictionRisk(%s, req=%g, limit=%g, actual=%g, pressure=%v) = %q, want %q",
+					tc.qos, tc.memReq, tc.memLimit, tc.memActual, tc.pressure, got.Label, tc.want.Label)
+			}
+		})
+	}
+}
+
+func TestPodEvictionRisk(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  kube.PodInfo
+		want Verdict
+	}{
+		{"every container limited, near the limit", kube.PodInfo{QOSClass: "Burstable", MemRequest: 256, MemLimit: 512, MemActual: 500}, VerdictEvictionHigh},
+		{"a container unlimited, past the others' limits", kube.PodInfo{QOSClass: "Burstable", MemRequest: 1024, MemLimit: 512, MemActual: 900, MissingMemLimit: true}, VerdictEvictionLow},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, reason := PodEvictionRisk(tc.pod, false); got != tc.want {
+				t.Errorf("PodEvictionRisk(%+v) = %q (%s), want %q", tc.pod, got.Label, reason, tc.want.Label)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package analysis
+
+import (
+	"slices"
+
+	"github.com/amasotti/kusa/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// WhatIfChange is a hypothetical change to one workload: its replica count and/or per-pod requests.
+type WhatIfChange struct {
+	Namespace, Kind, Name string
+	Replicas              int     // ignored for DaemonSets, which run one pod per node
+	CPURequest            int64   // millicores per pod
+	MemRequest            float64 // MiB per pod
+
+	// Placement constraints of the pods, see kube.PodInfo. Labels are matched by the pod
+	// anti-affinity of the workload itself and of other pods.
+	Labels       map[string]string
+	Tolerations  []corev1.Toleration
+	NodeSelector map[string]string
+	Affinity     *corev1.Affinity
+}
+
+// WhatIfNode is a node's request load before and after the change.
+type WhatIfNode struct {
+	Name                string
+	AllocatableCPU      int64
+	AllocatableMem      float64
+	Unschedulable       bool
+	Untolerated         bool    // tainted in a way the workload's pods don't tolerate
+	Unselected          bool    // not matched by the pods' node selector or required node affinity
+	CPUBefore, CPUAfter int64   // requested millicores
+	MemBefore, MemAfter float64 // requested MiB
+	PodsBefore          int     // pods of the workload
+	PodsAfter           int
+}
+
+// WhatIfResult is the outcome of SimulateWhatIf.
+type WhatIfResult struct {
+	Nodes    []WhatIfNode
+	Unplaced int // pods no node has request headroom for
+}
+
+// WorkloadPods returns the running pods of the workload, matched by namespace and name
+// and, when kind is non-empty, by kind.
+func WorkloadPods(nodes []kube.NodeInfo, namespace, kind, name string) []kube.PodInfo {
+	var pods []kube.PodInfo
+	for _, n := range nodes {
+		for _, p := range n.Pods {
+			if p.Namespace == namespace && p.WorkloadName == name && (kind == "" || p.WorkloadKind == kind) {
+				pods = append(pods, p)
+			}
+		}
+	}
+	return pods
+}
+
+// SimulateWhatIf applies change to the cluster by requests.
+//
+// When the per-pod requests change, the rollout replaces every pod: change.Replicas pods
+// with the new requests are spread over schedulable nodes like the default scheduler
+// scoring does (least allocated first, up to allocatable), honoring the pods' placement
+// constraints: tolerations, node selector, required node affinity and required pod
+// anti-affinity. A pure scale keeps running pods in place, spreads the additional ones
+// the same way and removes surplus pods from the nodes running most of them. DaemonSet
+// pods stay on their nodes and only count as unplaced when their node runs out of room.
+func SimulateWhatIf(nodes []kube.NodeInfo, change WhatIfChange) WhatIfResult {
+	sim := newSimNodes(nodes)
+	isWorkload := func(p *simPod) bool {
+		return p.namespace == change.Namespace && p.kind == change.Kind && p.workload == change.Name
+	}
+
+	result := WhatIfResult{Nodes: make([]WhatIfNode, len(nodes))}
+	rollout := change.Kind == "DaemonSet"
+	current := 0
+	for i, n := range sim {
+		result.Nodes[i] = WhatIfNode{
+			Name:           n.name,
+			AllocatableCPU: n.allocCPU,
+			AllocatableMem: n.allocMem,
+			Unschedulable:  n.cordoned,
+			Untolerated:    !n.info.ToleratedBy(change.Tolerations),
+			Unselected:     !n.info.MatchesNodeSelector(change.NodeSelector, change.Affinity),
+			CPUBefore:      n.reqCPU,
+			MemBefore:      n.reqMem,
+		}
+		for _, p := range n.pods {
+			if isWorkload(p) {
+				result.Nodes[i].PodsBefore++
+				current++
+				rollout = rollout || p.cpu != change.CPURequest || p.mem != change.MemRequest
+			}
+		}
+	}
+
+	newPod := func() *simPod {
+		return &simPod{
+			namespace:    change.Namespace,
+			kind:         change.Kind,
+			workload:     change.Name,
+			cpu:          change.CPURequest,
+			mem:          change.MemRequest,
+			labels:       change.Labels,
+			tolerations:  change.Tolerations,
+			nodeSelector: change.NodeSelector,
+			affinity:     change.Affinity,
+		}
+	}
+
+	switch {
+	case change.Kind == "DaemonSet":
+		for _, n := range sim {
+			for _, p := range n.pods {
+				if !isWorkload(p) {
+					continue
+				}
+				n.reqCPU += change.CPURequest - p.cpu
+				n.reqMem += change.MemRequest - p.mem
+				p.cpu, p.mem = change.CPURequest, change.MemRequest
+				if n.reqCPU > n.allocCPU || n.reqMem > n.allocMem {
+					result.Unplaced++
+				}
+			}
+		}
+	case rollout:
+		for _, n := range sim {
+			for _, p := range slices.Clone(n.pods) {
+				if isWorkload(p) {
+					n.removePod(p)
+				}
+			}
+		}
+		result.Unplaced = spreadNewPods(sim, change.Replicas, newPod)
+	case change.Replicas > current:
+		result.Unplaced = spreadNewPods(sim, change.Replicas-current, newPod)
+	default:
+		for range current - change.Replicas {
+			var (
+				from  *simNode
+				count int
+			)
+			for _, n := range sim {
+				c := 0
+				for _, p := range n.pods {
+					if isWorkload(p) {
+						c++
+					}
+				}
+				if c > count {
+					from, count = n, c
+				}
+			}
+			if from == nil {
+				break // no pod of the workload left to remove
+			}
+			for _, p := range from.pods {
+				if isWorkload(p) {
+					from.removePod(p)
+					break
+				}
+			}
+		}
+	}
+
+	for i, n := range sim {
+		result.Nodes[i].CPUAfter = n.reqCPU
+		result.Nodes[i].MemAfter = n.reqMem
+		for _, p := range n.pods {
+			if isWorkload(p) {
+				result.Nodes[i].PodsAfter++
+			}
+		}
+	}
+	return result
+}
+
+// spreadNewPods places count pods built by newPod least-allocated first and returns
+// how many found no node with room.
+func spreadNewPods(sim []*simNode, count int, newPod func() *simPod) (unplaced int) {
+	pods := make([]*simPod, max(count, 0))
+	for i := range pods {
+		pods[i] = newPod()
+	}
+	placements, _ := placePods(pods, sim, nil, 1, leastAllocated)
+	for _, pl := range placements {
+		if pl.node == nil {
+			unplaced++
+			continue
+		}
+		pl.node.addPod(pl.pod)
+	}
+	return unplaced
+}
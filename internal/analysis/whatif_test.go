@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSimulateWhatIf(t *testing.T) {
+	web := rebalancePod("shop", "Deployment", "web", 1000, 500, 1024, 512)
+	agent := rebalancePod("kube-system", "DaemonSet", "agent", 100, 50, 128, 100)
+	infra := rebalanceNode("b")
+	infra.Taints = []corev1.Taint{{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule}}
+	infraToleration := []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "infra", Effect: corev1.TaintEffectNoSchedule}}
+	ssd := rebalanceNode("b")
+	ssd.Labels = map[string]string{"disk": "ssd"}
+
+	webLabels := map[string]string{"app": "web"}
+	oneWebPerNode := &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: webLabels},
+			TopologyKey:   corev1.LabelHostname,
+		}},
+	}}
+	spreadWeb := web
+	spreadWeb.Labels, spreadWeb.Affinity = webLabels, oneWebPerNode
+
+	tests := []struct {
+		name         string
+		nodes        []kube.NodeInfo
+		change       WhatIfChange
+		wantAfter    []int // workload pods per node after the change
+		wantUnplaced int
+	}{
+		{
+			name:      "scale up spreads over least allocated nodes",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web), rebalanceNode("b")},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 4, CPURequest: 1000, MemRequest: 1024},
+			wantAfter: []int{2, 2},
+		},
+		{
+			name:      "scale down removes pods from the busiest node",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web, web), rebalanceNode("b", web)},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 2, CPURequest: 1000, MemRequest: 1024},
+			wantAfter: []int{1, 1},
+		},
+		{
+			name:      "negative replicas remove every pod",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web, web), rebalanceNode("b", web)},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: -1, CPURequest: 1000, MemRequest: 1024},
+			wantAfter: []int{0, 0},
+		},
+		{
+			name:      "negative replicas with a request change place nothing",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web), rebalanceNode("b")},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: -1, CPURequest: 250, MemRequest: 1024},
+			wantAfter: []int{0, 0},
+		},
+		{
+			name:      "request change re-places every pod",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web, web), rebalanceNode("b")},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 2, CPURequest: 250, MemRequest: 1024},
+			wantAfter: []int{1, 1},
+		},
+		{
+			name:         "scale beyond capacity leaves pods unplaced",
+			nodes:        []kube.NodeInfo{rebalanceNode("a", web), rebalanceNode("b")},
+			change:       WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 10, CPURequest: 1000, MemRequest: 1024},
+			wantAfter:    []int{4, 4},
+			wantUnplaced: 2,
+		},
+		{
+			name: "cordoned node is skipped",
+			nodes: []kube.NodeInfo{
+				rebalanceNode("a", web),
+				{Name: "b", AllocatableCPU: 4000, AllocatableMem: 16384, Unschedulable: true},
+			},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 3, CPURequest: 1000, MemRequest: 1024},
+			wantAfter: []int{3, 0},
+		},
+		{
+			name:      "tainted node is skipped without a toleration",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web), infra},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 3, CPURequest: 1000, MemRequest: 1024},
+			wantAfter: []int{3, 0},
+		},
+		{
+			name:      "tolerating pods use the tainted node",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web), infra},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 3, CPURequest: 1000, MemRequest: 1024, Tolerations: infraToleration},
+			wantAfter: []int{2, 1},
+		},
+		{
+			name:      "node selector limits the nodes",
+			nodes:     []kube.NodeInfo{rebalanceNode("a", web), ssd},
+			change:    WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 3, CPURequest: 1000, MemRequest: 1024, NodeSelector: map[string]string{"disk": "ssd"}},
+			wantAfter: []int{1, 2},
+		},
+		{
+			name:         "anti-affinity allows one pod per node",
+			nodes:        []kube.NodeInfo{rebalanceNode("a", spreadWeb), rebalanceNode("b"), rebalanceNode("c")},
+			change:       WhatIfChange{Namespace: "shop", Kind: "Deployment", Name: "web", Replicas: 4, CPURequest: 1000, MemRequest: 1024, Labels: webLabels, Affinity: oneWebPerNode},
+			wantAfter:    []int{1, 1, 1},
+			wantUnplaced: 1,
+		},
+		{
+			name:         "daemonset request change stays on its nodes",
+			nodes:        []kube.NodeInfo{rebalanceNode("a", agent, rebalancePod("shop", "Deployment", "big", 3500, 100, 1024, 512)), rebalanceNode("b", agent)},
+			change:       WhatIfChange{Namespace: "kube-system", Kind: "DaemonSet", Name: "agent", CPURequest: 1000, MemRequest: 128},
+			wantAfter:    []int{1, 1},
+			wantUnplaced: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := SimulateWhatIf(tc.nodes, tc.change)
+			if got.Unplaced != tc.wantUnplaced {
+				t.Errorf("Unplaced = %d, want %d", got.Unplaced, tc.wantUnplaced)
+			}
+			for i, n := range got.Nodes {
+				if n.PodsAfter != tc.wantAfter[i] {
+					t.Errorf("node %s: PodsAfter = %d, want %d", n.Name, n.PodsAfter, tc.wantAfter[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Package config loads the kusa configuration file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the content of the configuration file.
+type Config struct {
+	// Ignore parks known issues: matching pods and workloads are left out of the findings
+	// until the rule expires.
+	Ignore []IgnoreRule `json:"ignore,omitempty"`
+
+	// SMTP is the mail server --email-to sends reports through.
+	SMTP *SMTP `json:"smtp,omitempty"`
+}
+
+// DefaultSMTPPort is the mail submission port, where the server offers STARTTLS.
+const DefaultSMTPPort = 587
+
+// DefaultSMTPPasswordEnv is the environment variable the SMTP password is read from, so the
+// configuration file holds no secret.
+const DefaultSMTPPasswordEnv = "KUSA_SMTP_PASSWORD"
+
+// SMTP is a mail server to submit reports to. With a username, the password is read from
+// the environment variable PasswordEnv.
+type SMTP struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port,omitempty"` // default DefaultSMTPPort
+	From        string `json:"from"`
+	Username    string `json:"username,omitempty"`
+	PasswordEnv string `json:"passwordEnv,omitempty"` // default DefaultSMTPPasswordEnv
+}
+
+// IgnoreRule matches workloads by namespace and name. Empty patterns match everything;
+// at least one must be set.
+type IgnoreRule struct {
+	Namespace string `json:"namespace,omitempty"` // shell glob on the namespace
+	Workload  string `json:"workload,omitempty"`  // shell glob on the workload name
+	Expires   string `json:"expires,omitempty"`   // last day the rule applies, YYYY-MM-DD ("" = never expires)
+	Reason    string `json:"reason,omitempty"`
+
+	expires time.Time // start of the day after Expires
+}
+
+// DefaultPath returns where the configuration file is read from without --config:
+// kusa/config.yaml in the user's configuration directory ($XDG_CONFIG_HOME or ~/.config
+// on Linux).
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kusa", "config.yaml"), nil
+}
+
+// Load reads and validates the configuration file. With optional set, a missing file
+// yields an empty configuration.
+func Load(file string, optional bool) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) && optional {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var c Config
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", file, err)
+	}
+	for i := range c.Ignore {
+		if err := c.Ignore[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid config %s: ignore[%d]: %w", file, i, err)
+		}
+	}
+	if c.SMTP != nil {
+		if err := c.SMTP.validate(); err != nil {
+			return nil, fmt.Errorf("invalid config %s: smtp: %w", file, err)
+		}
+	}
+	return &c, nil
+}
+
+func (s *SMTP) validate() error {
+	if s.Host == "" || s.From == "" {
+		return errors.New("set host and from")
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		return fmt.Errorf("invalid from %q: %w", s.From, err)
+	}
+	if s.Port == 0 {
+		s.Port = DefaultSMTPPort
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		return fmt.Errorf("invalid port %d", s.Port)
+	}
+	if s.PasswordEnv == "" {
+		s.PasswordEnv = DefaultSMTPPasswordEnv
+	}
+	return nil
+}
+
+func (r *IgnoreRule) validate() error {
+	if r.Namespace == "" && r.Workload == "" {
+		return errors.New("set namespace, workload or both")
+	}
+	for _, p := range []string{r.Namespace, r.Workload} {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	if r.Expires != "" {
+		day, err := time.ParseInLocation(time.DateOnly, r.Expires, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid expires %q, want YYYY-MM-DD", r.Expires)
+		}
+		r.expires = day.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+// Matches reports whether the rule covers the workload name in namespace.
+func (r IgnoreRule) Matches(namespace, workload string) bool {
+	return glob(r.Namespace, namespace) && glob(r.Workload, workload)
+}
+
+// Expired reports whether the rule's last day is before now.
+func (r IgnoreRule) Expired(now time.Time) bool {
+	return !r.expires.IsZero() && !now.Before(r.expires)
+}
+
+// String describes the rule for messages, e.g. "namespace=dev workload=legacy-*".
+func (r IgnoreRule) String() string {
+	s := ""
+	if r.Namespace != "" {
+		s = "namespace=" + r.Namespace
+	}
+	if r.Workload != "" {
+		if s != "" {
+			s += " "
+		}
+		s += "workload=" + r.Workload
+	}
+	return s
+}
+
+// ActiveIgnores splits the ignore rules into those in effect at now and the expired ones.
+func (c *Config) ActiveIgnores(now time.Time) (active, expired []IgnoreRule) {
+	for _, r := range c.Ignore {
+		if r.Expired(now) {
+			expired = append(expired, r)
+		} else {
+			active = append(active, r)
+		}
+	}
+	return active, expired
+}
+
+func glob(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
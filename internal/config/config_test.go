@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	file := writeConfig(t, `
+ignore:
+  - namespace: dev
+    reason: preview environments
+  - namespace: data
+    workload: etl-*
+    expires: 2026-03-31
+`)
+	c, err := Load(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Ignore) != 2 || c.Ignore[0].Reason != "preview environments" || c.Ignore[1].Expires != "2026-03-31" {
+		t.Fatalf("Ignore = %+v", c.Ignore)
+	}
+
+	active, expired := c.ActiveIgnores(time.Date(2026, 3, 31, 23, 0, 0, 0, time.Local))
+	if len(active) != 2 || len(expired) != 0 {
+		t.Errorf("on the last day: %d active, %d expired, want 2, 0", len(active), len(expired))
+	}
+	active, expired = c.ActiveIgnores(time.Date(2026, 4, 1, 0, 0, 0, 0, time.Local))
+	if len(active) != 1 || len(expired) != 1 || expired[0].Workload != "etl-*" {
+		t.Errorf("the day after: active %v, expired %v, want the etl-* rule expired", active, expired)
+	}
+}
+
+func TestLoadSMTP(t *testing.T) {
+	c, err := Load(writeConfig(t, "smtp:\n  host: mail.example.com\n  from: Capacity <kusa@example.com>\n  username: kusa\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SMTP{Host: "mail.example.com", Port: DefaultSMTPPort, From: "Capacity <kusa@example.com>", Username: "kusa", PasswordEnv: DefaultSMTPPasswordEnv}
+	if c.SMTP == nil || *c.SMTP != want {
+		t.Errorf("SMTP = %+v, want %+v", c.SMTP, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown field", "ignore:\n  - namespace: dev\n    team: web\n", "unknown field"},
+		{"empty rule", "ignore:\n  - reason: nothing\n", "ignore[0]: set namespace, workload or both"},
+		{"bad pattern", "ignore:\n  - workload: '[etl'\n", "invalid pattern"},
+		{"bad date", "ignore:\n  - namespace: dev\n    expires: next week\n", "want YYYY-MM-DD"},
+		{"smtp without host", "smtp:\n  from: kusa@example.com\n", "smtp: set host and from"},
+		{"smtp bad from", "smtp:\n  host: mail.example.com\n  from: kusa\n", "invalid from"},
+		{"smtp bad port", "smtp:\n  host: mail.example.com\n  from: kusa@example.com\n  port: 70000\n", "invalid port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content), false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	if c, err := Load(missing, true); err != nil || len(c.Ignore) != 0 {
+		t.Errorf("optional missing file: %+v, %v, want empty config", c, err)
+	}
+	if _, err := Load(missing, false); err == nil {
+		t.Error("missing file given explicitly loaded, want error")
+	}
+}
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	tests := []struct {
+		rule                IgnoreRule
+		namespace, workload string
+		want                bool
+	}{
+		{IgnoreRule{Namespace: "dev"}, "dev", "preview-api", true},
+		{IgnoreRule{Namespace: "dev"}, "shop", "preview-api", false},
+		{IgnoreRule{Workload: "etl-*"}, "data", "etl-worker", true},
+		{IgnoreRule{Namespace: "data", Workload: "etl-*"}, "staging", "etl-worker", false},
+		{IgnoreRule{Namespace: "team-*", Workload: "*-canary"}, "team-web", "cart-canary", true},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Matches(tt.namespace, tt.workload); got != tt.want {
+			t.Errorf("%s matches %s/%s = %v, want %v", tt.rule, tt.namespace, tt.workload, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package diag reports warnings and progress notices on stderr, keeping stdout for
+// results (tables, NDJSON, templates). Warnings are collected during a run and written
+// together at the end, where they are not scrolled away by the tables.
+package diag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	mu       sync.Mutex
+	out      io.Writer = os.Stderr
+	quiet    bool
+	warnings []warning
+)
+
+type warning struct {
+	msg   string
+	count int
+}
+
+// SetQuiet suppresses notices and warnings (--quiet). Errors are still reported.
+func SetQuiet(v bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	quiet = v
+}
+
+// Infof writes a progress notice immediately, unless quiet.
+func Infof(format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !quiet {
+		fmt.Fprintf(out, format+"\n", args...)
+	}
+}
+
+// Warnf records a warning for Flush. Repeats of the same message are counted once.
+// Safe for concurrent use by fetchers.
+func Warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range warnings {
+		if warnings[i].msg == msg {
+			warnings[i].count++
+			return
+		}
+	}
+	warnings = append(warnings, warning{msg: msg, count: 1})
+}
+
+// Flush writes the collected warnings in the order they were first seen, unless quiet,
+// and clears them.
+func Flush() {
+	mu.Lock()
+	defer mu.Unlock()
+	if !quiet {
+		for _, w := range warnings {
+			if w.count > 1 {
+				fmt.Fprintf(out, "Warning: %s (%d times)\n", w.msg, w.count)
+			} else {
+				fmt.Fprintf(out, "Warning: %s\n", w.msg)
+			}
+		}
+	}
+	warnings = nil
+}
@@ -0,0 +1,40 @@
+package diag
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name  string
+		quiet bool
+		want  string
+	}{
+		{"warnings deduplicated after notices", false, "starting\nWarning: metrics stale\nWarning: label missing (2 times)\n"},
+		{"quiet suppresses everything", true, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			prev := out
+			out = &buf
+			SetQuiet(tc.quiet)
+			t.Cleanup(func() { out = prev; SetQuiet(false) })
+
+			Infof("starting")
+			Warnf("metrics stale")
+			Warnf("label %s", "missing")
+			Warnf("label %s", "missing")
+			Flush()
+
+			if got := buf.String(); got != tc.want {
+				t.Errorf("output = %q, want %q", got, tc.want)
+			}
+			if len(warnings) != 0 {
+				t.Errorf("Flush left %d warnings", len(warnings))
+			}
+		})
+	}
+}
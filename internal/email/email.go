@@ -0,0 +1,138 @@
+// Package email sends saved reports as mail attachments.
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server is an SMTP server to submit mail to. Without a username no authentication is
+// attempted; with one, the server must offer STARTTLS, as net/smtp refuses to send the
+// password in the clear.
+type Server struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Name    string // file name shown to the recipient
+	Content []byte
+}
+
+// Message is a plain-text mail with attachments.
+type Message struct {
+	From        string
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// ParseRecipients validates the addresses given to --email-to and returns them bare
+// (without display names), as SMTP expects.
+func ParseRecipients(addrs []string) ([]string, error) {
+	var to []string
+	for _, a := range addrs {
+		parsed, err := mail.ParseAddress(strings.TrimSpace(a))
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address %q: %w", a, err)
+		}
+		to = append(to, parsed.Address)
+	}
+	return to, nil
+}
+
+// Send submits m to s.
+func Send(s Server, m Message, now time.Time) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", m.From, err)
+	}
+	data, err := m.bytes(now)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	if err := smtp.SendMail(addr, auth, from.Address, m.To, data); err != nil {
+		return fmt.Errorf("failed to send email via %s: %w", addr, err)
+	}
+	return nil
+}
+
+// bytes renders m as a multipart/mixed MIME message: the body as text/plain, then each
+// attachment base64-encoded as text/markdown.
+func (m Message) bytes(now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	header := []string{
+		"From: " + m.From,
+		"To: " + strings.Join(m.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary=" + strconv.Quote(w.Boundary()),
+	}
+	buf.WriteString(strings.Join(header, "\r\n") + "\r\n\r\n")
+
+	body, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBase64(body, []byte(m.Body)); err != nil {
+		return nil, err
+	}
+	for _, a := range m.Attachments {
+		name := filepath.Base(a.Name)
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType("text/markdown", map[string]string{"charset": "utf-8", "name": name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, a.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in lines of 76 characters, the limit of RFC 2045.
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(len(encoded), 76)
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:n]); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRecipients(t *testing.T) {
+	tests := []struct {
+		addrs   []string
+		want    string
+		wantErr bool
+	}{
+		{addrs: []string{"ops@example.com"}, want: "ops@example.com"},
+		{addrs: []string{"Capacity <capacity@example.com>", " ops@example.com"}, want: "capacity@example.com,ops@example.com"},
+		{addrs: []string{"ops"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRecipients(tt.addrs)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseRecipients(%q) err = %v, wantErr %v", tt.addrs, err, tt.wantErr)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("ParseRecipients(%q) = %q, want %q", tt.addrs, got, tt.want)
+		}
+	}
+}
+
+func TestMessageBytes(t *testing.T) {
+	report := "# kusa deployments — prod\n\n" + strings.Repeat("| row |\n", 50)
+	m := Message{
+		From:        "kusa@example.com",
+		To:          []string{"ops@example.com", "capacity@example.com"},
+		Subject:     "kusa deployments — prod",
+		Body:        "See attached.",
+		Attachments: []Attachment{{Name: "output/prod/deployments_20261015_120000.md", Content: []byte(report)}},
+	}
+	data, err := m.bytes(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err != nil || subject != m.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, m.Subject)
+	}
+	if to := msg.Header.Get("To"); to != "ops@example.com, capacity@example.com" {
+		t.Errorf("To = %q", to)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/mixed", mediaType, err)
+	}
+
+	var parts []string
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := io.ReadAll(p)
+		for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\r\n") {
+			if len(line) > 76 {
+				t.Errorf("line of %d characters, want at most 76", len(line))
+			}
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(raw), "\r\n", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, p.FileName()+":"+string(decoded))
+	}
+	want := []string{":See attached.", "deployments_20261015_120000.md:" + report}
+	if len(parts) != len(want) {
+		t.Fatalf("got %d parts, want %d", len(parts), len(want))
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("part %d = %q, want %q", i, parts[i], want[i])
+		}
+	}
+}
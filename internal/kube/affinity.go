@@ -0,0 +1,129 @@
+package kube
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// nodeFieldName is the only field node affinity matchFields supports.
+const nodeFieldName = "metadata.name"
+
+// MatchesNodeSelector reports whether a pod with this nodeSelector and affinity may run on
+// the node: every nodeSelector label has to match, and when the affinity has a required
+// node affinity, at least one of its terms. Preferred terms are ignored, like taints they
+// only steer the scheduler.
+func (n NodeInfo) MatchesNodeSelector(nodeSelector map[string]string, affinity *corev1.Affinity) bool {
+	for k, v := range nodeSelector {
+		if n.Labels[k] != v {
+			return false
+		}
+	}
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if n.matchesTerm(term) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTerm ANDs a term's requirements. A term without any matches no node, as in the
+// scheduler.
+func (n NodeInfo) matchesTerm(term corev1.NodeSelectorTerm) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, req := range term.MatchExpressions {
+		if !matchesRequirement(req, labels.Set(n.Labels)) {
+			return false
+		}
+	}
+	for _, req := range term.MatchFields {
+		if req.Key != nodeFieldName || !matchesRequirement(req, labels.Set{nodeFieldName: n.Name}) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesRequirement evaluates one node selector requirement; invalid ones match nothing.
+func matchesRequirement(req corev1.NodeSelectorRequirement, set labels.Set) bool {
+	var op selection.Operator
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		op = selection.In
+	case corev1.NodeSelectorOpNotIn:
+		op = selection.NotIn
+	case corev1.NodeSelectorOpExists:
+		op = selection.Exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		op = selection.DoesNotExist
+	case corev1.NodeSelectorOpGt:
+		op = selection.GreaterThan
+	case corev1.NodeSelectorOpLt:
+		op = selection.LessThan
+	default:
+		return false
+	}
+	r, err := labels.NewRequirement(req.Key, op, req.Values)
+	if err != nil {
+		return false
+	}
+	return r.Matches(set)
+}
+
+// AntiAffinityTerms returns the required pod anti-affinity terms of an affinity (nil when none).
+func AntiAffinityTerms(affinity *corev1.Affinity) []corev1.PodAffinityTerm {
+	if affinity == nil || affinity.PodAntiAffinity == nil {
+		return nil
+	}
+	return affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+}
+
+// TermMatches reports whether a pod anti-affinity term of a pod in ownerNamespace selects a
+// pod in namespace with podLabels. A namespaceSelector is taken to select every namespace,
+// since namespace labels are not fetched; that errs on the side of a conflict.
+func TermMatches(term corev1.PodAffinityTerm, ownerNamespace, namespace string, podLabels map[string]string) bool {
+	switch {
+	case term.NamespaceSelector != nil:
+	case len(term.Namespaces) > 0:
+		if !slices.Contains(term.Namespaces, namespace) {
+			return false
+		}
+	default:
+		if namespace != ownerNamespace {
+			return false
+		}
+	}
+	if term.LabelSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(podLabels))
+}
+
+// SameTopology reports whether two nodes are in the same domain of topologyKey. Nodes
+// without the label are in no domain; for the hostname key the node name stands in for a
+// missing label.
+func SameTopology(a, b NodeInfo, topologyKey string) bool {
+	va, oka := a.Labels[topologyKey]
+	vb, okb := b.Labels[topologyKey]
+	if topologyKey == corev1.LabelHostname {
+		if !oka {
+			va, oka = a.Name, true
+		}
+		if !okb {
+			vb, okb = b.Name, true
+		}
+	}
+	return oka && okb && va == vb
+}
@@ -0,0 +1,106 @@
+package kube
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchesNodeSelector(t *testing.T) {
+	node := NodeInfo{Name: "gpu-1", Labels: map[string]string{"pool": "gpu", "gpu-count": "4", corev1.LabelTopologyZone: "eu-1a"}}
+	required := func(terms ...corev1.NodeSelectorTerm) *corev1.Affinity {
+		return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+		}}
+	}
+	expr := func(key string, op corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorTerm {
+		return corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: key, Operator: op, Values: values}}}
+	}
+
+	tests := []struct {
+		name         string
+		nodeSelector map[string]string
+		affinity     *corev1.Affinity
+		want         bool
+	}{
+		{"no constraints", nil, nil, true},
+		{"node selector matches", map[string]string{"pool": "gpu"}, nil, true},
+		{"node selector mismatch", map[string]string{"pool": "general"}, nil, false},
+		{"In", nil, required(expr("pool", corev1.NodeSelectorOpIn, "gpu", "infra")), true},
+		{"NotIn", nil, required(expr("pool", corev1.NodeSelectorOpNotIn, "gpu")), false},
+		{"DoesNotExist", nil, required(expr("spot", corev1.NodeSelectorOpDoesNotExist)), true},
+		{"Gt", nil, required(expr("gpu-count", corev1.NodeSelectorOpGt, "2")), true},
+		{"Lt", nil, required(expr("gpu-count", corev1.NodeSelectorOpLt, "2")), false},
+		{"terms are ORed", nil, required(expr("pool", corev1.NodeSelectorOpIn, "infra"), expr(corev1.LabelTopologyZone, corev1.NodeSelectorOpIn, "eu-1a")), true},
+		{"empty term matches nothing", nil, required(corev1.NodeSelectorTerm{}), false},
+		{"match fields by name", nil, required(corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu-1"}}}}), true},
+		{"preferred only", nil, &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := node.MatchesNodeSelector(tc.nodeSelector, tc.affinity); got != tc.want {
+				t.Errorf("MatchesNodeSelector() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTermMatches(t *testing.T) {
+	term := func(namespaces []string, nsSelector *metav1.LabelSelector) corev1.PodAffinityTerm {
+		return corev1.PodAffinityTerm{
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Namespaces:        namespaces,
+			NamespaceSelector: nsSelector,
+			TopologyKey:       corev1.LabelHostname,
+		}
+	}
+	web := map[string]string{"app": "web"}
+
+	tests := []struct {
+		name      string
+		term      corev1.PodAffinityTerm
+		namespace string
+		labels    map[string]string
+		want      bool
+	}{
+		{"same namespace by default", term(nil, nil), "shop", web, true},
+		{"other namespace by default", term(nil, nil), "dev", web, false},
+		{"listed namespace", term([]string{"dev"}, nil), "dev", web, true},
+		{"namespace selector matches all", term(nil, &metav1.LabelSelector{}), "dev", web, true},
+		{"labels mismatch", term(nil, nil), "shop", map[string]string{"app": "db"}, false},
+		{"no label selector", corev1.PodAffinityTerm{TopologyKey: corev1.LabelHostname}, "shop", web, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TermMatches(tc.term, "shop", tc.namespace, tc.labels); got != tc.want {
+				t.Errorf("TermMatches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSameTopology(t *testing.T) {
+	a := NodeInfo{Name: "a", Labels: map[string]string{corev1.LabelTopologyZone: "eu-1a"}}
+	b := NodeInfo{Name: "b", Labels: map[string]string{corev1.LabelTopologyZone: "eu-1a"}}
+	c := NodeInfo{Name: "c"}
+
+	tests := []struct {
+		name string
+		x, y NodeInfo
+		key  string
+		want bool
+	}{
+		{"same zone", a, b, corev1.LabelTopologyZone, true},
+		{"unlabeled node is in no zone", a, c, corev1.LabelTopologyZone, false},
+		{"hostname falls back to the name", a, a, corev1.LabelHostname, true},
+		{"different hosts", a, b, corev1.LabelHostname, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SameTopology(tc.x, tc.y, tc.key); got != tc.want {
+				t.Errorf("SameTopology() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
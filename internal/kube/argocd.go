@@ -0,0 +1,22 @@
+package kube
+
+import "strings"
+
+// Argo CD marks the resources of an Application with a tracking label or, with annotation
+// tracking, an annotation of the form "<app>:<group>/<kind>:<namespace>/<name>".
+const (
+	argoCDInstanceLabel      = "argocd.argoproj.io/instance"
+	argoCDTrackingAnnotation = "argocd.argoproj.io/tracking-id"
+)
+
+// ArgoCDApp returns the Argo CD Application an object belongs to, or "" for objects Argo CD
+// does not manage. Argo CD only marks the objects it applies, so pods carry the marks only
+// when their template does.
+func ArgoCDApp(labels, annotations map[string]string) string {
+	if id := annotations[argoCDTrackingAnnotation]; id != "" {
+		if app, _, ok := strings.Cut(id, ":"); ok && app != "" {
+			return app
+		}
+	}
+	return labels[argoCDInstanceLabel]
+}
@@ -0,0 +1,26 @@
+package kube
+
+import "testing"
+
+func TestArgoCDApp(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        string
+	}{
+		{"instance label", map[string]string{"argocd.argoproj.io/instance": "shop"}, nil, "shop"},
+		{"tracking annotation", nil, map[string]string{"argocd.argoproj.io/tracking-id": "shop:apps/Deployment:shop/cart"}, "shop"},
+		{"annotation wins", map[string]string{"argocd.argoproj.io/instance": "old"}, map[string]string{"argocd.argoproj.io/tracking-id": "shop:apps/Deployment:shop/cart"}, "shop"},
+		{"malformed annotation", map[string]string{"argocd.argoproj.io/instance": "shop"}, map[string]string{"argocd.argoproj.io/tracking-id": "garbage"}, "shop"},
+		{"Helm instance label only", map[string]string{"app.kubernetes.io/instance": "shop"}, nil, ""},
+		{"no labels", nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ArgoCDApp(tt.labels, tt.annotations); got != tt.want {
+				t.Errorf("ArgoCDApp() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package kube
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/diag"
+)
+
+// ResponseCache persists the responses of list calls (pods, nodes, metrics, ...) on disk, so
+// commands run back to back within TTL reuse them instead of listing the whole cluster
+// again. Stored lists are redacted like a snapshot, and expired ones are deleted. The zero
+// value disables caching.
+type ResponseCache struct {
+	Dir string        // cache directory; a subdirectory is used per context
+	TTL time.Duration // how long a response is reused
+}
+
+// DefaultCacheDir returns the kusa directory in the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kusa"), nil
+}
+
+var unsafeCacheChars = regexp.MustCompile(`[^a-zA-Z0-9\-_.]`)
+
+// contextDir returns the directory holding the responses of contextName.
+func (c ResponseCache) contextDir(contextName string) string {
+	return filepath.Join(c.Dir, unsafeCacheChars.ReplaceAllString(contextName, "_"))
+}
+
+// cachingRoundTripper answers list requests from dir while the stored response is younger
+// than ttl, and stores the successful JSON responses it fetches, redacted. On a miss it
+// prunes the expired responses of every context.
+type cachingRoundTripper struct {
+	next http.RoundTripper
+	dir  string
+	ttl  time.Duration
+}
+
+func (rt *cachingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isListRequest(req) {
+		return rt.next.RoundTrip(req)
+	}
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept")))
+	path := filepath.Join(rt.dir, hex.EncodeToString(sum[:]))
+
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < rt.ttl {
+		if raw, err := os.ReadFile(path); err == nil {
+			if resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req); err == nil {
+				return resp, nil
+			}
+		}
+	}
+	pruneCache(filepath.Dir(rt.dir), rt.ttl)
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.TransferEncoding = nil
+	resp.Header.Set("Content-Length", fmt.Sprint(len(body)))
+
+	// Only JSON can be redacted; other encodings are passed through uncached.
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return resp, nil
+	}
+	redacted, err := redactCachedList(body)
+	if err == nil {
+		stored := *resp
+		stored.Header = resp.Header.Clone()
+		stored.Header.Set("Content-Length", fmt.Sprint(len(redacted)))
+		stored.ContentLength = int64(len(redacted))
+		stored.Body = io.NopCloser(bytes.NewReader(redacted))
+		var raw []byte
+		if raw, err = httputil.DumpResponse(&stored, true); err == nil {
+			err = writeCacheFile(path, raw)
+		}
+	}
+	if err != nil {
+		diag.Warnf("failed to cache a response in %s: %v", rt.dir, err)
+	}
+	return resp, nil
+}
+
+// redactCachedList strips from a JSON list response what no report reads but may carry
+// secrets, as the snapshot does: container commands, arguments, environment and volumes,
+// managed fields and the last-applied-configuration annotation.
+func redactCachedList(body []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+	redactJSON(v)
+	return json.Marshal(v)
+}
+
+func redactJSON(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		if _, ok := v["containers"].([]any); ok {
+			delete(v, "volumes")
+			for _, key := range []string{"initContainers", "containers", "ephemeralContainers"} {
+				containers, _ := v[key].([]any)
+				for _, c := range containers {
+					if c, ok := c.(map[string]any); ok {
+						for _, field := range []string{"command", "args", "env", "envFrom", "volumeMounts"} {
+							delete(c, field)
+						}
+					}
+				}
+			}
+		}
+		if meta, ok := v["metadata"].(map[string]any); ok {
+			delete(meta, "managedFields")
+			if annotations, ok := meta["annotations"].(map[string]any); ok {
+				delete(annotations, lastAppliedAnnotation)
+			}
+		}
+		for _, child := range v {
+			redactJSON(child)
+		}
+	case []any:
+		for _, child := range v {
+			redactJSON(child)
+		}
+	}
+}
+
+// pruneCache deletes the responses under dir, of every context, older than ttl, so
+// expired lists do not linger on disk.
+func pruneCache(dir string, ttl time.Duration) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && time.Since(info.ModTime()) >= ttl {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// writeCacheFile writes raw to path through a temporary file, so concurrent runs never
+// read a partial response.
+func writeCacheFile(path string, raw []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// isListRequest reports whether req lists a resource: a GET of /api/v1/<resource>,
+// /apis/<group>/<version>/<resource> or their namespaced forms, which is not a watch.
+func isListRequest(req *http.Request) bool {
+	if req.Method != http.MethodGet || req.URL.Query().Get("watch") == "true" {
+		return false
+	}
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return false
+	}
+	switch len(segments) {
+	case 1:
+		return true
+	case 3:
+		return segments[0] == "namespaces"
+	}
+	return false
+}
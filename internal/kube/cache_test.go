@@ -0,0 +1,154 @@
+package kube
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsListRequest(t *testing.T) {
+	tests := []struct {
+		method, url string
+		want        bool
+	}{
+		{"GET", "/api/v1/pods?limit=500", true},
+		{"GET", "/api/v1/namespaces", true},
+		{"GET", "/api/v1/namespaces/shop/pods", true},
+		{"GET", "/apis/apps/v1/namespaces/shop/replicasets", true},
+		{"GET", "/apis/metrics.k8s.io/v1beta1/nodes", true},
+		{"GET", "/api/v1/namespaces/shop", false},
+		{"GET", "/api/v1/namespaces/shop/pods/web", false},
+		{"GET", "/api/v1/nodes/node-a/proxy/configz", false},
+		{"GET", "/api/v1/pods?watch=true", false},
+		{"POST", "/api/v1/namespaces/shop/pods", false},
+		{"GET", "/version", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.url, nil)
+		if got := isListRequest(req); got != tt.want {
+			t.Errorf("isListRequest(%s %s) = %v, want %v", tt.method, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCachingRoundTripper(t *testing.T) {
+	hits := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"items":[],"kind":"PodList"}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: &cachingRoundTripper{next: http.DefaultTransport, dir: dir, ttl: time.Minute}}
+	get := func(path string) string {
+		t.Helper()
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("GET %s: status %d, content type %q", path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		return string(body)
+	}
+
+	for range 2 {
+		if body := get("/api/v1/pods"); body != `{"items":[],"kind":"PodList"}` {
+			t.Errorf("body = %q", body)
+		}
+		get("/api/v1/namespaces/shop/pods/web")
+	}
+	if hits["/api/v1/pods"] != 1 {
+		t.Errorf("list pods reached the server %d times, want 1", hits["/api/v1/pods"])
+	}
+	if hits["/api/v1/namespaces/shop/pods/web"] != 2 {
+		t.Errorf("get pod reached the server %d times, want 2 (not cached)", hits["/api/v1/namespaces/shop/pods/web"])
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	old := time.Now().Add(-2 * time.Minute)
+	for _, f := range files {
+		if err := os.Chtimes(f, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get("/api/v1/pods")
+	if hits["/api/v1/pods"] != 2 {
+		t.Errorf("list pods reached the server %d times after the TTL, want 2", hits["/api/v1/pods"])
+	}
+}
+
+func TestCachingRoundTripperRedacts(t *testing.T) {
+	const list = `{"kind":"PodList","items":[{"metadata":{"name":"web","managedFields":[{"manager":"kubectl"}],` +
+		`"annotations":{"team":"shop","kubectl.kubernetes.io/last-applied-configuration":"{}"}},` +
+		`"spec":{"volumes":[{"name":"token"}],"containers":[{"name":"app","args":["--password=hunter2"],` +
+		`"env":[{"name":"TOKEN","value":"secret"}],"resources":{"requests":{"cpu":"100m"}}}]}}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, list)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: &cachingRoundTripper{next: http.DefaultTransport, dir: filepath.Join(dir, "ctx"), ttl: time.Minute}}
+	get := func() string {
+		t.Helper()
+		resp, err := client.Get(server.URL + "/api/v1/pods")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	if body := get(); body != list {
+		t.Errorf("first response was altered: %s", body)
+	}
+	want := `{"items":[{"metadata":{"annotations":{"team":"shop"},"name":"web"},` +
+		`"spec":{"containers":[{"name":"app","resources":{"requests":{"cpu":"100m"}}}]}}],"kind":"PodList"}`
+	if body := get(); body != want {
+		t.Errorf("cached response = %s, want %s", body, want)
+	}
+}
+
+func TestPruneCache(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-2 * time.Minute)
+	files := map[string]bool{ // file -> kept
+		filepath.Join(dir, "prod", "fresh"):      true,
+		filepath.Join(dir, "prod", "expired"):    false,
+		filepath.Join(dir, "staging", "expired"): false,
+	}
+	for f, kept := range files {
+		if err := writeCacheFile(f, []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+		if !kept {
+			if err := os.Chtimes(f, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	pruneCache(dir, time.Minute)
+	for f, kept := range files {
+		if _, err := os.Stat(f); (err == nil) != kept {
+			t.Errorf("%s: exists = %v, want %v", f, err == nil, kept)
+		}
+	}
+}
@@ -1,42 +1,88 @@
 package kube
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
+	"github.com/amasotti/kusa/internal/diag"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // Clients holds the core and metrics Kubernetes clientsets and the resolved context name.
+// The clientsets are interfaces so fakes (k8s.io/client-go/kubernetes/fake and
+// k8s.io/metrics/pkg/client/clientset/versioned/fake) can stand in; see NewClientsFrom.
 type Clients struct {
-	Core        *kubernetes.Clientset
-	Metrics     *metricsclient.Clientset
+	Core        kubernetes.Interface
+	Metrics     metricsclient.Interface
 	ContextName string
+
+	// Namespace is the namespace set on the kubeconfig context ("" when none).
+	Namespace string
+
+	// Timings records per-call API diagnostics (see --timing).
+	Timings *Timings
+
+	// Sampling controls repeated metrics polls; the zero value takes a single sample.
+	Sampling Sampling
+
+	// MaxMetricsAge marks metrics samples older than this as stale (0 disables the check).
+	MaxMetricsAge time.Duration
+
+	// RequireMetrics makes fetchers fail with ErrMetricsUnavailable instead of warning
+	// and continuing without usage when the metrics API cannot be read.
+	RequireMetrics bool
+
+	// TakenAt is when the cluster state was read, for clients replaying a Snapshot; metrics
+	// staleness is judged against it. The zero value means now.
+	TakenAt time.Time
+
+	// Concurrency bounds the List calls made in parallel when a fetch fans out over
+	// namespaces; 0 uses DefaultConcurrency.
+	Concurrency int
+
+	// kubeletGet reads a path of a node's kubelet API; nil when it can't be reached (fakes).
+	kubeletGet kubeletGetFunc
+
+	// apiGet reads an absolute path of the API server, for resources without a typed
+	// client (custom resources); nil for fakes.
+	apiGet func(ctx context.Context, path string) ([]byte, error)
 }
 
-// NewClients builds Kubernetes clients from the given kubeconfig path and optional context override.
-func NewClients(kubeconfig, contextOverride string) (*Clients, error) {
-	if kubeconfig == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		kubeconfig = filepath.Join(home, ".kube", "config")
+// ErrMetricsUnavailable is returned (wrapped) by fetchers when Clients.RequireMetrics
+// is set and node or pod metrics cannot be read.
+var ErrMetricsUnavailable = errors.New("metrics unavailable")
+
+// metricsUnavailable handles a failed metrics call: an ErrMetricsUnavailable error when
+// metrics are required, otherwise a warning and nil so the fetch continues without usage.
+func (c *Clients) metricsUnavailable(what string, err error) error {
+	if c.RequireMetrics {
+		return fmt.Errorf("%w: failed to get %s (is metrics-server installed?): %v", ErrMetricsUnavailable, what, err)
 	}
+	diag.Warnf("failed to get %s (metrics-server may not be installed): %v", what, err)
+	return nil
+}
 
-	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig}
-	configOverrides := &clientcmd.ConfigOverrides{}
+// NewClients builds Kubernetes clients from the given kubeconfig path and optional context override.
+func NewClients(kubeconfig, contextOverride string) (*Clients, error) {
+	return NewCachedClients(kubeconfig, contextOverride, ResponseCache{})
+}
 
-	// Use specific context if provided, otherwise rely on the kubeconfig's current context
-	if contextOverride != "" {
-		configOverrides.CurrentContext = contextOverride
+// NewCachedClients is NewClients with the responses of list calls cached on disk, see
+// ResponseCache.
+func NewCachedClients(kubeconfig, contextOverride string, cache ResponseCache) (*Clients, error) {
+	clientConfig, err := newClientConfig(kubeconfig, contextOverride)
+	if err != nil {
+		return nil, err
 	}
 
-	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
-
 	restConfig, err := clientConfig.ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build REST config: %w", err)
@@ -47,6 +93,23 @@ func NewClients(kubeconfig, contextOverride string) (*Clients, error) {
 		return nil, fmt.Errorf("failed to load raw kubeconfig: %w", err)
 	}
 	contextName := rawConfig.CurrentContext
+	if contextOverride != "" {
+		contextName = contextOverride
+	}
+	var namespace string
+	if kctx, ok := rawConfig.Contexts[contextName]; ok {
+		namespace = kctx.Namespace
+	}
+
+	timings := &Timings{}
+	timings.instrument(restConfig)
+	if cache.TTL > 0 {
+		// Wrapped outside the timing round tripper: answers from the cache are no pages.
+		dir := cache.contextDir(contextName)
+		restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &cachingRoundTripper{next: rt, dir: dir, ttl: cache.TTL}
+		})
+	}
 
 	coreClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
@@ -59,8 +122,80 @@ func NewClients(kubeconfig, contextOverride string) (*Clients, error) {
 	}
 
 	return &Clients{
-		Core:        coreClient,
-		Metrics:     metricsClient,
-		ContextName: contextName,
+		Core:          coreClient,
+		Metrics:       metricsClient,
+		ContextName:   contextName,
+		Namespace:     namespace,
+		Timings:       timings,
+		MaxMetricsAge: DefaultMaxMetricsAge,
+		kubeletGet: func(ctx context.Context, node, path string) ([]byte, error) {
+			return coreClient.CoreV1().RESTClient().Get().
+				AbsPath("/api/v1/nodes", node, "proxy", path).DoRaw(ctx)
+		},
+		apiGet: func(ctx context.Context, path string) ([]byte, error) {
+			return coreClient.CoreV1().RESTClient().Get().AbsPath(path).DoRaw(ctx)
+		},
 	}, nil
 }
+
+// NewClientsFrom wraps existing clientsets, e.g. fakes in tests or clients built by an
+// embedding tool. API calls are still timed, but throttling and page counts are only
+// recorded for clients created by NewClients.
+func NewClientsFrom(core kubernetes.Interface, metrics metricsclient.Interface, contextName string) *Clients {
+	return &Clients{
+		Core:          core,
+		Metrics:       metrics,
+		ContextName:   contextName,
+		Timings:       &Timings{},
+		MaxMetricsAge: DefaultMaxMetricsAge,
+	}
+}
+
+// track runs fn as a named API call, recording diagnostics when Timings is set.
+func (c *Clients) track(ctx context.Context, name string, fn func(ctx context.Context) (int, error)) error {
+	if c.Timings == nil {
+		_, err := fn(ctx)
+		return err
+	}
+	return c.Timings.track(ctx, name, fn)
+}
+
+// ContextNames returns the sorted context names defined in the given kubeconfig.
+func ContextNames(kubeconfig string) ([]string, error) {
+	clientConfig, err := newClientConfig(kubeconfig, "")
+	if err != nil {
+		return nil, err
+	}
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load raw kubeconfig: %w", err)
+	}
+
+	names := make([]string, 0, len(rawConfig.Contexts))
+	for name := range rawConfig.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// newClientConfig resolves the kubeconfig path (default ~/.kube/config) and applies the context override.
+func newClientConfig(kubeconfig, contextOverride string) (clientcmd.ClientConfig, error) {
+	if kubeconfig == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		kubeconfig = filepath.Join(home, ".kube", "config")
+	}
+
+	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig}
+	configOverrides := &clientcmd.ConfigOverrides{}
+
+	// Use specific context if provided, otherwise rely on the kubeconfig's current context
+	if contextOverride != "" {
+		configOverrides.CurrentContext = contextOverride
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides), nil
+}
@@ -0,0 +1,628 @@
+package kube
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// DemoContextName is the context name reported by NewDemoClients.
+const DemoContextName = "demo"
+
+// demoWorkload describes one synthetic workload. Usage is given as a fraction of the
+// request, so the demo shows the whole range from massively over-requested to bursting.
+type demoWorkload struct {
+	namespace, kind, name string
+	replicas              int
+	cpuReq, memReq        string
+	cpuLimit, memLimit    string  // "" = no limit
+	cpuUse, memUse        float64 // actual / request
+	age                   time.Duration
+}
+
+var demoWorkloads = []demoWorkload{
+	{"shop", "Deployment", "storefront", 6, "1", "2Gi", "2", "2Gi", 0.08, 0.35, 90 * 24 * time.Hour},
+	{"shop", "Deployment", "cart", 3, "500m", "1Gi", "", "1Gi", 0.4, 0.6, 60 * 24 * time.Hour},
+	{"shop", "StatefulSet", "redis", 3, "250m", "4Gi", "", "4Gi", 0.3, 0.93, 120 * 24 * time.Hour},
+	{"payments", "Deployment", "checkout", 4, "2", "4Gi", "4", "", 0.03, 0.2, 45 * 24 * time.Hour},
+	{"payments", "Deployment", "fraud-scoring", 2, "500m", "512Mi", "", "", 1.8, 1.4, 30 * 24 * time.Hour},
+	{"data", "StatefulSet", "postgres", 2, "2", "8Gi", "2", "8Gi", 0.25, 0.7, 200 * 24 * time.Hour},
+	{"data", "Deployment", "etl-worker", 3, "1500m", "3Gi", "", "", 0.01, 0.05, 20 * 24 * time.Hour},
+	{"dev", "Deployment", "preview-api", 2, "1", "2Gi", "", "", 0.002, 0.04, 75 * 24 * time.Hour},
+	{"dev", "Deployment", "legacy-reporting", 0, "1", "2Gi", "", "", 0, 0, 300 * 24 * time.Hour},
+	{"monitoring", "Deployment", "grafana", 1, "200m", "512Mi", "", "1Gi", 0.3, 0.6, 150 * 24 * time.Hour},
+	{"monitoring", "StatefulSet", "prometheus", 1, "1", "6Gi", "", "8Gi", 0.6, 0.95, 150 * 24 * time.Hour},
+	{"kube-system", "Deployment", "coredns", 2, "100m", "70Mi", "", "170Mi", 0.1, 0.5, 400 * 24 * time.Hour},
+	{"kube-system", "DaemonSet", "kube-proxy", 0, "100m", "64Mi", "", "", 0.05, 0.6, 400 * 24 * time.Hour},
+	{"monitoring", "DaemonSet", "node-exporter", 0, "50m", "64Mi", "250m", "128Mi", 0.2, 0.4, 150 * 24 * time.Hour},
+}
+
+// NewDemoClients returns Clients backed by fake clientsets holding a synthetic, deterministic
+// cluster: five nodes, a dozen workloads with skewed usage, a forgotten debug pod and a
+// blocking PodDisruptionBudget. No cluster connection is made.
+func NewDemoClients() *Clients {
+	objects, nodeMetrics, podMetrics := demoCluster(time.Now())
+
+	metrics := metricsfake.NewSimpleClientset()
+	metrics.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: nodeMetrics}, nil
+	})
+	metrics.PrependReactor("list", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		list := &metricsv1beta1.PodMetricsList{}
+		for _, m := range podMetrics {
+			if a.GetNamespace() == "" || m.Namespace == a.GetNamespace() {
+				list.Items = append(list.Items, m)
+			}
+		}
+		return true, list, nil
+	})
+
+	clients := NewClientsFrom(fake.NewClientset(objects...), metrics, DemoContextName)
+	clients.kubeletGet = func(_ context.Context, node, path string) ([]byte, error) {
+		switch path {
+		case "configz":
+			return []byte(demoKubeletConfigz), nil
+		case "metrics/cadvisor":
+			return demoCadvisorMetrics(objects, node), nil
+		case "stats/summary":
+			return demoKubeletSummary(objects, node), nil
+		}
+		return nil, fmt.Errorf("the demo kubelet does not serve /%s", path)
+	}
+	clients.apiGet = func(_ context.Context, path string) ([]byte, error) {
+		if !strings.HasPrefix(path, "/apis/autoscaling.k8s.io/v1/") || !strings.HasSuffix(path, "/verticalpodautoscalers") {
+			return nil, fmt.Errorf("the demo API server does not serve %s", path)
+		}
+		namespace, _, _ := strings.Cut(strings.TrimPrefix(path, "/apis/autoscaling.k8s.io/v1/namespaces/"), "/")
+		return demoVPAList(namespace), nil
+	}
+	return clients
+}
+
+// demoVPAs are the VerticalPodAutoscalers of the demo cluster by workload, with the pods
+// their updater evicted or resized in place within the last hour.
+var demoVPAs = []struct {
+	namespace, workload, mode string
+	evicted, inPlace          int32
+}{
+	{"payments", "fraud-scoring", "Auto", 4, 0},
+	{"shop", "storefront", "InPlaceOrRecreate", 0, 2},
+	{"data", "postgres", "Off", 0, 0},
+}
+
+// demoVPAList renders the demo VPAs in namespace ("" = all) as a VerticalPodAutoscalerList.
+func demoVPAList(namespace string) []byte {
+	var items []string
+	for _, v := range demoVPAs {
+		if namespace != "" && namespace != v.namespace {
+			continue
+		}
+		kind := "Deployment"
+		for _, w := range demoWorkloads {
+			if w.name == v.workload {
+				kind = w.kind
+			}
+		}
+		items = append(items, fmt.Sprintf(`{"metadata": {"namespace": %q, "name": %q}, `+
+			`"spec": {"targetRef": {"apiVersion": "apps/v1", "kind": %q, "name": %q}, "updatePolicy": {"updateMode": %q}}}`,
+			v.namespace, v.workload, kind, v.workload, v.mode))
+	}
+	return []byte(`{"apiVersion": "autoscaling.k8s.io/v1", "kind": "VerticalPodAutoscalerList", "items": [` + strings.Join(items, ", ") + `]}`)
+}
+
+// demoVPAEvents are the events the VPA updater recorded on the pods of demoVPAs it evicted
+// or resized in place.
+func demoVPAEvents(now time.Time) []runtime.Object {
+	var events []runtime.Object
+	for _, v := range demoVPAs {
+		for i, n := range []int32{v.evicted, v.inPlace} {
+			if n == 0 {
+				continue
+			}
+			reason := []string{vpaEvictedReason, vpaInPlaceReason}[i]
+			pod := fmt.Sprintf("%s-%d", v.workload, i)
+			events = append(events, &corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Namespace: v.namespace, Name: pod + "." + strings.ToLower(reason)},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: v.namespace, Name: pod},
+				Reason:         reason,
+				Count:          n,
+				FirstTimestamp: metav1.NewTime(now.Add(-50 * time.Minute)),
+				LastTimestamp:  metav1.NewTime(now.Add(-time.Duration(10+5*i) * time.Minute)),
+			})
+		}
+	}
+	return events
+}
+
+// demoThrottling is the share of CFS periods in which the containers of these workloads
+// hit their CPU limit: bursty services are throttled even at a low average usage.
+var demoThrottling = map[string]float64{
+	"storefront":    0.38,
+	"postgres":      0.12,
+	"node-exporter": 0.04,
+}
+
+// demoCadvisorMetrics renders the CFS counters of the limited containers on node in the
+// Prometheus text format, like the kubelet's /metrics/cadvisor.
+func demoCadvisorMetrics(objects []runtime.Object, node string) []byte {
+	const periods = 250000
+	var b strings.Builder
+	b.WriteString("# TYPE container_cpu_cfs_periods_total counter\n# TYPE container_cpu_cfs_throttled_periods_total counter\n")
+	for _, obj := range objects {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok || pod.Spec.NodeName != node {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			if _, limited := c.Resources.Limits[corev1.ResourceCPU]; !limited {
+				continue
+			}
+			labels := fmt.Sprintf(`container=%q,namespace=%q,pod=%q`, c.Name, pod.Namespace, pod.Name)
+			fmt.Fprintf(&b, "%s{%s} %d\n", metricCFSPeriods, labels, periods)
+			fmt.Fprintf(&b, "%s{%s} %d\n", metricCFSThrottledPeriods, labels, int(demoThrottling[pod.Labels["app"]]*periods))
+		}
+	}
+	return []byte(b.String())
+}
+
+// demoEphemeral gives the main container of these workloads an ephemeral-storage request
+// and limit; use is the share of the request they write. Other pods write demoPodLogs.
+var demoEphemeral = map[string]struct {
+	request, limit string
+	use            float64
+}{
+	"prometheus": {"20Gi", "40Gi", 0.3},
+	"etl-worker": {"10Gi", "", 0.02},
+	"postgres":   {"5Gi", "10Gi", 0.8},
+}
+
+const demoPodLogs = 40 << 20
+
+// demoDisks is the used share of the demo nodes' 100Gi root disk, and of a separate 200Gi
+// image disk where they have one: pool-a-3 is close to the nodefs eviction threshold.
+var demoDisks = map[string]struct{ nodeFS, imageFS float64 }{
+	"pool-a-1": {0.46, 0},
+	"pool-a-2": {0.52, 0},
+	"pool-a-3": {0.84, 0},
+	"pool-b-1": {0.21, 0.38},
+	"pool-b-2": {0.12, 0},
+}
+
+// demoKubeletSummary renders the filesystems of node and the ephemeral storage its pods
+// use, like the kubelet's /stats/summary.
+func demoKubeletSummary(objects []runtime.Object, node string) []byte {
+	type fsStats struct {
+		CapacityBytes  int64 `json:"capacityBytes"`
+		AvailableBytes int64 `json:"availableBytes"`
+	}
+	type podStats struct {
+		EphemeralStorage struct {
+			UsedBytes int64 `json:"usedBytes"`
+		} `json:"ephemeral-storage"`
+	}
+	var summary struct {
+		Node struct {
+			FS      fsStats `json:"fs"`
+			Runtime struct {
+				ImageFS fsStats `json:"imageFs"`
+			} `json:"runtime"`
+		} `json:"node"`
+		Pods []podStats `json:"pods"`
+	}
+	disk := demoDisks[node]
+	summary.Node.FS = fsStats{100 << 30, int64((1 - disk.nodeFS) * (100 << 30))}
+	summary.Node.Runtime.ImageFS = summary.Node.FS
+	if disk.imageFS > 0 {
+		summary.Node.Runtime.ImageFS = fsStats{200 << 30, int64((1 - disk.imageFS) * (200 << 30))}
+	}
+	for _, obj := range objects {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok || pod.Spec.NodeName != node {
+			continue
+		}
+		var p podStats
+		p.EphemeralStorage.UsedBytes = demoPodLogs
+		if e, ok := demoEphemeral[pod.Labels["app"]]; ok {
+			request := resource.MustParse(e.request)
+			p.EphemeralStorage.UsedBytes = int64(float64(request.Value()) * e.use)
+		}
+		summary.Pods = append(summary.Pods, p)
+	}
+	raw, _ := json.Marshal(summary)
+	return raw
+}
+
+// demoKubeletConfigz is the kubelet config every demo node reports; demoReserved is the
+// capacity it withholds, subtracted from the demo nodes' allocatable.
+const demoKubeletConfigz = `{"kubeletconfig": {
+	"kubeReserved": {"cpu": "80m", "memory": "512Mi"},
+	"systemReserved": {"cpu": "100m", "memory": "512Mi"},
+	"evictionHard": {"memory.available": "100Mi", "nodefs.available": "10%"}}}`
+
+var demoReserved = map[corev1.ResourceName]string{
+	corev1.ResourceCPU:    "180m",
+	corev1.ResourceMemory: "1124Mi",
+
+	corev1.ResourceEphemeralStorage: "10Gi", // nodefs.available
+}
+
+// demoCluster generates the demo objects and metrics. A fixed seed keeps the output
+// identical across runs, which matters for screenshots and docs.
+func demoCluster(now time.Time) ([]runtime.Object, []metricsv1beta1.NodeMetrics, []metricsv1beta1.PodMetrics) {
+	rng := rand.New(rand.NewPCG(42, 7))
+	suffix := func() string {
+		const letters = "bcdfghjklmnpqrstvwxz2456789"
+		b := make([]byte, 5)
+		for i := range b {
+			b[i] = letters[rng.IntN(len(letters))]
+		}
+		return string(b)
+	}
+
+	var objects []runtime.Object
+	namespaces := []string{"shop", "payments", "data", "dev", "monitoring", "kube-system"}
+	for _, ns := range namespaces {
+		objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+	}
+
+	type demoNode struct {
+		name           string
+		cpu, mem       string
+		hugePages      string // hugepages-2Mi, "" = none
+		arch           string // "" = amd64
+		memoryPressure bool
+		cordoned       bool
+	}
+	nodeSpecs := []demoNode{
+		{name: "pool-a-1", cpu: "8", mem: "32Gi"},
+		{name: "pool-a-2", cpu: "8", mem: "32Gi"},
+		{name: "pool-a-3", cpu: "8", mem: "32Gi", memoryPressure: true},
+		{name: "pool-b-1", cpu: "16", mem: "64Gi", hugePages: "8Gi", arch: "arm64"},
+		{name: "pool-b-2", cpu: "4", mem: "16Gi", cordoned: true, arch: "arm64"},
+	}
+	usage := make(map[string][2]float64) // node → millicores, MiB
+
+	var nodeNames []string
+	for _, n := range nodeSpecs {
+		pressure := corev1.ConditionFalse
+		if n.memoryPressure {
+			pressure = corev1.ConditionTrue
+		}
+		capacity := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(n.cpu),
+			corev1.ResourceMemory: resource.MustParse(n.mem),
+			corev1.ResourcePods:   resource.MustParse("110"),
+
+			corev1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+		}
+		allocatable := capacity.DeepCopy()
+		for name, reserved := range demoReserved {
+			q := allocatable[name]
+			q.Sub(resource.MustParse(reserved))
+			allocatable[name] = q
+		}
+		if n.hugePages != "" {
+			// Pre-allocated hugepages are part of memory capacity but not of allocatable memory.
+			hp := resource.MustParse(n.hugePages)
+			capacity[corev1.ResourceHugePagesPrefix+"2Mi"] = hp
+			allocatable[corev1.ResourceHugePagesPrefix+"2Mi"] = hp
+			mem := allocatable[corev1.ResourceMemory]
+			mem.Sub(hp)
+			allocatable[corev1.ResourceMemory] = mem
+		}
+		objects = append(objects, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              n.name,
+				CreationTimestamp: metav1.NewTime(now.Add(-400 * 24 * time.Hour)),
+				Labels:            map[string]string{corev1.LabelOSStable: "linux", corev1.LabelArchStable: cmp.Or(n.arch, "amd64")},
+			},
+			Spec: corev1.NodeSpec{Unschedulable: n.cordoned, PodCIDR: fmt.Sprintf("10.244.%d.0/24", len(nodeNames))},
+			Status: corev1.NodeStatus{
+				Capacity:    capacity,
+				Allocatable: allocatable,
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+					{Type: corev1.NodeMemoryPressure, Status: pressure},
+				},
+			},
+		})
+		nodeNames = append(nodeNames, n.name)
+	}
+
+	var podMetrics []metricsv1beta1.PodMetrics
+	addPod := func(pod *corev1.Pod, cpuUse, memUse float64) {
+		objects = append(objects, pod)
+		c := pod.Spec.Containers[0]
+		cpuReq := c.Resources.Requests[corev1.ResourceCPU]
+		memReq := c.Resources.Requests[corev1.ResourceMemory]
+		jitter := 0.85 + 0.3*rng.Float64()
+		cpu := int64(float64(cpuReq.MilliValue()) * cpuUse * jitter)
+		mem := int64(float64(memReq.Value()) * memUse * jitter)
+		containers := []metricsv1beta1.ContainerMetrics{{
+			Name: c.Name,
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+				corev1.ResourceMemory: *resource.NewQuantity(mem, resource.BinarySI),
+			},
+		}}
+		if sc, ok := demoSidecars[pod.Labels["app"]]; ok {
+			scCPU, scMem := resource.MustParse(sc.cpuUse), resource.MustParse(sc.memUse)
+			containers = append(containers, metricsv1beta1.ContainerMetrics{
+				Name:  sc.name,
+				Usage: corev1.ResourceList{corev1.ResourceCPU: scCPU, corev1.ResourceMemory: scMem},
+			})
+			cpu += scCPU.MilliValue()
+			mem += scMem.Value()
+		}
+		podMetrics = append(podMetrics, metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+			Timestamp:  metav1.NewTime(now),
+			Window:     metav1.Duration{Duration: 30 * time.Second},
+			Containers: containers,
+		})
+		u := usage[pod.Spec.NodeName]
+		usage[pod.Spec.NodeName] = [2]float64{u[0] + float64(cpu), u[1] + float64(mem)/(1024*1024)}
+	}
+
+	// Replicas go to the schedulable node with the lowest CPU requested/allocatable,
+	// roughly like the default scheduler spreads them.
+	requested := make(map[string]int64)
+	leastAllocated := func(cpuReq int64) string {
+		best, bestRatio := "", 0.0
+		for _, n := range nodeSpecs {
+			if n.cordoned {
+				continue
+			}
+			alloc := resource.MustParse(n.cpu)
+			ratio := float64(requested[n.name]+cpuReq) / float64(alloc.MilliValue())
+			if best == "" || ratio < bestRatio {
+				best, bestRatio = n.name, ratio
+			}
+		}
+		return best
+	}
+	for _, w := range demoWorkloads {
+		spec := demoPodSpec(w)
+		labels := map[string]string{"app": w.name, "team": w.namespace}
+		if chart, ok := demoHelmCharts[w.name]; ok {
+			labels["app.kubernetes.io/instance"] = chart.release
+			labels["app.kubernetes.io/managed-by"] = "Helm"
+			labels["helm.sh/chart"] = chart.chart
+		}
+		template := corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: demoPodAnnotations[w.name]}, Spec: spec}
+		meta := metav1.ObjectMeta{Namespace: w.namespace, Name: w.name, CreationTimestamp: metav1.NewTime(now.Add(-w.age))}
+		if app, ok := demoArgoCDApps[w.name]; ok {
+			meta.Labels = map[string]string{"argocd.argoproj.io/instance": app}
+		}
+		if chart, ok := demoHelmCharts[w.name]; ok {
+			meta.Annotations = map[string]string{"meta.helm.sh/release-name": chart.release, "meta.helm.sh/release-namespace": w.namespace}
+		}
+		selector := &metav1.LabelSelector{MatchLabels: labels}
+		replicas := int32(w.replicas)
+
+		var owner metav1.OwnerReference
+		podName := func(int) string { return fmt.Sprintf("%s-%s", w.name, suffix()) }
+		switch w.kind {
+		case "Deployment":
+			objects = append(objects, &appsv1.Deployment{ObjectMeta: meta, Spec: appsv1.DeploymentSpec{Replicas: &replicas, Selector: selector, Template: template}})
+			rsName := w.name + "-" + suffix() + suffix()
+			objects = append(objects, &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Namespace:       w.namespace,
+				Name:            rsName,
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: w.name, Controller: ptrTo(true)}},
+			}, Spec: appsv1.ReplicaSetSpec{Replicas: &replicas, Selector: selector, Template: template}})
+			owner = metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: rsName, Controller: ptrTo(true)}
+			podName = func(int) string { return fmt.Sprintf("%s-%s", rsName, suffix()) }
+		case "StatefulSet":
+			objects = append(objects, &appsv1.StatefulSet{ObjectMeta: meta, Spec: appsv1.StatefulSetSpec{Replicas: &replicas, Selector: selector, Template: template}})
+			owner = metav1.OwnerReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: w.name, Controller: ptrTo(true)}
+			podName = func(i int) string { return fmt.Sprintf("%s-%d", w.name, i) }
+		case "DaemonSet":
+			objects = append(objects, &appsv1.DaemonSet{
+				ObjectMeta: meta,
+				Spec:       appsv1.DaemonSetSpec{Selector: selector, Template: template},
+				Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: int32(len(nodeNames))},
+			})
+			owner = metav1.OwnerReference{APIVersion: "apps/v1", Kind: "DaemonSet", Name: w.name, Controller: ptrTo(true)}
+		}
+
+		cpuReq := spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+		nodesFor := func(int) string { return leastAllocated(cpuReq.MilliValue()) }
+		count := w.replicas
+		if w.kind == "DaemonSet" {
+			count = len(nodeNames)
+			nodesFor = func(i int) string { return nodeNames[i] }
+		}
+		for i := range count {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         w.namespace,
+					Name:              podName(i),
+					Labels:            labels,
+					Annotations:       demoPodAnnotations[w.name],
+					CreationTimestamp: metav1.NewTime(now.Add(-w.age / 2)),
+					OwnerReferences:   []metav1.OwnerReference{owner},
+				},
+				Spec:   *spec.DeepCopy(),
+				Status: corev1.PodStatus{Phase: corev1.PodRunning, QOSClass: demoQOSClass(w), ContainerStatuses: demoContainerStatuses(w, now)},
+			}
+			pod.Spec.NodeName = nodesFor(i)
+			requested[pod.Spec.NodeName] += cpuReq.MilliValue()
+			addPod(pod, w.cpuUse, w.memUse)
+		}
+	}
+
+	// A debug pod someone forgot about three weeks ago.
+	debug := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "debug-shell", CreationTimestamp: metav1.NewTime(now.Add(-21 * 24 * time.Hour))},
+		Spec: corev1.PodSpec{NodeName: "pool-a-2", Containers: []corev1.Container{{
+			Name:  "shell",
+			Image: "busybox",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, QOSClass: corev1.PodQOSBurstable},
+	}
+	addPod(debug, 0, 0.01)
+
+	objects = append(objects, demoVPAEvents(now)...)
+
+	objects = append(objects, &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "checkout"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: ptrTo(intstr.FromInt32(4)),
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{CurrentHealthy: 4, DesiredHealthy: 4, ExpectedPods: 4, DisruptionsAllowed: 0},
+	})
+
+	var nodeMetrics []metricsv1beta1.NodeMetrics
+	for _, name := range nodeNames {
+		// System daemons and the kubelet use some capacity outside of pods.
+		u := usage[name]
+		nodeMetrics = append(nodeMetrics, metricsv1beta1.NodeMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Timestamp:  metav1.NewTime(now),
+			Window:     metav1.Duration{Duration: 30 * time.Second},
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    *resource.NewMilliQuantity(int64(u[0])+150, resource.DecimalSI),
+				corev1.ResourceMemory: *resource.NewQuantity(int64((u[1]+900)*1024*1024), resource.BinarySI),
+			},
+		})
+	}
+	return objects, nodeMetrics, podMetrics
+}
+
+func demoPodSpec(w demoWorkload) corev1.PodSpec {
+	res := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(w.cpuReq),
+			corev1.ResourceMemory: resource.MustParse(w.memReq),
+		},
+	}
+	if w.cpuLimit != "" || w.memLimit != "" {
+		res.Limits = corev1.ResourceList{}
+	}
+	if w.cpuLimit != "" {
+		res.Limits[corev1.ResourceCPU] = resource.MustParse(w.cpuLimit)
+	}
+	if w.memLimit != "" {
+		res.Limits[corev1.ResourceMemory] = resource.MustParse(w.memLimit)
+	}
+	if e, ok := demoEphemeral[w.name]; ok {
+		res.Requests[corev1.ResourceEphemeralStorage] = resource.MustParse(e.request)
+		if e.limit != "" {
+			if res.Limits == nil {
+				res.Limits = corev1.ResourceList{}
+			}
+			res.Limits[corev1.ResourceEphemeralStorage] = resource.MustParse(e.limit)
+		}
+	}
+	spec := corev1.PodSpec{Containers: []corev1.Container{{Name: w.name, Image: "registry.example.com/" + w.name, Resources: res}}}
+	if sc, ok := demoSidecars[w.name]; ok {
+		spec.Containers = append(spec.Containers, corev1.Container{
+			Name:  sc.name,
+			Image: "registry.example.com/" + sc.name,
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(sc.cpuReq),
+				corev1.ResourceMemory: resource.MustParse(sc.memReq),
+			}},
+		})
+	}
+	return spec
+}
+
+// demoSidecars adds a sidecar to the pods of these workloads, with a fixed usage far below
+// its request: the pod as a whole still looks reasonable.
+// demoHelmCharts marks workloads as installed by a Helm release, keyed by workload name.
+var demoHelmCharts = map[string]struct{ release, chart string }{
+	"redis":      {"redis", "redis-18.6.1"},
+	"postgres":   {"postgres", "postgresql-13.2.24"},
+	"grafana":    {"monitoring", "grafana-7.0.8"},
+	"prometheus": {"monitoring", "prometheus-25.8.0"},
+}
+
+// demoArgoCDApps marks workloads as managed by an Argo CD Application, keyed by workload name.
+var demoArgoCDApps = map[string]string{
+	"storefront":    "shop",
+	"cart":          "shop",
+	"redis":         "shop",
+	"checkout":      "payments",
+	"fraud-scoring": "payments",
+	"etl-worker":    "data-platform",
+	"postgres":      "data-platform",
+	"grafana":       "observability",
+	"prometheus":    "observability",
+	"node-exporter": "observability",
+}
+
+// demoPodAnnotations are set on the pod templates of these workloads: the nightly ETL batch
+// is sized for its peak, an accepted exception.
+var demoPodAnnotations = map[string]map[string]string{
+	"etl-worker": {"kusa.io/expected-factor": "200"},
+}
+
+var demoSidecars = map[string]struct {
+	name           string
+	cpuReq, memReq string
+	cpuUse, memUse string
+}{
+	"cart": {name: "log-shipper", cpuReq: "1", memReq: "256Mi", cpuUse: "5m", memUse: "40Mi"},
+}
+
+// demoRestarts gives the containers of these workloads a restart history; a non-zero
+// oomKilled is how long ago the last restart was an OOM kill.
+var demoRestarts = map[string]struct {
+	restarts  int32
+	oomKilled time.Duration
+}{
+	"redis":         {restarts: 3, oomKilled: 5 * time.Hour},
+	"prometheus":    {restarts: 1, oomKilled: 9 * 24 * time.Hour},
+	"fraud-scoring": {restarts: 2},
+}
+
+func demoContainerStatuses(w demoWorkload, now time.Time) []corev1.ContainerStatus {
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	st := corev1.ContainerStatus{Name: w.name, Ready: true, State: running}
+	if r, ok := demoRestarts[w.name]; ok {
+		st.RestartCount = r.restarts
+		reason, exitCode := "Error", int32(1)
+		finished := now.Add(-2 * time.Hour)
+		if r.oomKilled > 0 {
+			reason, exitCode, finished = "OOMKilled", 137, now.Add(-r.oomKilled)
+		}
+		st.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode, FinishedAt: metav1.NewTime(finished)}
+	}
+	statuses := []corev1.ContainerStatus{st}
+	if sc, ok := demoSidecars[w.name]; ok {
+		statuses = append(statuses, corev1.ContainerStatus{Name: sc.name, Ready: true, State: running})
+	}
+	return statuses
+}
+
+func demoQOSClass(w demoWorkload) corev1.PodQOSClass {
+	if w.cpuLimit == w.cpuReq && w.memLimit == w.memReq {
+		return corev1.PodQOSGuaranteed
+	}
+	return corev1.PodQOSBurstable
+}
+
+func ptrTo[T any](v T) *T { return &v }
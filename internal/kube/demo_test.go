@@ -0,0 +1,35 @@
+package kube
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDemoClients(t *testing.T) {
+	clients := NewDemoClients()
+	ctx := context.Background()
+
+	result, err := FetchNodes(ctx, clients, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Nodes) != 5 || !result.PodMetricsAvailable {
+		t.Fatalf("got %d nodes (pod metrics %v), want 5 with pod metrics", len(result.Nodes), result.PodMetricsAvailable)
+	}
+	for _, n := range result.Nodes {
+		if !n.MetricsAvailable {
+			t.Errorf("%s: no node metrics", n.Name)
+		}
+		if n.RequestedCPU > n.AllocatableCPU || n.RequestedMem > n.AllocatableMem {
+			t.Errorf("%s: requests %dm/%gMi exceed allocatable %dm/%gMi", n.Name, n.RequestedCPU, n.RequestedMem, n.AllocatableCPU, n.AllocatableMem)
+		}
+	}
+
+	pdbs, err := FetchPDBs(ctx, clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pdbs) != 1 || pdbs[0].DisruptionsAllowed != 0 {
+		t.Errorf("pdbs = %+v, want one blocking PodDisruptionBudget", pdbs)
+	}
+}
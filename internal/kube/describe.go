@@ -0,0 +1,83 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadSpec is what a workload controller specifies for its pods: the replica count and
+// the resources of its pod template's containers.
+type WorkloadSpec struct {
+	Kind        string // Deployment, StatefulSet or DaemonSet
+	Namespace   string
+	Name        string
+	DesiredPods int // spec.replicas; desiredNumberScheduled for a DaemonSet
+
+	// Containers holds the requests and limits of the template's regular containers.
+	Containers []ContainerInfo
+}
+
+// specKinds are the controllers FetchWorkloadSpec reads, in the order tried when no kind
+// is given.
+var specKinds = []string{"Deployment", "StatefulSet", "DaemonSet"}
+
+// FetchWorkloadSpec reads the controller name in namespace. kind is matched case-insensitively;
+// "" tries a Deployment, a StatefulSet and a DaemonSet in turn. It returns nil without an
+// error when no such controller exists, e.g. for standalone pods and Jobs.
+func FetchWorkloadSpec(ctx context.Context, clients *Clients, namespace, kind, name string) (*WorkloadSpec, error) {
+	for _, k := range specKinds {
+		if kind != "" && !strings.EqualFold(kind, k) {
+			continue
+		}
+		spec, err := fetchWorkloadSpec(ctx, clients, namespace, k, name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s %s/%s: %w", strings.ToLower(k), namespace, name, err)
+		}
+		return spec, nil
+	}
+	return nil, nil
+}
+
+func fetchWorkloadSpec(ctx context.Context, clients *Clients, namespace, kind, name string) (*WorkloadSpec, error) {
+	spec := &WorkloadSpec{Kind: kind, Namespace: namespace, Name: name}
+	var template corev1.PodSpec
+	err := clients.track(ctx, "get "+strings.ToLower(kind), func(ctx context.Context) (int, error) {
+		apps := clients.Core.AppsV1()
+		switch kind {
+		case "Deployment":
+			d, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return 0, err
+			}
+			spec.DesiredPods, template = desiredReplicas(d.Spec.Replicas), d.Spec.Template.Spec
+		case "StatefulSet":
+			st, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return 0, err
+			}
+			spec.DesiredPods, template = desiredReplicas(st.Spec.Replicas), st.Spec.Template.Spec
+		case "DaemonSet":
+			ds, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return 0, err
+			}
+			spec.DesiredPods, template = int(ds.Status.DesiredNumberScheduled), ds.Spec.Template.Spec
+		}
+		return 1, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range template.Containers {
+		spec.Containers = append(spec.Containers, containerResources(c))
+	}
+	return spec, nil
+}
@@ -0,0 +1,46 @@
+package kube
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/diag"
+)
+
+// Annotations declaring accepted exceptions on a pod (or its template) or workload.
+const (
+	IgnoreAnnotation         = "kusa.io/ignore"          // "true" leaves the object out of the findings
+	ExpectedFactorAnnotation = "kusa.io/expected-factor" // CPU over-request factor accepted as intentional, e.g. "30"
+)
+
+// Exception is an accepted exception declared with the kusa.io annotations, so recurring
+// reports are not polluted by known, intentional over-provisioning.
+type Exception struct {
+	Ignore         bool
+	ExpectedFactor float64 // accepted CPU request/actual factor (0 = none)
+}
+
+// IsZero reports whether no exception is declared.
+func (e Exception) IsZero() bool { return !e.Ignore && e.ExpectedFactor == 0 }
+
+// exceptionFrom reads the exception annotations of object (namespace/name, for warnings).
+// Values that do not parse are warned about and disregarded.
+func exceptionFrom(annotations map[string]string, object string) Exception {
+	var e Exception
+	if v, ok := annotations[IgnoreAnnotation]; ok {
+		ignore, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			diag.Warnf("%s: invalid %s annotation %q, want true or false; disregarding it", object, IgnoreAnnotation, v)
+		}
+		e.Ignore = ignore
+	}
+	if v, ok := annotations[ExpectedFactorAnnotation]; ok {
+		factor, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "x"), 64)
+		if err != nil || factor <= 0 {
+			diag.Warnf("%s: invalid %s annotation %q, want a positive number; disregarding it", object, ExpectedFactorAnnotation, v)
+		} else {
+			e.ExpectedFactor = factor
+		}
+	}
+	return e
+}
@@ -0,0 +1,27 @@
+package kube
+
+import "testing"
+
+func TestExceptionFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        Exception
+	}{
+		{"none", nil, Exception{}},
+		{"ignore", map[string]string{"kusa.io/ignore": "true"}, Exception{Ignore: true}},
+		{"ignore false", map[string]string{"kusa.io/ignore": "false"}, Exception{}},
+		{"invalid ignore", map[string]string{"kusa.io/ignore": "yes please"}, Exception{}},
+		{"expected factor", map[string]string{"kusa.io/expected-factor": "30"}, Exception{ExpectedFactor: 30}},
+		{"expected factor with x", map[string]string{"kusa.io/expected-factor": "2.5x"}, Exception{ExpectedFactor: 2.5}},
+		{"invalid expected factor", map[string]string{"kusa.io/expected-factor": "-3"}, Exception{}},
+		{"both", map[string]string{"kusa.io/ignore": "true", "kusa.io/expected-factor": "10"}, Exception{Ignore: true, ExpectedFactor: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exceptionFrom(tt.annotations, "shop/web"); got != tt.want {
+				t.Errorf("exceptionFrom() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
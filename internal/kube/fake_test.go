@@ -0,0 +1,95 @@
+package kube
+
+import (
+	"errors"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// fakeCluster builds Clients backed by fake clientsets. Metrics objects are served by
+// reactors because the metrics fake's object tracker can't map PodMetrics/NodeMetrics
+// to their resources; a nil metrics list makes the call fail like a missing metrics-server.
+func fakeCluster(objects []runtime.Object, nodeMetrics []metricsv1beta1.NodeMetrics, podMetrics []metricsv1beta1.PodMetrics) *Clients {
+	metrics := metricsfake.NewSimpleClientset()
+	metrics.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if nodeMetrics == nil {
+			return true, nil, errors.New("metrics unavailable")
+		}
+		return true, &metricsv1beta1.NodeMetricsList{Items: nodeMetrics}, nil
+	})
+	metrics.PrependReactor("list", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		if podMetrics == nil {
+			return true, nil, errors.New("metrics unavailable")
+		}
+		ns := a.GetNamespace()
+		list := &metricsv1beta1.PodMetricsList{}
+		for _, m := range podMetrics {
+			if ns == "" || m.Namespace == ns {
+				list.Items = append(list.Items, m)
+			}
+		}
+		return true, list, nil
+	})
+	return NewClientsFrom(fake.NewClientset(objects...), metrics, "fake")
+}
+
+func fakeNode(name, cpu, mem string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(mem),
+		}},
+	}
+}
+
+// fakePod returns a running pod with one container; owner is "Kind/name" or "" for a standalone pod.
+func fakePod(ns, name, node, owner, cpuReq, memReq string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpuReq),
+					corev1.ResourceMemory: resource.MustParse(memReq),
+				}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if owner != "" {
+		kind, ownerName, _ := strings.Cut(owner, "/")
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: kind, Name: ownerName, Controller: &controller}}
+	}
+	return pod
+}
+
+func fakeReplicaSet(ns, name, deployment string) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       ns,
+		Name:            name,
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: deployment}},
+	}}
+}
+
+func fakePodMetrics(ns, name, cpu, mem string) metricsv1beta1.PodMetrics {
+	return metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Containers: []metricsv1beta1.ContainerMetrics{{
+			Name:  "app",
+			Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)},
+		}},
+	}
+}
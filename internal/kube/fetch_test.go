@@ -0,0 +1,620 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestFetchNodes(t *testing.T) {
+	pending := fakePod("shop", "pending", "node-a", "", "1", "1Gi")
+	pending.Status.Phase = corev1.PodPending
+
+	clients := fakeCluster(
+		[]runtime.Object{
+			fakeNode("node-a", "4", "8Gi"),
+			fakeNode("node-b", "2", "4Gi"),
+			fakeReplicaSet("shop", "web-123", "web"),
+			fakePod("shop", "web-123-a", "node-a", "ReplicaSet/web-123", "500m", "512Mi"),
+			fakePod("shop", "web-123-b", "node-b", "ReplicaSet/web-123", "500m", "512Mi"),
+			fakePod("dev", "debug", "node-a", "", "100m", "128Mi"),
+			pending,
+		},
+		[]metricsv1beta1.NodeMetrics{},
+		[]metricsv1beta1.PodMetrics{fakePodMetrics("shop", "web-123-a", "50m", "100Mi")},
+	)
+
+	result, err := FetchNodes(context.Background(), clients, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.PodMetricsAvailable {
+		t.Error("PodMetricsAvailable = false, want true")
+	}
+
+	nodes := make(map[string]NodeInfo)
+	for _, n := range result.Nodes {
+		nodes[n.Name] = n
+	}
+	a := nodes["node-a"]
+	if a.AllocatableCPU != 4000 || a.RequestedCPU != 600 || a.RequestedMem != 640 {
+		t.Errorf("node-a: allocatable %dm, requested %dm/%gMi; want 4000m, 600m/640Mi", a.AllocatableCPU, a.RequestedCPU, a.RequestedMem)
+	}
+	if len(a.Pods) != 2 {
+		t.Fatalf("node-a has %d running pods, want 2", len(a.Pods))
+	}
+	for _, p := range a.Pods {
+		switch p.Name {
+		case "web-123-a":
+			if p.WorkloadKind != "Deployment" || p.WorkloadName != "web" || !p.MetricsAvailable || p.CPUActual != 50 {
+				t.Errorf("web-123-a = %+v, want Deployment/web with 50m actual", p)
+			}
+		case "debug":
+			if !p.Standalone || p.MetricsAvailable {
+				t.Errorf("debug = %+v, want standalone without metrics", p)
+			}
+		}
+	}
+}
+
+func TestFetchNodesWithoutMetrics(t *testing.T) {
+	clients := fakeCluster([]runtime.Object{fakeNode("node-a", "4", "8Gi")}, nil, nil)
+
+	result, err := FetchNodes(context.Background(), clients, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NodeMetricsAvailable || result.PodMetricsAvailable {
+		t.Errorf("metrics available = %v/%v, want false/false", result.NodeMetricsAvailable, result.PodMetricsAvailable)
+	}
+	if len(result.Nodes) != 1 {
+		t.Errorf("got %d nodes, want 1", len(result.Nodes))
+	}
+}
+
+func TestFetchNodesPlatform(t *testing.T) {
+	labelled := fakeNode("node-a", "4", "8Gi")
+	labelled.Labels = map[string]string{corev1.LabelOSStable: "linux", corev1.LabelArchStable: "arm64"}
+	reported := fakeNode("node-b", "4", "8Gi")
+	reported.Status.NodeInfo = corev1.NodeSystemInfo{OperatingSystem: "windows", Architecture: "amd64"}
+	clients := fakeCluster([]runtime.Object{labelled, reported}, nil, nil)
+
+	result, err := FetchNodes(context.Background(), clients, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]string{"node-a": {"linux", "arm64"}, "node-b": {"windows", "amd64"}}
+	for _, n := range result.Nodes {
+		if got := [2]string{n.OS, n.Arch}; got != want[n.Name] {
+			t.Errorf("%s os/arch = %v, want %v", n.Name, got, want[n.Name])
+		}
+	}
+}
+
+func TestFetchNodesPodSlots(t *testing.T) {
+	node := fakeNode("node-a", "4", "8Gi")
+	node.Status.Allocatable[corev1.ResourcePods] = resource.MustParse("110")
+	node.Spec.PodCIDR = "10.244.0.0/26"
+	web := fakePod("shop", "web", "node-a", "", "100m", "64Mi")
+	web.Status.PodIPs = []corev1.PodIP{{IP: "10.244.0.5"}, {IP: "fd00::5"}}
+	proxy := fakePod("kube-system", "proxy", "node-a", "DaemonSet/proxy", "100m", "64Mi")
+	proxy.Spec.HostNetwork = true
+	proxy.Status.PodIPs = []corev1.PodIP{{IP: "192.168.1.10"}}
+	pending := fakePod("shop", "starting", "node-a", "", "100m", "64Mi")
+	pending.Status.Phase = corev1.PodPending
+	done := fakePod("shop", "job", "node-a", "", "100m", "64Mi")
+	done.Status.Phase = corev1.PodSucceeded
+
+	// node-b's CNI assigns pod IPs outside its pod CIDR, so the CIDR caps nothing.
+	vpc := fakeNode("node-b", "4", "8Gi")
+	vpc.Status.Allocatable[corev1.ResourcePods] = resource.MustParse("110")
+	vpc.Spec.PodCIDR = "10.244.1.0/26"
+	api := fakePod("shop", "api", "node-b", "", "100m", "64Mi")
+	api.Status.PodIPs = []corev1.PodIP{{IP: "172.31.4.20"}}
+	clients := fakeCluster([]runtime.Object{node, web, proxy, pending, done, vpc, api}, nil, nil)
+
+	result, err := FetchNodes(context.Background(), clients, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := make(map[string]NodeInfo)
+	for _, n := range result.Nodes {
+		nodes[n.Name] = n
+	}
+	n := nodes["node-a"]
+	if n.AllocatablePods != 110 || n.PodCIDRAddresses != 62 || n.PodSlots() != 63 {
+		t.Errorf("allocatable pods %d, CIDR addresses %d, slots %d, want 110, 62, 63 with the host-network pod", n.AllocatablePods, n.PodCIDRAddresses, n.PodSlots())
+	}
+	if n.ScheduledPods != 3 || n.HostNetworkPods != 1 || len(n.Pods) != 2 {
+		t.Errorf("scheduled %d, host network %d, running %d, want 3, 1 and 2", n.ScheduledPods, n.HostNetworkPods, len(n.Pods))
+	}
+	if b := nodes["node-b"]; b.PodCIDRAddresses != 0 || b.PodSlots() != 110 {
+		t.Errorf("node-b CIDR addresses %d, slots %d, want 0 and 110: its pod IPs are outside the CIDR", b.PodCIDRAddresses, b.PodSlots())
+	}
+}
+
+func TestPodCIDRAddresses(t *testing.T) {
+	tests := []struct {
+		name   string
+		cidr   string
+		podIPs []string
+		want   int64
+	}{
+		{"allocated from the CIDR", "10.244.0.0/24", []string{"10.244.0.7", "10.244.0.9"}, 254},
+		{"one IP outside", "10.244.0.0/24", []string{"10.244.0.7", "10.0.12.3"}, 0},
+		{"no pod IPs yet", "10.244.0.0/24", nil, 0},
+		{"IPv6 pod IPs only", "10.244.0.0/24", []string{"fd00::7"}, 0},
+		{"IPv6 CIDR", "fd00::/64", []string{"fd00::7"}, 0},
+		{"unset", "", []string{"10.244.0.7"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podCIDRAddresses(tt.cidr, tt.podIPs); got != tt.want {
+				t.Errorf("podCIDRAddresses(%q, %v) = %d, want %d", tt.cidr, tt.podIPs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchNodesTaints(t *testing.T) {
+	node := fakeNode("node-a", "4", "8Gi")
+	node.Spec.Unschedulable = true
+	node.Spec.Taints = []corev1.Taint{
+		{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "batch", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+		{Key: "evict", Effect: corev1.TaintEffectNoExecute},
+	}
+	clients := fakeCluster([]runtime.Object{node}, nil, nil)
+
+	result, err := FetchNodes(context.Background(), clients, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := result.Nodes[0]
+	if !n.Unschedulable {
+		t.Error("Unschedulable = false, want true")
+	}
+	var keys []string
+	for _, taint := range n.Taints {
+		keys = append(keys, taint.Key)
+	}
+	if want := []string{"dedicated", "evict"}; !slices.Equal(keys, want) {
+		t.Errorf("taints = %v, want %v (cordon taint and PreferNoSchedule left out)", keys, want)
+	}
+}
+
+func TestFetchRequireMetrics(t *testing.T) {
+	clients := fakeCluster([]runtime.Object{fakeNode("node-a", "4", "8Gi")}, nil, nil)
+	clients.RequireMetrics = true
+
+	if _, err := FetchNodes(context.Background(), clients, false); !errors.Is(err, ErrMetricsUnavailable) {
+		t.Errorf("FetchNodes err = %v, want ErrMetricsUnavailable", err)
+	}
+	if _, err := FetchPods(context.Background(), clients, ""); !errors.Is(err, ErrMetricsUnavailable) {
+		t.Errorf("FetchPods err = %v, want ErrMetricsUnavailable", err)
+	}
+	if _, err := FetchWorkloads(context.Background(), clients, "", false); !errors.Is(err, ErrMetricsUnavailable) {
+		t.Errorf("FetchWorkloads err = %v, want ErrMetricsUnavailable", err)
+	}
+}
+
+func TestFetchPodsNamespace(t *testing.T) {
+	clients := fakeCluster(
+		[]runtime.Object{
+			fakePod("shop", "web", "node-a", "", "500m", "512Mi"),
+			fakePod("dev", "debug", "node-a", "", "100m", "128Mi"),
+		},
+		nil,
+		[]metricsv1beta1.PodMetrics{fakePodMetrics("shop", "web", "50m", "100Mi"), fakePodMetrics("dev", "debug", "1m", "10Mi")},
+	)
+
+	result, err := FetchPods(context.Background(), clients, "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Pods) != 1 || result.Pods[0].Name != "web" {
+		t.Fatalf("got pods %+v, want only shop/web", result.Pods)
+	}
+	if p := result.Pods[0]; p.CPURequest != 500 || p.CPUActual != 50 || p.MemActual != 100 {
+		t.Errorf("shop/web = %+v, want 500m request, 50m/100Mi actual", p)
+	}
+	if c := result.Pods[0].Containers; len(c) != 1 || c[0].CPURequest != 500 || !c[0].MetricsAvailable || c[0].CPUActual != 50 || c[0].MemActual != 100 {
+		t.Errorf("shop/web containers = %+v, want one with 500m request, 50m/100Mi actual", c)
+	}
+}
+
+func TestFetchNodePods(t *testing.T) {
+	clients := fakeCluster(
+		[]runtime.Object{
+			fakePod("shop", "web", "node-a", "", "500m", "512Mi"),
+			fakePod("shop", "cart", "node-b", "", "100m", "128Mi"),
+		},
+		nil, nil,
+	)
+	var selector string
+	clients.Core.(*fake.Clientset).PrependReactor("list", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		selector = a.(k8stesting.ListAction).GetListRestrictions().Fields.String()
+		return false, nil, nil
+	})
+
+	result, err := FetchNodePods(context.Background(), clients, "", "node-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Pods) != 1 || result.Pods[0].Name != "cart" {
+		t.Errorf("got pods %+v, want only cart", result.Pods)
+	}
+	if selector != "spec.nodeName=node-b" {
+		t.Errorf("field selector %q, want spec.nodeName=node-b", selector)
+	}
+}
+
+func TestParseWorkloadRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    WorkloadRef
+		wantErr bool
+	}{
+		{ref: "deployment/shop/web", want: WorkloadRef{Kind: "Deployment", Namespace: "shop", Name: "web"}},
+		{ref: "StatefulSet/data/postgres", want: WorkloadRef{Kind: "StatefulSet", Namespace: "data", Name: "postgres"}},
+		{ref: "shop/web", wantErr: true},
+		{ref: "shop/Deployment/web", wantErr: true},
+		{ref: "deployment//web", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseWorkloadRef(tt.ref)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseWorkloadRef(%q) = %+v, %v; want %+v (error %v)", tt.ref, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWorkloadPods(t *testing.T) {
+	notController := fakePod("shop", "web-extra", "node-a", "", "100m", "128Mi")
+	notController.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-123", Controller: ptrTo(false)}}
+	clients := fakeCluster(
+		[]runtime.Object{
+			fakeReplicaSet("shop", "web-123", "web"),
+			fakePod("shop", "web-123-a", "node-a", "ReplicaSet/web-123", "500m", "512Mi"),
+			fakePod("shop", "web-0", "node-a", "StatefulSet/web", "500m", "512Mi"),
+			fakePod("dev", "web-123-b", "node-a", "ReplicaSet/web-123", "500m", "512Mi"),
+			notController,
+		},
+		nil, nil,
+	)
+	result, err := FetchPods(context.Background(), clients, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range WorkloadPods(result.Pods, WorkloadRef{Kind: "Deployment", Namespace: "shop", Name: "web"}) {
+		names = append(names, p.Name)
+	}
+	if want := []string{"web-123-a"}; !slices.Equal(names, want) {
+		t.Errorf("WorkloadPods = %v, want %v (not the StatefulSet, the other namespace or a non-controller owner)", names, want)
+	}
+}
+
+// forbidPods makes listing pods fail with Forbidden across all namespaces and in the
+// given ones.
+func forbidPods(clients *Clients, namespaces ...string) {
+	clients.Core.(*fake.Clientset).PrependReactor("list", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		if ns := a.GetNamespace(); ns == "" || slices.Contains(namespaces, ns) {
+			return true, nil, apierrors.NewForbidden(corev1.Resource("pods"), "", errors.New("denied"))
+		}
+		return false, nil, nil
+	})
+}
+
+func TestFetchPodsForbiddenFallsBackToContextNamespace(t *testing.T) {
+	clients := fakeCluster(
+		[]runtime.Object{
+			fakePod("shop", "web", "node-a", "", "500m", "512Mi"),
+			fakePod("dev", "debug", "node-a", "", "100m", "128Mi"),
+		},
+		nil,
+		[]metricsv1beta1.PodMetrics{fakePodMetrics("shop", "web", "50m", "100Mi"), fakePodMetrics("dev", "debug", "1m", "10Mi")},
+	)
+	clients.Namespace = "shop"
+	forbidPods(clients)
+	clients.Core.(*fake.Clientset).PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(corev1.Resource("namespaces"), "", errors.New("denied"))
+	})
+
+	result, err := FetchPods(context.Background(), clients, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Pods) != 1 || result.Pods[0].Name != "web" {
+		t.Errorf("got pods %+v, want only shop/web", result.Pods)
+	}
+
+	if _, err := FetchNodes(context.Background(), clients, false); !apierrors.IsForbidden(err) {
+		t.Errorf("FetchNodes err = %v, want Forbidden", err)
+	}
+}
+
+func TestFetchForbiddenSkipsNamespaces(t *testing.T) {
+	clients := fakeCluster(
+		[]runtime.Object{
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+			fakePod("shop", "web", "node-a", "", "500m", "512Mi"),
+			fakePod("dev", "debug", "node-a", "", "100m", "128Mi"),
+			fakePod("payments", "ledger", "node-a", "", "1", "1Gi"),
+			fakePod("kube-system", "coredns", "node-a", "", "100m", "70Mi"),
+		},
+		nil,
+		[]metricsv1beta1.PodMetrics{fakePodMetrics("shop", "web", "50m", "100Mi"), fakePodMetrics("dev", "debug", "1m", "10Mi")},
+	)
+	clients.Concurrency = 1
+	forbidPods(clients, "payments")
+
+	pods, err := FetchPods(context.Background(), clients, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range pods.Pods {
+		names = append(names, p.Namespace+"/"+p.Name)
+	}
+	if want := []string{"dev/debug", "kube-system/coredns", "shop/web"}; !slices.Equal(names, want) {
+		t.Errorf("FetchPods got %v, want %v", names, want)
+	}
+	if want := []string{"payments"}; !slices.Equal(pods.Skipped, want) {
+		t.Errorf("FetchPods skipped %v, want %v", pods.Skipped, want)
+	}
+
+	lint, err := FetchLint(context.Background(), clients, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lint.PodsScanned != 2 {
+		t.Errorf("FetchLint scanned %d pods, want 2 (dev and shop)", lint.PodsScanned)
+	}
+	if want := []string{"payments"}; !slices.Equal(lint.Skipped, want) {
+		t.Errorf("FetchLint skipped %v, want %v", lint.Skipped, want)
+	}
+
+	forbidPods(clients, "dev", "shop", "kube-system")
+	if _, err := FetchPods(context.Background(), clients, ""); !apierrors.IsForbidden(err) {
+		t.Errorf("FetchPods with every namespace forbidden: err = %v, want Forbidden", err)
+	}
+}
+
+func TestFetchWorkloads(t *testing.T) {
+	replicas := int32(3)
+	idle := int32(0)
+	pending := int32(2)
+	limited := fakePod("shop", "web-123-a", "node-a", "ReplicaSet/web-123", "500m", "512Mi")
+	limited.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	clients := fakeCluster(
+		[]runtime.Object{
+			fakeReplicaSet("shop", "web-123", "web"),
+			limited,
+			fakePod("shop", "web-123-b", "node-b", "ReplicaSet/web-123", "500m", "512Mi"),
+			fakePod("kube-system", "proxy", "node-a", "DaemonSet/proxy", "100m", "64Mi"),
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "shop",
+					Name:        "web",
+					Labels:      map[string]string{"argocd.argoproj.io/instance": "storefront"},
+					Annotations: map[string]string{"meta.helm.sh/release-name": "shop"},
+				},
+				Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "old"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &idle},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "pending"},
+				Spec: appsv1.DeploymentSpec{Replicas: &pending, Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Overhead: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("50m"),
+						corev1.ResourceMemory: resource.MustParse("64Mi"),
+					},
+					Containers: []corev1.Container{
+						{Name: "app", Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("500m"),
+								corev1.ResourceMemory: resource.MustParse("256Mi"),
+							},
+						}},
+						{Name: "proxy", Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+						}},
+					},
+				}}},
+			},
+		},
+		nil,
+		[]metricsv1beta1.PodMetrics{fakePodMetrics("shop", "web-123-a", "50m", "100Mi"), fakePodMetrics("shop", "web-123-b", "70m", "100Mi")},
+	)
+
+	result, err := FetchWorkloads(context.Background(), clients, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workloads := make(map[string]WorkloadInfo)
+	for _, w := range result.Workloads {
+		workloads[w.Kind+"/"+w.Name] = w
+	}
+	if _, ok := workloads["DaemonSet/proxy"]; ok {
+		t.Error("system namespace workload included without includeSystem")
+	}
+	web, ok := workloads["Deployment/web"]
+	if !ok {
+		t.Fatalf("Deployment/web missing from %v", result.Workloads)
+	}
+	if web.PodCount != 2 || web.DesiredPods != 3 || web.CPURequest != 1000 || web.CPUActual != 120 {
+		t.Errorf("web = %+v, want 2/3 pods, 1000m requested, 120m actual", web)
+	}
+	if web.CPULimit != 1000 || web.MemLimit != 1024 || !web.MissingCPULimit || !web.MissingMemLimit {
+		t.Errorf("web limits %dm/%.0fMi (missing %v/%v), want 1000m/1024Mi from one pod, the other flagged as missing",
+			web.CPULimit, web.MemLimit, web.MissingCPULimit, web.MissingMemLimit)
+	}
+	if web.Release != "shop" || web.ArgoCDApp != "storefront" {
+		t.Errorf("web release %q, Argo CD app %q, want shop and storefront from its Deployment", web.Release, web.ArgoCDApp)
+	}
+	if old, ok := workloads["Deployment/old"]; !ok || old.PodCount != 0 || !old.DesiredKnown || old.Release != "" || old.ArgoCDApp != "" {
+		t.Errorf("old = %+v (present %v), want listed with 0 pods", old, ok)
+	}
+	if p := workloads["Deployment/pending"]; p.CPURequest != 600 || p.MemRequest != 128 || p.CPULimit != 1200 || p.MemLimit != 512 || p.MissingCPULimit || !p.MissingMemLimit {
+		t.Errorf("pending = %+v, want 600m/128Mi requested with overhead and 1200m/512Mi limits from its template ×2, proxy missing a memory limit", p)
+	}
+}
+
+func TestFetchNodesHugePages(t *testing.T) {
+	node := fakeNode("node-a", "4", "8Gi")
+	node.Status.Allocatable["hugepages-2Mi"] = resource.MustParse("4Gi")
+	node.Status.Allocatable["hugepages-1Gi"] = resource.MustParse("0")
+	db := fakePod("data", "db", "node-a", "", "1", "1Gi")
+	db.Spec.Containers[0].Resources.Requests["hugepages-2Mi"] = resource.MustParse("1Gi")
+
+	clients := fakeCluster([]runtime.Object{node, db}, []metricsv1beta1.NodeMetrics{}, nil)
+	result, err := FetchNodes(context.Background(), clients, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := result.Nodes[0]
+	if len(n.HugePages) != 1 {
+		t.Fatalf("HugePages = %+v, want only 2Mi", n.HugePages)
+	}
+	if hp := n.HugePages["2Mi"]; hp.Allocatable != 4096 || hp.Requested != 1024 {
+		t.Errorf("2Mi = %+v, want 4096 allocatable, 1024 requested", hp)
+	}
+	if n.AllocatableMem != 8192 || n.RequestedMem != 1024 {
+		t.Errorf("memory allocatable/requested = %g/%g, want hugepages kept out of 8192/1024", n.AllocatableMem, n.RequestedMem)
+	}
+}
+
+func TestFetchNodesPodOverhead(t *testing.T) {
+	sandboxed := fakePod("shop", "web", "node-a", "", "500m", "512Mi")
+	sandboxed.Spec.RuntimeClassName = ptrTo("kata")
+	sandboxed.Spec.Overhead = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("250m"),
+		corev1.ResourceMemory: resource.MustParse("160Mi"),
+	}
+
+	clients := fakeCluster([]runtime.Object{fakeNode("node-a", "4", "8Gi"), sandboxed}, []metricsv1beta1.NodeMetrics{}, nil)
+	result, err := FetchNodes(context.Background(), clients, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := result.Nodes[0]
+	if n.RequestedCPU != 750 || n.RequestedMem != 672 {
+		t.Errorf("node requests = %dm/%gMi, want overhead included: 750m/672Mi", n.RequestedCPU, n.RequestedMem)
+	}
+	if p := n.Pods[0]; p.CPURequest != 750 || p.MemRequest != 672 {
+		t.Errorf("pod requests = %dm/%gMi, want 750m/672Mi", p.CPURequest, p.MemRequest)
+	}
+}
+
+func TestPodInfoFromMirrorPod(t *testing.T) {
+	pod := fakePod("kube-system", "etcd-node-a", "node-a", "", "100m", "100Mi")
+	pod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "abc"}
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: "Node", Name: "node-a", Controller: ptrTo(true)}}
+
+	pi := podInfoFromPod(*pod, nil)
+	if !pi.Mirror || pi.Standalone || pi.WorkloadKind != "StaticPod" || pi.WorkloadName != "etcd-node-a" {
+		t.Errorf("got %+v, want a non-standalone mirror pod of kind StaticPod", pi)
+	}
+}
+
+func TestPodInfoContainerRestarts(t *testing.T) {
+	killedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		state    corev1.ContainerState
+		last     corev1.ContainerState
+		wantKill time.Time
+	}{
+		{name: "running", state: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		{
+			name:     "last termination was an OOM kill",
+			state:    corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			last:     corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: metav1.NewTime(killedAt)}},
+			wantKill: killedAt,
+		},
+		{
+			name:     "just OOM-killed",
+			state:    corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: metav1.NewTime(killedAt)}},
+			wantKill: killedAt,
+		},
+		{
+			name:  "crashed",
+			state: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			last:  corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", FinishedAt: metav1.NewTime(killedAt)}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := fakePod("shop", "web", "node-a", "", "100m", "128Mi")
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", RestartCount: 4, State: tc.state, LastTerminationState: tc.last}}
+
+			c := podInfoFromPod(*pod, nil).Containers[0]
+			if c.Restarts != 4 || !c.LastOOMKilled.Equal(tc.wantKill) {
+				t.Errorf("restarts %d, last OOM kill %v; want 4, %v", c.Restarts, c.LastOOMKilled, tc.wantKill)
+			}
+		})
+	}
+}
+
+func TestFetchWorkloadSpec(t *testing.T) {
+	replicas := int32(2)
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+		},
+	}}}}
+	clients := fakeCluster([]runtime.Object{
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "data", Name: "db"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, Template: template},
+		},
+	}, nil, nil)
+
+	for _, kind := range []string{"", "statefulset"} {
+		spec, err := FetchWorkloadSpec(context.Background(), clients, "data", kind, "db")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if spec == nil || spec.Kind != "StatefulSet" || spec.DesiredPods != 2 {
+			t.Fatalf("kind %q: got %+v, want StatefulSet db with 2 replicas", kind, spec)
+		}
+		if c := spec.Containers; len(c) != 1 || c[0].CPURequest != 250 || c[0].MemLimit != 512 || c[0].CPULimit != 0 {
+			t.Errorf("containers = %+v, want app with 250m request and 512Mi limit", c)
+		}
+	}
+
+	for _, kind := range []string{"Deployment", "Pod"} {
+		if spec, err := FetchWorkloadSpec(context.Background(), clients, "data", kind, "db"); err != nil || spec != nil {
+			t.Errorf("kind %s: got %+v, %v; want nil without an error", kind, spec, err)
+		}
+	}
+}
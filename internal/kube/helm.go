@@ -0,0 +1,30 @@
+package kube
+
+// Helm marks the resources of a release with these annotations (Helm 3) and labels (chart
+// conventions, Helm 2).
+const (
+	helmReleaseNameAnnotation = "meta.helm.sh/release-name"
+	helmInstanceLabel         = "app.kubernetes.io/instance"
+	helmManagedByLabel        = "app.kubernetes.io/managed-by"
+	helmChartLabel            = "helm.sh/chart"
+)
+
+// HelmRelease returns the Helm release an object belongs to, or "" for objects outside
+// one. Helm 3 annotates every object it installs with the release name; pods, which it
+// does not create itself, only carry the labels charts conventionally put on them:
+// app.kubernetes.io/instance next to app.kubernetes.io/managed-by=Helm or helm.sh/chart,
+// or the older release/heritage=Helm pair.
+func HelmRelease(labels, annotations map[string]string) string {
+	if name := annotations[helmReleaseNameAnnotation]; name != "" {
+		return name
+	}
+	if instance := labels[helmInstanceLabel]; instance != "" {
+		if labels[helmManagedByLabel] == "Helm" || labels[helmChartLabel] != "" {
+			return instance
+		}
+	}
+	if labels["heritage"] == "Helm" {
+		return labels["release"]
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package kube
+
+import "testing"
+
+func TestHelmRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        string
+	}{
+		{"release annotation", nil, map[string]string{"meta.helm.sh/release-name": "shop"}, "shop"},
+		{"annotation wins", map[string]string{"app.kubernetes.io/instance": "other", "helm.sh/chart": "x-1.0.0"}, map[string]string{"meta.helm.sh/release-name": "shop"}, "shop"},
+		{"managed-by", map[string]string{"app.kubernetes.io/instance": "shop", "app.kubernetes.io/managed-by": "Helm"}, nil, "shop"},
+		{"chart label", map[string]string{"app.kubernetes.io/instance": "shop", "helm.sh/chart": "storefront-1.2.0"}, nil, "shop"},
+		{"legacy heritage", map[string]string{"release": "db", "heritage": "Helm"}, nil, "db"},
+		{"instance without Helm", map[string]string{"app.kubernetes.io/instance": "shop", "app.kubernetes.io/managed-by": "argocd"}, nil, ""},
+		{"release without heritage", map[string]string{"release": "db"}, nil, ""},
+		{"no labels", nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HelmRelease(tt.labels, tt.annotations); got != tt.want {
+				t.Errorf("HelmRelease() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
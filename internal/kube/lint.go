@@ -0,0 +1,177 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LintFinding describes one container of a workload that is missing resource requests
+// (or, when limits are checked, limits).
+// Replicas share a pod template, so findings are grouped per workload and container name.
+type LintFinding struct {
+	Kind      string
+	Namespace string
+	Workload  string
+	Container string
+
+	MissingCPU bool
+	MissingMem bool
+
+	MissingCPULimit bool
+	MissingMemLimit bool
+
+	PodCount int // pods of the workload where this container is affected
+}
+
+// FetchLintResult holds the result of FetchLint.
+type FetchLintResult struct {
+	Findings          []LintFinding
+	PodsScanned       int
+	ContainersScanned int
+	Skipped           []string // namespaces left out of a per-namespace fetch, see fetchEachNamespace
+}
+
+// FetchLint lists pods and ReplicaSets and reports containers without CPU or memory requests,
+// grouped by owning workload. When checkLimits is true, containers without CPU or memory
+// limits are reported as well. Terminated (Succeeded/Failed) pods are ignored.
+// When namespace is non-empty the system-namespace filter is skipped automatically.
+// A cluster-wide fetch the user may not make is retried per namespace, see
+// fetchEachNamespace.
+func FetchLint(ctx context.Context, clients *Clients, namespace string, includeSystem, checkLimits bool) (*FetchLintResult, error) {
+	result, err := fetchLint(ctx, clients, namespace, includeSystem, checkLimits)
+	if namespace != "" || !apierrors.IsForbidden(err) {
+		return result, err
+	}
+	result, skipped, err := fetchEachNamespace(ctx, clients, err, includeSystem,
+		func(ctx context.Context, ns string) (*FetchLintResult, error) {
+			return fetchLint(ctx, clients, ns, includeSystem, checkLimits)
+		},
+		func(into, from *FetchLintResult) {
+			into.Findings = append(into.Findings, from.Findings...)
+			into.PodsScanned += from.PodsScanned
+			into.ContainersScanned += from.ContainersScanned
+		})
+	if result != nil {
+		result.Skipped = skipped
+	}
+	return result, err
+}
+
+func fetchLint(ctx context.Context, clients *Clients, namespace string, includeSystem, checkLimits bool) (*FetchLintResult, error) {
+	var (
+		pods        *corev1.PodList
+		replicaSets *appsv1.ReplicaSetList
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return clients.track(gctx, "list pods", func(ctx context.Context) (int, error) {
+			var err error
+			pods, err = clients.Core.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list pods: %w", err)
+			}
+			return len(pods.Items), nil
+		})
+	})
+
+	g.Go(func() error {
+		return clients.track(gctx, "list replicasets", func(ctx context.Context) (int, error) {
+			var err error
+			replicaSets, err = clients.Core.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list replicasets: %w", err)
+			}
+			return len(replicaSets.Items), nil
+		})
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	rsToDeployment := buildRSToDeployment(replicaSets)
+	result := &FetchLintResult{}
+	findings := make(map[string]*LintFinding)
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if namespace == "" && !includeSystem && SystemNamespaces[pod.Namespace] {
+			continue
+		}
+
+		result.PodsScanned++
+		owner := resolveWorkloadOwner(pod, rsToDeployment)
+
+		for _, c := range pod.Spec.Containers {
+			result.ContainersScanned++
+			missingCPU, missingMem := missingRequests(c)
+			var missingCPULimit, missingMemLimit bool
+			if checkLimits {
+				missingCPULimit, missingMemLimit = missingLimits(c)
+			}
+			if !missingCPU && !missingMem && !missingCPULimit && !missingMemLimit {
+				continue
+			}
+
+			key := owner.Namespace + "/" + owner.Kind + "/" + owner.Name + "/" + c.Name
+			f, ok := findings[key]
+			if !ok {
+				f = &LintFinding{
+					Kind:      owner.Kind,
+					Namespace: owner.Namespace,
+					Workload:  owner.Name,
+					Container: c.Name,
+				}
+				findings[key] = f
+			}
+			f.MissingCPU = f.MissingCPU || missingCPU
+			f.MissingMem = f.MissingMem || missingMem
+			f.MissingCPULimit = f.MissingCPULimit || missingCPULimit
+			f.MissingMemLimit = f.MissingMemLimit || missingMemLimit
+			f.PodCount++
+		}
+	}
+
+	for _, f := range findings {
+		result.Findings = append(result.Findings, *f)
+	}
+	sort.Slice(result.Findings, func(i, j int) bool {
+		a, b := result.Findings[i], result.Findings[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Workload != b.Workload {
+			return a.Workload < b.Workload
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Container < b.Container
+	})
+	return result, nil
+}
+
+// missingRequests reports whether a container lacks a CPU and/or memory request.
+func missingRequests(c corev1.Container) (cpu, mem bool) {
+	cpuReq := c.Resources.Requests[corev1.ResourceCPU]
+	memReq := c.Resources.Requests[corev1.ResourceMemory]
+	return cpuReq.IsZero(), memReq.IsZero()
+}
+
+// missingLimits reports whether a container lacks a CPU and/or memory limit.
+func missingLimits(c corev1.Container) (cpu, mem bool) {
+	cpuLim := c.Resources.Limits[corev1.ResourceCPU]
+	memLim := c.Resources.Limits[corev1.ResourceMemory]
+	return cpuLim.IsZero(), memLim.IsZero()
+}
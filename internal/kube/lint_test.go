@@ -0,0 +1,37 @@
+package kube
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestMissingRequests(t *testing.T) {
+	tests := []struct {
+		name             string
+		requests         corev1.ResourceList
+		wantCPU, wantMem bool
+	}{
+		{"no requests at all", nil, true, true},
+		{"cpu only", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}, false, true},
+		{"memory only", corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}, true, false},
+		{"both set", corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		}, false, false},
+		{"explicit zero counts as missing", corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("0"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		}, true, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := corev1.Container{Resources: corev1.ResourceRequirements{Requests: tc.requests}}
+			gotCPU, gotMem := missingRequests(c)
+			if gotCPU != tc.wantCPU || gotMem != tc.wantMem {
+				t.Errorf("missingRequests() = (%v, %v), want (%v, %v)", gotCPU, gotMem, tc.wantCPU, tc.wantMem)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package kube
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/diag"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListNamespaceNames returns the sorted names of all namespaces in the cluster.
+func ListNamespaceNames(ctx context.Context, clients *Clients) ([]string, error) {
+	var list *corev1.NamespaceList
+	err := clients.track(ctx, "list namespaces", func(ctx context.Context) (int, error) {
+		var err error
+		list, err = clients.Core.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		return len(list.Items), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		names = append(names, ns.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FetchNamespaceLabels returns the labels of each namespace, keyed by namespace name.
+// When namespace is non-empty only that namespace is read, which needs no cluster-wide
+// list permission.
+func FetchNamespaceLabels(ctx context.Context, clients *Clients, namespace string) (map[string]map[string]string, error) {
+	labels := make(map[string]map[string]string)
+	if namespace != "" {
+		err := clients.track(ctx, "get namespace", func(ctx context.Context) (int, error) {
+			ns, err := clients.Core.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+			}
+			labels[ns.Name] = ns.Labels
+			return 1, nil
+		})
+		return labels, err
+	}
+
+	err := clients.track(ctx, "list namespaces", func(ctx context.Context) (int, error) {
+		list, err := clients.Core.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		for _, ns := range list.Items {
+			labels[ns.Name] = ns.Labels
+		}
+		return len(list.Items), nil
+	})
+	return labels, err
+}
+
+// DefaultConcurrency is how many namespaces fetchEachNamespace fetches in parallel by
+// default; see Clients.Concurrency.
+const DefaultConcurrency = 4
+
+// fetchEachNamespace recovers from a cluster-wide fetch the user may not make, as is
+// common for developers. When the namespaces can be listed, fetch runs in each of them
+// (system namespaces only with includeSystem), Clients.Concurrency at a time, and the results are merged in namespace
+// order; namespaces fetch is forbidden in too are skipped, returned in skipped for the
+// report to list and named in a warning, so one RBAC gap does not cost the whole report.
+// Otherwise only the namespace of the kubeconfig context ("default" when it names none) is
+// fetched. forbidden is the error of the cluster-wide fetch, returned when no namespace
+// could be read.
+func fetchEachNamespace[T any](ctx context.Context, clients *Clients, forbidden error, includeSystem bool,
+	fetch func(ctx context.Context, namespace string) (*T, error), merge func(into, from *T)) (result *T, skipped []string, err error) {
+	names, err := ListNamespaceNames(ctx, clients)
+	if err != nil {
+		fallback := cmp.Or(clients.Namespace, metav1.NamespaceDefault)
+		diag.Warnf("listing across all namespaces is forbidden (%v); showing namespace %s only, pass --namespace to pick another", forbidden, fallback)
+		result, err := fetch(ctx, fallback)
+		return result, nil, err
+	}
+
+	var namespaces []string
+	for _, ns := range names {
+		if includeSystem || !SystemNamespaces[ns] {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	results := make([]*T, len(namespaces))
+	errs := make([]error, len(namespaces))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(cmp.Or(clients.Concurrency, DefaultConcurrency))
+	for i, ns := range namespaces {
+		g.Go(func() error {
+			results[i], errs[i] = fetch(gctx, ns)
+			if errs[i] != nil && !apierrors.IsForbidden(errs[i]) {
+				return errs[i]
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	for i, ns := range namespaces {
+		switch {
+		case errs[i] != nil:
+			skipped = append(skipped, ns)
+		case result == nil:
+			result = results[i]
+		default:
+			merge(result, results[i])
+		}
+	}
+	if result == nil {
+		return nil, nil, forbidden
+	}
+	if len(skipped) > 0 {
+		diag.Warnf("listing across all namespaces is forbidden; skipped %d of %d namespaces you may not read: %s",
+			len(skipped), len(namespaces), strings.Join(skipped, ", "))
+	}
+	return result, skipped, nil
+}
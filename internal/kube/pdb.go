@@ -0,0 +1,56 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// PDBInfo holds the parts of a PodDisruptionBudget that decide whether an eviction is allowed.
+type PDBInfo struct {
+	Namespace          string
+	Name               string
+	Selector           labels.Selector
+	DisruptionsAllowed int32
+}
+
+// Matches reports whether pod is covered by the budget.
+func (p PDBInfo) Matches(pod PodInfo) bool {
+	return pod.Namespace == p.Namespace && p.Selector.Matches(labels.Set(pod.Labels))
+}
+
+// FetchPDBs lists PodDisruptionBudgets in all namespaces. Budgets with an invalid
+// selector are skipped. As in policy/v1, an empty selector matches every pod in its
+// namespace and a missing one matches none.
+func FetchPDBs(ctx context.Context, clients *Clients) ([]PDBInfo, error) {
+	var list *policyv1.PodDisruptionBudgetList
+	err := clients.track(ctx, "list poddisruptionbudgets", func(ctx context.Context) (int, error) {
+		var err error
+		list, err = clients.Core.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list poddisruptionbudgets: %w", err)
+		}
+		return len(list.Items), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pdbs := make([]PDBInfo, 0, len(list.Items))
+	for _, pdb := range list.Items {
+		sel, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		pdbs = append(pdbs, PDBInfo{
+			Namespace:          pdb.Namespace,
+			Name:               pdb.Name,
+			Selector:           sel,
+			DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+		})
+	}
+	return pdbs, nil
+}
@@ -0,0 +1,140 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// KubeletReserved is the part of a node's capacity the kubelet withholds from pods, read
+// from its configuration: allocatable = capacity − kube-reserved − system-reserved − the
+// hard eviction threshold (memory only).
+type KubeletReserved struct {
+	KubeReservedCPU   int64 // millicores
+	SystemReservedCPU int64 // millicores
+	KubeReservedMem   float64
+	SystemReservedMem float64
+	EvictionMem       float64 // evictionHard memory.available, MiB
+}
+
+// kubeletConfigz is the part of the kubelet's /configz response kusa reads.
+type kubeletConfigz struct {
+	KubeletConfig struct {
+		KubeReserved   map[string]string `json:"kubeReserved"`
+		SystemReserved map[string]string `json:"systemReserved"`
+		EvictionHard   map[string]string `json:"evictionHard"`
+	} `json:"kubeletconfig"`
+}
+
+// kubeletGetFunc returns the raw response of a node's kubelet for path (e.g. "configz"),
+// read through the API server's node proxy.
+type kubeletGetFunc func(ctx context.Context, node, path string) ([]byte, error)
+
+// maxKubeletCalls bounds concurrent kubelet proxy calls.
+const maxKubeletCalls = 10
+
+// FetchKubeletReserved reads the reserved resources of each node's kubelet through the
+// API server's node proxy (/api/v1/nodes/<node>/proxy/configz, which needs the
+// nodes/proxy permission). Nodes whose config can't be read are left out; the error of
+// the first failure is returned only when no node could be read.
+func FetchKubeletReserved(ctx context.Context, clients *Clients, nodes []NodeInfo) (map[string]KubeletReserved, error) {
+	if clients.kubeletGet == nil {
+		return nil, fmt.Errorf("kubelet config is not available for these clients")
+	}
+
+	results := make([]*KubeletReserved, len(nodes))
+	errs := make([]error, len(nodes))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxKubeletCalls)
+	for i, n := range nodes {
+		g.Go(func() error {
+			errs[i] = clients.track(gctx, "get kubelet configz", func(ctx context.Context) (int, error) {
+				raw, err := clients.kubeletGet(ctx, n.Name, "configz")
+				if err != nil {
+					return 0, fmt.Errorf("failed to read kubelet config of %s: %w", n.Name, err)
+				}
+				r, err := parseKubeletReserved(raw, n.CapacityMem)
+				if err != nil {
+					return 0, fmt.Errorf("failed to parse kubelet config of %s: %w", n.Name, err)
+				}
+				results[i] = &r
+				return 1, nil
+			})
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	reserved := make(map[string]KubeletReserved)
+	var firstErr error
+	for i, n := range nodes {
+		if results[i] != nil {
+			reserved[n.Name] = *results[i]
+		} else if firstErr == nil {
+			firstErr = errs[i]
+		}
+	}
+	if len(reserved) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return reserved, nil
+}
+
+// parseKubeletReserved extracts the reservations from a /configz response. A percentage
+// eviction threshold ("5%") is taken of capacityMem (MiB).
+func parseKubeletReserved(raw []byte, capacityMem float64) (KubeletReserved, error) {
+	var cfg kubeletConfigz
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return KubeletReserved{}, err
+	}
+	kc := cfg.KubeletConfig
+
+	var r KubeletReserved
+	var err error
+	if r.KubeReservedCPU, r.KubeReservedMem, err = parseReservedList(kc.KubeReserved); err != nil {
+		return r, fmt.Errorf("kubeReserved: %w", err)
+	}
+	if r.SystemReservedCPU, r.SystemReservedMem, err = parseReservedList(kc.SystemReserved); err != nil {
+		return r, fmt.Errorf("systemReserved: %w", err)
+	}
+	if v, ok := kc.EvictionHard["memory.available"]; ok {
+		if pct, isPct := strings.CutSuffix(v, "%"); isPct {
+			f, err := strconv.ParseFloat(pct, 64)
+			if err != nil {
+				return r, fmt.Errorf("evictionHard memory.available %q: %w", v, err)
+			}
+			r.EvictionMem = capacityMem * f / 100
+		} else {
+			q, err := resource.ParseQuantity(v)
+			if err != nil {
+				return r, fmt.Errorf("evictionHard memory.available %q: %w", v, err)
+			}
+			r.EvictionMem = MiBFromQuantity(q)
+		}
+	}
+	return r, nil
+}
+
+func parseReservedList(m map[string]string) (cpu int64, mem float64, err error) {
+	if v, ok := m[string(corev1.ResourceCPU)]; ok {
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cpu %q: %w", v, err)
+		}
+		cpu = MillicoresFromQuantity(q)
+	}
+	if v, ok := m[string(corev1.ResourceMemory)]; ok {
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return 0, 0, fmt.Errorf("memory %q: %w", v, err)
+		}
+		mem = MiBFromQuantity(q)
+	}
+	return cpu, mem, nil
+}
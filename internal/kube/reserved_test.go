@@ -0,0 +1,75 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseKubeletReserved(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    KubeletReserved
+		wantErr bool
+	}{
+		{
+			name: "absolute eviction threshold",
+			raw: `{"kubeletconfig": {"kubeReserved": {"cpu": "80m", "memory": "512Mi"},
+				"systemReserved": {"cpu": "0.1", "memory": "1Gi"}, "evictionHard": {"memory.available": "100Mi"}}}`,
+			want: KubeletReserved{KubeReservedCPU: 80, SystemReservedCPU: 100, KubeReservedMem: 512, SystemReservedMem: 1024, EvictionMem: 100},
+		},
+		{
+			name: "percentage eviction threshold",
+			raw:  `{"kubeletconfig": {"evictionHard": {"memory.available": "5%"}}}`,
+			want: KubeletReserved{EvictionMem: 400},
+		},
+		{
+			name: "nothing reserved",
+			raw:  `{"kubeletconfig": {}}`,
+			want: KubeletReserved{},
+		},
+		{name: "invalid quantity", raw: `{"kubeletconfig": {"kubeReserved": {"cpu": "lots"}}}`, wantErr: true},
+		{name: "invalid percentage", raw: `{"kubeletconfig": {"evictionHard": {"memory.available": "x%"}}}`, wantErr: true},
+		{name: "not json", raw: `404 page not found`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseKubeletReserved([]byte(tt.raw), 8000)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchKubeletReserved(t *testing.T) {
+	nodes := []NodeInfo{{Name: "node-a"}, {Name: "node-b"}}
+	clients := NewClientsFrom(nil, nil, "test")
+
+	if _, err := FetchKubeletReserved(context.Background(), clients, nodes); err == nil {
+		t.Error("want an error without a kubelet config reader")
+	}
+
+	clients.kubeletGet = func(_ context.Context, node, _ string) ([]byte, error) {
+		if node == "node-b" {
+			return nil, errors.New("forbidden")
+		}
+		return []byte(`{"kubeletconfig": {"kubeReserved": {"cpu": "100m"}}}`), nil
+	}
+	got, err := FetchKubeletReserved(context.Background(), clients, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got["node-a"].KubeReservedCPU != 100 {
+		t.Errorf("got %+v, want node-a only with 100m kube-reserved", got)
+	}
+
+	clients.kubeletGet = func(context.Context, string, string) ([]byte, error) { return nil, errors.New("forbidden") }
+	if _, err := FetchKubeletReserved(context.Background(), clients, nodes); err == nil {
+		t.Error("want an error when no node's config can be read")
+	}
+}
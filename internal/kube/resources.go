@@ -1,13 +1,24 @@
 package kube
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/klog/v2"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
@@ -24,33 +35,195 @@ type NodeInfo struct {
 	AllocatableCPU int64   // millicores
 	AllocatableMem float64 // MiB
 
+	// CapacityCPU and CapacityMem are the node's total resources. The gap to allocatable is
+	// what the kubelet reserves for itself and the OS, see FetchKubeletReserved.
+	CapacityCPU int64   // millicores
+	CapacityMem float64 // MiB
+
+	// AllocatableEphemeral is the node's allocatable ephemeral-storage in MiB (container
+	// writable layers, logs and emptyDir volumes); 0 when unreported.
+	AllocatableEphemeral float64
+
+	// AllocatablePods is how many pods the kubelet admits (its max-pods); 0 when unreported.
+	AllocatablePods int64
+	// PodCIDRAddresses is the number of pod IPs in the node's IPv4 pod CIDR, which caps its
+	// pods; 0 without one, or when the IPs of its pods show the CNI allocates elsewhere.
+	PodCIDRAddresses int64
+	// ScheduledPods counts the non-terminated pods bound to the node, which take a pod slot
+	// whether or not they are running yet. HostNetworkPods are those of them on the host
+	// network, which take no pod IP.
+	ScheduledPods   int
+	HostNetworkPods int
+
+	// Labels are the node's labels, matched by pod node selectors and affinity.
+	Labels map[string]string
+
+	// OS and Arch come from the kubernetes.io/os and kubernetes.io/arch labels, falling
+	// back to the kubelet-reported node info ("linux", "arm64").
+	OS   string
+	Arch string
+
 	// From metrics API (zero if metrics-server unavailable)
 	ActualCPU        int64
 	ActualMem        float64
 	MetricsAvailable bool
+	MetricsTimestamp time.Time     // when the sample was taken
+	MetricsWindow    time.Duration // interval the usage was averaged over
+	MetricsStale     bool          // sample older than Clients.MaxMetricsAge
 
 	// Aggregated from all running pods on this node
 	RequestedCPU int64
 	RequestedMem float64
-
-	// Per-pod breakdown (populated when withPodMetrics=true)
+	LimitCPU     int64   // containers without a limit contribute nothing
+	LimitMem     float64 // MiB
+
+	RequestedEphemeral float64 // MiB of ephemeral-storage
+	LimitEphemeral     float64 // MiB; containers without a limit contribute nothing
+
+	// HugePages holds allocatable and requested hugepages per page size ("2Mi", "1Gi").
+	// Hugepages are pre-allocated outside of allocatable memory, so unrequested pages
+	// are memory no pod can use. Nil when the node has none.
+	HugePages map[string]HugePagesInfo
+
+	// MemoryPressure mirrors the node's MemoryPressure condition: the kubelet is evicting.
+	MemoryPressure bool
+	// DiskPressure mirrors the node's DiskPressure condition: the kubelet is evicting pods to
+	// free disk space, and places no new ones.
+	DiskPressure bool
+	// Unschedulable is set on cordoned nodes: no new pods are placed there.
+	Unschedulable bool
+	// Taints holds the NoSchedule and NoExecute taints: only pods tolerating them are
+	// placed on the node. The taint the node controller adds to cordoned nodes is left
+	// out, see Unschedulable.
+	Taints []corev1.Taint
+
+	// Pods holds the running pods on the node; their usage is only set when FetchNodes
+	// is called withPodMetrics.
 	Pods []PodInfo
 }
 
+// HugePagesInfo is the allocatable and requested amount of one hugepage size on a node, in MiB.
+type HugePagesInfo struct {
+	Allocatable float64
+	Requested   float64
+}
+
+// HeadroomCPU returns allocatable minus requested CPU in millicores, floored at 0:
+// the largest CPU request a new pod could have and still be scheduled on this node.
+func (n NodeInfo) HeadroomCPU() int64 {
+	return max(n.AllocatableCPU-n.RequestedCPU, 0)
+}
+
+// HeadroomMem returns allocatable minus requested memory in MiB, floored at 0.
+func (n NodeInfo) HeadroomMem() float64 {
+	return max(n.AllocatableMem-n.RequestedMem, 0)
+}
+
+// PodSlots returns how many pods fit on the node: its allocatable pods, or the addresses
+// of its pod CIDR plus its host-network pods when that is fewer. 0 when neither is known.
+func (n NodeInfo) PodSlots() int64 {
+	if n.PodCIDRAddresses == 0 {
+		return n.AllocatablePods
+	}
+	cidrSlots := n.PodCIDRAddresses + int64(n.HostNetworkPods)
+	if n.AllocatablePods == 0 || cidrSlots < n.AllocatablePods {
+		return cidrSlots
+	}
+	return n.AllocatablePods
+}
+
+// ToleratedBy reports whether a pod with these tolerations may be placed on the node:
+// every NoSchedule and NoExecute taint has to be tolerated. Cordoning is not considered.
+func (n NodeInfo) ToleratedBy(tolerations []corev1.Toleration) bool {
+	return ToleratesTaints(n.Taints, tolerations)
+}
+
+// ToleratesTaints reports whether tolerations tolerate all of taints.
+func ToleratesTaints(taints []corev1.Taint, tolerations []corev1.Toleration) bool {
+	for _, taint := range taints {
+		if !slices.ContainsFunc(tolerations, func(t corev1.Toleration) bool { return t.ToleratesTaint(klog.Background(), &taint, false) }) {
+			return false
+		}
+	}
+	return true
+}
+
 // PodInfo holds per-pod resource data.
 type PodInfo struct {
 	Namespace string
 	Name      string
 	NodeName  string
+	CreatedAt time.Time
+
+	// Standalone is set when the pod has no controller owner reference
+	// (e.g. a debug pod created with `kubectl run`).
+	Standalone bool
+
+	QOSClass string // Guaranteed, Burstable or BestEffort
+	Labels   map[string]string
+
+	// Exception is declared by the kusa.io annotations on the pod (from its template).
+	Exception Exception
 
-	CPURequest int64   // millicores
+	// Placement constraints: which nodes the pod (or a replacement) may run on. Tolerations
+	// decide over tainted nodes, NodeSelector and Affinity over node labels and, through
+	// pod anti-affinity, over the pods already running there.
+	Tolerations  []corev1.Toleration
+	NodeSelector map[string]string
+	Affinity     *corev1.Affinity
+
+	// Mirror is set for the API mirror of a static pod, which the kubelet runs from a manifest
+	// on the node: its requests can only be changed there, and draining leaves it in place.
+	Mirror bool
+
+	// Owning workload controller, resolved like FetchWorkloads does (Kind "Pod" for standalone
+	// pods, "StaticPod" for mirror pods).
+	// Deployments are only resolved when ReplicaSets were fetched; otherwise Kind is "ReplicaSet".
+	WorkloadKind string
+	WorkloadName string
+
+	CPURequest int64   // millicores, including RuntimeClass overhead
 	CPULimit   int64   // millicores (0 = not set)
-	MemRequest float64 // MiB
+	MemRequest float64 // MiB, including RuntimeClass overhead
 	MemLimit   float64 // MiB (0 = not set)
 
+	EphemeralRequest float64 // MiB of ephemeral-storage (0 = not set)
+	EphemeralLimit   float64 // MiB (0 = not set)
+
+	// HugePagesRequest is the requested hugepages per page size in MiB (nil when none).
+	HugePagesRequest map[string]float64
+
+	// Set when at least one container has no CPU / memory limit.
+	MissingCPULimit bool
+	MissingMemLimit bool
+
 	CPUActual        int64
 	MemActual        float64
 	MetricsAvailable bool
+	MetricsTimestamp time.Time
+	MetricsStale     bool // sample older than Clients.MaxMetricsAge
+
+	// Containers breaks requests, limits and usage down per (regular) container.
+	Containers []ContainerInfo
+}
+
+// ContainerInfo holds the resources of one container of a pod. Init and ephemeral
+// containers are not included.
+type ContainerInfo struct {
+	Name       string
+	CPURequest int64   // millicores (0 = not set)
+	CPULimit   int64   // millicores (0 = not set)
+	MemRequest float64 // MiB (0 = not set)
+	MemLimit   float64 // MiB (0 = not set)
+
+	CPUActual        int64
+	MemActual        float64 // working set
+	MetricsAvailable bool
+
+	Restarts int32
+	// LastOOMKilled is when the container was last OOM-killed, as far as its current or
+	// last terminated state tells (zero = not OOM-killed since).
+	LastOOMKilled time.Time
 }
 
 // MillicoresFromQuantity converts a CPU Quantity to millicores.
@@ -63,16 +236,51 @@ func MiBFromQuantity(q resource.Quantity) float64 {
 	return float64(q.Value()) / (1024 * 1024)
 }
 
-// FormatMem formats a MiB value as "512Mi" or "1.5Gi".
+// hugePageSize returns the page size of a hugepages resource ("2Mi" for hugepages-2Mi).
+func hugePageSize(name corev1.ResourceName) (string, bool) {
+	return strings.CutPrefix(string(name), corev1.ResourceHugePagesPrefix)
+}
+
+// AutoDecimals is the SetDecimals value that restores the default formatting.
+const AutoDecimals = -1
+
+var decimals = AutoDecimals
+
+// SetDecimals fixes the decimals FormatCPU shows cores and FormatMem shows GiB with, whole
+// values included, for reports that line up. AutoDecimals restores the default: two for
+// cores, one for GiB, none for whole values. Millicores and MiB are always whole.
+func SetDecimals(n int) { decimals = n }
+
+// Memory units of FormatMem: powers of 1024 as in Kubernetes quantities (UnitsBinary,
+// "512Mi"), or of 1000 as in cloud billing (UnitsDecimal, "536MB").
+const (
+	UnitsBinary  = "binary"
+	UnitsDecimal = "decimal"
+)
+
+var units = UnitsBinary
+
+// SetUnits selects the memory units of FormatMem, UnitsBinary or UnitsDecimal.
+func SetUnits(u string) { units = u }
+
+// FormatMem formats a MiB value as "512Mi" or "1.5Gi", or with UnitsDecimal as "536MB" or
+// "1.6GB". Values below one Gi or GB are rounded down to whole Mi or MB.
 func FormatMem(mib float64) string {
-	if mib >= 1024 {
-		gib := mib / 1024
-		if gib == float64(int64(gib)) {
-			return fmt.Sprintf("%dGi", int64(gib))
+	small, large, suffix := mib, mib/1024, [2]string{"Mi", "Gi"}
+	if units == UnitsDecimal {
+		bytes := mib * 1024 * 1024
+		small, large, suffix = bytes/1e6, bytes/1e9, [2]string{"MB", "GB"}
+	}
+	if large >= 1 {
+		if decimals != AutoDecimals {
+			return strconv.FormatFloat(large, 'f', decimals, 64) + suffix[1]
 		}
-		return fmt.Sprintf("%.1fGi", gib)
+		if large == float64(int64(large)) {
+			return fmt.Sprintf("%d%s", int64(large), suffix[1])
+		}
+		return fmt.Sprintf("%.1f%s", large, suffix[1])
 	}
-	return fmt.Sprintf("%dMi", int64(mib))
+	return fmt.Sprintf("%d%s", int64(small), suffix[0])
 }
 
 // FormatCPU formats millicores as "250m" or "1.5" (cores) when >= 1000m.
@@ -84,6 +292,9 @@ func FormatCPU(millicores int64) string {
 		return fmt.Sprintf("%dm", millicores)
 	}
 	cores := float64(millicores) / 1000
+	if decimals != AutoDecimals {
+		return strconv.FormatFloat(cores, 'f', decimals, 64)
+	}
 	if float64(int64(cores)) == cores {
 		return fmt.Sprintf("%d", int64(cores))
 	}
@@ -91,14 +302,23 @@ func FormatCPU(millicores int64) string {
 }
 
 // FormatFactor returns the over-request factor string: "42x", "N/A" (actual=0), or "no req" (req=0).
-func FormatFactor(req, actual int64) string {
+// req and actual are millicores or MiB; the factor is rounded down.
+func FormatFactor[T int64 | float64](req, actual T) string {
 	if req == 0 {
 		return "no req"
 	}
 	if actual == 0 {
 		return "N/A"
 	}
-	return fmt.Sprintf("%dx", req/actual)
+	return fmt.Sprintf("%dx", int64(req/actual))
+}
+
+// Plural returns one when n is 1 and many otherwise.
+func Plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
 }
 
 // FetchNodesResult holds the result of FetchNodes.
@@ -115,6 +335,7 @@ func FetchNodes(ctx context.Context, clients *Clients, withPodMetrics bool) (*Fe
 		pods        *corev1.PodList
 		nodeMetrics *metricsv1beta1.NodeMetricsList
 		podMetrics  *metricsv1beta1.PodMetricsList
+		replicaSets *appsv1.ReplicaSetList
 
 		nodeMetricsAvail = true
 		podMetricsAvail  = true
@@ -123,46 +344,78 @@ func FetchNodes(ctx context.Context, clients *Clients, withPodMetrics bool) (*Fe
 	g, gctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		var err error
-		nodes, err = clients.Core.CoreV1().Nodes().List(gctx, metav1.ListOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to list nodes: %w", err)
-		}
-		return nil
+		return clients.track(gctx, "list nodes", func(ctx context.Context) (int, error) {
+			var err error
+			nodes, err = clients.Core.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list nodes: %w", err)
+			}
+			return len(nodes.Items), nil
+		})
 	})
 
 	g.Go(func() error {
-		var err error
-		pods, err = clients.Core.CoreV1().Pods("").List(gctx, metav1.ListOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to list pods: %w", err)
-		}
-		return nil
+		return clients.track(gctx, "list pods", func(ctx context.Context) (int, error) {
+			var err error
+			pods, err = clients.Core.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list pods: %w", err)
+			}
+			return len(pods.Items), nil
+		})
 	})
 
 	g.Go(func() error {
-		var err error
-		nodeMetrics, err = clients.Metrics.MetricsV1beta1().NodeMetricses().List(gctx, metav1.ListOptions{})
+		err := clients.track(gctx, "list node metrics", func(ctx context.Context) (int, error) {
+			var err error
+			nodeMetrics, err = clients.listNodeMetrics(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return len(nodeMetrics.Items), nil
+		})
 		if err != nil {
-			fmt.Printf("Warning: failed to get node metrics (metrics-server may not be installed): %v\n", err)
 			nodeMetricsAvail = false
+			return clients.metricsUnavailable("node metrics", err)
 		}
 		return nil
 	})
 
 	if withPodMetrics {
 		g.Go(func() error {
-			var err error
-			podMetrics, err = clients.Metrics.MetricsV1beta1().PodMetricses("").List(gctx, metav1.ListOptions{})
+			err := clients.track(gctx, "list pod metrics", func(ctx context.Context) (int, error) {
+				var err error
+				podMetrics, err = clients.listPodMetrics(ctx, "")
+				if err != nil {
+					return 0, err
+				}
+				return len(podMetrics.Items), nil
+			})
 			if err != nil {
-				fmt.Printf("Warning: failed to get pod metrics: %v\n", err)
 				podMetricsAvail = false
+				return clients.metricsUnavailable("pod metrics", err)
 			}
 			return nil
 		})
+
+		// Per-pod detail includes the owning workload, which needs ReplicaSet → Deployment resolution.
+		g.Go(func() error {
+			return clients.track(gctx, "list replicasets", func(ctx context.Context) (int, error) {
+				var err error
+				replicaSets, err = clients.Core.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
+				if err != nil {
+					return 0, fmt.Errorf("failed to list replicasets: %w", err)
+				}
+				return len(replicaSets.Items), nil
+			})
+		})
 	}
 
 	if err := g.Wait(); err != nil {
+		if apierrors.IsForbidden(err) {
+			return nil, fmt.Errorf("%w; node views need cluster-wide read access to nodes and pods, "+
+				"while pods, deployments, idle, recommend, oom, orphans and lint also work within a namespace", err)
+		}
 		return nil, err
 	}
 
@@ -182,13 +435,29 @@ func FetchNodes(ctx context.Context, clients *Clients, withPodMetrics bool) (*Fe
 		}
 	}
 
-	// Group running pods by node
+	var rsToDeployment map[string]ownerKey
+	if replicaSets != nil {
+		rsToDeployment = buildRSToDeployment(replicaSets)
+	}
+
+	// Group running pods by node; pending ones bound to a node take a pod slot too.
 	podsByNode := make(map[string][]corev1.Pod)
+	scheduledByNode := make(map[string]int)
+	hostNetworkByNode := make(map[string]int)
+	podIPsByNode := make(map[string][]string)
 	for _, pod := range pods.Items {
-		if pod.Status.Phase != corev1.PodRunning {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
 			continue
 		}
-		if pod.Spec.NodeName != "" {
+		scheduledByNode[pod.Spec.NodeName]++
+		if pod.Spec.HostNetwork {
+			hostNetworkByNode[pod.Spec.NodeName]++
+		} else {
+			for _, ip := range pod.Status.PodIPs {
+				podIPsByNode[pod.Spec.NodeName] = append(podIPsByNode[pod.Spec.NodeName], ip.IP)
+			}
+		}
+		if pod.Status.Phase == corev1.PodRunning {
 			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
 		}
 	}
@@ -198,85 +467,242 @@ func FetchNodes(ctx context.Context, clients *Clients, withPodMetrics bool) (*Fe
 		PodMetricsAvailable:  withPodMetrics && podMetricsAvail,
 	}
 
+	nodeStaleness, podStaleness := clients.newStaleness(), clients.newStaleness()
 	for _, node := range nodes.Items {
 		ni := NodeInfo{
 			Name:           node.Name,
 			AllocatableCPU: MillicoresFromQuantity(node.Status.Allocatable[corev1.ResourceCPU]),
 			AllocatableMem: MiBFromQuantity(node.Status.Allocatable[corev1.ResourceMemory]),
+			CapacityCPU:    MillicoresFromQuantity(node.Status.Capacity[corev1.ResourceCPU]),
+			CapacityMem:    MiBFromQuantity(node.Status.Capacity[corev1.ResourceMemory]),
+			Labels:         node.Labels,
+			Unschedulable:  node.Spec.Unschedulable,
+			OS:             cmp.Or(node.Labels[corev1.LabelOSStable], node.Status.NodeInfo.OperatingSystem),
+			Arch:           cmp.Or(node.Labels[corev1.LabelArchStable], node.Status.NodeInfo.Architecture),
+
+			AllocatableEphemeral: MiBFromQuantity(node.Status.Allocatable[corev1.ResourceEphemeralStorage]),
+			AllocatablePods:      node.Status.Allocatable.Pods().Value(),
+			PodCIDRAddresses:     podCIDRAddresses(node.Spec.PodCIDR, podIPsByNode[node.Name]),
+			ScheduledPods:        scheduledByNode[node.Name],
+			HostNetworkPods:      hostNetworkByNode[node.Name],
+		}
+		for name, q := range node.Status.Allocatable {
+			if size, ok := hugePageSize(name); ok && !q.IsZero() {
+				if ni.HugePages == nil {
+					ni.HugePages = make(map[string]HugePagesInfo)
+				}
+				ni.HugePages[size] = HugePagesInfo{Allocatable: MiBFromQuantity(q)}
+			}
+		}
+
+		for _, t := range node.Spec.Taints {
+			if (t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute) && t.Key != corev1.TaintNodeUnschedulable {
+				ni.Taints = append(ni.Taints, t)
+			}
+		}
+
+		for _, cond := range node.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch cond.Type {
+			case corev1.NodeMemoryPressure:
+				ni.MemoryPressure = true
+			case corev1.NodeDiskPressure:
+				ni.DiskPressure = true
+			}
 		}
 
 		if m, ok := nodeMetricsMap[node.Name]; ok {
 			ni.ActualCPU = MillicoresFromQuantity(m.Usage[corev1.ResourceCPU])
 			ni.ActualMem = MiBFromQuantity(m.Usage[corev1.ResourceMemory])
 			ni.MetricsAvailable = true
+			ni.MetricsTimestamp = m.Timestamp.Time
+			ni.MetricsWindow = m.Window.Duration
+			ni.MetricsStale = nodeStaleness.check(m.Timestamp.Time)
 		}
 
 		for _, pod := range podsByNode[node.Name] {
-			pi := podInfoFromPod(pod)
+			pi := podInfoFromPod(pod, rsToDeployment)
 
 			if withPodMetrics {
 				key := pod.Namespace + "/" + pod.Name
 				if pm, ok := podMetricsMap[key]; ok {
-					pi.MetricsAvailable = true
-					for _, c := range pm.Containers {
-						pi.CPUActual += MillicoresFromQuantity(c.Usage[corev1.ResourceCPU])
-						pi.MemActual += MiBFromQuantity(c.Usage[corev1.ResourceMemory])
-					}
+					pi.applyMetrics(pm, podStaleness.check(pm.Timestamp.Time))
 				}
 			}
 
 			// Always include all pods (including system) in node totals
 			ni.RequestedCPU += pi.CPURequest
 			ni.RequestedMem += pi.MemRequest
+			ni.LimitCPU += pi.CPULimit
+			ni.LimitMem += pi.MemLimit
+			ni.RequestedEphemeral += pi.EphemeralRequest
+			ni.LimitEphemeral += pi.EphemeralLimit
+			for size, mib := range pi.HugePagesRequest {
+				if ni.HugePages == nil {
+					ni.HugePages = make(map[string]HugePagesInfo)
+				}
+				hp := ni.HugePages[size]
+				hp.Requested += mib
+				ni.HugePages[size] = hp
+			}
 			ni.Pods = append(ni.Pods, pi)
 		}
 
 		result.Nodes = append(result.Nodes, ni)
 	}
+	nodeStaleness.warn("node")
+	podStaleness.warn("pod")
 
 	return result, nil
 }
 
+// podCIDRAddresses returns the number of pod IPs an IPv4 pod CIDR leaves after the network
+// address and the gateway; 0 for IPv6 or an unset or invalid CIDR. The CIDR only caps pods
+// when the CNI allocates from it, as with host-local IPAM, while others (AWS VPC CNI, Azure
+// CNI, Calico or Cilium pools) ignore it: podIPs, those of the node's pods off the host
+// network, have to include an IPv4 address, all within the CIDR, or it returns 0.
+func podCIDRAddresses(cidr string, podIPs []string) int64 {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil || network.IP.To4() == nil {
+		return 0
+	}
+	allocated := false
+	for _, s := range podIPs {
+		ip := net.ParseIP(s)
+		if ip == nil || ip.To4() == nil {
+			continue
+		}
+		if !network.Contains(ip) {
+			return 0
+		}
+		allocated = true
+	}
+	if !allocated {
+		return 0
+	}
+	ones, bits := network.Mask.Size()
+	return max(int64(1)<<(bits-ones)-2, 0)
+}
+
+// ListNodeNames returns the sorted names of all nodes in the cluster.
+func ListNodeNames(ctx context.Context, clients *Clients) ([]string, error) {
+	var list *corev1.NodeList
+	err := clients.track(ctx, "list nodes", func(ctx context.Context) (int, error) {
+		var err error
+		list, err = clients.Core.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list nodes: %w", err)
+		}
+		return len(list.Items), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, n := range list.Items {
+		names = append(names, n.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 // FetchPodsResult holds the result of FetchPods.
 type FetchPodsResult struct {
 	Pods             []PodInfo
 	MetricsAvailable bool
+	Skipped          []string // namespaces left out of a per-namespace fetch, see fetchEachNamespace
 }
 
 // FetchPods fetches running pods and their metrics concurrently.
 // When namespace is non-empty only that namespace is queried; pass "" for cluster-wide.
+// A cluster-wide fetch the user may not make is retried per namespace, see
+// fetchEachNamespace.
 func FetchPods(ctx context.Context, clients *Clients, namespace string) (*FetchPodsResult, error) {
+	return FetchNodePods(ctx, clients, namespace, "")
+}
+
+// FetchNodePods is FetchPods restricted to the pods scheduled on node, selected by the API
+// server through a spec.nodeName field selector; "" means all nodes.
+func FetchNodePods(ctx context.Context, clients *Clients, namespace, node string) (*FetchPodsResult, error) {
+	result, err := fetchPods(ctx, clients, namespace, node)
+	if namespace != "" || !apierrors.IsForbidden(err) {
+		return result, err
+	}
+	result, skipped, err := fetchEachNamespace(ctx, clients, err, true,
+		func(ctx context.Context, ns string) (*FetchPodsResult, error) {
+			return fetchPods(ctx, clients, ns, node)
+		},
+		func(into, from *FetchPodsResult) {
+			into.Pods = append(into.Pods, from.Pods...)
+			into.MetricsAvailable = into.MetricsAvailable && from.MetricsAvailable
+		})
+	if result != nil {
+		result.Skipped = skipped
+	}
+	return result, err
+}
+
+func fetchPods(ctx context.Context, clients *Clients, namespace, node string) (*FetchPodsResult, error) {
 	var (
 		pods         *corev1.PodList
 		podMetrics   *metricsv1beta1.PodMetricsList
+		replicaSets  *appsv1.ReplicaSetList
 		metricsAvail = true
 	)
 
 	g, gctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		var err error
-		pods, err = clients.Core.CoreV1().Pods(namespace).List(gctx, metav1.ListOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to list pods: %w", err)
-		}
-		return nil
+		return clients.track(gctx, "list pods", func(ctx context.Context) (int, error) {
+			var err error
+			opts := metav1.ListOptions{}
+			if node != "" {
+				opts.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", node).String()
+			}
+			pods, err = clients.Core.CoreV1().Pods(namespace).List(ctx, opts)
+			if err != nil {
+				return 0, fmt.Errorf("failed to list pods: %w", err)
+			}
+			return len(pods.Items), nil
+		})
 	})
 
 	g.Go(func() error {
-		var err error
-		podMetrics, err = clients.Metrics.MetricsV1beta1().PodMetricses(namespace).List(gctx, metav1.ListOptions{})
+		err := clients.track(gctx, "list pod metrics", func(ctx context.Context) (int, error) {
+			var err error
+			podMetrics, err = clients.listPodMetrics(ctx, namespace)
+			if err != nil {
+				return 0, err
+			}
+			return len(podMetrics.Items), nil
+		})
 		if err != nil {
-			fmt.Printf("Warning: failed to get pod metrics (metrics-server may not be installed): %v\n", err)
 			metricsAvail = false
+			return clients.metricsUnavailable("pod metrics", err)
 		}
 		return nil
 	})
 
+	g.Go(func() error {
+		return clients.track(gctx, "list replicasets", func(ctx context.Context) (int, error) {
+			var err error
+			replicaSets, err = clients.Core.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list replicasets: %w", err)
+			}
+			return len(replicaSets.Items), nil
+		})
+	})
+
 	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 
+	rsToDeployment := buildRSToDeployment(replicaSets)
+
 	podMetricsMap := make(map[string]metricsv1beta1.PodMetrics)
 	if podMetrics != nil {
 		for _, m := range podMetrics.Items {
@@ -285,48 +711,124 @@ func FetchPods(ctx context.Context, clients *Clients, namespace string) (*FetchP
 	}
 
 	result := &FetchPodsResult{MetricsAvailable: metricsAvail}
+	stale := clients.newStaleness()
 
 	for _, pod := range pods.Items {
-		if pod.Status.Phase != corev1.PodRunning {
+		// The fake clients behind --demo and snapshots ignore field selectors.
+		if pod.Status.Phase != corev1.PodRunning || (node != "" && pod.Spec.NodeName != node) {
 			continue
 		}
 
-		pi := podInfoFromPod(pod)
+		pi := podInfoFromPod(pod, rsToDeployment)
 
 		key := pod.Namespace + "/" + pod.Name
 		if pm, ok := podMetricsMap[key]; ok {
-			pi.MetricsAvailable = true
-			for _, c := range pm.Containers {
-				pi.CPUActual += MillicoresFromQuantity(c.Usage[corev1.ResourceCPU])
-				pi.MemActual += MiBFromQuantity(c.Usage[corev1.ResourceMemory])
-			}
+			pi.applyMetrics(pm, stale.check(pm.Timestamp.Time))
 		}
 
 		result.Pods = append(result.Pods, pi)
 	}
+	stale.warn("pod")
 
 	return result, nil
 }
 
-func podInfoFromPod(pod corev1.Pod) PodInfo {
+// applyMetrics sets the pod's and its containers' usage from a metrics sample.
+func (pi *PodInfo) applyMetrics(pm metricsv1beta1.PodMetrics, stale bool) {
+	pi.MetricsAvailable = true
+	pi.MetricsTimestamp = pm.Timestamp.Time
+	pi.MetricsStale = stale
+	for _, c := range pm.Containers {
+		cpu := MillicoresFromQuantity(c.Usage[corev1.ResourceCPU])
+		mem := MiBFromQuantity(c.Usage[corev1.ResourceMemory])
+		pi.CPUActual += cpu
+		pi.MemActual += mem
+		for i := range pi.Containers {
+			if pi.Containers[i].Name == c.Name {
+				pi.Containers[i].CPUActual, pi.Containers[i].MemActual = cpu, mem
+				pi.Containers[i].MetricsAvailable = true
+			}
+		}
+	}
+}
+
+// lastOOMKilled returns when the container was last OOM-killed according to its current
+// and last terminated state, or the zero time.
+func lastOOMKilled(st corev1.ContainerStatus) time.Time {
+	for _, t := range []*corev1.ContainerStateTerminated{st.State.Terminated, st.LastTerminationState.Terminated} {
+		if t != nil && t.Reason == "OOMKilled" {
+			return t.FinishedAt.Time
+		}
+	}
+	return time.Time{}
+}
+
+// containerResources returns the CPU and memory requests and limits of a container.
+func containerResources(c corev1.Container) ContainerInfo {
+	return ContainerInfo{
+		Name:       c.Name,
+		CPURequest: MillicoresFromQuantity(c.Resources.Requests[corev1.ResourceCPU]),
+		CPULimit:   MillicoresFromQuantity(c.Resources.Limits[corev1.ResourceCPU]),
+		MemRequest: MiBFromQuantity(c.Resources.Requests[corev1.ResourceMemory]),
+		MemLimit:   MiBFromQuantity(c.Resources.Limits[corev1.ResourceMemory]),
+	}
+}
+
+// podInfoFromPod builds a PodInfo from the pod spec. rsToDeployment may be nil, in which
+// case Deployment-owned pods report their ReplicaSet as workload.
+func podInfoFromPod(pod corev1.Pod, rsToDeployment map[string]ownerKey) PodInfo {
+	owner := resolveWorkloadOwner(pod, rsToDeployment)
+
 	pi := PodInfo{
-		Namespace: pod.Namespace,
-		Name:      pod.Name,
-		NodeName:  pod.Spec.NodeName,
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		NodeName:   pod.Spec.NodeName,
+		CreatedAt:  pod.CreationTimestamp.Time,
+		Standalone: metav1.GetControllerOf(&pod) == nil,
+		QOSClass:   string(pod.Status.QOSClass),
+		Labels:     pod.Labels,
+
+		Tolerations:  pod.Spec.Tolerations,
+		NodeSelector: pod.Spec.NodeSelector,
+		Affinity:     pod.Spec.Affinity,
+
+		WorkloadKind: owner.Kind,
+		WorkloadName: owner.Name,
 	}
+	_, pi.Mirror = pod.Annotations[corev1.MirrorPodAnnotationKey]
+	pi.Exception = exceptionFrom(pod.Annotations, pod.Namespace+"/"+pod.Name)
 	for _, c := range pod.Spec.Containers {
-		if q := c.Resources.Requests[corev1.ResourceCPU]; !q.IsZero() {
-			pi.CPURequest += MillicoresFromQuantity(q)
-		}
-		if q := c.Resources.Limits[corev1.ResourceCPU]; !q.IsZero() {
-			pi.CPULimit += MillicoresFromQuantity(q)
-		}
-		if q := c.Resources.Requests[corev1.ResourceMemory]; !q.IsZero() {
-			pi.MemRequest += MiBFromQuantity(q)
+		ci := containerResources(c)
+		for _, st := range pod.Status.ContainerStatuses {
+			if st.Name == c.Name {
+				ci.Restarts = st.RestartCount
+				ci.LastOOMKilled = lastOOMKilled(st)
+			}
 		}
-		if q := c.Resources.Limits[corev1.ResourceMemory]; !q.IsZero() {
-			pi.MemLimit += MiBFromQuantity(q)
+		pi.Containers = append(pi.Containers, ci)
+		pi.CPURequest += ci.CPURequest
+		pi.CPULimit += ci.CPULimit
+		pi.MemRequest += ci.MemRequest
+		pi.MemLimit += ci.MemLimit
+		pi.EphemeralRequest += MiBFromQuantity(c.Resources.Requests[corev1.ResourceEphemeralStorage])
+		pi.EphemeralLimit += MiBFromQuantity(c.Resources.Limits[corev1.ResourceEphemeralStorage])
+		// Hugepages requests must equal limits; the API server defaults a missing request to the limit.
+		for name, q := range c.Resources.Requests {
+			if size, ok := hugePageSize(name); ok && !q.IsZero() {
+				if pi.HugePagesRequest == nil {
+					pi.HugePagesRequest = make(map[string]float64)
+				}
+				pi.HugePagesRequest[size] += MiBFromQuantity(q)
+			}
 		}
+		missingCPU, missingMem := missingLimits(c)
+		pi.MissingCPULimit = pi.MissingCPULimit || missingCPU
+		pi.MissingMemLimit = pi.MissingMemLimit || missingMem
 	}
+
+	// A RuntimeClass overhead (kata, gVisor) is set on the pod at admission and counted
+	// by the scheduler on top of the container requests.
+	pi.CPURequest += MillicoresFromQuantity(pod.Spec.Overhead[corev1.ResourceCPU])
+	pi.MemRequest += MiBFromQuantity(pod.Spec.Overhead[corev1.ResourceMemory])
 	return pi
 }
@@ -3,6 +3,7 @@ package kube
 import (
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -69,6 +70,28 @@ func TestFormatMem(t *testing.T) {
 	}
 }
 
+func TestFormatMemDecimalUnits(t *testing.T) {
+	SetUnits(UnitsDecimal)
+	defer SetUnits(UnitsBinary)
+	tests := []struct {
+		mib  float64
+		want string
+	}{
+		{0, "0MB"},
+		{512, "536MB"},
+		{953.67431640625, "1GB"}, // exactly 10^9 bytes
+		{1024, "1.1GB"},
+		{32 * 1024, "34.4GB"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			if got := FormatMem(tc.mib); got != tc.want {
+				t.Errorf("FormatMem(%g) = %q, want %q", tc.mib, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestFormatCPU(t *testing.T) {
 	tests := []struct {
 		millicores int64
@@ -91,6 +114,30 @@ func TestFormatCPU(t *testing.T) {
 	}
 }
 
+func TestFormatDecimals(t *testing.T) {
+	defer SetDecimals(AutoDecimals)
+	tests := []struct {
+		decimals         int
+		millicores       int64
+		mib              float64
+		wantCPU, wantMem string
+	}{
+		{1, 2000, 2048, "2.0", "2.0Gi"},
+		{1, 1250, 1536, "1.2", "1.5Gi"},
+		{0, 1500, 1536, "2", "2Gi"},
+		{2, 250, 512, "250m", "512Mi"}, // millicores and MiB stay whole
+	}
+	for _, tc := range tests {
+		SetDecimals(tc.decimals)
+		if got := FormatCPU(tc.millicores); got != tc.wantCPU {
+			t.Errorf("decimals %d: FormatCPU(%d) = %q, want %q", tc.decimals, tc.millicores, got, tc.wantCPU)
+		}
+		if got := FormatMem(tc.mib); got != tc.wantMem {
+			t.Errorf("decimals %d: FormatMem(%g) = %q, want %q", tc.decimals, tc.mib, got, tc.wantMem)
+		}
+	}
+}
+
 func TestFormatFactor(t *testing.T) {
 	tests := []struct {
 		req, actual int64
@@ -110,4 +157,68 @@ func TestFormatFactor(t *testing.T) {
 			}
 		})
 	}
+
+	// Memory factors are of MiB values.
+	for _, tc := range []struct {
+		req, actual float64
+		want        string
+	}{
+		{0, 100, "no req"},
+		{512, 0, "N/A"},
+		{1024, 100.5, "10x"},
+		{256, 512, "0x"},
+	} {
+		if got := FormatFactor(tc.req, tc.actual); got != tc.want {
+			t.Errorf("FormatFactor(%g, %g) = %q, want %q", tc.req, tc.actual, got, tc.want)
+		}
+	}
+}
+
+func TestNodeHeadroom(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    NodeInfo
+		wantCPU int64
+		wantMem float64
+	}{
+		{"empty node", NodeInfo{AllocatableCPU: 4000, AllocatableMem: 8192}, 4000, 8192},
+		{"partially requested", NodeInfo{AllocatableCPU: 4000, AllocatableMem: 8192, RequestedCPU: 3500, RequestedMem: 2048}, 500, 6144},
+		{"over-requested floors at zero", NodeInfo{AllocatableCPU: 1000, AllocatableMem: 1024, RequestedCPU: 1200, RequestedMem: 2048}, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.node.HeadroomCPU(); got != tc.wantCPU {
+				t.Errorf("HeadroomCPU() = %d, want %d", got, tc.wantCPU)
+			}
+			if got := tc.node.HeadroomMem(); got != tc.wantMem {
+				t.Errorf("HeadroomMem() = %g, want %g", got, tc.wantMem)
+			}
+		})
+	}
+}
+
+func TestToleratesTaints(t *testing.T) {
+	gpu := corev1.Taint{Key: "nvidia.com/gpu", Effect: corev1.TaintEffectNoSchedule}
+	infra := corev1.Taint{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoExecute}
+
+	tests := []struct {
+		name        string
+		taints      []corev1.Taint
+		tolerations []corev1.Toleration
+		want        bool
+	}{
+		{"untainted node", nil, nil, true},
+		{"no toleration", []corev1.Taint{gpu}, nil, false},
+		{"exists toleration", []corev1.Taint{gpu}, []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}}, true},
+		{"value mismatch", []corev1.Taint{infra}, []corev1.Toleration{{Key: "dedicated", Value: "batch", Operator: corev1.TolerationOpEqual}}, false},
+		{"only one of two taints tolerated", []corev1.Taint{gpu, infra}, []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}}, false},
+		{"tolerate everything", []corev1.Taint{gpu, infra}, []corev1.Toleration{{Operator: corev1.TolerationOpExists}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ToleratesTaints(tc.taints, tc.tolerations); got != tc.want {
+				t.Errorf("ToleratesTaints() = %v, want %v", got, tc.want)
+			}
+		})
+	}
 }
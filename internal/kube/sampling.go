@@ -0,0 +1,160 @@
+package kube
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// Sampling controls how often the metrics API is polled within one run (see --samples).
+// A single metrics-server scrape is an instantaneous value; several samples smooth it.
+type Sampling struct {
+	Samples  int           // number of polls; 0 or 1 means a single scrape
+	Interval time.Duration // wait between polls
+	Peak     bool          // aggregate to the maximum instead of the mean
+}
+
+// usageSampler aggregates ResourceLists of the same objects across samples.
+type usageSampler struct {
+	peak  bool
+	sums  map[string]corev1.ResourceList
+	seen  map[string]int
+	order []string
+}
+
+func newUsageSampler(peak bool) *usageSampler {
+	return &usageSampler{peak: peak, sums: make(map[string]corev1.ResourceList), seen: make(map[string]int)}
+}
+
+func (s *usageSampler) add(key string, usage corev1.ResourceList) {
+	acc, ok := s.sums[key]
+	if !ok {
+		acc = corev1.ResourceList{}
+		s.sums[key] = acc
+		s.order = append(s.order, key)
+	}
+	s.seen[key]++
+	for name, q := range usage {
+		cur, ok := acc[name]
+		switch {
+		case !ok:
+			acc[name] = q.DeepCopy()
+		case s.peak:
+			if q.Cmp(cur) > 0 {
+				acc[name] = q.DeepCopy()
+			}
+		default:
+			cur.Add(q)
+			acc[name] = cur
+		}
+	}
+}
+
+// usage returns the aggregated usage of key: the peak, or the mean over the samples that
+// contained it (objects may appear or disappear between samples).
+func (s *usageSampler) usage(key string) corev1.ResourceList {
+	acc := s.sums[key]
+	if s.peak || s.seen[key] <= 1 {
+		return acc
+	}
+	out := corev1.ResourceList{}
+	n := int64(s.seen[key])
+	for name, q := range acc {
+		if name == corev1.ResourceCPU {
+			out[name] = *resource.NewMilliQuantity(q.MilliValue()/n, q.Format)
+		} else {
+			out[name] = *resource.NewQuantity(q.Value()/n, q.Format)
+		}
+	}
+	return out
+}
+
+// pollMetrics calls list Samples times, Interval apart, stopping early when ctx is done.
+func (c *Clients) pollMetrics(ctx context.Context, list func(ctx context.Context) error) error {
+	samples := max(c.Sampling.Samples, 1)
+	for i := range samples {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.Sampling.Interval):
+			}
+		}
+		if err := list(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listNodeMetrics lists node metrics, aggregated over the configured samples.
+func (c *Clients) listNodeMetrics(ctx context.Context) (*metricsv1beta1.NodeMetricsList, error) {
+	sampler := newUsageSampler(c.Sampling.Peak)
+	latest := make(map[string]metricsv1beta1.NodeMetrics)
+	err := c.pollMetrics(ctx, func(ctx context.Context) error {
+		list, err := c.Metrics.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, m := range list.Items {
+			sampler.add(m.Name, m.Usage)
+			latest[m.Name] = m
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &metricsv1beta1.NodeMetricsList{}
+	for _, name := range sampler.order {
+		m := latest[name]
+		m.Usage = sampler.usage(name)
+		result.Items = append(result.Items, m)
+	}
+	return result, nil
+}
+
+// listPodMetrics lists pod metrics in namespace ("" for all), aggregated per container
+// over the configured samples.
+func (c *Clients) listPodMetrics(ctx context.Context, namespace string) (*metricsv1beta1.PodMetricsList, error) {
+	sampler := newUsageSampler(c.Sampling.Peak)
+	latest := make(map[string]metricsv1beta1.PodMetrics)
+	var order []string
+	err := c.pollMetrics(ctx, func(ctx context.Context) error {
+		list, err := c.Metrics.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, m := range list.Items {
+			key := m.Namespace + "/" + m.Name
+			if _, ok := latest[key]; !ok {
+				order = append(order, key)
+			}
+			for _, ctr := range m.Containers {
+				sampler.add(key+"/"+ctr.Name, ctr.Usage)
+			}
+			latest[key] = m
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &metricsv1beta1.PodMetricsList{}
+	for _, key := range order {
+		m := latest[key]
+		containers := make([]metricsv1beta1.ContainerMetrics, len(m.Containers))
+		for i, ctr := range m.Containers {
+			containers[i] = metricsv1beta1.ContainerMetrics{Name: ctr.Name, Usage: sampler.usage(key + "/" + ctr.Name)}
+		}
+		m.Containers = containers
+		result.Items = append(result.Items, m)
+	}
+	return result, nil
+}
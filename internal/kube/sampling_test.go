@@ -0,0 +1,61 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestListPodMetricsSampling(t *testing.T) {
+	// Three scrapes of the same pod with varying usage; "late" only appears in the last one.
+	scrapes := [][]metricsv1beta1.PodMetrics{
+		{fakePodMetrics("shop", "web", "100m", "100Mi")},
+		{fakePodMetrics("shop", "web", "400m", "300Mi")},
+		{fakePodMetrics("shop", "web", "100m", "200Mi"), fakePodMetrics("shop", "late", "50m", "10Mi")},
+	}
+
+	tests := []struct {
+		name     string
+		sampling Sampling
+		wantCPU  int64
+		wantMem  float64
+	}{
+		{"single scrape", Sampling{}, 100, 100},
+		{"average", Sampling{Samples: 3}, 200, 200},
+		{"peak", Sampling{Samples: 3, Peak: true}, 400, 300},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			call := 0
+			metrics := metricsfake.NewSimpleClientset()
+			metrics.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+				list := &metricsv1beta1.PodMetricsList{Items: scrapes[call]}
+				call++
+				return true, list, nil
+			})
+			clients := &Clients{Metrics: metrics, Sampling: tc.sampling}
+
+			list, err := clients.listPodMetrics(context.Background(), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := max(tc.sampling.Samples, 1); call != want {
+				t.Errorf("made %d metrics calls, want %d", call, want)
+			}
+			web := list.Items[0]
+			cpu := MillicoresFromQuantity(web.Containers[0].Usage["cpu"])
+			mem := MiBFromQuantity(web.Containers[0].Usage["memory"])
+			if web.Name != "web" || cpu != tc.wantCPU || mem != tc.wantMem {
+				t.Errorf("%s = %dm/%gMi, want web at %dm/%gMi", web.Name, cpu, mem, tc.wantCPU, tc.wantMem)
+			}
+			if tc.sampling.Samples == 3 && len(list.Items) != 2 {
+				t.Errorf("got %d pods, want pods from every sample", len(list.Items))
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// Snapshot holds everything the fetchers read from a cluster, so reports can be rendered
+// again, with other filters and formats, without access to it. Kubelet data (reserved
+// resources, CPU throttling) is not included, nor what no report reads but may hold
+// secrets, see redact. NodeMetrics and PodMetrics are nil when the metrics API could not
+// be read.
+type Snapshot struct {
+	Context string    `json:"context"`
+	TakenAt time.Time `json:"takenAt"`
+
+	Namespaces   []corev1.Namespace              `json:"namespaces"`
+	Nodes        []corev1.Node                   `json:"nodes"`
+	Pods         []corev1.Pod                    `json:"pods"`
+	ReplicaSets  []appsv1.ReplicaSet             `json:"replicaSets"`
+	Deployments  []appsv1.Deployment             `json:"deployments"`
+	StatefulSets []appsv1.StatefulSet            `json:"statefulSets"`
+	DaemonSets   []appsv1.DaemonSet              `json:"daemonSets"`
+	PDBs         []policyv1.PodDisruptionBudget  `json:"podDisruptionBudgets"`
+	NodeMetrics  *metricsv1beta1.NodeMetricsList `json:"nodeMetrics,omitempty"`
+	PodMetrics   *metricsv1beta1.PodMetricsList  `json:"podMetrics,omitempty"`
+}
+
+// TakeSnapshot lists the objects and metrics of the whole cluster concurrently. Metrics
+// are sampled as configured by Clients.Sampling.
+func TakeSnapshot(ctx context.Context, clients *Clients) (*Snapshot, error) {
+	s := &Snapshot{Context: clients.ContextName, TakenAt: time.Now().UTC()}
+
+	g, gctx := errgroup.WithContext(ctx)
+	list := func(what string, fn func(ctx context.Context) (int, error)) {
+		g.Go(func() error {
+			return clients.track(gctx, "list "+what, func(ctx context.Context) (int, error) {
+				n, err := fn(ctx)
+				if err != nil {
+					return 0, fmt.Errorf("failed to list %s: %w", what, err)
+				}
+				return n, nil
+			})
+		})
+	}
+	list("namespaces", func(ctx context.Context) (int, error) {
+		l, err := clients.Core.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		s.Namespaces = l.Items
+		return len(l.Items), nil
+	})
+	list("nodes", func(ctx context.Context) (int, error) {
+		l, err := clients.Core.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		s.Nodes = l.Items
+		return len(l.Items), nil
+	})
+	list("pods", func(ctx context.Context) (int, error) {
+		l, err := clients.Core.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		s.Pods = l.Items
+		return len(l.Items), nil
+	})
+	list("replicasets", func(ctx context.Context) (int, error) {
+		l, err := clients.Core.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		s.ReplicaSets = l.Items
+		return len(l.Items), nil
+	})
+	list("deployments", func(ctx context.Context) (int, error) {
+		l, err := clients.Core.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		s.Deployments = l.Items
+		return len(l.Items), nil
+	})
+	list("statefulsets", func(ctx context.Context) (int, error) {
+		l, err := clients.Core.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		s.StatefulSets = l.Items
+		return len(l.Items), nil
+	})
+	list("daemonsets", func(ctx context.Context) (int, error) {
+		l, err := clients.Core.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		s.DaemonSets = l.Items
+		return len(l.Items), nil
+	})
+	list("poddisruptionbudgets", func(ctx context.Context) (int, error) {
+		l, err := clients.Core.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		s.PDBs = l.Items
+		return len(l.Items), nil
+	})
+	g.Go(func() error {
+		err := clients.track(gctx, "list node metrics", func(ctx context.Context) (int, error) {
+			var err error
+			s.NodeMetrics, err = clients.listNodeMetrics(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return len(s.NodeMetrics.Items), nil
+		})
+		if err != nil {
+			return clients.metricsUnavailable("node metrics", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		err := clients.track(gctx, "list pod metrics", func(ctx context.Context) (int, error) {
+			var err error
+			s.PodMetrics, err = clients.listPodMetrics(ctx, "")
+			if err != nil {
+				return 0, err
+			}
+			return len(s.PodMetrics.Items), nil
+		})
+		if err != nil {
+			return clients.metricsUnavailable("pod metrics", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	s.redact()
+	return s, nil
+}
+
+// lastAppliedAnnotation holds the whole manifest applied by kubectl apply, env included.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// redact strips what no report reads but may hold secrets or internal details from the
+// objects of s: the command, arguments, environment and volume mounts of every container,
+// pod volumes, managed fields and the last-applied-configuration annotation.
+func (s *Snapshot) redact() {
+	for i := range s.Namespaces {
+		redactMeta(&s.Namespaces[i].ObjectMeta)
+	}
+	for i := range s.Nodes {
+		redactMeta(&s.Nodes[i].ObjectMeta)
+	}
+	for i := range s.Pods {
+		redactMeta(&s.Pods[i].ObjectMeta)
+		redactPodSpec(&s.Pods[i].Spec)
+	}
+	for i := range s.ReplicaSets {
+		redactMeta(&s.ReplicaSets[i].ObjectMeta)
+		redactPodTemplate(&s.ReplicaSets[i].Spec.Template)
+	}
+	for i := range s.Deployments {
+		redactMeta(&s.Deployments[i].ObjectMeta)
+		redactPodTemplate(&s.Deployments[i].Spec.Template)
+	}
+	for i := range s.StatefulSets {
+		redactMeta(&s.StatefulSets[i].ObjectMeta)
+		redactPodTemplate(&s.StatefulSets[i].Spec.Template)
+	}
+	for i := range s.DaemonSets {
+		redactMeta(&s.DaemonSets[i].ObjectMeta)
+		redactPodTemplate(&s.DaemonSets[i].Spec.Template)
+	}
+	for i := range s.PDBs {
+		redactMeta(&s.PDBs[i].ObjectMeta)
+	}
+}
+
+func redactMeta(m *metav1.ObjectMeta) {
+	m.ManagedFields = nil
+	delete(m.Annotations, lastAppliedAnnotation)
+}
+
+func redactPodTemplate(t *corev1.PodTemplateSpec) {
+	redactMeta(&t.ObjectMeta)
+	redactPodSpec(&t.Spec)
+}
+
+func redactPodSpec(spec *corev1.PodSpec) {
+	spec.Volumes = nil
+	redact := func(c *corev1.Container) {
+		c.Command, c.Args, c.Env, c.EnvFrom, c.VolumeMounts = nil, nil, nil, nil, nil
+	}
+	for i := range spec.InitContainers {
+		redact(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		redact(&spec.Containers[i])
+	}
+	for i := range spec.EphemeralContainers {
+		c := &spec.EphemeralContainers[i].EphemeralContainerCommon
+		c.Command, c.Args, c.Env, c.EnvFrom, c.VolumeMounts = nil, nil, nil, nil, nil
+	}
+}
+
+// SaveSnapshot writes s to path as JSON, readable by the owner only: even redacted, it
+// describes the whole cluster.
+func SaveSnapshot(path string, s *Snapshot) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	// An existing file keeps its mode through OpenFile.
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// NewSnapshotClients returns Clients backed by fake clientsets holding s, reporting its
+// context. Metrics staleness is judged against the time s was taken.
+func NewSnapshotClients(s *Snapshot) *Clients {
+	var objects []runtime.Object
+	for i := range s.Namespaces {
+		objects = append(objects, &s.Namespaces[i])
+	}
+	for i := range s.Nodes {
+		objects = append(objects, &s.Nodes[i])
+	}
+	for i := range s.Pods {
+		objects = append(objects, &s.Pods[i])
+	}
+	for i := range s.ReplicaSets {
+		objects = append(objects, &s.ReplicaSets[i])
+	}
+	for i := range s.Deployments {
+		objects = append(objects, &s.Deployments[i])
+	}
+	for i := range s.StatefulSets {
+		objects = append(objects, &s.StatefulSets[i])
+	}
+	for i := range s.DaemonSets {
+		objects = append(objects, &s.DaemonSets[i])
+	}
+	for i := range s.PDBs {
+		objects = append(objects, &s.PDBs[i])
+	}
+
+	errNoMetrics := errors.New("the snapshot holds no metrics")
+	metrics := metricsfake.NewSimpleClientset()
+	metrics.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if s.NodeMetrics == nil {
+			return true, nil, errNoMetrics
+		}
+		return true, s.NodeMetrics, nil
+	})
+	metrics.PrependReactor("list", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		if s.PodMetrics == nil {
+			return true, nil, errNoMetrics
+		}
+		list := &metricsv1beta1.PodMetricsList{}
+		for _, m := range s.PodMetrics.Items {
+			if a.GetNamespace() == "" || m.Namespace == a.GetNamespace() {
+				list.Items = append(list.Items, m)
+			}
+		}
+		return true, list, nil
+	})
+
+	clients := NewClientsFrom(fake.NewClientset(objects...), metrics, s.Context)
+	clients.TakenAt = s.TakenAt
+	return clients
+}
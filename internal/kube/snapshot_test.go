@@ -0,0 +1,128 @@
+package kube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		podMetrics []metricsv1beta1.PodMetrics
+	}{
+		{"with metrics", []metricsv1beta1.PodMetrics{fakePodMetrics("shop", "web", "50m", "100Mi")}},
+		{"without metrics", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := fakeCluster(
+				[]runtime.Object{
+					fakeNode("node-a", "4", "8Gi"),
+					fakePod("shop", "web", "node-a", "", "500m", "512Mi"),
+				},
+				nil,
+				tt.podMetrics,
+			)
+			snapshot, err := TakeSnapshot(context.Background(), clients)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "snapshot.json")
+			if err := SaveSnapshot(path, snapshot); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := LoadSnapshot(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			replay := NewSnapshotClients(loaded)
+			if replay.ContextName != "fake" || !replay.TakenAt.Equal(snapshot.TakenAt) {
+				t.Errorf("replay context %q taken %v, want fake taken %v", replay.ContextName, replay.TakenAt, snapshot.TakenAt)
+			}
+			pods, err := FetchPods(context.Background(), replay, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pods.Pods) != 1 || pods.Pods[0].CPURequest != 500 {
+				t.Fatalf("replayed pods = %+v, want shop/web requesting 500m", pods.Pods)
+			}
+			if want := tt.podMetrics != nil; pods.MetricsAvailable != want {
+				t.Errorf("MetricsAvailable = %v, want %v", pods.MetricsAvailable, want)
+			}
+			if tt.podMetrics != nil && pods.Pods[0].CPUActual != 50 {
+				t.Errorf("replayed CPU usage = %dm, want 50m", pods.Pods[0].CPUActual)
+			}
+			nodes, err := FetchNodes(context.Background(), replay, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(nodes.Nodes) != 1 || nodes.NodeMetricsAvailable {
+				t.Errorf("replayed nodes = %+v (metrics %v), want node-a without metrics", nodes.Nodes, nodes.NodeMetricsAvailable)
+			}
+		})
+	}
+}
+
+func TestSnapshotRedacts(t *testing.T) {
+	pod := fakePod("shop", "web", "node-a", "", "500m", "512Mi")
+	pod.Annotations = map[string]string{lastAppliedAnnotation: `{"spec":{}}`, "kusa.io/ignore": "true"}
+	pod.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+	pod.Spec.Volumes = []corev1.Volume{{Name: "creds", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "db"}}}}
+	pod.Spec.InitContainers = []corev1.Container{{Name: "migrate", Command: []string{"migrate", "--password=hunter2"}}}
+	app := &pod.Spec.Containers[0]
+	app.Args = []string{"--token=abc"}
+	app.Env = []corev1.EnvVar{{Name: "DB_PASSWORD", Value: "hunter2"}}
+	app.EnvFrom = []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}}}}
+	app.VolumeMounts = []corev1.VolumeMount{{Name: "creds", MountPath: "/creds"}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Env: []corev1.EnvVar{{Name: "API_KEY", Value: "abc"}}}},
+		}}},
+	}
+
+	snapshot, err := TakeSnapshot(context.Background(), fakeCluster([]runtime.Object{pod, deployment}, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSnapshot(path, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("snapshot mode = %v (%v), want 0600", info.Mode().Perm(), err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := loaded.Pods[0]
+	if _, ok := got.Annotations[lastAppliedAnnotation]; ok || got.Annotations["kusa.io/ignore"] != "true" {
+		t.Errorf("annotations = %v, want only kusa.io/ignore", got.Annotations)
+	}
+	if got.ManagedFields != nil || got.Spec.Volumes != nil || got.Spec.InitContainers[0].Command != nil {
+		t.Errorf("pod keeps managed fields, volumes or init commands: %+v", got)
+	}
+	if c := got.Spec.Containers[0]; c.Args != nil || c.Env != nil || c.EnvFrom != nil || c.VolumeMounts != nil {
+		t.Errorf("container keeps args, env or mounts: %+v", c)
+	}
+	if c := got.Spec.Containers[0]; c.Resources.Requests.Cpu().MilliValue() != 500 {
+		t.Errorf("container lost its requests: %+v", c.Resources)
+	}
+	if env := loaded.Deployments[0].Spec.Template.Spec.Containers[0].Env; env != nil {
+		t.Errorf("deployment template keeps env %v", env)
+	}
+}
@@ -0,0 +1,49 @@
+package kube
+
+import (
+	"time"
+
+	"github.com/amasotti/kusa/internal/diag"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// DefaultMaxMetricsAge is how old a metrics sample may be before it is reported as stale.
+// metrics-server scrapes every 15–60s, so older samples mean it is lagging or broken.
+const DefaultMaxMetricsAge = 5 * time.Minute
+
+// staleness counts metrics samples older than Clients.MaxMetricsAge while a result is built.
+type staleness struct {
+	maxAge time.Duration
+	now    time.Time
+	stale  int
+	oldest time.Time
+}
+
+func (c *Clients) newStaleness() *staleness {
+	now := c.TakenAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return &staleness{maxAge: c.MaxMetricsAge, now: now}
+}
+
+// check reports whether a sample taken at ts is stale. Samples without a timestamp never are.
+func (s *staleness) check(ts time.Time) bool {
+	if s.maxAge <= 0 || ts.IsZero() || s.now.Sub(ts) <= s.maxAge {
+		return false
+	}
+	s.stale++
+	if s.oldest.IsZero() || ts.Before(s.oldest) {
+		s.oldest = ts
+	}
+	return true
+}
+
+// warn prints a warning when stale samples were seen; what names the objects ("node", "pod").
+func (s *staleness) warn(what string) {
+	if s.stale == 0 {
+		return
+	}
+	diag.Warnf("metrics of %d %s are older than %s (oldest sampled %s ago); usage and verdicts may be wrong",
+		s.stale, Plural(s.stale, what, what+"s"), s.maxAge, duration.HumanDuration(s.now.Sub(s.oldest)))
+}
@@ -0,0 +1,29 @@
+package kube
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStalenessCheck(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		ts     time.Time
+		want   bool
+	}{
+		{"fresh", time.Minute, now.Add(-30 * time.Second), false},
+		{"stale", time.Minute, now.Add(-2 * time.Minute), true},
+		{"no timestamp", time.Minute, time.Time{}, false},
+		{"check disabled", 0, now.Add(-time.Hour), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &staleness{maxAge: tc.maxAge, now: now}
+			if got := s.check(tc.ts); got != tc.want {
+				t.Errorf("check(%s ago) with max age %s = %v, want %v", now.Sub(tc.ts), tc.maxAge, got, tc.want)
+			}
+		})
+	}
+}
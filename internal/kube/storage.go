@@ -0,0 +1,196 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// NodeStorage is the disk use on a node as reported by its kubelet's summary API.
+type NodeStorage struct {
+	// PodsEphemeralUsed is what the node's pods write to ephemeral storage (writable layers,
+	// logs and emptyDir volumes), in MiB: the usage their ephemeral-storage requests reserve.
+	PodsEphemeralUsed float64
+
+	// NodeFS is the filesystem holding the kubelet's directory (pod logs, emptyDir volumes,
+	// usually the container writable layers); ImageFS the one holding container images,
+	// often the same disk.
+	NodeFS  FilesystemUsage
+	ImageFS FilesystemUsage
+}
+
+// SeparateImageFS reports whether images are on their own filesystem; otherwise the kubelet
+// only applies the nodefs eviction threshold.
+func (s NodeStorage) SeparateImageFS() bool {
+	return s.ImageFS.Capacity > 0 && (s.ImageFS.Capacity != s.NodeFS.Capacity || s.ImageFS.Available != s.NodeFS.Available)
+}
+
+// FilesystemUsage is the size and free space of a node filesystem in MiB, with the free
+// space below which the kubelet starts evicting pods (its evictionHard threshold).
+type FilesystemUsage struct {
+	Capacity          float64
+	Available         float64
+	EvictionAvailable float64
+}
+
+// UsedPct returns the used share of the filesystem in percent; 0 when its size is unknown.
+func (f FilesystemUsage) UsedPct() float64 {
+	if f.Capacity == 0 {
+		return 0
+	}
+	return (f.Capacity - f.Available) / f.Capacity * 100
+}
+
+// EvictionPct returns the used share at which the kubelet starts evicting pods.
+func (f FilesystemUsage) EvictionPct() float64 {
+	if f.Capacity == 0 {
+		return 0
+	}
+	return (f.Capacity - f.EvictionAvailable) / f.Capacity * 100
+}
+
+// Default hard eviction thresholds of the kubelet, used when its config can't be read.
+const (
+	defaultEvictionNodeFS  = "10%"
+	defaultEvictionImageFS = "15%"
+)
+
+// kubeletSummary is the part of the kubelet's /stats/summary response kusa reads.
+type kubeletSummary struct {
+	Node struct {
+		FS      *summaryFS `json:"fs"`
+		Runtime *struct {
+			ImageFS *summaryFS `json:"imageFs"`
+		} `json:"runtime"`
+	} `json:"node"`
+	Pods []struct {
+		EphemeralStorage *struct {
+			UsedBytes *uint64 `json:"usedBytes"`
+		} `json:"ephemeral-storage"`
+	} `json:"pods"`
+}
+
+type summaryFS struct {
+	CapacityBytes  *uint64 `json:"capacityBytes"`
+	AvailableBytes *uint64 `json:"availableBytes"`
+}
+
+func (fs *summaryFS) usage() FilesystemUsage {
+	if fs == nil || fs.CapacityBytes == nil || fs.AvailableBytes == nil {
+		return FilesystemUsage{}
+	}
+	return FilesystemUsage{Capacity: float64(*fs.CapacityBytes) / (1024 * 1024), Available: float64(*fs.AvailableBytes) / (1024 * 1024)}
+}
+
+// FetchNodeStorage reads the storage use of each node from its kubelet's summary API through
+// the API server's node proxy (/api/v1/nodes/<node>/proxy/stats/summary, which needs the
+// nodes/proxy permission), and the eviction thresholds from its config, falling back to the
+// kubelet defaults. Nodes whose summary can't be read are left out; the error of the first
+// failure is returned only when no node could be read.
+func FetchNodeStorage(ctx context.Context, clients *Clients, nodes []NodeInfo) (map[string]NodeStorage, error) {
+	if clients.kubeletGet == nil {
+		return nil, fmt.Errorf("kubelet stats are not available for these clients")
+	}
+
+	results := make([]*NodeStorage, len(nodes))
+	errs := make([]error, len(nodes))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxKubeletCalls)
+	for i, n := range nodes {
+		g.Go(func() error {
+			errs[i] = clients.track(gctx, "get kubelet stats summary", func(ctx context.Context) (int, error) {
+				raw, err := clients.kubeletGet(ctx, n.Name, "stats/summary")
+				if err != nil {
+					return 0, fmt.Errorf("failed to read kubelet stats of %s: %w", n.Name, err)
+				}
+				s, err := parseNodeStorage(raw)
+				if err != nil {
+					return 0, fmt.Errorf("failed to parse kubelet stats of %s: %w", n.Name, err)
+				}
+				// Both need nodes/proxy; a config that can't be read leaves the defaults.
+				var eviction map[string]string
+				if raw, err := clients.kubeletGet(ctx, n.Name, "configz"); err == nil {
+					var cfg kubeletConfigz
+					if json.Unmarshal(raw, &cfg) == nil {
+						eviction = cfg.KubeletConfig.EvictionHard
+					}
+				}
+				if err := s.applyEviction(eviction); err != nil {
+					return 0, fmt.Errorf("failed to parse kubelet config of %s: %w", n.Name, err)
+				}
+				results[i] = &s
+				return 1, nil
+			})
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	storage := make(map[string]NodeStorage)
+	var firstErr error
+	for i, n := range nodes {
+		if results[i] != nil {
+			storage[n.Name] = *results[i]
+		} else if firstErr == nil {
+			firstErr = errs[i]
+		}
+	}
+	if len(storage) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return storage, nil
+}
+
+// parseNodeStorage sums the ephemeral storage the pods of a kubelet summary use.
+func parseNodeStorage(raw []byte) (NodeStorage, error) {
+	var summary kubeletSummary
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return NodeStorage{}, err
+	}
+	s := NodeStorage{NodeFS: summary.Node.FS.usage()}
+	if summary.Node.Runtime != nil {
+		s.ImageFS = summary.Node.Runtime.ImageFS.usage()
+	}
+	for _, p := range summary.Pods {
+		if p.EphemeralStorage != nil && p.EphemeralStorage.UsedBytes != nil {
+			s.PodsEphemeralUsed += float64(*p.EphemeralStorage.UsedBytes) / (1024 * 1024)
+		}
+	}
+	return s, nil
+}
+
+// applyEviction sets the eviction thresholds of the filesystems from a kubelet's evictionHard
+// ("nodefs.available": "10%" or "5Gi"), defaulting the ones it doesn't set.
+func (s *NodeStorage) applyEviction(evictionHard map[string]string) error {
+	for _, t := range []struct {
+		signal, fallback string
+		fs               *FilesystemUsage
+	}{
+		{"nodefs.available", defaultEvictionNodeFS, &s.NodeFS},
+		{"imagefs.available", defaultEvictionImageFS, &s.ImageFS},
+	} {
+		v, ok := evictionHard[t.signal]
+		if !ok {
+			v = t.fallback
+		}
+		if pct, isPct := strings.CutSuffix(v, "%"); isPct {
+			f, err := strconv.ParseFloat(pct, 64)
+			if err != nil {
+				return fmt.Errorf("evictionHard %s %q: %w", t.signal, v, err)
+			}
+			t.fs.EvictionAvailable = t.fs.Capacity * f / 100
+			continue
+		}
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return fmt.Errorf("evictionHard %s %q: %w", t.signal, v, err)
+		}
+		t.fs.EvictionAvailable = MiBFromQuantity(q)
+	}
+	return nil
+}
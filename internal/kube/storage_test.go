@@ -0,0 +1,99 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseNodeStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    NodeStorage
+		wantErr bool
+	}{
+		{
+			name: "pods with and without stats",
+			raw: `{"pods": [{"ephemeral-storage": {"usedBytes": 104857600}}, {"ephemeral-storage": {"usedBytes": 52428800}},
+				{"ephemeral-storage": {}}, {}]}`,
+			want: NodeStorage{PodsEphemeralUsed: 150},
+		},
+		{
+			name: "node filesystems",
+			raw: `{"node": {"fs": {"capacityBytes": 10737418240, "availableBytes": 2147483648},
+				"runtime": {"imageFs": {"capacityBytes": 1073741824, "availableBytes": 536870912}}}}`,
+			want: NodeStorage{NodeFS: FilesystemUsage{Capacity: 10240, Available: 2048}, ImageFS: FilesystemUsage{Capacity: 1024, Available: 512}},
+		},
+		{name: "no pods", raw: `{"node": {}}`, want: NodeStorage{}},
+		{name: "not json", raw: `404 page not found`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNodeStorage([]byte(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEviction(t *testing.T) {
+	tests := []struct {
+		name          string
+		evictionHard  map[string]string
+		nodeFS, image float64
+		wantErr       bool
+	}{
+		{name: "kubelet defaults", nodeFS: 1000, image: 150},
+		{name: "percentage and quantity", evictionHard: map[string]string{"nodefs.available": "5%", "imagefs.available": "2Gi"}, nodeFS: 500, image: 2048},
+		{name: "invalid", evictionHard: map[string]string{"nodefs.available": "lots"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NodeStorage{NodeFS: FilesystemUsage{Capacity: 10000}, ImageFS: FilesystemUsage{Capacity: 1000}}
+			err := s.applyEviction(tt.evictionHard)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (s.NodeFS.EvictionAvailable != tt.nodeFS || s.ImageFS.EvictionAvailable != tt.image) {
+				t.Errorf("eviction at %v/%v available, want %v/%v", s.NodeFS.EvictionAvailable, s.ImageFS.EvictionAvailable, tt.nodeFS, tt.image)
+			}
+		})
+	}
+}
+
+func TestFetchNodeStorage(t *testing.T) {
+	nodes := []NodeInfo{{Name: "node-a"}, {Name: "node-b"}}
+	clients := NewClientsFrom(nil, nil, "test")
+
+	if _, err := FetchNodeStorage(context.Background(), clients, nodes); err == nil {
+		t.Error("want an error without a kubelet stats reader")
+	}
+
+	clients.kubeletGet = func(_ context.Context, node, path string) ([]byte, error) {
+		if node == "node-b" || path != "stats/summary" {
+			return nil, errors.New("forbidden")
+		}
+		return []byte(`{"node": {"fs": {"capacityBytes": 104857600, "availableBytes": 52428800}},
+			"pods": [{"ephemeral-storage": {"usedBytes": 1048576}}]}`), nil
+	}
+	got, err := FetchNodeStorage(context.Background(), clients, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got["node-a"].PodsEphemeralUsed != 1 {
+		t.Errorf("got %+v, want node-a only with 1Mi used", got)
+	}
+	if fs := got["node-a"].NodeFS; fs.UsedPct() != 50 || fs.EvictionPct() != 90 {
+		t.Errorf("nodefs %.0f%% used, evicting at %.0f%%; want 50%% and the default 90%%", fs.UsedPct(), fs.EvictionPct())
+	}
+
+	clients.kubeletGet = func(context.Context, string, string) ([]byte, error) { return nil, errors.New("forbidden") }
+	if _, err := FetchNodeStorage(context.Background(), clients, nodes); err == nil {
+		t.Error("want an error when no node's stats can be read")
+	}
+}
@@ -0,0 +1,172 @@
+package kube
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// CPUThrottling counts the CFS scheduling periods of a container since it started, read
+// from the kubelet's cAdvisor metrics. Only containers with a CPU limit have a CFS quota
+// and can be throttled.
+type CPUThrottling struct {
+	Periods          float64 // container_cpu_cfs_periods_total
+	ThrottledPeriods float64 // container_cpu_cfs_throttled_periods_total
+}
+
+// Ratio returns the share of periods in which the container hit its CPU limit.
+func (t CPUThrottling) Ratio() float64 {
+	if t.Periods == 0 {
+		return 0
+	}
+	return t.ThrottledPeriods / t.Periods
+}
+
+// ContainerKey identifies a container of a pod.
+type ContainerKey struct {
+	Namespace string
+	Pod       string
+	Container string
+}
+
+// FetchCPUThrottling reads the CFS throttling counters of the containers on each node from
+// its kubelet's cAdvisor endpoint, through the API server's node proxy
+// (/api/v1/nodes/<node>/proxy/metrics/cadvisor, which needs the nodes/proxy permission).
+// Nodes whose metrics can't be read are left out; the error of the first failure is
+// returned only when no node could be read.
+func FetchCPUThrottling(ctx context.Context, clients *Clients, nodes []string) (map[ContainerKey]CPUThrottling, error) {
+	if clients.kubeletGet == nil {
+		return nil, fmt.Errorf("kubelet metrics are not available for these clients")
+	}
+
+	results := make([]map[ContainerKey]CPUThrottling, len(nodes))
+	errs := make([]error, len(nodes))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxKubeletCalls)
+	for i, node := range nodes {
+		g.Go(func() error {
+			errs[i] = clients.track(gctx, "get kubelet cadvisor", func(ctx context.Context) (int, error) {
+				raw, err := clients.kubeletGet(ctx, node, "metrics/cadvisor")
+				if err != nil {
+					return 0, fmt.Errorf("failed to read cAdvisor metrics of %s: %w", node, err)
+				}
+				m, err := parseCPUThrottling(raw)
+				if err != nil {
+					return 0, fmt.Errorf("failed to parse cAdvisor metrics of %s: %w", node, err)
+				}
+				results[i] = m
+				return len(m), nil
+			})
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	throttling := make(map[ContainerKey]CPUThrottling)
+	read := 0
+	var firstErr error
+	for i := range nodes {
+		if results[i] == nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		read++
+		for k, v := range results[i] {
+			throttling[k] = v
+		}
+	}
+	if read == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return throttling, nil
+}
+
+// CFS counters in the cAdvisor metrics.
+const (
+	metricCFSPeriods          = "container_cpu_cfs_periods_total"
+	metricCFSThrottledPeriods = "container_cpu_cfs_throttled_periods_total"
+)
+
+// parseCPUThrottling extracts the CFS counters per container from the Prometheus text
+// format. Series without a container label (the pod's cgroup, the pause container) are
+// skipped.
+func parseCPUThrottling(raw []byte) (map[ContainerKey]CPUThrottling, error) {
+	throttling := make(map[ContainerKey]CPUThrottling)
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "container_cpu_cfs_") {
+			continue
+		}
+		name, rest, ok := strings.Cut(line, "{")
+		if !ok || (name != metricCFSPeriods && name != metricCFSThrottledPeriods) {
+			continue
+		}
+		labels, rest, err := parsePromLabels(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s: missing value", name)
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		key := ContainerKey{Namespace: labels["namespace"], Pod: labels["pod"], Container: labels["container"]}
+		if key.Pod == "" || key.Container == "" || key.Container == "POD" {
+			continue
+		}
+		t := throttling[key]
+		if name == metricCFSPeriods {
+			t.Periods = v
+		} else {
+			t.ThrottledPeriods = v
+		}
+		throttling[key] = t
+	}
+	return throttling, sc.Err()
+}
+
+// parsePromLabels parses the label set of a Prometheus text format sample, s being the
+// rest of the line after the opening brace. It returns the remainder after the closing one.
+func parsePromLabels(s string) (map[string]string, string, error) {
+	labels := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " ,")
+		if strings.HasPrefix(s, "}") {
+			return labels, s[1:], nil
+		}
+		key, rest, ok := strings.Cut(s, `="`)
+		if !ok {
+			return nil, "", fmt.Errorf("malformed labels")
+		}
+		var value strings.Builder
+		i := 0
+		for ; i < len(rest) && rest[i] != '"'; i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				i++
+				if rest[i] == 'n' {
+					value.WriteByte('\n')
+					continue
+				}
+			}
+			value.WriteByte(rest[i])
+		}
+		if i == len(rest) {
+			return nil, "", fmt.Errorf("unterminated label value")
+		}
+		labels[strings.TrimSpace(key)] = value.String()
+		s = rest[i+1:]
+	}
+}
@@ -0,0 +1,74 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const testCadvisor = `# HELP container_cpu_cfs_periods_total Number of elapsed enforcement period intervals.
+# TYPE container_cpu_cfs_periods_total counter
+container_cpu_cfs_periods_total{container="web",id="/kubepods/burstable/pod1/abc",image="web:1",name="abc",namespace="shop",pod="web-1"} 1000 1700000000000
+container_cpu_cfs_periods_total{container="",id="/kubepods/burstable/pod1",image="",name="",namespace="shop",pod="web-1"} 1000 1700000000000
+container_cpu_cfs_periods_total{container="POD",namespace="shop",pod="web-1"} 1000
+container_cpu_cfs_periods_total{container="odd",namespace="dev",pod="a \"quoted\", pod"} 10
+# TYPE container_cpu_cfs_throttled_periods_total counter
+container_cpu_cfs_throttled_periods_total{container="web",id="/kubepods/burstable/pod1/abc",image="web:1",name="abc",namespace="shop",pod="web-1"} 250 1700000000000
+container_cpu_cfs_throttled_periods_total{container="odd",namespace="dev",pod="a \"quoted\", pod"} 1
+container_cpu_cfs_throttled_seconds_total{container="web",namespace="shop",pod="web-1"} 12.5
+container_cpu_usage_seconds_total{container="web",namespace="shop",pod="web-1"} 99
+`
+
+func TestParseCPUThrottling(t *testing.T) {
+	got, err := parseCPUThrottling([]byte(testCadvisor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[ContainerKey]CPUThrottling{
+		{Namespace: "shop", Pod: "web-1", Container: "web"}:          {Periods: 1000, ThrottledPeriods: 250},
+		{Namespace: "dev", Pod: `a "quoted", pod`, Container: "odd"}: {Periods: 10, ThrottledPeriods: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%+v = %+v, want %+v", k, got[k], w)
+		}
+	}
+	if r := got[ContainerKey{Namespace: "shop", Pod: "web-1", Container: "web"}].Ratio(); r != 0.25 {
+		t.Errorf("Ratio() = %g, want 0.25", r)
+	}
+
+	if _, err := parseCPUThrottling([]byte(`container_cpu_cfs_periods_total{container="web 1`)); err == nil {
+		t.Error("want an error for an unterminated label value")
+	}
+}
+
+func TestFetchCPUThrottling(t *testing.T) {
+	nodes := []string{"node-a", "node-b"}
+	clients := NewClientsFrom(nil, nil, "test")
+
+	if _, err := FetchCPUThrottling(context.Background(), clients, nodes); err == nil {
+		t.Error("want an error without a kubelet reader")
+	}
+
+	clients.kubeletGet = func(_ context.Context, node, path string) ([]byte, error) {
+		if node == "node-b" || path != "metrics/cadvisor" {
+			return nil, errors.New("forbidden")
+		}
+		return []byte(testCadvisor), nil
+	}
+	got, err := FetchCPUThrottling(context.Background(), clients, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %+v, want the two containers of node-a", got)
+	}
+
+	clients.kubeletGet = func(context.Context, string, string) ([]byte, error) { return nil, errors.New("forbidden") }
+	if _, err := FetchCPUThrottling(context.Background(), clients, nodes); err == nil {
+		t.Error("want an error when no node's metrics can be read")
+	}
+}
@@ -0,0 +1,126 @@
+package kube
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// CallTiming records diagnostics for a single logical API call (e.g. "list pods").
+type CallTiming struct {
+	Name     string
+	Duration time.Duration
+	Objects  int
+	Requests int // HTTP round trips made for this call, retries and list pages included
+	Err      error
+
+	// Client-side throttling: time spent waiting on the client-go rate limiter.
+	ThrottleWait time.Duration
+	// Server-side throttling: HTTP 429 responses (API Priority and Fairness).
+	Throttled429 int
+}
+
+// Timings collects CallTiming entries for all API calls made through a Clients instance.
+// It is safe for concurrent use by the errgroup-based fetchers.
+type Timings struct {
+	mu    sync.Mutex
+	calls []*CallTiming
+}
+
+type callKey struct{}
+
+// Calls returns a snapshot of the recorded calls in the order they were started.
+func (t *Timings) Calls() []CallTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]CallTiming, len(t.calls))
+	for i, c := range t.calls {
+		out[i] = *c
+	}
+	return out
+}
+
+// track runs fn as the named call, attributing HTTP round trips and rate-limiter waits
+// made with the derived context to it. fn returns the number of objects fetched.
+func (t *Timings) track(ctx context.Context, name string, fn func(ctx context.Context) (int, error)) error {
+	call := &CallTiming{Name: name}
+	t.mu.Lock()
+	t.calls = append(t.calls, call)
+	t.mu.Unlock()
+
+	start := time.Now()
+	n, err := fn(context.WithValue(ctx, callKey{}, call))
+	elapsed := time.Since(start)
+
+	t.mu.Lock()
+	call.Duration = elapsed
+	call.Objects = n
+	call.Err = err
+	t.mu.Unlock()
+	return err
+}
+
+// record applies update to the call attached to ctx, if any.
+func (t *Timings) record(ctx context.Context, update func(c *CallTiming)) {
+	call, ok := ctx.Value(callKey{}).(*CallTiming)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	update(call)
+	t.mu.Unlock()
+}
+
+// instrument installs the transport wrapper and rate limiter that feed t.
+func (t *Timings) instrument(cfg *rest.Config) {
+	qps, burst := cfg.QPS, cfg.Burst
+	if qps == 0 {
+		qps = rest.DefaultQPS
+	}
+	if burst == 0 {
+		burst = rest.DefaultBurst
+	}
+	cfg.RateLimiter = &timedRateLimiter{
+		RateLimiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+		timings:     t,
+	}
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &timedRoundTripper{next: rt, timings: t}
+	})
+}
+
+// timedRateLimiter attributes time spent waiting for a client-side token to the current call.
+type timedRateLimiter struct {
+	flowcontrol.RateLimiter
+	timings *Timings
+}
+
+func (l *timedRateLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := l.RateLimiter.Wait(ctx)
+	if waited := time.Since(start); waited > time.Millisecond {
+		l.timings.record(ctx, func(c *CallTiming) { c.ThrottleWait += waited })
+	}
+	return err
+}
+
+// timedRoundTripper counts HTTP round trips and 429 responses per call.
+type timedRoundTripper struct {
+	next    http.RoundTripper
+	timings *Timings
+}
+
+func (rt *timedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	rt.timings.record(req.Context(), func(c *CallTiming) {
+		c.Requests++
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			c.Throttled429++
+		}
+	})
+	return resp, err
+}
@@ -0,0 +1,193 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// VPA update modes that change the resources of running pods; Off and Initial only
+// recommend, or apply to new pods.
+var vpaUpdatingModes = map[string]bool{"Auto": true, "Recreate": true, "InPlaceOrRecreate": true}
+
+// Reasons of the events the VPA updater records on the pods it resizes.
+const (
+	vpaEvictedReason  = "EvictedByVPA"
+	vpaInPlaceReason  = "InPlaceResizedByVPA"
+	defaultUpdateMode = "Auto"
+)
+
+// VPAInfo is a VerticalPodAutoscaler with how often it resized its target's pods, as far
+// as the API server still keeps the events (one hour by default).
+type VPAInfo struct {
+	Namespace  string
+	Name       string
+	TargetKind string
+	TargetName string
+	UpdateMode string // Off, Initial, Recreate, InPlaceOrRecreate or Auto
+
+	Evictions      int32 // pods evicted to apply a recommendation
+	InPlaceUpdates int32 // pods resized in place
+	LastUpdate     time.Time
+}
+
+// Updating reports whether the VPA changes the resources of running pods.
+func (v VPAInfo) Updating() bool { return vpaUpdatingModes[v.UpdateMode] }
+
+// Resizes returns the pods the VPA evicted or resized in place.
+func (v VPAInfo) Resizes() int32 { return v.Evictions + v.InPlaceUpdates }
+
+// vpaList is the part of an autoscaling.k8s.io/v1 VerticalPodAutoscalerList kusa reads.
+type vpaList struct {
+	Items []struct {
+		Metadata struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			TargetRef *struct {
+				Kind string `json:"kind"`
+				Name string `json:"name"`
+			} `json:"targetRef"`
+			UpdatePolicy *struct {
+				UpdateMode *string `json:"updateMode"`
+			} `json:"updatePolicy"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// FetchVPAs lists the VerticalPodAutoscalers (autoscaling.k8s.io/v1) in namespace ("" =
+// all) and counts the pods the VPA updater recently evicted or resized in place for each,
+// from the events it records on them. Evicted pods are gone, so an event is attributed to
+// the VPA whose target name is the longest prefix of the pod name. Without the VPA CRD
+// installed the list is empty.
+func FetchVPAs(ctx context.Context, clients *Clients, namespace string) ([]VPAInfo, error) {
+	if clients.apiGet == nil {
+		return nil, fmt.Errorf("VerticalPodAutoscalers are not available for these clients")
+	}
+
+	reasons := []string{vpaEvictedReason, vpaInPlaceReason}
+	var (
+		vpas   []VPAInfo
+		events = make([][]corev1.Event, len(reasons))
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return clients.track(gctx, "list verticalpodautoscalers", func(ctx context.Context) (int, error) {
+			path := "/apis/autoscaling.k8s.io/v1/verticalpodautoscalers"
+			if namespace != "" {
+				path = "/apis/autoscaling.k8s.io/v1/namespaces/" + namespace + "/verticalpodautoscalers"
+			}
+			raw, err := clients.apiGet(ctx, path)
+			if apierrors.IsNotFound(err) {
+				return 0, nil // the VPA CRD is not installed
+			}
+			if err != nil {
+				return 0, fmt.Errorf("failed to list verticalpodautoscalers: %w", err)
+			}
+			if vpas, err = parseVPAs(raw); err != nil {
+				return 0, fmt.Errorf("failed to parse verticalpodautoscalers: %w", err)
+			}
+			return len(vpas), nil
+		})
+	})
+	for i, reason := range reasons {
+		g.Go(func() error {
+			return clients.track(gctx, "list events", func(ctx context.Context) (int, error) {
+				list, err := clients.Core.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+					FieldSelector: fields.OneTermEqualSelector("reason", reason).String(),
+				})
+				if err != nil {
+					return 0, fmt.Errorf("failed to list events: %w", err)
+				}
+				for _, e := range list.Items {
+					// The fake clients behind --demo and snapshots ignore field selectors.
+					if e.Reason == reason {
+						events[i] = append(events[i], e)
+					}
+				}
+				return len(list.Items), nil
+			})
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	countVPAUpdates(vpas, slices.Concat(events...))
+	return vpas, nil
+}
+
+// parseVPAs reads a VerticalPodAutoscalerList, defaulting an unset update mode to Auto.
+func parseVPAs(raw []byte) ([]VPAInfo, error) {
+	var list vpaList
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	var vpas []VPAInfo
+	for _, item := range list.Items {
+		v := VPAInfo{Namespace: item.Metadata.Namespace, Name: item.Metadata.Name, UpdateMode: defaultUpdateMode}
+		if t := item.Spec.TargetRef; t != nil {
+			v.TargetKind, v.TargetName = t.Kind, t.Name
+		}
+		if p := item.Spec.UpdatePolicy; p != nil && p.UpdateMode != nil {
+			v.UpdateMode = *p.UpdateMode
+		}
+		vpas = append(vpas, v)
+	}
+	return vpas, nil
+}
+
+// countVPAUpdates attributes the VPA updater's pod events to the VPAs in vpas.
+func countVPAUpdates(vpas []VPAInfo, events []corev1.Event) {
+	for _, e := range events {
+		if e.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		best := -1
+		for i, v := range vpas {
+			if v.Namespace == e.InvolvedObject.Namespace && v.TargetName != "" &&
+				strings.HasPrefix(e.InvolvedObject.Name, v.TargetName+"-") &&
+				(best < 0 || len(v.TargetName) > len(vpas[best].TargetName)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		count := max(e.Count, 1)
+		if e.Series != nil {
+			count = max(count, e.Series.Count)
+		}
+		v := &vpas[best]
+		if e.Reason == vpaEvictedReason {
+			v.Evictions += count
+		} else {
+			v.InPlaceUpdates += count
+		}
+		if last := eventTime(e); last.After(v.LastUpdate) {
+			v.LastUpdate = last
+		}
+	}
+}
+
+// eventTime returns when an event was last seen.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.FirstTimestamp.Time
+}
@@ -0,0 +1,145 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseVPAs(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []VPAInfo
+		wantErr bool
+	}{
+		{
+			name: "explicit and default update mode",
+			raw: `{"items": [
+				{"metadata": {"namespace": "shop", "name": "cart"}, "spec": {"targetRef": {"kind": "Deployment", "name": "cart"}, "updatePolicy": {"updateMode": "Off"}}},
+				{"metadata": {"namespace": "data", "name": "db"}, "spec": {"targetRef": {"kind": "StatefulSet", "name": "postgres"}}}]}`,
+			want: []VPAInfo{
+				{Namespace: "shop", Name: "cart", TargetKind: "Deployment", TargetName: "cart", UpdateMode: "Off"},
+				{Namespace: "data", Name: "db", TargetKind: "StatefulSet", TargetName: "postgres", UpdateMode: "Auto"},
+			},
+		},
+		{name: "no target", raw: `{"items": [{"metadata": {"name": "orphan"}, "spec": {}}]}`, want: []VPAInfo{{Name: "orphan", UpdateMode: "Auto"}}},
+		{name: "empty", raw: `{"items": []}`},
+		{name: "not json", raw: `404 page not found`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVPAs([]byte(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d VPAs, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("VPA %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func vpaEvent(namespace, pod, reason string, count int32, last time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: namespace, Name: pod + "." + reason},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: pod},
+		Reason:         reason,
+		Count:          count,
+		LastTimestamp:  metav1.NewTime(last),
+	}
+}
+
+func TestCountVPAUpdates(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	vpas := []VPAInfo{
+		{Namespace: "shop", TargetName: "cart", UpdateMode: "Auto"},
+		{Namespace: "shop", TargetName: "cart-worker", UpdateMode: "InPlaceOrRecreate"},
+		{Namespace: "data", TargetName: "cart", UpdateMode: "Auto"},
+	}
+	series := vpaEvent("shop", "cart-worker-0", vpaInPlaceReason, 0, time.Time{})
+	series.Series = &corev1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(now.Add(-time.Minute))}
+	node := vpaEvent("shop", "cart-1", vpaEvictedReason, 1, now)
+	node.InvolvedObject.Kind = "Node"
+	countVPAUpdates(vpas, []corev1.Event{
+		vpaEvent("shop", "cart-7d9f-abcde", vpaEvictedReason, 2, now.Add(-20*time.Minute)),
+		vpaEvent("shop", "cart-7d9f-fghij", vpaEvictedReason, 0, now.Add(-5*time.Minute)),
+		series,
+		vpaEvent("shop", "cartographer-0", vpaEvictedReason, 1, now), // not a pod of cart
+		node,
+	})
+
+	want := []VPAInfo{
+		{Namespace: "shop", TargetName: "cart", UpdateMode: "Auto", Evictions: 3, LastUpdate: now.Add(-5 * time.Minute)},
+		{Namespace: "shop", TargetName: "cart-worker", UpdateMode: "InPlaceOrRecreate", InPlaceUpdates: 3, LastUpdate: now.Add(-time.Minute)},
+		{Namespace: "data", TargetName: "cart", UpdateMode: "Auto"},
+	}
+	for i := range want {
+		if !vpas[i].LastUpdate.Equal(want[i].LastUpdate) {
+			t.Errorf("VPA %d last update = %v, want %v", i, vpas[i].LastUpdate, want[i].LastUpdate)
+		}
+		vpas[i].LastUpdate, want[i].LastUpdate = time.Time{}, time.Time{}
+		if vpas[i] != want[i] {
+			t.Errorf("VPA %d = %+v, want %+v", i, vpas[i], want[i])
+		}
+	}
+}
+
+func TestFetchVPAs(t *testing.T) {
+	const list = `{"items": [{"metadata": {"namespace": "shop", "name": "cart"},
+		"spec": {"targetRef": {"kind": "Deployment", "name": "cart"}, "updatePolicy": {"updateMode": "Recreate"}}}]}`
+	now := time.Now()
+	evicted := vpaEvent("shop", "cart-7d9f-abcde", vpaEvictedReason, 2, now)
+	other := vpaEvent("shop", "cart-7d9f-abcde", "Killing", 1, now)
+
+	tests := []struct {
+		name          string
+		apiErr        error
+		wantVPAs      int
+		wantEvictions int32
+		wantErr       bool
+	}{
+		{name: "vpa with evictions", wantVPAs: 1, wantEvictions: 2},
+		{name: "crd not installed", apiErr: apierrors.NewNotFound(schema.GroupResource{Group: "autoscaling.k8s.io", Resource: "verticalpodautoscalers"}, "")},
+		{name: "forbidden", apiErr: errors.New("forbidden"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := fakeCluster([]runtime.Object{&evicted, &other}, nil, nil)
+			var gotPath string
+			clients.apiGet = func(_ context.Context, path string) ([]byte, error) {
+				gotPath = path
+				return []byte(list), tt.apiErr
+			}
+			vpas, err := FetchVPAs(context.Background(), clients, "shop")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := "/apis/autoscaling.k8s.io/v1/namespaces/shop/verticalpodautoscalers"; gotPath != want {
+				t.Errorf("path = %q, want %q", gotPath, want)
+			}
+			if len(vpas) != tt.wantVPAs {
+				t.Fatalf("got %d VPAs, want %d", len(vpas), tt.wantVPAs)
+			}
+			if tt.wantVPAs > 0 && vpas[0].Evictions != tt.wantEvictions {
+				t.Errorf("evictions = %d, want %d", vpas[0].Evictions, tt.wantEvictions)
+			}
+		})
+	}
+
+	if _, err := FetchVPAs(context.Background(), fakeCluster(nil, nil, nil), ""); err == nil {
+		t.Error("expected an error without an API client")
+	}
+}
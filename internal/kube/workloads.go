@@ -3,10 +3,12 @@ package kube
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"golang.org/x/sync/errgroup"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
@@ -16,20 +18,47 @@ type WorkloadInfo struct {
 	Kind      string // Deployment, StatefulSet, DaemonSet, Job, ReplicaSet, Pod
 	Namespace string
 	Name      string
-	PodCount  int
+	PodCount  int // running pods
+
+	// Release is the Helm release that installed the workload, from the controller's
+	// annotations and labels or, failing those, its pods' labels ("" = none), see HelmRelease.
+	Release string
+	// ArgoCDApp is the Argo CD Application that manages the workload, from the controller's
+	// or its pods' tracking label or annotation ("" = none), see ArgoCDApp.
+	ArgoCDApp string
+
+	// Exception is declared by the kusa.io annotations on the controller or, failing those,
+	// on its pods.
+	Exception Exception
+
+	// DesiredPods is the controller's desired replica count (Deployment/StatefulSet
+	// spec.replicas, DaemonSet desiredNumberScheduled). Only meaningful when DesiredKnown.
+	DesiredPods  int
+	DesiredKnown bool
 
 	CPURequest int64   // millicores — sum across all pods
 	CPUActual  int64   // millicores
 	MemRequest float64 // MiB
 	MemActual  float64 // MiB
 
+	// CPULimit and MemLimit sum the limits across all pods; containers without a limit
+	// contribute nothing, so they are 0 when no container has one.
+	CPULimit int64   // millicores
+	MemLimit float64 // MiB
+
+	// Set when at least one container of any pod has no CPU / memory limit.
+	MissingCPULimit bool
+	MissingMemLimit bool
+
 	MetricsAvailable bool
+	MetricsStale     bool // at least one pod's sample is older than Clients.MaxMetricsAge
 }
 
 // FetchWorkloadsResult holds the result of FetchWorkloads.
 type FetchWorkloadsResult struct {
 	Workloads        []WorkloadInfo
 	MetricsAvailable bool
+	Skipped          []string // namespaces left out of a per-namespace fetch, see fetchEachNamespace
 }
 
 // ownerKey identifies a workload controller.
@@ -39,63 +68,122 @@ type ownerKey struct {
 	Name      string
 }
 
-// FetchWorkloads fetches pods, pod metrics, and ReplicaSets concurrently, then
-// aggregates pod resource data grouped by the owning workload controller.
+// FetchWorkloads fetches pods, pod metrics, ReplicaSets and the workload controllers
+// (Deployments, StatefulSets, DaemonSets) concurrently, then aggregates pod resource data
+// grouped by the owning workload controller. Controllers without running pods are
+// included with PodCount 0.
 // When namespace is non-empty only that namespace is queried; pass "" for cluster-wide.
 // When namespace is non-empty the system-namespace filter is skipped automatically.
+// A cluster-wide fetch the user may not make is retried per namespace, see
+// fetchEachNamespace.
 func FetchWorkloads(ctx context.Context, clients *Clients, namespace string, includeSystem bool) (*FetchWorkloadsResult, error) {
+	result, err := fetchWorkloads(ctx, clients, namespace, includeSystem)
+	if namespace != "" || !apierrors.IsForbidden(err) {
+		return result, err
+	}
+	result, skipped, err := fetchEachNamespace(ctx, clients, err, includeSystem,
+		func(ctx context.Context, ns string) (*FetchWorkloadsResult, error) {
+			return fetchWorkloads(ctx, clients, ns, includeSystem)
+		},
+		func(into, from *FetchWorkloadsResult) {
+			into.Workloads = append(into.Workloads, from.Workloads...)
+			into.MetricsAvailable = into.MetricsAvailable && from.MetricsAvailable
+		})
+	if result != nil {
+		result.Skipped = skipped
+	}
+	return result, err
+}
+
+func fetchWorkloads(ctx context.Context, clients *Clients, namespace string, includeSystem bool) (*FetchWorkloadsResult, error) {
 	var (
 		pods         *corev1.PodList
 		podMetrics   *metricsv1beta1.PodMetricsList
 		replicaSets  *appsv1.ReplicaSetList
+		deployments  *appsv1.DeploymentList
+		statefulSets *appsv1.StatefulSetList
+		daemonSets   *appsv1.DaemonSetList
 		metricsAvail = true
 	)
 
 	g, gctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		var err error
-		pods, err = clients.Core.CoreV1().Pods(namespace).List(gctx, metav1.ListOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to list pods: %w", err)
-		}
-		return nil
+		return clients.track(gctx, "list pods", func(ctx context.Context) (int, error) {
+			var err error
+			pods, err = clients.Core.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list pods: %w", err)
+			}
+			return len(pods.Items), nil
+		})
 	})
 
 	g.Go(func() error {
-		var err error
-		podMetrics, err = clients.Metrics.MetricsV1beta1().PodMetricses(namespace).List(gctx, metav1.ListOptions{})
+		err := clients.track(gctx, "list pod metrics", func(ctx context.Context) (int, error) {
+			var err error
+			podMetrics, err = clients.listPodMetrics(ctx, namespace)
+			if err != nil {
+				return 0, err
+			}
+			return len(podMetrics.Items), nil
+		})
 		if err != nil {
-			fmt.Printf("Warning: failed to get pod metrics (metrics-server may not be installed): %v\n", err)
 			metricsAvail = false
+			return clients.metricsUnavailable("pod metrics", err)
 		}
 		return nil
 	})
 
 	g.Go(func() error {
-		var err error
-		replicaSets, err = clients.Core.AppsV1().ReplicaSets(namespace).List(gctx, metav1.ListOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to list replicasets: %w", err)
-		}
-		return nil
+		return clients.track(gctx, "list replicasets", func(ctx context.Context) (int, error) {
+			var err error
+			replicaSets, err = clients.Core.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list replicasets: %w", err)
+			}
+			return len(replicaSets.Items), nil
+		})
+	})
+
+	g.Go(func() error {
+		return clients.track(gctx, "list deployments", func(ctx context.Context) (int, error) {
+			var err error
+			deployments, err = clients.Core.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list deployments: %w", err)
+			}
+			return len(deployments.Items), nil
+		})
+	})
+
+	g.Go(func() error {
+		return clients.track(gctx, "list statefulsets", func(ctx context.Context) (int, error) {
+			var err error
+			statefulSets, err = clients.Core.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list statefulsets: %w", err)
+			}
+			return len(statefulSets.Items), nil
+		})
+	})
+
+	g.Go(func() error {
+		return clients.track(gctx, "list daemonsets", func(ctx context.Context) (int, error) {
+			var err error
+			daemonSets, err = clients.Core.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list daemonsets: %w", err)
+			}
+			return len(daemonSets.Items), nil
+		})
 	})
 
 	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 
-	// Build map: "namespace/replicaset-name" → Deployment ownerKey
-	rsToDeployment := make(map[string]ownerKey)
-	for _, rs := range replicaSets.Items {
-		for _, ref := range rs.OwnerReferences {
-			if ref.Kind == "Deployment" {
-				key := rs.Namespace + "/" + rs.Name
-				rsToDeployment[key] = ownerKey{Kind: "Deployment", Namespace: rs.Namespace, Name: ref.Name}
-				break
-			}
-		}
-	}
+	rsToDeployment := buildRSToDeployment(replicaSets)
 
 	// Build pod metrics map: "namespace/pod-name" → PodMetrics
 	podMetricsMap := make(map[string]metricsv1beta1.PodMetrics)
@@ -107,6 +195,7 @@ func FetchWorkloads(ctx context.Context, clients *Clients, namespace string, inc
 
 	// Aggregate running pods into workloads
 	workloadMap := make(map[string]*WorkloadInfo)
+	stale := clients.newStaleness()
 
 	for _, pod := range pods.Items {
 		if pod.Status.Phase != corev1.PodRunning {
@@ -130,6 +219,15 @@ func FetchWorkloads(ctx context.Context, clients *Clients, namespace string, inc
 
 		w := workloadMap[key]
 		w.PodCount++
+		if w.Release == "" {
+			w.Release = HelmRelease(pod.Labels, nil)
+		}
+		if w.ArgoCDApp == "" {
+			w.ArgoCDApp = ArgoCDApp(pod.Labels, pod.Annotations)
+		}
+		if w.Exception.IsZero() {
+			w.Exception = exceptionFrom(pod.Annotations, pod.Namespace+"/"+pod.Name)
+		}
 
 		for _, c := range pod.Spec.Containers {
 			if q := c.Resources.Requests[corev1.ResourceCPU]; !q.IsZero() {
@@ -138,11 +236,19 @@ func FetchWorkloads(ctx context.Context, clients *Clients, namespace string, inc
 			if q := c.Resources.Requests[corev1.ResourceMemory]; !q.IsZero() {
 				w.MemRequest += MiBFromQuantity(q)
 			}
+			w.CPULimit += MillicoresFromQuantity(c.Resources.Limits[corev1.ResourceCPU])
+			w.MemLimit += MiBFromQuantity(c.Resources.Limits[corev1.ResourceMemory])
+			missingCPU, missingMem := missingLimits(c)
+			w.MissingCPULimit = w.MissingCPULimit || missingCPU
+			w.MissingMemLimit = w.MissingMemLimit || missingMem
 		}
+		w.CPURequest += MillicoresFromQuantity(pod.Spec.Overhead[corev1.ResourceCPU])
+		w.MemRequest += MiBFromQuantity(pod.Spec.Overhead[corev1.ResourceMemory])
 
 		if metricsAvail {
 			pmKey := pod.Namespace + "/" + pod.Name
 			if pm, ok := podMetricsMap[pmKey]; ok {
+				w.MetricsStale = stale.check(pm.Timestamp.Time) || w.MetricsStale
 				for _, c := range pm.Containers {
 					w.CPUActual += MillicoresFromQuantity(c.Usage[corev1.ResourceCPU])
 					w.MemActual += MiBFromQuantity(c.Usage[corev1.ResourceMemory])
@@ -151,6 +257,85 @@ func FetchWorkloads(ctx context.Context, clients *Clients, namespace string, inc
 		}
 	}
 
+	// Merge in controllers themselves, so zero-replica, crash-looping or fully-Pending
+	// workloads appear too. Those without running pods get their spec'd requests
+	// (pod template × desired replicas) and no usage.
+	var controllers []controllerSpec
+	for _, d := range deployments.Items {
+		controllers = append(controllers, controllerSpec{
+			key:      ownerKey{Kind: "Deployment", Namespace: d.Namespace, Name: d.Name},
+			release:  HelmRelease(d.Labels, d.Annotations),
+			app:      ArgoCDApp(d.Labels, d.Annotations),
+			except:   exceptionFrom(d.Annotations, d.Namespace+"/"+d.Name),
+			desired:  desiredReplicas(d.Spec.Replicas),
+			template: d.Spec.Template.Spec,
+		})
+	}
+	for _, st := range statefulSets.Items {
+		controllers = append(controllers, controllerSpec{
+			key:      ownerKey{Kind: "StatefulSet", Namespace: st.Namespace, Name: st.Name},
+			release:  HelmRelease(st.Labels, st.Annotations),
+			app:      ArgoCDApp(st.Labels, st.Annotations),
+			except:   exceptionFrom(st.Annotations, st.Namespace+"/"+st.Name),
+			desired:  desiredReplicas(st.Spec.Replicas),
+			template: st.Spec.Template.Spec,
+		})
+	}
+	for _, ds := range daemonSets.Items {
+		controllers = append(controllers, controllerSpec{
+			key:      ownerKey{Kind: "DaemonSet", Namespace: ds.Namespace, Name: ds.Name},
+			release:  HelmRelease(ds.Labels, ds.Annotations),
+			app:      ArgoCDApp(ds.Labels, ds.Annotations),
+			except:   exceptionFrom(ds.Annotations, ds.Namespace+"/"+ds.Name),
+			desired:  int(ds.Status.DesiredNumberScheduled),
+			template: ds.Spec.Template.Spec,
+		})
+	}
+
+	for _, c := range controllers {
+		if namespace == "" && !includeSystem && SystemNamespaces[c.key.Namespace] {
+			continue
+		}
+		key := c.key.Namespace + "/" + c.key.Kind + "/" + c.key.Name
+		if w, ok := workloadMap[key]; ok {
+			w.DesiredPods = c.desired
+			w.DesiredKnown = true
+			if c.release != "" {
+				w.Release = c.release
+			}
+			if c.app != "" {
+				w.ArgoCDApp = c.app
+			}
+			if !c.except.IsZero() {
+				w.Exception = c.except
+			}
+			continue
+		}
+		cpu, mem := podSpecRequests(c.template)
+		cpuLimit, memLimit, missingCPULimit, missingMemLimit := podSpecLimits(c.template)
+		workloadMap[key] = &WorkloadInfo{
+			Kind:         c.key.Kind,
+			Namespace:    c.key.Namespace,
+			Name:         c.key.Name,
+			Release:      c.release,
+			ArgoCDApp:    c.app,
+			Exception:    c.except,
+			DesiredPods:  c.desired,
+			DesiredKnown: true,
+			CPURequest:   cpu * int64(c.desired),
+			MemRequest:   mem * float64(c.desired),
+			CPULimit:     cpuLimit * int64(c.desired),
+			MemLimit:     memLimit * float64(c.desired),
+
+			MissingCPULimit: missingCPULimit,
+			MissingMemLimit: missingMemLimit,
+			// No running pods → nothing to measure; keeps them out of the "consumes nothing" ranking.
+			MetricsAvailable: false,
+		}
+	}
+
+	stale.warn("pod")
+
 	result := &FetchWorkloadsResult{MetricsAvailable: metricsAvail}
 	for _, w := range workloadMap {
 		result.Workloads = append(result.Workloads, *w)
@@ -158,10 +343,125 @@ func FetchWorkloads(ctx context.Context, clients *Clients, namespace string, inc
 	return result, nil
 }
 
+// controllerSpec is the subset of a workload controller needed to list it without running pods.
+type controllerSpec struct {
+	key      ownerKey
+	release  string
+	app      string // Argo CD Application
+	except   Exception
+	desired  int
+	template corev1.PodSpec
+}
+
+// desiredReplicas dereferences spec.replicas, which the API server defaults to 1.
+func desiredReplicas(replicas *int32) int {
+	if replicas == nil {
+		return 1
+	}
+	return int(*replicas)
+}
+
+// podSpecRequests sums container CPU (millicores) and memory (MiB) requests of a pod spec,
+// plus its pod overhead, as for running pods.
+func podSpecRequests(spec corev1.PodSpec) (cpu int64, mem float64) {
+	for _, c := range spec.Containers {
+		if q := c.Resources.Requests[corev1.ResourceCPU]; !q.IsZero() {
+			cpu += MillicoresFromQuantity(q)
+		}
+		if q := c.Resources.Requests[corev1.ResourceMemory]; !q.IsZero() {
+			mem += MiBFromQuantity(q)
+		}
+	}
+	cpu += MillicoresFromQuantity(spec.Overhead[corev1.ResourceCPU])
+	mem += MiBFromQuantity(spec.Overhead[corev1.ResourceMemory])
+	return cpu, mem
+}
+
+// podSpecLimits sums the CPU (millicores) and memory (MiB) limits of spec's containers and
+// reports whether any container lacks one.
+func podSpecLimits(spec corev1.PodSpec) (cpu int64, mem float64, missingCPU, missingMem bool) {
+	for _, c := range spec.Containers {
+		cpu += MillicoresFromQuantity(c.Resources.Limits[corev1.ResourceCPU])
+		mem += MiBFromQuantity(c.Resources.Limits[corev1.ResourceMemory])
+		noCPU, noMem := missingLimits(c)
+		missingCPU = missingCPU || noCPU
+		missingMem = missingMem || noMem
+	}
+	return cpu, mem, missingCPU, missingMem
+}
+
+// buildRSToDeployment maps "namespace/replicaset-name" → Deployment ownerKey.
+func buildRSToDeployment(replicaSets *appsv1.ReplicaSetList) map[string]ownerKey {
+	rsToDeployment := make(map[string]ownerKey)
+	for _, rs := range replicaSets.Items {
+		for _, ref := range rs.OwnerReferences {
+			if ref.Kind == "Deployment" {
+				key := rs.Namespace + "/" + rs.Name
+				rsToDeployment[key] = ownerKey{Kind: "Deployment", Namespace: rs.Namespace, Name: ref.Name}
+				break
+			}
+		}
+	}
+	return rsToDeployment
+}
+
+// WorkloadRef names a workload controller, as given to kusa pods --workload.
+type WorkloadRef struct {
+	Kind      string // canonical, e.g. "Deployment"
+	Namespace string
+	Name      string
+}
+
+func (w WorkloadRef) String() string { return w.Kind + "/" + w.Namespace + "/" + w.Name }
+
+// workloadKinds maps the lower-case names of the controllers pods resolve to (see
+// resolveWorkloadOwner) to their canonical kind.
+var workloadKinds = map[string]string{
+	"deployment":  "Deployment",
+	"statefulset": "StatefulSet",
+	"daemonset":   "DaemonSet",
+	"replicaset":  "ReplicaSet",
+	"job":         "Job",
+}
+
+// ParseWorkloadRef parses "Kind/namespace/name", e.g. "deployment/shop/web"; the kind is
+// case-insensitive.
+func ParseWorkloadRef(ref string) (WorkloadRef, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return WorkloadRef{}, fmt.Errorf("invalid workload %q: expected Kind/namespace/name, e.g. deployment/shop/web", ref)
+	}
+	kind, ok := workloadKinds[strings.ToLower(parts[0])]
+	if !ok {
+		return WorkloadRef{}, fmt.Errorf("invalid workload %q: unknown kind %s (want Deployment, StatefulSet, DaemonSet, ReplicaSet or Job)", ref, parts[0])
+	}
+	return WorkloadRef{Kind: kind, Namespace: parts[1], Name: parts[2]}, nil
+}
+
+// WorkloadPods returns the pods whose controller owner reference resolves to w, a
+// Deployment's through its ReplicaSets.
+func WorkloadPods(pods []PodInfo, w WorkloadRef) []PodInfo {
+	var matched []PodInfo
+	for _, p := range pods {
+		if p.WorkloadKind == w.Kind && p.Namespace == w.Namespace && p.WorkloadName == w.Name {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
 // resolveWorkloadOwner walks a pod's ownerReferences to find its top-level controller.
-// Pod → ReplicaSet → Deployment is resolved via rsToDeployment.
+// Pod → ReplicaSet → Deployment is resolved via rsToDeployment. References explicitly
+// marked as not the controller are skipped.
 func resolveWorkloadOwner(pod corev1.Pod, rsToDeployment map[string]ownerKey) ownerKey {
+	// Mirror pods are owned by their Node; the kubelet runs them from a manifest on disk.
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return ownerKey{Kind: "StaticPod", Namespace: pod.Namespace, Name: pod.Name}
+	}
 	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && !*ref.Controller {
+			continue
+		}
 		switch ref.Kind {
 		case "ReplicaSet":
 			rsKey := pod.Namespace + "/" + ref.Name
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// ArgoCDAppsOptions controls truncation in RenderArgoCDApps.
+type ArgoCDAppsOptions struct {
+	Limit            int  // top N applications (0 = all)
+	MetricsAvailable bool // pod metrics were fetched
+}
+
+// RenderArgoCDApps renders requests, usage and waste per Argo CD Application to stdout and
+// saves a markdown file.
+func RenderArgoCDApps(groups []analysis.ArgoCDAppGroup, contextName string, opts ArgoCDAppsOptions) {
+	ts := time.Now()
+
+	var (
+		totalWasteCPU int64
+		totalWasteMem float64
+		apps          int
+		unmanaged     int
+	)
+	for _, g := range groups {
+		totalWasteCPU += g.Metered.CPUWaste()
+		totalWasteMem += g.Metered.MemWaste()
+		if g.App == "" {
+			unmanaged = len(g.Workloads)
+		} else {
+			apps++
+		}
+	}
+	if opts.Limit > 0 && len(groups) > opts.Limit {
+		groups = groups[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("Workloads by Argo CD Application — %s", contextName)
+	headers := append([]string{"#", "Application", "Namespaces", "Workloads"}, totalsHeaders...)
+
+	var rows [][]cellValue
+	for i, g := range groups {
+		rows = append(rows, append([]cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(orNone(g.App)),
+			cv(strings.Join(g.Namespaces, ", ")),
+			cv(fmt.Sprintf("%d", len(g.Workloads))),
+		}, totalsCells(g.WorkloadTotals, opts.MetricsAvailable, totalWasteCPU)...))
+	}
+
+	summary := fmt.Sprintf("%d Argo CD %s; %d %s not managed by Argo CD.",
+		apps, kube.Plural(apps, "Application", "Applications"), unmanaged, kube.Plural(unmanaged, "workload", "workloads"))
+	if opts.MetricsAvailable {
+		summary += fmt.Sprintf(" %s CPU and %s memory requested but unused.", kube.FormatCPU(totalWasteCPU), kube.FormatMem(totalWasteMem))
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	mdContent += "\n\n" + summary
+	if mermaidCharts && opts.MetricsAvailable {
+		waste := make(map[string]float64, len(groups))
+		for _, g := range groups {
+			waste[orNone(g.App)] = float64(g.Metered.CPUWaste())
+		}
+		if chart := mermaidPie("CPU requested but unused by Argo CD Application (millicores)", waste, 10); chart != "" {
+			mdContent += "\n\n" + chart
+		}
+	}
+	saveMarkdownFile("deployments-by-argocd-app", contextName, ts, mdContent)
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// ChargebackOptions describes the report in RenderChargeback.
+type ChargebackOptions struct {
+	Label            string
+	Prices           analysis.Prices
+	MetricsAvailable bool // pod metrics were fetched
+}
+
+// RenderChargeback renders the monthly cost per label value to stdout and saves a markdown file.
+func RenderChargeback(lines []analysis.ChargebackLine, contextName string, opts ChargebackOptions) {
+	ts := time.Now()
+
+	var requested, used, idle float64
+	for _, l := range lines {
+		requested += l.RequestedCost
+		used += l.UsedCost
+		idle += l.IdleCost
+	}
+
+	title := fmt.Sprintf("Monthly Chargeback by %s — %s", opts.Label, contextName)
+	headers := []string{"#", opts.Label, "Namespaces", "Pods", "CPU Req", "Mem Req", "Requested Cost", "Share", "Used Cost", "Idle Cost"}
+
+	var rows [][]cellValue
+	for i, l := range lines {
+		g := l.Group
+		usedCell, idleCell := naCell(), naCell()
+		if opts.MetricsAvailable && g.Metered.Pods > 0 {
+			usedCell = cv(formatCost(l.UsedCost))
+			idleCell = cv(formatCost(l.IdleCost))
+		}
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(orNone(g.Value)),
+			cv(strings.Join(g.Namespaces, ", ")),
+			cv(fmt.Sprintf("%d", g.Pods)),
+			cv(kube.FormatCPU(g.CPURequest)),
+			cv(kube.FormatMem(g.MemRequest)),
+			cv(formatCost(l.RequestedCost)),
+			cv(formatPct(safePctFloat(l.RequestedCost, requested))),
+			usedCell,
+			idleCell,
+		})
+	}
+
+	summary := fmt.Sprintf("Requested capacity costs %s per month at %s per core and %s per GiB.",
+		formatCost(requested), formatCost(opts.Prices.CPUPerCoreMonth), formatCost(opts.Prices.MemPerGiBMonth))
+	if opts.MetricsAvailable {
+		summary += fmt.Sprintf(" Actual usage accounts for %s; %s is idle.", formatCost(used), formatCost(idle))
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	saveMarkdownFile("chargeback", contextName, ts, mdContent+"\n\n"+summary)
+}
+
+// WriteChargebackCSV writes one row per label value with raw numbers (millicores, MiB and
+// monthly costs), for spreadsheets and FinOps tooling.
+func WriteChargebackCSV(w io.Writer, lines []analysis.ChargebackLine, label string, metricsAvailable bool) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{label, "namespaces", "pods", "cpu_request_millicores", "mem_request_mib",
+		"cpu_actual_millicores", "mem_actual_mib", "requested_cost", "used_cost", "idle_cost"})
+	for _, l := range lines {
+		g := l.Group
+		actualCPU, actualMem, used, idle := "", "", "", ""
+		if metricsAvailable && g.Metered.Pods > 0 {
+			actualCPU = fmt.Sprintf("%d", g.Metered.CPUActual)
+			actualMem = fmt.Sprintf("%.0f", g.Metered.MemActual)
+			used = fmt.Sprintf("%.2f", l.UsedCost)
+			idle = fmt.Sprintf("%.2f", l.IdleCost)
+		}
+		_ = cw.Write([]string{
+			g.Value,
+			strings.Join(g.Namespaces, " "),
+			fmt.Sprintf("%d", g.Pods),
+			fmt.Sprintf("%d", g.CPURequest),
+			fmt.Sprintf("%.0f", g.MemRequest),
+			actualCPU,
+			actualMem,
+			fmt.Sprintf("%.2f", l.RequestedCost),
+			used,
+			idle,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCost(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// RenderCheck renders current efficiency against a baseline to stdout and saves a markdown file.
+// Only the efficiency rows are gated; requests and waste are shown for context.
+func RenderCheck(baseline analysis.Baseline, current analysis.Efficiency, regressions []analysis.Regression, contextName string, tolerance float64) {
+	ts := time.Now()
+
+	title := fmt.Sprintf("Efficiency vs Baseline (%s) — %s", baseline.CreatedAt.UTC().Format("2006-01-02"), contextName)
+	headers := []string{"Metric", "Baseline", "Current", "Change", "Status"}
+
+	regressed := make(map[string]bool)
+	for _, r := range regressions {
+		regressed[r.Metric] = true
+	}
+	status := func(metric string) cellValue {
+		if regressed[metric] {
+			return cvColored("Regressed", text.Colors{text.FgRed})
+		}
+		return cvColored("OK", text.Colors{text.FgGreen})
+	}
+
+	b := baseline.Efficiency
+	rows := [][]cellValue{
+		{
+			cv("CPU efficiency"),
+			cv(formatPctFine(b.CPUEfficiency())),
+			cv(formatPctFine(current.CPUEfficiency())),
+			cv(fmt.Sprintf("%+.1f pp", current.CPUEfficiency()-b.CPUEfficiency())),
+			status("CPU efficiency"),
+		},
+		{
+			cv("Memory efficiency"),
+			cv(formatPctFine(b.MemEfficiency())),
+			cv(formatPctFine(current.MemEfficiency())),
+			cv(fmt.Sprintf("%+.1f pp", current.MemEfficiency()-b.MemEfficiency())),
+			status("Memory efficiency"),
+		},
+		{cv("CPU requested"), cv(kube.FormatCPU(b.CPURequested)), cv(kube.FormatCPU(current.CPURequested)), cv("-"), cv("-")},
+		{cv("CPU waste"), cv(kube.FormatCPU(b.CPUWaste())), cv(kube.FormatCPU(current.CPUWaste())), cv("-"), cv("-")},
+		{cv("Memory requested"), cv(kube.FormatMem(b.MemRequested)), cv(kube.FormatMem(current.MemRequested)), cv("-"), cv("-")},
+		{cv("Memory waste"), cv(kube.FormatMem(b.MemWaste())), cv(kube.FormatMem(current.MemWaste())), cv("-"), cv("-")},
+		{cv("Pods measured"), cv(fmt.Sprintf("%d", b.Pods)), cv(fmt.Sprintf("%d", current.Pods)), cv("-"), cv("-")},
+	}
+
+	summary := fmt.Sprintf("No efficiency regression beyond %.1f percentage points.", tolerance)
+	if len(regressions) > 0 {
+		summary = fmt.Sprintf("%d %s beyond the %.1f percentage point tolerance.",
+			len(regressions), kube.Plural(len(regressions), "metric regressed", "metrics regressed"), tolerance)
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	saveMarkdownFile("check", contextName, ts, mdContent+"\n\n"+summary)
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+type containerRow struct {
+	pod          kube.PodInfo
+	c            kube.ContainerInfo
+	metricsAvail bool
+}
+
+// containerScore returns the severity score of a container, or -1 without metrics so such
+// rows sort last.
+func containerScore(c kube.ContainerInfo, metricsAvail bool) int {
+	if !metricsAvail {
+		return -1
+	}
+	return analysis.SeverityScore(c.CPURequest, c.CPUActual, c.MemRequest, c.MemActual, 1)
+}
+
+// renderPodContainers renders one row per container of pods to stdout and saves a markdown
+// file. The over-request, missing-limits and DaemonSet filters of opts apply per container,
+// so a sidecar wasting its requests shows up even when its pod looks reasonable in aggregate.
+func renderPodContainers(pods []kube.PodInfo, metricsAvailable bool, contextName string, opts PodsOptions) {
+	ts := time.Now()
+
+	entries := filterContainers(pods, metricsAvailable, opts)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].c.CPURequest > entries[j].c.CPURequest
+	})
+	if opts.SortByScore {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return containerScore(entries[i].c, entries[i].metricsAvail) > containerScore(entries[j].c, entries[j].metricsAvail)
+		})
+	}
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("Top Containers — %s", contextName)
+	headers := []string{"#", "Namespace", "Pod", "Container", "CPU Req", "CPU Actual", "CPU Waste", "Over-req", "Score", "CPU Verdict", "Mem Req", "Mem Actual", "Mem Waste", "Mem Verdict"}
+
+	var rows [][]cellValue
+	for i, e := range entries {
+		c := e.c
+		cpuActualCell, memActualCell := naCell(), naCell()
+		if e.metricsAvail {
+			cpuActualCell = actualCell(kube.FormatCPU(c.CPUActual), e.pod.MetricsStale)
+			memActualCell = actualCell(kube.FormatMem(c.MemActual), e.pod.MetricsStale)
+		}
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(e.pod.Namespace),
+			podNameCell(e.pod),
+			cv(c.Name),
+			cv(kube.FormatCPU(c.CPURequest)),
+			cpuActualCell,
+			cpuWasteCell(c.CPURequest, c.CPUActual, e.metricsAvail),
+			cvColored(kube.FormatFactor(c.CPURequest, c.CPUActual), analysis.FactorColors(c.CPURequest, c.CPUActual)),
+			scoreCell(containerScore(c, e.metricsAvail), e.metricsAvail),
+			verdictFromRatio(float64(c.CPURequest), float64(c.CPUActual), e.metricsAvail),
+			cv(kube.FormatMem(c.MemRequest)),
+			memActualCell,
+			memWasteCell(c.MemRequest, c.MemActual, e.metricsAvail),
+			verdictFromRatio(c.MemRequest, c.MemActual, e.metricsAvail),
+		})
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	saveMarkdownFile("containers", contextName, ts, mdContent)
+}
+
+// filterContainers flattens pods into container rows, keeping those that pass the
+// DaemonSet, over-request factor and missing-limits filters of opts.
+func filterContainers(pods []kube.PodInfo, metricsAvailable bool, opts PodsOptions) []containerRow {
+	var entries []containerRow
+	for _, p := range pods {
+		if opts.ExcludeDaemonSets && p.WorkloadKind == "DaemonSet" {
+			continue
+		}
+		for _, c := range p.Containers {
+			metricsAvail := metricsAvailable && c.MetricsAvailable
+			if meetsFactorFilter(c.CPURequest, c.CPUActual, metricsAvail, opts.MinFactor) &&
+				meetsNoLimitsFilter(c.CPULimit == 0, c.MemLimit == 0, opts.NoLimits) {
+				entries = append(entries, containerRow{pod: p, c: c, metricsAvail: metricsAvail})
+			}
+		}
+	}
+	return entries
+}
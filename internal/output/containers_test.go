@@ -0,0 +1,50 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestFilterContainers(t *testing.T) {
+	// The pod as a whole is requested about 2x its usage, but its sidecar is 200x.
+	pods := []kube.PodInfo{
+		{Namespace: "shop", Name: "cart-1", WorkloadKind: "Deployment", Containers: []kube.ContainerInfo{
+			{Name: "app", CPURequest: 500, CPULimit: 1000, MemLimit: 512, CPUActual: 400, MetricsAvailable: true},
+			{Name: "log-shipper", CPURequest: 1000, CPUActual: 5, MetricsAvailable: true},
+		}},
+		{Namespace: "monitoring", Name: "exporter-x", WorkloadKind: "DaemonSet", Containers: []kube.ContainerInfo{
+			{Name: "exporter", CPURequest: 1000, CPUActual: 1, MetricsAvailable: true},
+		}},
+	}
+
+	tests := []struct {
+		name             string
+		metricsAvailable bool
+		opts             PodsOptions
+		want             []string
+	}{
+		{"no filter", true, PodsOptions{}, []string{"app", "log-shipper", "exporter"}},
+		{"factor applies per container", true, PodsOptions{MinFactor: 50}, []string{"log-shipper", "exporter"}},
+		{"daemonsets excluded", true, PodsOptions{MinFactor: 50, ExcludeDaemonSets: true}, []string{"log-shipper"}},
+		{"no metrics drops factor matches", false, PodsOptions{MinFactor: 50}, nil},
+		{"no limits applies per container", true, PodsOptions{NoLimits: "any"}, []string{"log-shipper", "exporter"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, e := range filterContainers(pods, tc.metricsAvailable, tc.opts) {
+				got = append(got, e.c.Name)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("got %v, want %v", got, tc.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// renderNodesDaemonSets renders the fixed per-node overhead of DaemonSet pods: what every
+// node pays before any workload is scheduled, which matters most on small nodes.
+func renderNodesDaemonSets(result *kube.FetchNodesResult, contextName string) string {
+	title := fmt.Sprintf("DaemonSet Overhead — %s", contextName)
+	headers := []string{"Node", "DaemonSet Pods", "CPU Req", "CPU Actual", "Mem Req", "Mem Actual"}
+
+	type daemonSet struct {
+		key  string
+		pods int
+		cpu  int64
+		mem  float64
+	}
+	byKey := make(map[string]*daemonSet)
+
+	var rows [][]cellValue
+	var totalCPU, allocCPU int64
+	var totalMem, allocMem float64
+	for _, node := range result.Nodes {
+		var (
+			pods             int
+			cpuReq, cpuAct   int64
+			memReq, memAct   float64
+			metricsAvailable = result.PodMetricsAvailable
+		)
+		for _, p := range node.Pods {
+			if p.WorkloadKind != "DaemonSet" {
+				continue
+			}
+			pods++
+			cpuReq += p.CPURequest
+			memReq += p.MemRequest
+			cpuAct += p.CPUActual
+			memAct += p.MemActual
+			metricsAvailable = metricsAvailable && p.MetricsAvailable
+
+			key := p.Namespace + "/" + p.WorkloadName
+			ds, ok := byKey[key]
+			if !ok {
+				ds = &daemonSet{key: key}
+				byKey[key] = ds
+			}
+			ds.pods++
+			ds.cpu += p.CPURequest
+			ds.mem += p.MemRequest
+		}
+		totalCPU += cpuReq
+		totalMem += memReq
+		allocCPU += node.AllocatableCPU
+		allocMem += node.AllocatableMem
+
+		cpuActCell, memActCell := naCell(), naCell()
+		if metricsAvailable && pods > 0 {
+			cpuActCell = cv(kube.FormatCPU(cpuAct))
+			memActCell = cv(kube.FormatMem(memAct))
+		}
+		rows = append(rows, []cellValue{
+			cv(node.Name),
+			cv(fmt.Sprintf("%d", pods)),
+			cv(fmt.Sprintf("%s (%s)", formatPct(safePctInt(cpuReq, node.AllocatableCPU)), kube.FormatCPU(cpuReq))),
+			cpuActCell,
+			cv(fmt.Sprintf("%s (%s)", formatPct(safePctFloat(memReq, node.AllocatableMem)), kube.FormatMem(memReq))),
+			memActCell,
+		})
+	}
+
+	md := renderTable(title, headers, rows)
+
+	footer := fmt.Sprintf("DaemonSets request %s CPU (%s) and %s memory (%s) of allocatable cluster-wide.",
+		kube.FormatCPU(totalCPU), formatPct(safePctInt(totalCPU, allocCPU)), kube.FormatMem(totalMem), formatPct(safePctFloat(totalMem, allocMem)))
+	if len(byKey) > 0 {
+		sets := make([]*daemonSet, 0, len(byKey))
+		for _, ds := range byKey {
+			sets = append(sets, ds)
+		}
+		sort.Slice(sets, func(i, j int) bool {
+			if sets[i].cpu != sets[j].cpu {
+				return sets[i].cpu > sets[j].cpu
+			}
+			return sets[i].key < sets[j].key
+		})
+		top := sets[0]
+		footer += fmt.Sprintf(" Largest: %s with %s CPU / %s memory per node.",
+			top.key, kube.FormatCPU(top.cpu/int64(top.pods)), kube.FormatMem(top.mem/float64(top.pods)))
+	}
+	fmt.Println(footer)
+	return md + "\n\n" + footer
+}
@@ -0,0 +1,146 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// RenderDescribeWorkload renders one workload to stdout and saves a markdown file: the
+// resources its controller specifies per container, next to the peak usage across its pods,
+// then every pod container by container with placement, QoS, usage, verdicts and restarts.
+// spec is nil for workloads without a controller kusa reads (standalone pods, Jobs).
+func RenderDescribeWorkload(spec *kube.WorkloadSpec, pods []kube.PodInfo, metricsAvailable bool, ref, contextName string) {
+	ts := time.Now()
+
+	var sections []string
+	if spec != nil {
+		ref = fmt.Sprintf("%s/%s/%s", spec.Namespace, spec.Kind, spec.Name)
+		fmt.Println()
+		sections = append(sections, renderWorkloadSpec(spec, pods, metricsAvailable, ref))
+	}
+
+	title := fmt.Sprintf("Pods — %s", ref)
+	headers := []string{"Pod", "Node", "QoS", "Container", "CPU Req", "CPU Limit", "CPU Actual", "CPU Verdict", "Mem Req", "Mem Limit", "Mem Actual", "Mem Verdict", "Restarts"}
+
+	var rows [][]cellValue
+	drifted := 0
+	for _, p := range pods {
+		podDrifted := false
+		for _, c := range p.Containers {
+			metricsAvail := metricsAvailable && c.MetricsAvailable
+			cpuActual, memActual := naCell(), naCell()
+			if metricsAvail {
+				cpuActual = actualCell(kube.FormatCPU(c.CPUActual), p.MetricsStale)
+				memActual = actualCell(kube.FormatMem(c.MemActual), p.MetricsStale)
+			}
+			name := cv(c.Name)
+			if spec != nil && differsFromSpec(c, spec) {
+				name = cvColored(c.Name+" (differs from spec)", text.Colors{text.FgYellow})
+				podDrifted = true
+			}
+			restarts := cv(fmt.Sprintf("%d", c.Restarts))
+			if c.Restarts > 0 {
+				restarts = cvColored(restarts.text, text.Colors{text.FgYellow})
+			}
+			if !c.LastOOMKilled.IsZero() {
+				restarts = cvColored(fmt.Sprintf("%d (OOMKilled %s ago)", c.Restarts, duration.HumanDuration(ts.Sub(c.LastOOMKilled))), text.Colors{text.FgRed})
+			}
+			rows = append(rows, []cellValue{
+				podNameCell(p),
+				cv(p.NodeName),
+				cv(p.QOSClass),
+				name,
+				cv(kube.FormatCPU(c.CPURequest)),
+				cv(cpuOrNone(c.CPULimit)),
+				cpuActual,
+				verdictFromRatio(float64(c.CPURequest), float64(c.CPUActual), metricsAvail),
+				cv(kube.FormatMem(c.MemRequest)),
+				cv(memOrNone(c.MemLimit)),
+				memActual,
+				verdictFromRatio(c.MemRequest, c.MemActual, metricsAvail),
+				restarts,
+			})
+		}
+		if podDrifted {
+			drifted++
+		}
+	}
+
+	if len(rows) > 0 {
+		fmt.Println()
+		sections = append(sections, renderTable(title, headers, rows))
+	}
+	if note := describeNote(spec, len(pods), drifted); note != "" {
+		fmt.Println(note)
+		sections = append(sections, note)
+	}
+	saveMarkdownFile("describe-workload", contextName, ts, strings.Join(sections, "\n\n"))
+}
+
+// renderWorkloadSpec renders the requests and limits of the controller's pod template per
+// container, with the highest usage of that container across the running pods: the numbers
+// a right-sizing change starts from.
+func renderWorkloadSpec(spec *kube.WorkloadSpec, pods []kube.PodInfo, metricsAvailable bool, ref string) string {
+	title := fmt.Sprintf("Spec — %s (%d %s desired)", ref, spec.DesiredPods, kube.Plural(spec.DesiredPods, "pod", "pods"))
+	headers := []string{"Container", "CPU Req", "CPU Limit", "Peak CPU", "Mem Req", "Mem Limit", "Peak Mem"}
+
+	var rows [][]cellValue
+	for _, c := range spec.Containers {
+		var peakCPU int64
+		var peakMem float64
+		measured := false
+		for _, p := range pods {
+			for _, pc := range p.Containers {
+				if pc.Name == c.Name && metricsAvailable && pc.MetricsAvailable {
+					measured = true
+					peakCPU, peakMem = max(peakCPU, pc.CPUActual), max(peakMem, pc.MemActual)
+				}
+			}
+		}
+		cpuCell, memCell := naCell(), naCell()
+		if measured {
+			cpuCell, memCell = cv(kube.FormatCPU(peakCPU)), cv(kube.FormatMem(peakMem))
+		}
+		rows = append(rows, []cellValue{
+			cv(c.Name),
+			cv(cpuOrNone(c.CPURequest)),
+			cv(cpuOrNone(c.CPULimit)),
+			cpuCell,
+			cv(memOrNone(c.MemRequest)),
+			cv(memOrNone(c.MemLimit)),
+			memCell,
+		})
+	}
+	return renderTable(title, headers, rows)
+}
+
+// differsFromSpec reports whether a running container's requests or limits differ from the
+// same container in the controller's template.
+func differsFromSpec(c kube.ContainerInfo, spec *kube.WorkloadSpec) bool {
+	for _, sc := range spec.Containers {
+		if sc.Name == c.Name {
+			return sc.CPURequest != c.CPURequest || sc.CPULimit != c.CPULimit || sc.MemRequest != c.MemRequest || sc.MemLimit != c.MemLimit
+		}
+	}
+	return true
+}
+
+// describeNote explains pods whose resources differ from the spec and workloads without a
+// controller kusa reads.
+func describeNote(spec *kube.WorkloadSpec, pods, drifted int) string {
+	switch {
+	case spec == nil:
+		return "No Deployment, StatefulSet or DaemonSet of that name: only its running pods are shown."
+	case pods == 0:
+		return "No running pods: only the spec is shown."
+	case drifted > 0:
+		return fmt.Sprintf("%d of %d %s run resources that differ from the spec: a rollout is in progress, or "+
+			"a VPA or admission webhook changed them.", drifted, pods, kube.Plural(pods, "pod", "pods"))
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// RenderDrainCheck renders a drain simulation (pods to move and blocking PDBs) to stdout
+// and saves a markdown file.
+func RenderDrainCheck(check analysis.DrainCheck, contextName string) {
+	ts := time.Now()
+
+	title := fmt.Sprintf("Drain Check: %s — %s", check.Node, contextName)
+	headers := []string{"#", "Namespace", "Pod", "Workload", "CPU Req", "Mem Req", "Moves to"}
+
+	var rows [][]cellValue
+	targets := make(map[string]bool)
+	for i, m := range check.Moves {
+		targetCell := cv(m.Target)
+		if m.Target == "" {
+			targetCell = cvColored("no room", text.Colors{text.FgRed})
+		} else {
+			targets[m.Target] = true
+		}
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(m.Namespace),
+			cv(m.Pod),
+			cv(m.Kind + "/" + m.Workload),
+			cv(kube.FormatCPU(m.CPU)),
+			cv(kube.FormatMem(m.Mem)),
+			targetCell,
+		})
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+
+	if len(check.PDBs) > 0 {
+		pdbHeaders := []string{"Namespace", "PodDisruptionBudget", "Pods on node", "Disruptions allowed", "Impact"}
+		var pdbRows [][]cellValue
+		for _, p := range check.PDBs {
+			pdbRows = append(pdbRows, []cellValue{
+				cv(p.Namespace),
+				cv(p.Name),
+				cv(fmt.Sprintf("%d", p.Pods)),
+				cv(fmt.Sprintf("%d", p.DisruptionsAllowed)),
+				cvColored(p.Verdict.Label, text.Colors{p.Verdict.Color}),
+			})
+		}
+		fmt.Println()
+		mdContent += "\n\n" + renderTable(fmt.Sprintf("PodDisruptionBudgets: %s", check.Node), pdbHeaders, pdbRows)
+	}
+
+	summary := drainSummary(check, len(targets))
+	fmt.Println(summary)
+	saveMarkdownFile("drain-check", contextName, ts, mdContent+"\n\n"+summary)
+}
+
+func drainSummary(check analysis.DrainCheck, targetNodes int) string {
+	placed := len(check.Moves) - check.Unplaced
+	moved := fmt.Sprintf("%d %s move to %d %s; %d DaemonSet/mirror %s.",
+		placed, kube.Plural(placed, "pod", "pods"), targetNodes, kube.Plural(targetNodes, "node", "nodes"),
+		check.Pinned, kube.Plural(check.Pinned, "pod stays", "pods stay"))
+	if check.Feasible() {
+		return fmt.Sprintf("Drain of %s is feasible: %s", check.Node, moved)
+	}
+
+	var reasons []string
+	if check.Unplaced > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d %s no room on the remaining nodes", check.Unplaced, kube.Plural(check.Unplaced, "pod has", "pods have")))
+	}
+	blocking := 0
+	for _, p := range check.PDBs {
+		if p.Verdict == analysis.VerdictPDBBlocks {
+			blocking++
+		}
+	}
+	if blocking > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d %s no disruption", blocking, kube.Plural(blocking, "PodDisruptionBudget allows", "PodDisruptionBudgets allow")))
+	}
+	return fmt.Sprintf("Drain of %s is NOT feasible: %s. %s", check.Node, strings.Join(reasons, ", "), moved)
+}
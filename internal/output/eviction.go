@@ -0,0 +1,99 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// EvictionOptions controls filtering and truncation in RenderEviction.
+type EvictionOptions struct {
+	Namespace     string // "" = all namespaces
+	IncludeSystem bool
+	Limit         int // top N pods (0 = all)
+}
+
+type evictionRow struct {
+	pod      kube.PodInfo
+	pressure bool
+	verdict  analysis.Verdict
+	reason   string
+}
+
+// evictionRank orders verdicts for sorting: higher = evicted earlier.
+var evictionRank = map[analysis.Verdict]int{
+	analysis.VerdictEvictionHigh:   2,
+	analysis.VerdictEvictionMedium: 1,
+	analysis.VerdictEvictionLow:    0,
+}
+
+// RenderEviction renders pods ranked by memory eviction risk to stdout and saves a markdown file.
+func RenderEviction(result *kube.FetchNodesResult, contextName string, opts EvictionOptions) {
+	ts := time.Now()
+
+	var entries []evictionRow
+	for _, node := range result.Nodes {
+		for _, pod := range node.Pods {
+			if opts.Namespace != "" && pod.Namespace != opts.Namespace {
+				continue
+			}
+			if opts.Namespace == "" && !opts.IncludeSystem && kube.SystemNamespaces[pod.Namespace] {
+				continue
+			}
+			if !pod.MetricsAvailable {
+				continue
+			}
+			v, reason := analysis.PodEvictionRisk(pod, node.MemoryPressure)
+			entries = append(entries, evictionRow{pod: pod, pressure: node.MemoryPressure, verdict: v, reason: reason})
+		}
+	}
+
+	// Riskiest first; within a level, the pod furthest above its request goes first (as the kubelet does).
+	sort.Slice(entries, func(i, j int) bool {
+		ri, rj := evictionRank[entries[i].verdict], evictionRank[entries[j].verdict]
+		if ri != rj {
+			return ri > rj
+		}
+		return entries[i].pod.MemActual-entries[i].pod.MemRequest > entries[j].pod.MemActual-entries[j].pod.MemRequest
+	})
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("Eviction Risk — %s", contextName)
+	headers := []string{"#", "Namespace", "Pod", "Node", "QoS", "Mem Req", "Mem Limit", "Mem Actual", "Node Pressure", "Risk", "Reason"}
+
+	var rows [][]cellValue
+	for i, e := range entries {
+		memLimitStr := kube.FormatMem(e.pod.MemLimit)
+		if e.pod.MemLimit == 0 {
+			memLimitStr = "-"
+		}
+		pressureCell := cv("-")
+		if e.pressure {
+			pressureCell = cvColored("MemoryPressure", text.Colors{text.FgRed})
+		}
+
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(e.pod.Namespace),
+			podNameCell(e.pod),
+			cv(e.pod.NodeName),
+			cv(e.pod.QOSClass),
+			cv(kube.FormatMem(e.pod.MemRequest)),
+			cv(memLimitStr),
+			cv(kube.FormatMem(e.pod.MemActual)),
+			pressureCell,
+			cvColored(e.verdict.Label, text.Colors{e.verdict.Color}),
+			cv(e.reason),
+		})
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	saveMarkdownFile("eviction", contextName, ts, mdContent)
+}
@@ -0,0 +1,157 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// ExplainOptions describes how the suggestion in RenderExplainPod was computed.
+type ExplainOptions struct {
+	Headroom  float64 // fraction added on top of peak usage (0.3 = +30%)
+	MinFactor float64 // requests are only lowered when at least this multiple of the result
+}
+
+// RenderExplainPod explains in plain language how kusa judges one pod: its requests and
+// limits, the observed usage, the factor and the thresholds behind each verdict, and the
+// change it suggests. The text is markdown, printed as is and saved, for pasting into a
+// ticket to the owning team. rec is the recommendation for the pod alone, nil when there
+// is none.
+func RenderExplainPod(p kube.PodInfo, rec *analysis.Recommendation, metricsAvailable bool, contextName string, opts ExplainOptions) {
+	ts := time.Now()
+	metricsAvail := metricsAvailable && p.MetricsAvailable
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Pod %s/%s — %s\n\n", p.Namespace, p.Name, contextName)
+	fmt.Fprintf(&b, "Owned by %s %s, running on node %s with QoS class %s.", p.WorkloadKind, p.WorkloadName, orNone(p.NodeName), orNone(p.QOSClass))
+	switch {
+	case metricsAvail && !p.MetricsTimestamp.IsZero():
+		fmt.Fprintf(&b, " Usage is the metrics-server sample of %s ago", duration.HumanDuration(ts.Sub(p.MetricsTimestamp)))
+		if p.MetricsStale {
+			b.WriteString(", which is stale")
+		}
+		b.WriteString(".")
+	case !metricsAvail:
+		b.WriteString(" No usage is known for it (no metrics-server sample), so kusa gives no verdict.")
+	}
+	b.WriteString("\n\n")
+
+	var cpuParts, memParts []string
+	for _, c := range p.Containers {
+		cpuParts = append(cpuParts, fmt.Sprintf("%s %s", c.Name, cpuOrNone(c.CPURequest)))
+		memParts = append(memParts, fmt.Sprintf("%s %s", c.Name, memOrNone(c.MemRequest)))
+	}
+	explainResource(&b, "CPU", float64(p.CPURequest), float64(p.CPULimit), float64(p.CPUActual), p.MissingCPULimit, metricsAvail, cpuParts,
+		func(v float64) string { return kube.FormatCPU(int64(v)) })
+	explainResource(&b, "Memory", p.MemRequest, p.MemLimit, p.MemActual, p.MissingMemLimit, metricsAvail, memParts, kube.FormatMem)
+	if metricsAvail && p.MemRequest > 0 {
+		risk, reason := analysis.PodEvictionRisk(p, false)
+		fmt.Fprintf(&b, "Eviction risk when its node runs short of memory: %s (%s).\n\n", risk.Label, reason)
+	}
+
+	b.WriteString("### Suggested change\n\n")
+	b.WriteString(explainSuggestion(p, rec, metricsAvail, opts))
+	b.WriteString("\n")
+
+	md := strings.TrimRight(b.String(), "\n")
+	fmt.Println()
+	fmt.Println(md)
+	saveMarkdownFile("explain-pod", contextName, ts, md)
+}
+
+// explainResource writes the section of one resource: request and limit (limitMissing when
+// some container sets none), usage, the factor between them, and the verdict with the
+// thresholds it was decided by.
+func explainResource(b *strings.Builder, name string, request, limit, actual float64, limitMissing, metricsAvail bool, perContainer []string, format func(float64) string) {
+	fmt.Fprintf(b, "### %s\n\n", name)
+	if request == 0 {
+		fmt.Fprintf(b, "No %s request is set, so the scheduler reserves nothing for the pod and kusa gives no verdict (\"no req\"). "+
+			"Without a request the pod is placed on nodes regardless of their free %s.\n\n", strings.ToLower(name), strings.ToLower(name))
+		return
+	}
+	fmt.Fprintf(b, "Requested: %s", format(request))
+	if len(perContainer) > 1 {
+		fmt.Fprintf(b, " (%s)", strings.Join(perContainer, ", "))
+	}
+	b.WriteString(". Limit: ")
+	switch {
+	case limit == 0:
+		b.WriteString("none, so the pod may use whatever its node has spare.")
+	case limitMissing:
+		fmt.Fprintf(b, "%s, but not on every container: those may use whatever the node has spare.", format(limit))
+	default:
+		fmt.Fprintf(b, "%s.", format(limit))
+	}
+	b.WriteString("\n")
+	if !metricsAvail {
+		b.WriteString("\n")
+		return
+	}
+
+	usedPct := actual / request * 100
+	fmt.Fprintf(b, "Used: %s, %s of the request", format(actual), formatPct(usedPct))
+	if actual > 0 && request >= actual {
+		fmt.Fprintf(b, " (over-request factor %s: request divided by usage, rounded down)", kube.FormatFactor(request, actual))
+	}
+	b.WriteString(".\n\n")
+
+	v := analysis.ResourceVerdict(100, usedPct)
+	fmt.Fprintf(b, "Verdict: **%s**. ", v.Label)
+	switch v {
+	case analysis.VerdictBursting:
+		fmt.Fprintf(b, "Usage exceeds the request by %s; the pod relies on capacity its node has spare and is the first to lose it when the node gets busy.",
+			formatPct(usedPct-100))
+	default:
+		fmt.Fprintf(b, "The request is %.0f percentage points above usage.", 100-usedPct)
+	}
+	fmt.Fprintf(b, " kusa calls a request more than %d points above usage over-requested and more than %d points massively over-requested; "+
+		"usage above the request is bursting.\n\n", analysis.OverRequestedPoints, analysis.MassivelyOverRequestedPoints)
+}
+
+// explainSuggestion describes the right-sizing kusa suggests for the pod, or why there is none.
+func explainSuggestion(p kube.PodInfo, rec *analysis.Recommendation, metricsAvail bool, opts ExplainOptions) string {
+	switch {
+	case p.WorkloadKind == "Pod" || p.WorkloadKind == "StaticPod":
+		return "None: the pod has no controller whose template could be changed. Adjust the manifest it is created from " +
+			"(for a static pod, the file on its node) using the usage above.\n"
+	case !metricsAvail:
+		return "None without usage data. Install metrics-server or retry once the pod has been sampled.\n"
+	case rec == nil:
+		return fmt.Sprintf("None: every request is within %gx of the peak usage plus %.0f%% headroom.\n", opts.MinFactor, opts.Headroom*100)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "In the pod template of %s %s/%s:\n\n", rec.Kind, rec.Namespace, rec.Name)
+	for _, c := range rec.Containers {
+		var changes []string
+		if c.CPUChanged() {
+			changes = append(changes, fmt.Sprintf("CPU request %s → %s", cpuOrNone(c.CPURequest), cpuOrNone(c.NewCPURequest)))
+		}
+		if c.NewCPULimit != c.CPULimit {
+			changes = append(changes, fmt.Sprintf("CPU limit %s → %s", cpuOrNone(c.CPULimit), cpuOrNone(c.NewCPULimit)))
+		}
+		if c.MemChanged() {
+			changes = append(changes, fmt.Sprintf("memory request %s → %s", memOrNone(c.MemRequest), memOrNone(c.NewMemRequest)))
+		}
+		if c.NewMemLimit != c.MemLimit {
+			changes = append(changes, fmt.Sprintf("memory limit %s → %s", memOrNone(c.MemLimit), memOrNone(c.NewMemLimit)))
+		}
+		fmt.Fprintf(&b, "- `%s`: %s\n", c.Name, strings.Join(changes, ", "))
+	}
+	fmt.Fprintf(&b, "\nEach new request is the usage observed plus %.0f%% headroom, rounded up, and a request is only lowered when it is "+
+		"at least %gx that value. This frees %s CPU / %s memory of requests per pod.",
+		opts.Headroom*100, opts.MinFactor, kube.FormatCPU(rec.CPUFreed()), kube.FormatMem(rec.MemFreed()))
+	if rec.Guaranteed {
+		b.WriteString(" The limits follow the requests to keep the pod Guaranteed.")
+	}
+	fmt.Fprintf(&b, " Confidence: %s", rec.Confidence.Label)
+	if len(rec.ConfidenceReasons) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(rec.ConfidenceReasons, ", "))
+	}
+	fmt.Fprintf(&b, ". This is based on one pod; `kusa recommend --namespace %s` takes the peak across all pods of the workload.\n", rec.Namespace)
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestExplainResource(t *testing.T) {
+	tests := []struct {
+		name                   string
+		request, limit, actual float64
+		limitMissing           bool
+		metricsAvail           bool
+		want                   []string
+	}{
+		{name: "no request", actual: 100, metricsAvail: true, want: []string{"No cpu request is set", `"no req"`}},
+		{name: "massively over-requested", request: 1000, actual: 100, metricsAvail: true,
+			want: []string{"Limit: none", "10% of the request", "factor 10x", "**Massively over-requested**", "90 percentage points"}},
+		{name: "bursting", request: 100, limit: 500, actual: 150, metricsAvail: true,
+			want: []string{"Limit: 500m.", "150% of the request", "**Bursting**", "exceeds the request by 50%"}},
+		{name: "partial limit", request: 100, limit: 200, limitMissing: true, actual: 90, metricsAvail: true,
+			want: []string{"not on every container", "**OK**"}},
+		{name: "no metrics", request: 100, want: []string{"Requested: 100m"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			explainResource(&b, "CPU", tt.request, tt.limit, tt.actual, tt.limitMissing, tt.metricsAvail, nil,
+				func(v float64) string { return kube.FormatCPU(int64(v)) })
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("missing %q in:\n%s", want, b.String())
+				}
+			}
+			if !tt.metricsAvail && strings.Contains(b.String(), "Verdict") {
+				t.Errorf("verdict without metrics:\n%s", b.String())
+			}
+		})
+	}
+}
+
+func TestExplainSuggestion(t *testing.T) {
+	rec := &analysis.Recommendation{Namespace: "shop", Kind: "Deployment", Name: "cart", Pods: 1,
+		Confidence: analysis.VerdictConfidenceLow, ConfidenceReasons: []string{"1 sample"},
+		Containers: []analysis.ContainerRecommendation{{Name: "app", CPURequest: 1000, NewCPURequest: 130, MemRequest: 512, NewMemRequest: 512}}}
+	opts := ExplainOptions{Headroom: 0.3, MinFactor: 2}
+
+	tests := []struct {
+		name string
+		pod  kube.PodInfo
+		rec  *analysis.Recommendation
+		want string
+	}{
+		{name: "standalone", pod: kube.PodInfo{WorkloadKind: "Pod"}, rec: rec, want: "no controller"},
+		{name: "right-sized", pod: kube.PodInfo{WorkloadKind: "Deployment"}, want: "within 2x of the peak usage plus 30% headroom"},
+		{name: "change", pod: kube.PodInfo{WorkloadKind: "Deployment"}, rec: rec, want: "- `app`: CPU request 1 → 130m\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := explainSuggestion(tt.pod, tt.rec, true, opts); !strings.Contains(got, tt.want) {
+				t.Errorf("missing %q in:\n%s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/amasotti/kusa/internal/diag"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Output formats accepted by --output. Templates take their expression after "=",
+// e.g. go-template={{.name}} or jsonpath={.items[*].name}, like kubectl.
+const (
+	FormatTable      = "table"
+	FormatNDJSON     = "ndjson"
+	FormatGoTemplate = "go-template"
+	FormatJSONPath   = "jsonpath"
+)
+
+// Formats lists the accepted values of --output, for help and completion.
+var Formats = []string{FormatTable, FormatNDJSON, FormatGoTemplate + "=", FormatJSONPath + "="}
+
+var (
+	format       = FormatTable
+	formatTmpl   *template.Template
+	formatJSONPT *jsonpath.JSONPath
+
+	// pending collects records for the template formats, which see all rows at once.
+	pending []any
+	// pendingSummary is the summary record the template formats see next to the rows.
+	pendingSummary any
+)
+
+// recordsOut receives structured output; tests replace it.
+var recordsOut io.Writer = os.Stdout
+
+// SetFormat parses an --output value and selects how pods, deployments and nodes
+// results are written. Anything but FormatTable replaces the tables (and saved
+// reports) with machine-readable stdout.
+func SetFormat(spec string) error {
+	name, expr, hasExpr := strings.Cut(spec, "=")
+	switch name {
+	case FormatTable, FormatNDJSON:
+		if hasExpr {
+			return fmt.Errorf("--output %s takes no expression", name)
+		}
+	case FormatGoTemplate:
+		if expr == "" {
+			return fmt.Errorf("--output go-template needs a template, e.g. go-template='{{range .items}}{{.name}}{{\"\\n\"}}{{end}}'")
+		}
+		t, err := template.New("output").Option("missingkey=zero").Parse(expr)
+		if err != nil {
+			return fmt.Errorf("invalid go-template: %w", err)
+		}
+		formatTmpl = t
+	case FormatJSONPath:
+		if expr == "" {
+			return fmt.Errorf("--output jsonpath needs an expression, e.g. jsonpath='{.items[*].name}'")
+		}
+		if !strings.Contains(expr, "{") {
+			expr = "{" + expr + "}"
+		}
+		jp := jsonpath.New("output").AllowMissingKeys(true)
+		if err := jp.Parse(expr); err != nil {
+			return fmt.Errorf("invalid jsonpath: %w", err)
+		}
+		formatJSONPT = jp
+	default:
+		return fmt.Errorf("invalid --output %q: must be one of %s", spec, strings.Join(Formats, ", "))
+	}
+	format = name
+	return nil
+}
+
+// structured reports whether results are written machine-readable instead of as tables.
+func structured() bool { return format != FormatTable }
+
+// emitRecord writes one record. NDJSON writes it right away as a single line, so
+// consumers see rows as they are produced; templates collect it for flushRecords.
+func emitRecord(v any) {
+	if format != FormatNDJSON {
+		pending = append(pending, v)
+		return
+	}
+	if err := json.NewEncoder(recordsOut).Encode(v); err != nil {
+		diag.Warnf("failed to write record: %v", err)
+	}
+}
+
+// emitSummary writes the summary record of the rows emitted before it: the last line of
+// NDJSON, "summary" next to "items" for templates.
+func emitSummary(v any) {
+	if format == FormatNDJSON {
+		emitRecord(v)
+		return
+	}
+	pendingSummary = v
+}
+
+// flushRecords executes a go-template or jsonpath --output over the collected records,
+// wrapped like a kubectl list: {"kind": "List", "items": [...]}, with the summary record
+// under "summary" when there is one. Field names are the JSON names of the records. An
+// error executing the template fails the command, as in kubectl.
+func flushRecords() error {
+	records, summary := pending, pendingSummary
+	pending, pendingSummary = nil, nil
+	if format != FormatGoTemplate && format != FormatJSONPath {
+		return nil
+	}
+
+	// Round-trip through JSON so templates address records by their JSON field names.
+	if records == nil {
+		records = []any{}
+	}
+	list := map[string]any{"kind": "List", "items": records}
+	if summary != nil {
+		list["summary"] = summary
+	}
+	raw, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if format == FormatGoTemplate {
+		err = formatTmpl.Execute(&buf, data)
+	} else {
+		err = formatJSONPT.Execute(&buf, data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute %s: %w", format, err)
+	}
+	_, err = recordsOut.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,180 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultHelmValuePath is where charts conventionally take the resources of their main
+// container from.
+const DefaultHelmValuePath = "resources"
+
+// HelmOverride holds the recommended resources of the workloads of one Helm release as
+// chart values.
+type HelmOverride struct {
+	Namespace string
+	Release   string
+	Workloads []analysis.Recommendation
+
+	values map[string]helmQuantity // dotted values key → quantity, e.g. "resources.requests.cpu"
+}
+
+// helmQuantity is a CPU (millicores) or memory (MiB) value.
+type helmQuantity struct {
+	cpu int64
+	mem float64
+}
+
+// HelmOverrides groups the recommendations of Helm-released workloads by release and maps
+// their changed requests and limits to values keys: valuePath, a dotted path to the
+// resources block whose placeholders {workload} and {container} are replaced by the
+// workload name without the "<release>-" prefix and the container name, gets requests and
+// limits below it. Recommendations without a release are left out.
+//
+// When workloads or containers of a release map to the same key with different values,
+// the larger one is kept and the key is returned in conflicts: add a placeholder to
+// valuePath to tell them apart. Every container of the release's pods counts, recommended
+// or not, as the key would change it too.
+func HelmOverrides(recs []analysis.Recommendation, pods []kube.PodInfo, valuePath string) (overrides []HelmOverride, conflicts []string) {
+	byRelease := make(map[string]*HelmOverride)
+	var keys []string
+	seen := make(map[string]helmQuantity) // release/key → value of every container mapped to it
+	type conflict struct {
+		o   *HelmOverride
+		key string
+	}
+	var (
+		differing  []conflict
+		conflicted = make(map[string]bool)
+	)
+	set := func(o *HelmOverride, base, field string, v helmQuantity, changed bool) {
+		if !changed && v == (helmQuantity{}) {
+			return // unset and left unset
+		}
+		release, k := o.Namespace+"/"+o.Release, base+"."+field
+		prev, ok := seen[release+"/"+k]
+		if ok && prev != v {
+			if !conflicted[release+"/"+k] {
+				conflicted[release+"/"+k] = true
+				differing = append(differing, conflict{o, k})
+			}
+			v = helmQuantity{cpu: max(prev.cpu, v.cpu), mem: max(prev.mem, v.mem)}
+		}
+		seen[release+"/"+k] = v
+		if _, ok := o.values[k]; ok || changed {
+			o.values[k] = v
+		}
+	}
+	replacer := func(name, release, container string) *strings.Replacer {
+		return strings.NewReplacer("{workload}", strings.TrimPrefix(name, release+"-"), "{container}", container)
+	}
+
+	recommended := make(map[string]bool) // namespace/kind/name/container
+	for _, r := range recs {
+		if r.Release == "" {
+			continue
+		}
+		key := r.Namespace + "/" + r.Release
+		o, ok := byRelease[key]
+		if !ok {
+			o = &HelmOverride{Namespace: r.Namespace, Release: r.Release, values: make(map[string]helmQuantity)}
+			byRelease[key] = o
+			keys = append(keys, key)
+		}
+		o.Workloads = append(o.Workloads, r)
+
+		for _, c := range r.Containers {
+			recommended[r.Namespace+"/"+r.Kind+"/"+r.Name+"/"+c.Name] = true
+			base := replacer(r.Name, r.Release, c.Name).Replace(valuePath)
+			set(o, base, "requests.cpu", helmQuantity{cpu: c.NewCPURequest}, c.CPUChanged())
+			set(o, base, "requests.memory", helmQuantity{mem: c.NewMemRequest}, c.MemChanged())
+			set(o, base, "limits.cpu", helmQuantity{cpu: c.NewCPULimit}, c.NewCPULimit != c.CPULimit)
+			set(o, base, "limits.memory", helmQuantity{mem: c.NewMemLimit}, c.NewMemLimit != c.MemLimit)
+		}
+	}
+
+	// The containers left unchanged keep their current resources, which a shared key
+	// would overwrite.
+	for _, p := range pods {
+		release := kube.HelmRelease(p.Labels, nil)
+		o, ok := byRelease[p.Namespace+"/"+release]
+		if release == "" || !ok {
+			continue
+		}
+		for _, c := range p.Containers {
+			if recommended[p.Namespace+"/"+p.WorkloadKind+"/"+p.WorkloadName+"/"+c.Name] {
+				continue
+			}
+			base := replacer(p.WorkloadName, release, c.Name).Replace(valuePath)
+			set(o, base, "requests.cpu", helmQuantity{cpu: c.CPURequest}, false)
+			set(o, base, "requests.memory", helmQuantity{mem: c.MemRequest}, false)
+			set(o, base, "limits.cpu", helmQuantity{cpu: c.CPULimit}, false)
+			set(o, base, "limits.memory", helmQuantity{mem: c.MemLimit}, false)
+		}
+	}
+
+	// Only the keys the values file sets change anything.
+	for _, c := range differing {
+		if _, ok := c.o.values[c.key]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s/%s: %s", c.o.Namespace, c.o.Release, c.key))
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		overrides = append(overrides, *byRelease[key])
+	}
+	return overrides, conflicts
+}
+
+// HelmValuesName returns the file name of o's values: "<namespace>-<release>-values.yaml".
+func HelmValuesName(o HelmOverride) string {
+	return fmt.Sprintf("%s-%s-values.yaml", o.Namespace, o.Release)
+}
+
+// WriteHelmValues writes o as a values file to pass to helm upgrade with -f, on top of the
+// values the release was installed with. Only the keys that change are set.
+func WriteHelmValues(w io.Writer, o HelmOverride, headroom float64) error {
+	values := make(map[string]any)
+	for k, v := range o.values {
+		path := strings.Split(k, ".")
+		node := values
+		for _, p := range path[:len(path)-1] {
+			child, ok := node[p].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				node[p] = child
+			}
+			node = child
+		}
+		if strings.HasSuffix(k, ".cpu") {
+			node[path[len(path)-1]] = cpuQuantity(v.cpu)
+		} else {
+			node[path[len(path)-1]] = memQuantity(v.mem)
+		}
+	}
+	out, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Right-sizing of Helm release %s in namespace %s generated by kusa recommend\n", o.Release, o.Namespace)
+	fmt.Fprintf(&b, "# (peak usage +%.0f%%); apply with --reuse-values:\n", headroom*100)
+	fmt.Fprintf(&b, "#   helm upgrade %s <chart> -n %s --reuse-values -f %s\n", o.Release, o.Namespace, HelmValuesName(o))
+	for _, r := range o.Workloads {
+		fmt.Fprintf(&b, "# %s/%s: %s confidence", r.Kind, r.Name, r.Confidence.Label)
+		if len(r.ConfidenceReasons) > 0 {
+			b.WriteString(" (" + strings.Join(r.ConfidenceReasons, ", ") + ")")
+		}
+		b.WriteString("\n")
+	}
+	b.Write(out)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
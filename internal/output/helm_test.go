@@ -0,0 +1,139 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"sigs.k8s.io/yaml"
+)
+
+func TestHelmOverrides(t *testing.T) {
+	recs := []analysis.Recommendation{
+		{Namespace: "shop", Kind: "Deployment", Name: "shop-storefront", Release: "shop", Pods: 3,
+			Containers: []analysis.ContainerRecommendation{
+				{Name: "app", CPURequest: 1000, MemRequest: 2048, NewCPURequest: 200, NewMemRequest: 2048},
+				{Name: "proxy", CPURequest: 500, MemRequest: 256, NewCPURequest: 50, NewMemRequest: 64},
+			}},
+		{Namespace: "shop", Kind: "Deployment", Name: "shop-cart", Release: "shop", Pods: 2,
+			Containers: []analysis.ContainerRecommendation{
+				{Name: "app", CPURequest: 500, CPULimit: 500, MemRequest: 1024, MemLimit: 1024,
+					NewCPURequest: 100, NewCPULimit: 100, NewMemRequest: 1024, NewMemLimit: 1024},
+			}},
+		{Namespace: "dev", Kind: "Deployment", Name: "preview", Pods: 1,
+			Containers: []analysis.ContainerRecommendation{{Name: "app", CPURequest: 1000, NewCPURequest: 10}}},
+	}
+
+	helm := func(release string) map[string]string {
+		return map[string]string{"app.kubernetes.io/instance": release, "app.kubernetes.io/managed-by": "Helm"}
+	}
+	pods := []kube.PodInfo{
+		{Namespace: "shop", WorkloadKind: "Deployment", WorkloadName: "shop-storefront", Labels: helm("shop"),
+			Containers: []kube.ContainerInfo{{Name: "app", CPURequest: 1000, MemRequest: 2048}, {Name: "proxy", CPURequest: 500, MemRequest: 256}}},
+		{Namespace: "shop", WorkloadKind: "Deployment", WorkloadName: "shop-cart", Labels: helm("shop"),
+			Containers: []kube.ContainerInfo{
+				{Name: "app", CPURequest: 500, CPULimit: 500, MemRequest: 1024, MemLimit: 1024},
+				{Name: "metrics", CPURequest: 50, MemRequest: 32},
+			}},
+		{Namespace: "shop", WorkloadKind: "Deployment", WorkloadName: "shop-redis", Labels: helm("shop"),
+			Containers: []kube.ContainerInfo{{Name: "redis", CPURequest: 100, MemRequest: 256, MemLimit: 256}}},
+	}
+
+	tests := []struct {
+		name          string
+		valuePath     string
+		wantValues    map[string]helmQuantity
+		wantConflicts []string
+	}{
+		{
+			name:      "per workload and container",
+			valuePath: "{workload}.{container}.resources",
+			wantValues: map[string]helmQuantity{
+				"storefront.app.resources.requests.cpu":      {cpu: 200},
+				"storefront.proxy.resources.requests.cpu":    {cpu: 50},
+				"storefront.proxy.resources.requests.memory": {mem: 64},
+				"cart.app.resources.requests.cpu":            {cpu: 100},
+				"cart.app.resources.limits.cpu":              {cpu: 100},
+			},
+		},
+		{
+			// The memory of proxy changes while that of app does not: both map to
+			// resources.requests.memory, a conflict even though only one value changes.
+			name:      "shared key keeps the larger value",
+			valuePath: "resources",
+			wantValues: map[string]helmQuantity{
+				"resources.requests.cpu":    {cpu: 200},
+				"resources.requests.memory": {mem: 2048},
+				"resources.limits.cpu":      {cpu: 100},
+			},
+			wantConflicts: []string{"shop/shop: resources.requests.cpu", "shop/shop: resources.requests.memory"},
+		},
+		{
+			// The metrics sidecar of cart is not recommended, but shares cart.resources
+			// with its app container; redis shares no key that changes.
+			name:      "unrecommended container sharing a key",
+			valuePath: "{workload}.resources",
+			wantValues: map[string]helmQuantity{
+				"storefront.resources.requests.cpu":    {cpu: 200},
+				"storefront.resources.requests.memory": {mem: 2048},
+				"cart.resources.requests.cpu":          {cpu: 100},
+				"cart.resources.limits.cpu":            {cpu: 100},
+			},
+			wantConflicts: []string{"shop/shop: storefront.resources.requests.cpu", "shop/shop: storefront.resources.requests.memory", "shop/shop: cart.resources.requests.cpu"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides, conflicts := HelmOverrides(recs, pods, tt.valuePath)
+			if len(overrides) != 1 || overrides[0].Release != "shop" || len(overrides[0].Workloads) != 2 {
+				t.Fatalf("overrides = %+v, want the shop release with 2 workloads", overrides)
+			}
+			got := overrides[0].values
+			if len(got) != len(tt.wantValues) {
+				t.Errorf("values = %v, want %v", got, tt.wantValues)
+			}
+			for k, want := range tt.wantValues {
+				if got[k] != want {
+					t.Errorf("%s = %+v, want %+v", k, got[k], want)
+				}
+			}
+			if strings.Join(conflicts, ";") != strings.Join(tt.wantConflicts, ";") {
+				t.Errorf("conflicts = %v, want %v", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestWriteHelmValues(t *testing.T) {
+	o := HelmOverride{
+		Namespace: "data", Release: "db",
+		Workloads: []analysis.Recommendation{{Kind: "StatefulSet", Name: "db-postgresql", Confidence: analysis.VerdictConfidenceHigh}},
+		values: map[string]helmQuantity{
+			"primary.resources.requests.cpu":    {cpu: 710},
+			"primary.resources.requests.memory": {mem: 3072},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteHelmValues(&buf, o, 0.3); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "helm upgrade db <chart> -n data --reuse-values -f data-db-values.yaml") {
+		t.Errorf("values do not say how to apply them:\n%s", buf.String())
+	}
+
+	var values struct {
+		Primary struct {
+			Resources struct {
+				Requests map[string]string `json:"requests"`
+			} `json:"resources"`
+		} `json:"primary"`
+	}
+	if err := yaml.UnmarshalStrict(buf.Bytes(), &values); err != nil {
+		t.Fatalf("values do not nest under primary.resources: %v\n%s", err, buf.String())
+	}
+	if r := values.Primary.Resources.Requests; r["cpu"] != "710m" || r["memory"] != "3Gi" {
+		t.Errorf("requests = %v, want cpu 710m, memory 3Gi", r)
+	}
+}
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// IdleOptions controls what RenderIdle considers idle.
+type IdleOptions struct {
+	Limit           int     // top N workloads (0 = all)
+	MaxCPUPerPod    int64   // millicores; average actual CPU per pod at or below this is idle
+	MaxMemPerPodMiB float64 // average actual memory per pod at or below this is idle (0 = ignore memory)
+}
+
+// RenderIdle renders workloads whose actual usage is ~0, ranked by the capacity they hold,
+// to stdout and saves a markdown file.
+func RenderIdle(result *kube.FetchWorkloadsResult, contextName string, opts IdleOptions) {
+	ts := time.Now()
+
+	var idle []kube.WorkloadInfo
+	for _, w := range result.Workloads {
+		if result.MetricsAvailable && w.MetricsAvailable && isIdle(w, opts.MaxCPUPerPod, opts.MaxMemPerPodMiB) {
+			idle = append(idle, w)
+		}
+	}
+
+	// Rank by held capacity: CPU requests first, memory requests as tie-breaker.
+	sort.Slice(idle, func(i, j int) bool {
+		if idle[i].CPURequest != idle[j].CPURequest {
+			return idle[i].CPURequest > idle[j].CPURequest
+		}
+		return idle[i].MemRequest > idle[j].MemRequest
+	})
+
+	var heldCPU int64
+	var heldMem float64
+	for _, w := range idle {
+		heldCPU += w.CPURequest
+		heldMem += w.MemRequest
+	}
+	total := len(idle)
+	if opts.Limit > 0 && len(idle) > opts.Limit {
+		idle = idle[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("Idle Workloads — %s", contextName)
+	headers := []string{"#", "Kind", "Namespace", "Workload", "Pods", "CPU Req", "CPU Actual", "Mem Req", "Mem Actual"}
+
+	var rows [][]cellValue
+	for i, w := range idle {
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(w.Kind),
+			cv(w.Namespace),
+			cv(w.Name),
+			cv(fmt.Sprintf("%d", w.PodCount)),
+			cv(kube.FormatCPU(w.CPURequest)),
+			cv(kube.FormatCPU(w.CPUActual)),
+			cv(kube.FormatMem(w.MemRequest)),
+			cv(kube.FormatMem(w.MemActual)),
+		})
+	}
+
+	summary := fmt.Sprintf("%d idle workloads hold %s CPU and %s memory in requests.",
+		total, kube.FormatCPU(heldCPU), kube.FormatMem(heldMem))
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	saveMarkdownFile("idle", contextName, ts, mdContent+"\n\n"+summary)
+}
+
+// isIdle reports whether a workload's average per-pod usage is at or below the thresholds.
+// A maxMemMiB of 0 disables the memory check.
+func isIdle(w kube.WorkloadInfo, maxCPU int64, maxMemMiB float64) bool {
+	if w.PodCount == 0 {
+		return false
+	}
+	pods := int64(w.PodCount)
+	if w.CPUActual > maxCPU*pods {
+		return false
+	}
+	if maxMemMiB > 0 && w.MemActual/float64(pods) > maxMemMiB {
+		return false
+	}
+	return true
+}
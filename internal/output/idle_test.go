@@ -0,0 +1,35 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestIsIdle(t *testing.T) {
+	tests := []struct {
+		name      string
+		w         kube.WorkloadInfo
+		maxCPU    int64
+		maxMemMiB float64
+		want      bool
+	}{
+		{"zero usage is idle", kube.WorkloadInfo{PodCount: 1}, 5, 256, true},
+		{"no pods is never idle", kube.WorkloadInfo{PodCount: 0}, 5, 256, false},
+		{"cpu at threshold is idle", kube.WorkloadInfo{PodCount: 1, CPUActual: 5, MemActual: 100}, 5, 256, true},
+		{"cpu above threshold is busy", kube.WorkloadInfo{PodCount: 1, CPUActual: 6, MemActual: 100}, 5, 256, false},
+		{"cpu averaged per pod", kube.WorkloadInfo{PodCount: 4, CPUActual: 16, MemActual: 400}, 5, 256, true},
+		{"cpu average at threshold is idle", kube.WorkloadInfo{PodCount: 2, CPUActual: 2}, 1, 256, true},
+		{"cpu average of a fraction above threshold is busy", kube.WorkloadInfo{PodCount: 2, CPUActual: 3}, 1, 256, false},
+		{"memory above threshold is busy", kube.WorkloadInfo{PodCount: 1, CPUActual: 1, MemActual: 300}, 5, 256, false},
+		{"memory check disabled", kube.WorkloadInfo{PodCount: 1, CPUActual: 1, MemActual: 4096}, 5, 0, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isIdle(tc.w, tc.maxCPU, tc.maxMemMiB); got != tc.want {
+				t.Errorf("isIdle(%+v, %d, %g) = %v, want %v", tc.w, tc.maxCPU, tc.maxMemMiB, got, tc.want)
+			}
+		})
+	}
+}
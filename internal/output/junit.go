@@ -0,0 +1,127 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// JUnitCase is one test case of a JUnit report: a gated rule, or a workload with findings.
+type JUnitCase struct {
+	Classname string // groups cases in CI test views, e.g. the namespace
+	Name      string
+	Failure   string // why the case failed, its first line the message; empty when it passed
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Classname string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes cases as one JUnit XML test suite, the format CI systems (GitLab,
+// Jenkins, GitHub Actions reporters) render in their test report and history views.
+func WriteJUnit(w io.Writer, suite string, cases []JUnitCase) error {
+	s := junitSuite{Name: suite, Tests: len(cases)}
+	for _, c := range cases {
+		tc := junitTestCase{Classname: c.Classname, Name: c.Name}
+		if c.Failure != "" {
+			message, _, _ := strings.Cut(c.Failure, "\n")
+			tc.Failure = &junitFailure{Message: message, Text: c.Failure}
+			s.Failures++
+		}
+		s.Cases = append(s.Cases, tc)
+	}
+	doc := junitSuites{Tests: s.Tests, Failures: s.Failures, Suites: []junitSuite{s}}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// CheckJUnitCases returns one case per efficiency gated by kusa check, failed when it
+// regressed beyond the tolerance.
+func CheckJUnitCases(baseline, current analysis.Efficiency, regressions []analysis.Regression, tolerance float64) []JUnitCase {
+	regressed := make(map[string]bool)
+	for _, r := range regressions {
+		regressed[r.Metric] = true
+	}
+	gated := []struct {
+		metric            string
+		baseline, current float64
+	}{
+		{"CPU efficiency", baseline.CPUEfficiency(), current.CPUEfficiency()},
+		{"Memory efficiency", baseline.MemEfficiency(), current.MemEfficiency()},
+	}
+	var cases []JUnitCase
+	for _, g := range gated {
+		c := JUnitCase{Classname: "efficiency", Name: g.metric}
+		if regressed[g.metric] {
+			c.Failure = fmt.Sprintf("%s dropped from %s to %s (%+.1f pp), beyond the tolerance of %.1f pp",
+				g.metric, formatPctFine(g.baseline), formatPctFine(g.current), g.current-g.baseline, tolerance)
+		}
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// LintJUnitCases returns one failed case per workload with lint findings, listing its
+// containers and what each lacks, or a single passed case when there is none.
+func LintJUnitCases(findings []kube.LintFinding) []JUnitCase {
+	if len(findings) == 0 {
+		return []JUnitCase{{Classname: "lint", Name: "every container sets its requests"}}
+	}
+	var (
+		cases []JUnitCase
+		lines [][]string
+		index = make(map[string]int)
+	)
+	for _, f := range findings {
+		key := f.Namespace + "/" + f.Kind + "/" + f.Workload
+		i, ok := index[key]
+		if !ok {
+			i = len(cases)
+			index[key] = i
+			cases = append(cases, JUnitCase{Classname: f.Namespace, Name: f.Kind + "/" + f.Workload})
+			lines = append(lines, nil)
+		}
+		lines[i] = append(lines[i], fmt.Sprintf("container %s lacks %s", f.Container, missingCell(f).text))
+	}
+	for i := range cases {
+		cases[i].Failure = strings.Join(lines[i], "\n")
+		if len(lines[i]) > 1 {
+			cases[i].Failure = fmt.Sprintf("%d containers lack requests or limits\n", len(lines[i])) + cases[i].Failure
+		}
+	}
+	return cases
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	var b strings.Builder
+	err := WriteJUnit(&b, "kusa lint", []JUnitCase{
+		{Classname: "shop", Name: "Deployment/cart", Failure: "2 containers lack requests or limits\ncontainer cart lacks CPU req\ncontainer log-shipper lacks mem limit"},
+		{Classname: "shop", Name: "Deployment/storefront"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), xml.Header) {
+		t.Errorf("missing XML header: %q", b.String())
+	}
+
+	var got junitSuites
+	if err := xml.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Tests != 2 || got.Failures != 1 || len(got.Suites) != 1 || got.Suites[0].Name != "kusa lint" || got.Suites[0].Failures != 1 {
+		t.Fatalf("got %+v", got)
+	}
+	cases := got.Suites[0].Cases
+	if f := cases[0].Failure; f == nil || f.Message != "2 containers lack requests or limits" || !strings.Contains(f.Text, "log-shipper") {
+		t.Errorf("failure = %+v", f)
+	}
+	if cases[1].Failure != nil {
+		t.Errorf("passed case has failure %+v", cases[1].Failure)
+	}
+}
+
+func TestCheckJUnitCases(t *testing.T) {
+	baseline := analysis.Efficiency{CPURequested: 1000, CPUActual: 500, MemRequested: 1000, MemActual: 500}
+	current := analysis.Efficiency{CPURequested: 1000, CPUActual: 300, MemRequested: 1000, MemActual: 480}
+	cases := CheckJUnitCases(baseline, current, analysis.CompareEfficiency(baseline, current, 5), 5)
+	if len(cases) != 2 {
+		t.Fatalf("got %d cases, want 2", len(cases))
+	}
+	if want := "CPU efficiency dropped from 50.0% to 30.0% (-20.0 pp), beyond the tolerance of 5.0 pp"; cases[0].Failure != want {
+		t.Errorf("CPU failure = %q, want %q", cases[0].Failure, want)
+	}
+	if cases[1].Failure != "" {
+		t.Errorf("memory within tolerance failed: %q", cases[1].Failure)
+	}
+}
+
+func TestLintJUnitCases(t *testing.T) {
+	if cases := LintJUnitCases(nil); len(cases) != 1 || cases[0].Failure != "" {
+		t.Errorf("no findings: got %+v, want one passed case", cases)
+	}
+
+	cases := LintJUnitCases([]kube.LintFinding{
+		{Namespace: "shop", Kind: "Deployment", Workload: "cart", Container: "cart", MissingCPU: true},
+		{Namespace: "data", Kind: "StatefulSet", Workload: "postgres", Container: "postgres", MissingMemLimit: true},
+		{Namespace: "shop", Kind: "Deployment", Workload: "cart", Container: "log-shipper", MissingCPU: true, MissingMem: true},
+	})
+	want := []JUnitCase{
+		{Classname: "shop", Name: "Deployment/cart", Failure: "2 containers lack requests or limits\ncontainer cart lacks CPU req\ncontainer log-shipper lacks CPU req, mem req"},
+		{Classname: "data", Name: "StatefulSet/postgres", Failure: "container postgres lacks mem limit"},
+	}
+	if len(cases) != len(want) {
+		t.Fatalf("got %d cases, want %d", len(cases), len(want))
+	}
+	for i := range want {
+		if cases[i] != want[i] {
+			t.Errorf("case %d = %+v, want %+v", i, cases[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"sigs.k8s.io/yaml"
+)
+
+// patchAPIVersions lists the workload kinds whose pod template a patch can change. Jobs
+// are immutable and a ReplicaSet owned by a Deployment is overwritten by it.
+var patchAPIVersions = map[string]string{
+	"Deployment":  "apps/v1",
+	"StatefulSet": "apps/v1",
+	"DaemonSet":   "apps/v1",
+}
+
+// Patchable reports whether recommendations for kind can be emitted as patches.
+func Patchable(kind string) bool {
+	_, ok := patchAPIVersions[kind]
+	return ok
+}
+
+// KustomizePatchName returns the file name of r's patch: "<namespace>-<kind>-<name>.yaml".
+func KustomizePatchName(r analysis.Recommendation) string {
+	return fmt.Sprintf("%s-%s-%s.yaml", r.Namespace, strings.ToLower(r.Kind), r.Name)
+}
+
+// WriteKustomizePatch writes a strategic-merge patch setting the recommended requests of r's
+// containers, and the limits lowered with them. Values that stay as they are are left out,
+// so the patch only touches what changes.
+func WriteKustomizePatch(w io.Writer, r analysis.Recommendation, headroom float64) error {
+	apiVersion, ok := patchAPIVersions[r.Kind]
+	if !ok {
+		return fmt.Errorf("cannot patch the pod template of a %s", r.Kind)
+	}
+	containers := make([]map[string]any, 0, len(r.Containers))
+	for _, c := range r.Containers {
+		requests, limits := map[string]string{}, map[string]string{}
+		if c.CPUChanged() {
+			requests["cpu"] = cpuQuantity(c.NewCPURequest)
+		}
+		if c.MemChanged() {
+			requests["memory"] = memQuantity(c.NewMemRequest)
+		}
+		if c.NewCPULimit != c.CPULimit {
+			limits["cpu"] = cpuQuantity(c.NewCPULimit)
+		}
+		if c.NewMemLimit != c.MemLimit {
+			limits["memory"] = memQuantity(c.NewMemLimit)
+		}
+		resources := map[string]any{"requests": requests}
+		if len(limits) > 0 {
+			resources["limits"] = limits
+		}
+		containers = append(containers, map[string]any{"name": c.Name, "resources": resources})
+	}
+
+	comment := fmt.Sprintf("%s/%s/%s: peak usage +%.0f%%, %s confidence", r.Namespace, r.Kind, r.Name, headroom*100, r.Confidence.Label)
+	if len(r.ConfidenceReasons) > 0 {
+		comment += " (" + strings.Join(r.ConfidenceReasons, ", ") + ")"
+	}
+	return writeManifests(w, []manifest{{
+		comment: comment,
+		object: map[string]any{
+			"apiVersion": apiVersion,
+			"kind":       r.Kind,
+			"metadata":   map[string]any{"name": r.Name, "namespace": r.Namespace},
+			"spec": map[string]any{
+				"template": map[string]any{
+					"spec": map[string]any{"containers": containers},
+				},
+			},
+		},
+	}})
+}
+
+// WriteKustomization writes a kustomize Component applying the patch files: reference its
+// directory under components in the overlay that renders the workloads.
+func WriteKustomization(w io.Writer, patches []string) error {
+	entries := make([]map[string]string, len(patches))
+	for i, p := range patches {
+		entries[i] = map[string]string{"path": p}
+	}
+	out, err := yaml.Marshal(map[string]any{
+		"apiVersion": "kustomize.config.k8s.io/v1alpha1",
+		"kind":       "Component",
+		"patches":    entries,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "# Right-sizing patches generated by kusa recommend; add this directory to the\n"+
+		"# components of the overlay that renders these workloads.\n"+string(out))
+	return err
+}
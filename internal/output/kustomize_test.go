@@ -0,0 +1,67 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestWriteKustomizePatch(t *testing.T) {
+	r := analysis.Recommendation{
+		Namespace: "data", Kind: "StatefulSet", Name: "db", Pods: 2, Guaranteed: true,
+		Confidence: analysis.VerdictConfidenceMedium, ConfidenceReasons: []string{"4 samples"},
+		Containers: []analysis.ContainerRecommendation{
+			{Name: "db", CPURequest: 2000, CPULimit: 2000, MemRequest: 4096, MemLimit: 4096,
+				NewCPURequest: 710, NewCPULimit: 710, NewMemRequest: 4096, NewMemLimit: 4096},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteKustomizePatch(&buf, r, 0.3); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "# data/StatefulSet/db: peak usage +30%, Medium confidence (4 samples)\n") {
+		t.Errorf("patch does not start with its comment:\n%s", buf.String())
+	}
+
+	var sts appsv1.StatefulSet
+	if err := yaml.UnmarshalStrict(buf.Bytes(), &sts); err != nil {
+		t.Fatalf("patch is not a StatefulSet: %v\n%s", err, buf.String())
+	}
+	if sts.APIVersion != "apps/v1" || sts.Namespace != "data" || sts.Name != "db" {
+		t.Errorf("patch targets %s %s/%s", sts.APIVersion, sts.Namespace, sts.Name)
+	}
+	c := sts.Spec.Template.Spec.Containers[0]
+	if c.Name != "db" || c.Resources.Requests.Cpu().String() != "710m" || c.Resources.Limits.Cpu().String() != "710m" {
+		t.Errorf("container %s requests %v limits %v, want CPU 710m/710m", c.Name, c.Resources.Requests, c.Resources.Limits)
+	}
+	if _, ok := c.Resources.Requests["memory"]; ok {
+		t.Errorf("unchanged memory request is in the patch: %v", c.Resources.Requests)
+	}
+
+	r.Kind = "Job"
+	if err := WriteKustomizePatch(&buf, r, 0.3); err == nil {
+		t.Error("patching a Job succeeded, want error")
+	}
+}
+
+func TestWriteKustomization(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteKustomization(&buf, []string{"shop-deployment-cart.yaml", "data-statefulset-db.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	var k struct {
+		APIVersion string              `json:"apiVersion"`
+		Kind       string              `json:"kind"`
+		Patches    []map[string]string `json:"patches"`
+	}
+	if err := yaml.UnmarshalStrict(buf.Bytes(), &k); err != nil {
+		t.Fatal(err)
+	}
+	if k.Kind != "Component" || len(k.Patches) != 2 || k.Patches[1]["path"] != "data-statefulset-db.yaml" {
+		t.Errorf("kustomization = %+v", k)
+	}
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// LabelGroupsOptions controls truncation in RenderLabelGroups.
+type LabelGroupsOptions struct {
+	Label            string
+	Limit            int  // top N groups (0 = all)
+	MetricsAvailable bool // pod metrics were fetched
+}
+
+// RenderLabelGroups renders requests, usage and waste per label value to stdout and saves
+// a markdown file.
+func RenderLabelGroups(groups []analysis.LabelGroup, contextName string, opts LabelGroupsOptions) {
+	ts := time.Now()
+
+	var (
+		totalWasteCPU int64
+		totalWasteMem float64
+		values        = len(groups)
+		unlabelled    int
+	)
+	for _, g := range groups {
+		totalWasteCPU += g.Metered.CPUWaste()
+		totalWasteMem += g.Metered.MemWaste()
+		if g.Value == "" {
+			values--
+			unlabelled = g.Pods
+		}
+	}
+	all := groups
+	if opts.Limit > 0 && len(groups) > opts.Limit {
+		groups = groups[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("Pods by %s — %s", opts.Label, contextName)
+	headers := []string{"#", opts.Label, "Namespaces", "Pods", "CPU Req", "CPU Actual", "CPU Waste", "Mem Req", "Mem Actual", "Mem Waste", "Waste Share (CPU)"}
+
+	var rows [][]cellValue
+	for i, g := range groups {
+		cpuActual, cpuWaste, memActual, memWaste, share := naCell(), naCell(), naCell(), naCell(), naCell()
+		if opts.MetricsAvailable && g.Metered.Pods > 0 {
+			cpuActual = cv(kube.FormatCPU(g.Metered.CPUActual))
+			cpuWaste = cv(kube.FormatCPU(g.Metered.CPUWaste()))
+			memActual = cv(kube.FormatMem(g.Metered.MemActual))
+			memWaste = cv(kube.FormatMem(g.Metered.MemWaste()))
+			share = cv(formatPct(safePctInt(g.Metered.CPUWaste(), totalWasteCPU)))
+		}
+
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(orNone(g.Value)),
+			cv(strings.Join(g.Namespaces, ", ")),
+			cv(fmt.Sprintf("%d", g.Pods)),
+			cv(kube.FormatCPU(g.CPURequest)),
+			cpuActual,
+			cpuWaste,
+			cv(kube.FormatMem(g.MemRequest)),
+			memActual,
+			memWaste,
+			share,
+		})
+	}
+
+	summary := fmt.Sprintf("%d %s of %s; %d %s without the label.",
+		values, kube.Plural(values, "value", "values"), opts.Label, unlabelled, kube.Plural(unlabelled, "pod", "pods"))
+	if opts.MetricsAvailable {
+		summary += fmt.Sprintf(" %s CPU and %s memory requested but unused.", kube.FormatCPU(totalWasteCPU), kube.FormatMem(totalWasteMem))
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	mdContent += "\n\n" + summary
+	if mermaidCharts && opts.MetricsAvailable {
+		waste := labelGroupsWaste(all, len(groups))
+		if chart := mermaidPie(fmt.Sprintf("CPU requested but unused by %s (millicores)", opts.Label), waste, 10); chart != "" {
+			mdContent += "\n\n" + chart
+		}
+	}
+	saveMarkdownFile("pods-by-label", contextName, ts, mdContent)
+}
+
+// labelGroupsWaste returns the unused CPU of the first shown groups by label value, with
+// the groups cut by --limit summed into "other", so the chart covers the whole cluster.
+func labelGroupsWaste(groups []analysis.LabelGroup, shown int) map[string]float64 {
+	waste := make(map[string]float64, shown+1)
+	for i, g := range groups {
+		if i < shown {
+			waste[orNone(g.Value)] += float64(g.Metered.CPUWaste())
+		} else {
+			waste["other"] += float64(g.Metered.CPUWaste())
+		}
+	}
+	return waste
+}
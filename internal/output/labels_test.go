@@ -0,0 +1,36 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/analysis"
+)
+
+func TestLabelGroupsWaste(t *testing.T) {
+	group := func(value string, requested, actual int64) analysis.LabelGroup {
+		return analysis.LabelGroup{Value: value, Metered: analysis.Efficiency{Pods: 1, CPURequested: requested, CPUActual: actual}}
+	}
+	groups := []analysis.LabelGroup{
+		group("payments", 4000, 1000),
+		group("search", 2000, 1000),
+		group("web", 1000, 500),
+		group("", 600, 200),
+	}
+
+	tests := []struct {
+		name  string
+		shown int
+		want  map[string]float64
+	}{
+		{"all shown", 4, map[string]float64{"payments": 3000, "search": 1000, "web": 500, "none": 400}},
+		{"limited", 2, map[string]float64{"payments": 3000, "search": 1000, "other": 900}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labelGroupsWaste(groups, tt.shown); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("labelGroupsWaste = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LimitRangeOptions describes the suggestions in RenderLimitRangeSuggestions and
+// WriteLimitRangeManifests.
+type LimitRangeOptions struct {
+	Name           string  // name of the LimitRange objects
+	MemLimitFactor float64 // default memory limit as a multiple of the default request
+}
+
+// RenderLimitRangeSuggestions renders the suggested container defaults per namespace to
+// stdout and saves a markdown file including the manifests.
+func RenderLimitRangeSuggestions(suggestions []analysis.LimitRangeSuggestion, contextName string, opts LimitRangeOptions) {
+	ts := time.Now()
+
+	title := fmt.Sprintf("LimitRange Suggestions — %s", contextName)
+	headers := []string{"Namespace", "Containers", "Without Requests", "Median CPU", "Median Mem", "defaultRequest.cpu", "defaultRequest.memory", "default.memory", "Note"}
+
+	var rows [][]cellValue
+	unset := 0
+	for _, s := range suggestions {
+		unset += s.Unset
+		note := cv("")
+		if s.FewSamples() {
+			note = cvColored(fmt.Sprintf("only %d metered %s", s.Metered, kube.Plural(s.Metered, "container", "containers")), text.Colors{text.FgYellow})
+		}
+		rows = append(rows, []cellValue{
+			cv(s.Namespace),
+			cv(fmt.Sprintf("%d", s.Containers)),
+			cv(fmt.Sprintf("%d", s.Unset)),
+			cv(kube.FormatCPU(s.MedianCPU)),
+			cv(kube.FormatMem(s.MedianMem)),
+			cv(cpuQuantity(s.DefaultRequestCPU)),
+			cv(memQuantity(s.DefaultRequestMem)),
+			cv(memQuantity(s.DefaultLimitMem)),
+			note,
+		})
+	}
+
+	summary := fmt.Sprintf("Container defaults for %d %s from the median usage of their containers; default memory limit %.1fx the request, no default CPU limit.",
+		len(suggestions), kube.Plural(len(suggestions), "namespace", "namespaces"), opts.MemLimitFactor)
+	if unset > 0 {
+		summary += fmt.Sprintf(" %d running %s without requests would get these defaults once recreated.",
+			unset, kube.Plural(unset, "container", "containers"))
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+
+	var manifests bytes.Buffer
+	if err := WriteLimitRangeManifests(&manifests, suggestions, opts); err == nil {
+		summary += "\n\n```yaml\n" + manifests.String() + "```"
+	}
+	saveMarkdownFile("limitrange-suggest", contextName, ts, mdContent+"\n\n"+summary)
+}
+
+// WriteLimitRangeManifests writes one LimitRange per namespace as a multi-document YAML
+// stream, ready for kubectl apply.
+func WriteLimitRangeManifests(w io.Writer, suggestions []analysis.LimitRangeSuggestion, opts LimitRangeOptions) error {
+	docs := make([]manifest, len(suggestions))
+	for i, s := range suggestions {
+		docs[i] = manifest{
+			comment: fmt.Sprintf("%s: median usage %s CPU / %s memory over %d %s",
+				s.Namespace, kube.FormatCPU(s.MedianCPU), kube.FormatMem(s.MedianMem), s.Metered, kube.Plural(s.Metered, "container", "containers")),
+			object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "LimitRange",
+				"metadata":   map[string]any{"name": opts.Name, "namespace": s.Namespace},
+				"spec": map[string]any{
+					"limits": []map[string]any{{
+						"type": corev1.LimitTypeContainer,
+						"defaultRequest": map[corev1.ResourceName]string{
+							corev1.ResourceCPU:    cpuQuantity(s.DefaultRequestCPU),
+							corev1.ResourceMemory: memQuantity(s.DefaultRequestMem),
+						},
+						"default": map[corev1.ResourceName]string{
+							corev1.ResourceMemory: memQuantity(s.DefaultLimitMem),
+						},
+					}},
+				},
+			},
+		}
+	}
+	return writeManifests(w, docs)
+}
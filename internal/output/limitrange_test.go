@@ -0,0 +1,52 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestWriteLimitRangeManifests(t *testing.T) {
+	suggestions := []analysis.LimitRangeSuggestion{
+		{Namespace: "dev", Metered: 1, DefaultRequestCPU: 10, DefaultRequestMem: 32, DefaultLimitMem: 64},
+		{Namespace: "shop", Metered: 4, DefaultRequestCPU: 1500, DefaultRequestMem: 1024, DefaultLimitMem: 2048},
+	}
+	var buf bytes.Buffer
+	if err := WriteLimitRangeManifests(&buf, suggestions, LimitRangeOptions{Name: "container-defaults", MemLimitFactor: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := strings.Split(buf.String(), "---\n")
+	if len(docs) != len(suggestions) {
+		t.Fatalf("got %d documents, want %d:\n%s", len(docs), len(suggestions), buf.String())
+	}
+	want := []struct{ cpu, mem, limit string }{
+		{cpu: "10m", mem: "32Mi", limit: "64Mi"},
+		{cpu: "1500m", mem: "1Gi", limit: "2Gi"},
+	}
+	for i, doc := range docs {
+		var lr corev1.LimitRange
+		if err := yaml.UnmarshalStrict([]byte(doc), &lr); err != nil {
+			t.Fatalf("document %d is not a LimitRange: %v", i, err)
+		}
+		if lr.Kind != "LimitRange" || lr.Name != "container-defaults" || lr.Namespace != suggestions[i].Namespace {
+			t.Errorf("document %d: %s %s/%s", i, lr.Kind, lr.Namespace, lr.Name)
+		}
+		if len(lr.Spec.Limits) != 1 || lr.Spec.Limits[0].Type != corev1.LimitTypeContainer {
+			t.Fatalf("%s: want a single Container limit, got %+v", lr.Namespace, lr.Spec.Limits)
+		}
+		l := lr.Spec.Limits[0]
+		cpu, mem, limit := l.DefaultRequest[corev1.ResourceCPU], l.DefaultRequest[corev1.ResourceMemory], l.Default[corev1.ResourceMemory]
+		if cpu.String() != want[i].cpu || mem.String() != want[i].mem || limit.String() != want[i].limit {
+			t.Errorf("%s: defaultRequest %s/%s, default memory %s, want %s/%s, %s",
+				lr.Namespace, cpu.String(), mem.String(), limit.String(), want[i].cpu, want[i].mem, want[i].limit)
+		}
+		if _, ok := l.Default[corev1.ResourceCPU]; ok {
+			t.Errorf("%s: unexpected default CPU limit", lr.Namespace)
+		}
+	}
+}
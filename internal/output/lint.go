@@ -0,0 +1,97 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// RenderLint renders containers missing resource requests (and limits, when checked)
+// to stdout and saves a markdown file.
+func RenderLint(result *kube.FetchLintResult, contextName string, checkLimits bool) {
+	ts := time.Now()
+
+	title := fmt.Sprintf("Missing Requests — %s", contextName)
+	if checkLimits {
+		title = fmt.Sprintf("Missing Requests/Limits — %s", contextName)
+	}
+	headers := []string{"#", "Namespace", "Kind", "Workload", "Container", "Missing", "Pods"}
+
+	var (
+		rows            [][]cellValue
+		missingCPU      int
+		missingMem      int
+		missingCPULimit int
+		missingMemLimit int
+		workloads       = make(map[string]bool)
+	)
+	for i, f := range result.Findings {
+		if f.MissingCPU {
+			missingCPU++
+		}
+		if f.MissingMem {
+			missingMem++
+		}
+		if f.MissingCPULimit {
+			missingCPULimit++
+		}
+		if f.MissingMemLimit {
+			missingMemLimit++
+		}
+		workloads[f.Namespace+"/"+f.Kind+"/"+f.Workload] = true
+
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(f.Namespace),
+			cv(f.Kind),
+			cv(f.Workload),
+			cv(f.Container),
+			missingCell(f),
+			cv(fmt.Sprintf("%d", f.PodCount)),
+		})
+	}
+
+	summary := fmt.Sprintf("%d containers in %d workloads lack requests (%d missing CPU, %d missing memory); scanned %d containers in %d pods.",
+		len(result.Findings), len(workloads), missingCPU, missingMem, result.ContainersScanned, result.PodsScanned)
+	if checkLimits {
+		summary = fmt.Sprintf("%d containers in %d workloads lack requests or limits (requests: %d CPU, %d memory; limits: %d CPU, %d memory); scanned %d containers in %d pods.",
+			len(result.Findings), len(workloads), missingCPU, missingMem, missingCPULimit, missingMemLimit, result.ContainersScanned, result.PodsScanned)
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	mdContent += "\n\n" + summary
+	if note := skippedNote(result.Skipped); note != "" {
+		fmt.Println(note)
+		mdContent += "\n\n" + note
+	}
+	saveMarkdownFile("lint", contextName, ts, mdContent)
+}
+
+// missingCell lists what a finding lacks. Missing requests and missing memory limits are
+// red (unbounded scheduling / OOM blast radius); a missing CPU limit alone is only yellow.
+func missingCell(f kube.LintFinding) cellValue {
+	var parts []string
+	if f.MissingCPU {
+		parts = append(parts, "CPU req")
+	}
+	if f.MissingMem {
+		parts = append(parts, "mem req")
+	}
+	if f.MissingCPULimit {
+		parts = append(parts, "CPU limit")
+	}
+	if f.MissingMemLimit {
+		parts = append(parts, "mem limit")
+	}
+
+	color := text.FgYellow
+	if f.MissingCPU || f.MissingMem || f.MissingMemLimit {
+		color = text.FgRed
+	}
+	return cvColored(strings.Join(parts, ", "), text.Colors{color})
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// manifest is one generated Kubernetes object with a comment explaining where its
+// values come from.
+type manifest struct {
+	comment string
+	object  map[string]any
+}
+
+// writeManifests writes docs as a multi-document YAML stream, ready for kubectl apply.
+// Objects are maps rather than API types, which would add empty status and metadata fields.
+func writeManifests(w io.Writer, docs []manifest) error {
+	for i, doc := range docs {
+		out, err := yaml.Marshal(doc.object)
+		if err != nil {
+			return err
+		}
+		sep := ""
+		if i > 0 {
+			sep = "---\n"
+		}
+		if _, err := io.WriteString(w, sep+"# "+doc.comment+"\n"+string(out)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cpuQuantity and memQuantity format values as Kubernetes quantities ("700m", "2", "1280Mi").
+func cpuQuantity(millicores int64) string {
+	return resource.NewMilliQuantity(millicores, resource.DecimalSI).String()
+}
+
+func memQuantity(mib float64) string {
+	return resource.NewQuantity(int64(mib)*1024*1024, resource.BinarySI).String()
+}
@@ -5,9 +5,56 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"time"
+
+	"github.com/amasotti/kusa/internal/diag"
 )
 
+// outputDir is the directory reports are saved under, relative to the working directory.
+const outputDir = "output"
+
+var writeLatest bool
+
+// SetWriteLatest makes every saved report also overwrite output/<context>/<command>_latest.md.
+func SetWriteLatest(v bool) { writeLatest = v }
+
+var noSave bool
+
+// SetNoSave stops reports from being saved under output/.
+func SetNoSave(v bool) { noSave = v }
+
+// savedReports are the markdown reports written by this run, in order.
+var savedReports []string
+
+// SavedReports returns the paths of the markdown reports written by this run, e.g. to mail
+// them.
+func SavedReports() []string { return savedReports }
+
+// PrintSaved reports a file written for the user on stdout, unless output is plain.
+func PrintSaved(what string) {
+	if !plain {
+		fmt.Printf("Saved: %s\n", what)
+	}
+}
+
+var frontMatter bool
+
+// SetFrontMatter makes saved reports start with YAML front matter (title, date, context,
+// tags) for Obsidian vaults and static site generators such as Hugo.
+func SetFrontMatter(v bool) { frontMatter = v }
+
+// yamlFrontMatter returns the front matter block for a report. Strings are double-quoted
+// with Go escaping, which YAML accepts for the characters context names contain.
+func yamlFrontMatter(command, contextName string, ts time.Time) string {
+	return fmt.Sprintf("---\ntitle: %s\ndate: %s\ncontext: %s\ncommand: %s\ntags: [kusa, %s]\n---\n\n",
+		strconv.Quote(fmt.Sprintf("kusa %s — %s", command, contextName)),
+		ts.UTC().Format(time.RFC3339),
+		strconv.Quote(contextName),
+		strconv.Quote(command),
+		strconv.Quote(command))
+}
+
 var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9\-_.]`)
 
 func sanitizeContextName(name string) string {
@@ -19,9 +66,12 @@ func sanitizeContextName(name string) string {
 
 // saveMarkdownFile writes a markdown file to output/<context>/<command>_<timestamp>.md.
 func saveMarkdownFile(command, contextName string, ts time.Time, tableMarkdown string) {
-	dir := filepath.Join("output", sanitizeContextName(contextName))
+	if noSave {
+		return
+	}
+	dir := filepath.Join(outputDir, sanitizeContextName(contextName))
 	if err := os.MkdirAll(dir, 0o755); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to create output directory %s: %v\n", dir, err)
+		diag.Warnf("failed to create output directory %s: %v", dir, err)
 		return
 	}
 
@@ -30,12 +80,27 @@ func saveMarkdownFile(command, contextName string, ts time.Time, tableMarkdown s
 
 	header := fmt.Sprintf("# kusa %s — %s\n\n_Generated at %s_\n\n",
 		command, contextName, ts.UTC().Format("2006-01-02 15:04:05 UTC"))
+	if frontMatter {
+		header = yamlFrontMatter(command, contextName, ts) + header
+	}
 	content := header + tableMarkdown + "\n"
 
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to write markdown file %s: %v\n", path, err)
+		diag.Warnf("failed to write markdown file %s: %v", path, err)
 		return
 	}
 
-	fmt.Printf("Saved: %s\n", path)
+	PrintSaved(path)
+	savedReports = append(savedReports, path)
+
+	if writeLatest {
+		latest := filepath.Join(dir, command+"_latest.md")
+		if err := os.WriteFile(latest, []byte(content), 0o644); err != nil {
+			diag.Warnf("failed to write markdown file %s: %v", latest, err)
+		}
+	}
+
+	if _, err := pruneDir(dir, command, retention, ts, false); err != nil {
+		diag.Warnf("failed to prune old reports: %v", err)
+	}
 }
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestYAMLFrontMatter(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	fm := yamlFrontMatter("nodes", `arn:aws:eks:eu-west-1:123:cluster/prod "blue"`, ts)
+
+	body, ok := strings.CutPrefix(fm, "---\n")
+	if !ok {
+		t.Fatalf("front matter does not start with ---: %q", fm)
+	}
+	body, _, ok = strings.Cut(body, "---\n")
+	if !ok {
+		t.Fatalf("front matter is not closed: %q", fm)
+	}
+
+	var got struct {
+		Title   string   `json:"title"`
+		Date    string   `json:"date"`
+		Context string   `json:"context"`
+		Tags    []string `json:"tags"`
+	}
+	if err := yaml.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("invalid YAML %q: %v", body, err)
+	}
+	if got.Context != `arn:aws:eks:eu-west-1:123:cluster/prod "blue"` || got.Date != "2026-03-01T09:30:00Z" {
+		t.Errorf("got %+v", got)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "kusa" || got.Tags[1] != "nodes" {
+		t.Errorf("tags = %v, want [kusa nodes]", got.Tags)
+	}
+}
+
+func TestMermaidPie(t *testing.T) {
+	got := mermaidPie(`waste "by" ns`, map[string]float64{"a": 50, "b": 30, "c": 15, "d": 5, "idle": 0}, 3)
+	want := "```mermaid\npie title waste 'by' ns\n" +
+		"    \"a\" : 50\n" +
+		"    \"b\" : 30\n" +
+		"    \"other\" : 20\n" +
+		"```"
+	if got != want {
+		t.Errorf("mermaidPie =\n%s\nwant\n%s", got, want)
+	}
+	if got := mermaidPie("empty", map[string]float64{"a": 0}, 3); got != "" {
+		t.Errorf("mermaidPie without positive values = %q, want empty", got)
+	}
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var mermaidCharts bool
+
+// SetMermaid makes saved reports embed mermaid charts next to their tables. Charts only
+// appear in the markdown files; GitHub and most wikis render them natively.
+func SetMermaid(v bool) { mermaidCharts = v }
+
+// mermaidLabel makes s safe inside a double-quoted mermaid string.
+func mermaidLabel(s string) string {
+	return strings.ReplaceAll(s, `"`, "'")
+}
+
+// mermaidBarLine renders an xychart with bars (e.g. requested) and a line (e.g. actual)
+// per label, as percentages from 0 to max(100, highest value).
+func mermaidBarLine(title string, labels []string, bars, line []float64) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	top := 100.0
+	quoted := make([]string, len(labels))
+	barVals := make([]string, len(bars))
+	lineVals := make([]string, len(line))
+	for i, l := range labels {
+		quoted[i] = `"` + mermaidLabel(l) + `"`
+	}
+	for i, v := range bars {
+		barVals[i] = fmt.Sprintf("%.0f", v)
+		top = max(top, v)
+	}
+	for i, v := range line {
+		lineVals[i] = fmt.Sprintf("%.0f", v)
+		top = max(top, v)
+	}
+
+	var b strings.Builder
+	b.WriteString("```mermaid\nxychart-beta\n")
+	fmt.Fprintf(&b, "    title \"%s\"\n", mermaidLabel(title))
+	fmt.Fprintf(&b, "    x-axis [%s]\n", strings.Join(quoted, ", "))
+	fmt.Fprintf(&b, "    y-axis \"%% of allocatable\" 0 --> %.0f\n", top)
+	fmt.Fprintf(&b, "    bar [%s]\n", strings.Join(barVals, ", "))
+	fmt.Fprintf(&b, "    line [%s]\n", strings.Join(lineVals, ", "))
+	b.WriteString("```")
+	return b.String()
+}
+
+// mermaidPie renders a pie chart of the positive values, largest slices first. Slices
+// beyond maxSlices are merged into "other".
+func mermaidPie(title string, values map[string]float64, maxSlices int) string {
+	type slice struct {
+		label string
+		value float64
+	}
+	var slices []slice
+	for l, v := range values {
+		if v > 0 {
+			slices = append(slices, slice{l, v})
+		}
+	}
+	if len(slices) == 0 {
+		return ""
+	}
+	sort.Slice(slices, func(i, j int) bool {
+		if slices[i].value != slices[j].value {
+			return slices[i].value > slices[j].value
+		}
+		return slices[i].label < slices[j].label
+	})
+	if maxSlices > 0 && len(slices) > maxSlices {
+		other := slice{label: "other"}
+		for _, s := range slices[maxSlices-1:] {
+			other.value += s.value
+		}
+		slices = append(slices[:maxSlices-1], other)
+	}
+
+	var b strings.Builder
+	b.WriteString("```mermaid\n")
+	fmt.Fprintf(&b, "pie title %s\n", mermaidLabel(title))
+	for _, s := range slices {
+		fmt.Fprintf(&b, "    \"%s\" : %.0f\n", mermaidLabel(s.label), s.value)
+	}
+	b.WriteString("```")
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// NamespacesOptions controls truncation in RenderNamespaces.
+type NamespacesOptions struct {
+	Limit int // top N namespaces (0 = all)
+}
+
+// RenderNamespaces renders namespaces ranked by reclaimable capacity, with each one's share
+// of the cluster-wide total, to stdout and saves a markdown file.
+func RenderNamespaces(namespaces []analysis.NamespaceWaste, contextName string, opts NamespacesOptions) {
+	ts := time.Now()
+
+	var (
+		totalCPU  int64
+		totalMem  float64
+		unmetered int
+	)
+	for _, ns := range namespaces {
+		totalCPU += ns.ReclaimableCPU
+		totalMem += ns.ReclaimableMem
+		unmetered += ns.Pods - ns.Metered.Pods
+	}
+	all := len(namespaces)
+	if opts.Limit > 0 && len(namespaces) > opts.Limit {
+		namespaces = namespaces[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("Namespaces by Waste — %s", contextName)
+	headers := []string{"#", "Namespace", "Pods", "CPU Req", "CPU Actual", "CPU Waste", "CPU Share", "Mem Req", "Mem Actual", "Mem Waste", "Mem Share"}
+
+	var (
+		rows     [][]cellValue
+		shownCPU int64
+		shownMem float64
+	)
+	for i, ns := range namespaces {
+		cpuActual, cpuWaste, cpuShare, memActual, memWaste, memShare := naCell(), naCell(), naCell(), naCell(), naCell(), naCell()
+		if ns.Metered.Pods > 0 {
+			cpuActual = cv(kube.FormatCPU(ns.Metered.CPUActual))
+			cpuWaste = cv(kube.FormatCPU(ns.ReclaimableCPU))
+			cpuShare = cv(formatPct(safePctInt(ns.ReclaimableCPU, totalCPU)))
+			memActual = cv(kube.FormatMem(ns.Metered.MemActual))
+			memWaste = cv(kube.FormatMem(ns.ReclaimableMem))
+			memShare = cv(formatPct(safePctFloat(ns.ReclaimableMem, totalMem)))
+		}
+		shownCPU += ns.ReclaimableCPU
+		shownMem += ns.ReclaimableMem
+
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(ns.Namespace),
+			cv(fmt.Sprintf("%d", ns.Pods)),
+			cv(kube.FormatCPU(ns.CPURequest)),
+			cpuActual,
+			cpuWaste,
+			cpuShare,
+			cv(kube.FormatMem(ns.MemRequest)),
+			memActual,
+			memWaste,
+			memShare,
+		})
+	}
+
+	summary := fmt.Sprintf("%s CPU and %s memory requested but unused across %d %s",
+		kube.FormatCPU(totalCPU), kube.FormatMem(totalMem), all, kube.Plural(all, "namespace", "namespaces"))
+	if len(namespaces) < all {
+		summary += fmt.Sprintf("; the top %d hold %s of the CPU and %s of the memory",
+			len(namespaces), formatPct(safePctInt(shownCPU, totalCPU)), formatPct(safePctFloat(shownMem, totalMem)))
+	}
+	summary += "."
+	if unmetered > 0 {
+		summary += fmt.Sprintf(" %d %s without metrics not counted.", unmetered, kube.Plural(unmetered, "pod", "pods"))
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	mdContent += "\n\n" + summary
+	if mermaidCharts {
+		waste := make(map[string]float64, len(namespaces))
+		for _, ns := range namespaces {
+			waste[ns.Namespace] = float64(ns.ReclaimableCPU)
+		}
+		if chart := mermaidPie("CPU requested but unused by namespace (millicores)", waste, 10); chart != "" {
+			mdContent += "\n\n" + chart
+		}
+	}
+	saveMarkdownFile("namespaces", contextName, ts, mdContent)
+}
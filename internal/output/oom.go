@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// OOMOptions controls filtering and truncation in RenderOOMRisk.
+type OOMOptions struct {
+	Limit    int           // top N containers (0 = all)
+	MinScore int           // only containers scoring at least this
+	Window   time.Duration // OOM kills within this window count as recent
+}
+
+// RenderOOMRisk renders containers ranked by OOM-kill risk to stdout and saves a markdown file.
+func RenderOOMRisk(risks []analysis.OOMRisk, contextName string, opts OOMOptions) {
+	ts := time.Now()
+
+	if opts.MinScore > 0 {
+		filtered := risks[:0]
+		for _, r := range risks {
+			if r.Score >= opts.MinScore {
+				filtered = append(filtered, r)
+			}
+		}
+		risks = filtered
+	}
+	high := 0
+	for _, r := range risks {
+		if analysis.OOMRiskVerdict(r.Score) == analysis.VerdictOOMHigh {
+			high++
+		}
+	}
+	total := len(risks)
+	if opts.Limit > 0 && len(risks) > opts.Limit {
+		risks = risks[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("OOM Risk — %s", contextName)
+	headers := []string{"#", "Namespace", "Pod", "Container", "Mem Req", "Mem Limit", "Working Set", "Of Limit", "Restarts", "Last OOM Kill", "Score", "Risk"}
+
+	var rows [][]cellValue
+	for i, r := range risks {
+		workingSet, ofLimit := naCell(), cv("-")
+		if r.MetricsAvailable {
+			workingSet = cv(kube.FormatMem(r.MemActual))
+			if r.MemLimit > 0 {
+				share := r.LimitShare()
+				ofLimit = withGauge(cv(formatPct(share*100)), share*100)
+			}
+		}
+		lastKill := cv("-")
+		if !r.LastOOMKilled.IsZero() {
+			lastKill = cv(duration.HumanDuration(ts.Sub(r.LastOOMKilled)) + " ago")
+			if r.RecentlyOOMKilled {
+				lastKill = cvColored(lastKill.text, text.Colors{text.FgRed})
+			}
+		}
+		restarts := cv(fmt.Sprintf("%d", r.Restarts))
+		if r.Restarts > 0 {
+			restarts = cvColored(restarts.text, text.Colors{text.FgYellow})
+		}
+		verdict := analysis.OOMRiskVerdict(r.Score)
+
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(r.Namespace),
+			cv(r.Pod),
+			cv(r.Container),
+			cv(memOrNone(r.MemRequest)),
+			cv(memOrNone(r.MemLimit)),
+			workingSet,
+			ofLimit,
+			restarts,
+			lastKill,
+			cv(fmt.Sprintf("%d", r.Score)),
+			cvColored(verdict.Label, text.Colors{verdict.Color}),
+		})
+	}
+
+	summary := fmt.Sprintf("%d of %d %s at high OOM risk. OOM kills in the last %s count as recent.",
+		high, total, kube.Plural(total, "container", "containers"), duration.HumanDuration(opts.Window))
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	saveMarkdownFile("oom", contextName, ts, mdContent+"\n\n"+summary)
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// OrphansOptions controls filtering and truncation in RenderOrphans.
+type OrphansOptions struct {
+	IncludeSystem bool
+	Limit         int           // top N pods (0 = all)
+	MinAge        time.Duration // only pods at least this old (0 = all)
+}
+
+// RenderOrphans renders running pods without a controller owner, ranked by the capacity
+// they hold, to stdout and saves a markdown file.
+func RenderOrphans(result *kube.FetchPodsResult, contextName string, opts OrphansOptions) {
+	ts := time.Now()
+
+	var pods []kube.PodInfo
+	for _, p := range result.Pods {
+		if !p.Standalone {
+			continue
+		}
+		if !opts.IncludeSystem && kube.SystemNamespaces[p.Namespace] {
+			continue
+		}
+		if opts.MinAge > 0 && ts.Sub(p.CreatedAt) < opts.MinAge {
+			continue
+		}
+		pods = append(pods, p)
+	}
+
+	// Rank by held capacity, oldest first on ties.
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].CPURequest != pods[j].CPURequest {
+			return pods[i].CPURequest > pods[j].CPURequest
+		}
+		if pods[i].MemRequest != pods[j].MemRequest {
+			return pods[i].MemRequest > pods[j].MemRequest
+		}
+		return pods[i].CreatedAt.Before(pods[j].CreatedAt)
+	})
+
+	var heldCPU int64
+	var heldMem float64
+	for _, p := range pods {
+		heldCPU += p.CPURequest
+		heldMem += p.MemRequest
+	}
+	total := len(pods)
+	if opts.Limit > 0 && len(pods) > opts.Limit {
+		pods = pods[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("Standalone Pods — %s", contextName)
+	headers := []string{"#", "Namespace", "Pod", "Node", "Age", "CPU Req", "CPU Actual", "Mem Req", "Mem Actual"}
+
+	var rows [][]cellValue
+	for i, pod := range pods {
+		var cpuActualCell, memActualCell cellValue
+		if result.MetricsAvailable && pod.MetricsAvailable {
+			cpuActualCell = cv(kube.FormatCPU(pod.CPUActual))
+			memActualCell = cv(kube.FormatMem(pod.MemActual))
+		} else {
+			cpuActualCell = naCell()
+			memActualCell = naCell()
+		}
+
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(pod.Namespace),
+			cv(pod.Name),
+			cv(pod.NodeName),
+			cv(duration.HumanDuration(ts.Sub(pod.CreatedAt))),
+			cv(kube.FormatCPU(pod.CPURequest)),
+			cpuActualCell,
+			cv(kube.FormatMem(pod.MemRequest)),
+			memActualCell,
+		})
+	}
+
+	summary := fmt.Sprintf("%d standalone pods hold %s CPU and %s memory in requests.",
+		total, kube.FormatCPU(heldCPU), kube.FormatMem(heldMem))
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	saveMarkdownFile("orphans", contextName, ts, mdContent+"\n\n"+summary)
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// nodePlatform returns "os/arch" for a node, "?" for a part that is unknown.
+func nodePlatform(n kube.NodeInfo) string {
+	return fmt.Sprintf("%s/%s", orUnknown(n.OS), orUnknown(n.Arch))
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "?"
+	}
+	return s
+}
+
+// platformSlice sums capacity, requests and usage of the nodes sharing an OS/arch.
+type platformSlice struct {
+	name                        string
+	nodes                       int
+	allocCPU, reqCPU, actualCPU int64
+	allocMem, reqMem, actualMem float64
+	metricsAvailable            bool
+}
+
+// renderNodesPlatforms splits cluster capacity by OS/architecture. Pods only fit on
+// nodes of their image's platform, so in mixed clusters free capacity on arm64 nodes
+// does not help a pending amd64 pod. Returns "" when all nodes share one platform.
+func renderNodesPlatforms(result *kube.FetchNodesResult, contextName string) string {
+	byPlatform := make(map[string]*platformSlice)
+	for _, n := range result.Nodes {
+		name := nodePlatform(n)
+		s, ok := byPlatform[name]
+		if !ok {
+			s = &platformSlice{name: name, metricsAvailable: true}
+			byPlatform[name] = s
+		}
+		s.nodes++
+		s.allocCPU += n.AllocatableCPU
+		s.reqCPU += n.RequestedCPU
+		s.actualCPU += n.ActualCPU
+		s.allocMem += n.AllocatableMem
+		s.reqMem += n.RequestedMem
+		s.actualMem += n.ActualMem
+		s.metricsAvailable = s.metricsAvailable && result.NodeMetricsAvailable && n.MetricsAvailable
+	}
+	if len(byPlatform) < 2 {
+		return ""
+	}
+
+	names := make([]string, 0, len(byPlatform))
+	for name := range byPlatform {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	title := fmt.Sprintf("Capacity by OS/Arch — %s", contextName)
+	headers := []string{"OS/Arch", "Nodes", "CPU Allocatable", "CPU Requested", "CPU Actual", "Mem Allocatable", "Mem Requested", "Mem Actual"}
+	var rows [][]cellValue
+	for _, name := range names {
+		s := byPlatform[name]
+		cpuActual, memActual := naCell(), naCell()
+		if s.metricsAvailable {
+			cpuActual = cv(fmt.Sprintf("%s (%s)", formatPct(safePctInt(s.actualCPU, s.allocCPU)), kube.FormatCPU(s.actualCPU)))
+			memActual = cv(fmt.Sprintf("%s (%s)", formatPct(safePctFloat(s.actualMem, s.allocMem)), kube.FormatMem(s.actualMem)))
+		}
+		rows = append(rows, []cellValue{
+			cv(name),
+			cv(fmt.Sprintf("%d", s.nodes)),
+			cv(kube.FormatCPU(s.allocCPU)),
+			cv(fmt.Sprintf("%s (%s)", formatPct(safePctInt(s.reqCPU, s.allocCPU)), kube.FormatCPU(s.reqCPU))),
+			cpuActual,
+			cv(kube.FormatMem(s.allocMem)),
+			cv(fmt.Sprintf("%s (%s)", formatPct(safePctFloat(s.reqMem, s.allocMem)), kube.FormatMem(s.reqMem))),
+			memActual,
+		})
+	}
+
+	fmt.Println()
+	return renderTable(title, headers, rows)
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// QuotaOptions describes the suggestions in RenderQuotaSuggestions and WriteQuotaManifests.
+type QuotaOptions struct {
+	Name   string  // name of the ResourceQuota objects
+	Buffer float64 // fraction added on top of usage (0.3 = +30%)
+}
+
+// RenderQuotaSuggestions renders the suggested quota per namespace next to today's usage
+// and requests to stdout and saves a markdown file including the manifests.
+func RenderQuotaSuggestions(suggestions []analysis.QuotaSuggestion, contextName string, opts QuotaOptions) {
+	ts := time.Now()
+
+	title := fmt.Sprintf("Quota Suggestions — %s", contextName)
+	headers := []string{"Namespace", "Pods", "CPU Actual", "CPU Req", "requests.cpu", "Mem Actual", "Mem Req", "requests.memory", "pods", "Note"}
+
+	var rows [][]cellValue
+	blocked := 0
+	for _, s := range suggestions {
+		note := cv("")
+		switch {
+		case s.ExceedsRequests():
+			blocked++
+			note = cvColored("requests exceed quota: right-size first", text.Colors{text.FgYellow})
+		case s.Unmetered > 0:
+			note = cvColored(fmt.Sprintf("%d %s without metrics", s.Unmetered, kube.Plural(s.Unmetered, "pod", "pods")), text.Colors{text.FgYellow})
+		}
+		rows = append(rows, []cellValue{
+			cv(s.Namespace),
+			cv(fmt.Sprintf("%d", s.Pods)),
+			cv(kube.FormatCPU(s.CPUActual)),
+			cv(kube.FormatCPU(s.CPURequest)),
+			cv(cpuQuantity(s.CPUQuota)),
+			cv(kube.FormatMem(s.MemActual)),
+			cv(kube.FormatMem(s.MemRequest)),
+			cv(memQuantity(s.MemQuota)),
+			cv(fmt.Sprintf("%d", s.PodsQuota)),
+			note,
+		})
+	}
+
+	summary := fmt.Sprintf("Suggested quotas for %d %s, from observed usage plus %.0f%%.",
+		len(suggestions), kube.Plural(len(suggestions), "namespace", "namespaces"), opts.Buffer*100)
+	if blocked > 0 {
+		summary += fmt.Sprintf(" %d %s already %s more than suggested: applying the quota would block new pods and rollouts until requests shrink.",
+			blocked, kube.Plural(blocked, "namespace", "namespaces"), kube.Plural(blocked, "requests", "request"))
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+
+	var manifests bytes.Buffer
+	if err := WriteQuotaManifests(&manifests, suggestions, opts); err == nil {
+		summary += "\n\n```yaml\n" + manifests.String() + "```"
+	}
+	saveMarkdownFile("quota-suggest", contextName, ts, mdContent+"\n\n"+summary)
+}
+
+// WriteQuotaManifests writes one ResourceQuota per namespace as a multi-document YAML
+// stream, ready for kubectl apply.
+func WriteQuotaManifests(w io.Writer, suggestions []analysis.QuotaSuggestion, opts QuotaOptions) error {
+	docs := make([]manifest, len(suggestions))
+	for i, s := range suggestions {
+		docs[i] = manifest{
+			comment: fmt.Sprintf("%s: usage %s CPU / %s memory over %d %s, +%.0f%%",
+				s.Namespace, kube.FormatCPU(s.CPUActual), kube.FormatMem(s.MemActual), s.Pods, kube.Plural(s.Pods, "pod", "pods"), opts.Buffer*100),
+			object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ResourceQuota",
+				"metadata":   map[string]any{"name": opts.Name, "namespace": s.Namespace},
+				"spec": map[string]any{
+					"hard": map[corev1.ResourceName]string{
+						corev1.ResourceRequestsCPU:    cpuQuantity(s.CPUQuota),
+						corev1.ResourceRequestsMemory: memQuantity(s.MemQuota),
+						corev1.ResourcePods:           fmt.Sprintf("%d", s.PodsQuota),
+					},
+				},
+			},
+		}
+	}
+	return writeManifests(w, docs)
+}
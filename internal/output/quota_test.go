@@ -0,0 +1,46 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestWriteQuotaManifests(t *testing.T) {
+	suggestions := []analysis.QuotaSuggestion{
+		{Namespace: "data", Pods: 2, CPUQuota: 1200, MemQuota: 8192, PodsQuota: 3},
+		{Namespace: "shop", Pods: 3, CPUQuota: 2000, MemQuota: 2432, PodsQuota: 4},
+	}
+	var buf bytes.Buffer
+	if err := WriteQuotaManifests(&buf, suggestions, QuotaOptions{Name: "compute-resources", Buffer: 0.3}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := strings.Split(buf.String(), "---\n")
+	if len(docs) != len(suggestions) {
+		t.Fatalf("got %d documents, want %d:\n%s", len(docs), len(suggestions), buf.String())
+	}
+	want := []map[corev1.ResourceName]string{
+		{corev1.ResourceRequestsCPU: "1200m", corev1.ResourceRequestsMemory: "8Gi", corev1.ResourcePods: "3"},
+		{corev1.ResourceRequestsCPU: "2", corev1.ResourceRequestsMemory: "2432Mi", corev1.ResourcePods: "4"},
+	}
+	for i, doc := range docs {
+		var q corev1.ResourceQuota
+		if err := yaml.UnmarshalStrict([]byte(doc), &q); err != nil {
+			t.Fatalf("document %d is not a ResourceQuota: %v", i, err)
+		}
+		if q.Kind != "ResourceQuota" || q.Name != "compute-resources" || q.Namespace != suggestions[i].Namespace {
+			t.Errorf("document %d: %s %s/%s", i, q.Kind, q.Namespace, q.Name)
+		}
+		for name, v := range want[i] {
+			got := q.Spec.Hard[name]
+			if got.String() != v {
+				t.Errorf("%s %s = %s, want %s", q.Namespace, name, got.String(), v)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// RenderRebalance renders a rebalance plan to stdout and saves a markdown file.
+func RenderRebalance(plan analysis.RebalancePlan, contextName string) {
+	ts := time.Now()
+
+	title := fmt.Sprintf("Rebalance Plan — %s", contextName)
+	headers := []string{"#", "Action", "Target", "Detail", "Freed (CPU/Mem)"}
+
+	var rows [][]cellValue
+	for i, a := range plan.Actions {
+		actionCell := cvColored(a.Kind, text.Colors{text.FgYellow})
+		if a.Kind == analysis.ActionDrain {
+			actionCell = cvColored(a.Kind, text.Colors{text.FgGreen})
+		}
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			actionCell,
+			cv(a.Target),
+			cv(a.Detail),
+			cv(fmt.Sprintf("%s / %s", kube.FormatCPU(a.CPUFreed), kube.FormatMem(a.MemFreed))),
+		})
+	}
+
+	summary := fmt.Sprintf("Nodes: %d → %d (−%d)", plan.NodesBefore, plan.NodesAfter, plan.NodesBefore-plan.NodesAfter)
+	if len(plan.Actions) == 0 {
+		summary = fmt.Sprintf("No rebalance opportunities found across %d nodes.", plan.NodesBefore)
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	saveMarkdownFile("rebalance", contextName, ts, mdContent+"\n\n"+summary)
+}
@@ -0,0 +1,154 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// RecommendOptions describes the recommendations in RenderRecommendations.
+type RecommendOptions struct {
+	Headroom       float64 // fraction added on top of peak usage (0.3 = +30%)
+	GuaranteedOnly bool    // only Guaranteed workloads were asked for
+
+	// Throttling is set when CPU throttling was read; LimitRemovals are then shown.
+	Throttling   bool
+	MinThrottled float64 // threshold the limit removals were found with (0.25 = 25%)
+}
+
+// RenderRecommendations renders the right-sized containers of each workload, current →
+// recommended, and with throttling data the CPU limits worth removing, to stdout and saves
+// a markdown file.
+func RenderRecommendations(recs []analysis.Recommendation, removals []analysis.LimitRemoval, contextName string, opts RecommendOptions) {
+	ts := time.Now()
+
+	title := fmt.Sprintf("Recommendations — %s", contextName)
+	headers := []string{"Workload", "Container", "QoS", "Pods", "Confidence", "CPU Peak", "CPU Req", "CPU Limit", "Mem Peak", "Mem Req", "Mem Limit"}
+
+	var rows [][]cellValue
+	var cpuFreed int64
+	var memFreed float64
+	var caveats []string
+	guaranteed, confident := 0, 0
+	for _, r := range recs {
+		if r.Confidence == analysis.VerdictConfidenceHigh {
+			confident++
+		} else {
+			caveats = append(caveats, fmt.Sprintf("%s/%s/%s (%s): %s", r.Namespace, r.Kind, r.Name, r.Confidence.Label, strings.Join(r.ConfidenceReasons, ", ")))
+		}
+		cpuFreed += r.CPUFreed()
+		memFreed += r.MemFreed()
+		qos := cv("")
+		if r.Guaranteed {
+			guaranteed++
+			qos = cvColored("Guaranteed", text.Colors{text.FgCyan})
+		}
+		for _, c := range r.Containers {
+			rows = append(rows, []cellValue{
+				cv(fmt.Sprintf("%s/%s/%s", r.Namespace, r.Kind, r.Name)),
+				cv(c.Name),
+				qos,
+				cv(fmt.Sprintf("%d", r.Pods)),
+				cvColored(r.Confidence.Label, text.Colors{r.Confidence.Color}),
+				cv(kube.FormatCPU(c.CPUPeak)),
+				changeCell(cpuOrNone(c.CPURequest), cpuOrNone(c.NewCPURequest)),
+				changeCell(cpuOrNone(c.CPULimit), cpuOrNone(c.NewCPULimit)),
+				cv(kube.FormatMem(c.MemPeak)),
+				changeCell(memOrNone(c.MemRequest), memOrNone(c.NewMemRequest)),
+				changeCell(memOrNone(c.MemLimit), memOrNone(c.NewMemLimit)),
+			})
+		}
+	}
+
+	workloads := "workloads"
+	if opts.GuaranteedOnly {
+		workloads = "Guaranteed workloads"
+	}
+	summary := fmt.Sprintf("No right-sizing opportunities found among the %s.", workloads)
+	if len(recs) > 0 {
+		summary = fmt.Sprintf("Right-sizing %d %s to peak usage +%.0f%% releases %s CPU / %s memory of requests. %d of %d %s high confidence and safe to apply as is.",
+			len(recs), kube.Plural(len(recs), "workload", "workloads"), opts.Headroom*100, kube.FormatCPU(cpuFreed), kube.FormatMem(memFreed),
+			confident, len(recs), kube.Plural(len(recs), "is", "are"))
+	}
+	if guaranteed > 0 {
+		summary += fmt.Sprintf(" %d Guaranteed %s the limits with the requests to keep the QoS class; a lowered memory limit is a hard ceiling at peak +%.0f%%.",
+			guaranteed, kube.Plural(guaranteed, "workload lowers", "workloads lower"), opts.Headroom*100)
+	}
+	if len(caveats) > 0 {
+		summary += "\n\nLower confidence, review before applying:\n- " + strings.Join(caveats, "\n- ")
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	mdContent += "\n\n" + summary
+	if opts.Throttling {
+		mdContent += "\n\n" + renderLimitRemovals(removals, contextName, opts.MinThrottled)
+	}
+	saveMarkdownFile("recommend", contextName, ts, mdContent)
+}
+
+// renderLimitRemovals renders the throttled containers whose CPU limit is worth removing
+// and returns the markdown.
+func renderLimitRemovals(removals []analysis.LimitRemoval, contextName string, minThrottled float64) string {
+	title := fmt.Sprintf("CPU Limit Removal — %s", contextName)
+	headers := []string{"Workload", "Container", "Pods", "CPU Req", "CPU Limit", "CPU Peak", "Throttled", "Node CPU", "Recommendation"}
+
+	var rows [][]cellValue
+	for _, r := range removals {
+		advice := "consider removing the CPU limit"
+		if r.Guaranteed {
+			advice += " (demotes to Burstable)"
+		}
+		rows = append(rows, []cellValue{
+			cv(fmt.Sprintf("%s/%s/%s", r.Namespace, r.Kind, r.Name)),
+			cv(r.Container),
+			cv(fmt.Sprintf("%d", r.Pods)),
+			cv(cpuOrNone(r.CPURequest)),
+			cv(kube.FormatCPU(r.CPULimit)),
+			cv(kube.FormatCPU(r.CPUPeak)),
+			cvColored(formatPct(r.Throttled*100), text.Colors{text.FgRed}),
+			cv(formatPct(r.NodeUtilization * 100)),
+			cvColored(advice, text.Colors{text.FgYellow}),
+		})
+	}
+
+	summary := fmt.Sprintf("No container with a CPU limit is throttled in %.0f%% or more of its CFS periods on a node with CPU to spare.", minThrottled*100)
+	if len(removals) > 0 {
+		summary = fmt.Sprintf("%d %s throttled in %.0f%% or more of the CFS periods on nodes with CPU to spare: the limit, not the node, is the bottleneck. "+
+			"Without a CPU limit, bursts can use idle CPU while the request still guarantees each container's share.",
+			len(removals), kube.Plural(len(removals), "container is", "containers are"), minThrottled*100)
+	}
+	summary += " Throttling is counted since each container started; Node CPU is the busiest node the pods run on."
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	return mdContent + "\n\n" + summary
+}
+
+// changeCell shows a value that is kept as is, or the change "from → to" highlighted.
+func changeCell(from, to string) cellValue {
+	if from == to {
+		return cv(from)
+	}
+	return cvColored(from+" → "+to, text.Colors{text.FgGreen})
+}
+
+func cpuOrNone(m int64) string {
+	if m == 0 {
+		return "-"
+	}
+	return kube.FormatCPU(m)
+}
+
+func memOrNone(mib float64) string {
+	if mib == 0 {
+		return "-"
+	}
+	return kube.FormatMem(mib)
+}
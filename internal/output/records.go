@@ -0,0 +1,213 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// Usage values are nil when metrics are unavailable, verdicts are "" then.
+type (
+	podRecord struct {
+		Type         string   `json:"type"` // "pod"
+		Context      string   `json:"context"`
+		Namespace    string   `json:"namespace"`
+		Name         string   `json:"name"`
+		Node         string   `json:"node"`
+		WorkloadKind string   `json:"workloadKind"`
+		WorkloadName string   `json:"workloadName"`
+		CPURequest   int64    `json:"cpuRequestMillicores"`
+		CPUActual    *int64   `json:"cpuActualMillicores"`
+		MemRequest   float64  `json:"memRequestMiB"`
+		MemActual    *float64 `json:"memActualMiB"`
+		CPUWaste     *int64   `json:"cpuWasteMillicores"`
+		MemWaste     *float64 `json:"memWasteMiB"`
+		CPUVerdict   string   `json:"cpuVerdict"`
+		MemVerdict   string   `json:"memVerdict"`
+		Score        *int     `json:"score"`
+		MetricsStale bool     `json:"metricsStale"`
+	}
+
+	workloadRecord struct {
+		Type         string   `json:"type"` // "workload"
+		Context      string   `json:"context"`
+		Kind         string   `json:"kind"`
+		Namespace    string   `json:"namespace"`
+		Name         string   `json:"name"`
+		Pods         int      `json:"pods"`
+		DesiredPods  *int     `json:"desiredPods"`
+		CPURequest   int64    `json:"cpuRequestMillicores"`
+		CPUActual    *int64   `json:"cpuActualMillicores"`
+		MemRequest   float64  `json:"memRequestMiB"`
+		MemActual    *float64 `json:"memActualMiB"`
+		CPULimit     int64    `json:"cpuLimitMillicores"`
+		MemLimit     float64  `json:"memLimitMiB"`
+		CPUWaste     *int64   `json:"cpuWasteMillicores"`
+		MemWaste     *float64 `json:"memWasteMiB"`
+		CPUVerdict   string   `json:"cpuVerdict"`
+		MemVerdict   string   `json:"memVerdict"`
+		Overall      string   `json:"overallVerdict"`
+		Score        *int     `json:"score"`
+		MetricsStale bool     `json:"metricsStale"`
+	}
+
+	nodeRecord struct {
+		Type           string   `json:"type"` // "node"
+		Context        string   `json:"context"`
+		Name           string   `json:"name"`
+		OS             string   `json:"os"`
+		Arch           string   `json:"arch"`
+		AllocatableCPU int64    `json:"allocatableCpuMillicores"`
+		RequestedCPU   int64    `json:"requestedCpuMillicores"`
+		LimitCPU       int64    `json:"limitCpuMillicores"`
+		ActualCPU      *int64   `json:"actualCpuMillicores"`
+		AllocatableMem float64  `json:"allocatableMemMiB"`
+		RequestedMem   float64  `json:"requestedMemMiB"`
+		LimitMem       float64  `json:"limitMemMiB"`
+		ActualMem      *float64 `json:"actualMemMiB"`
+		CPUVerdict     string   `json:"cpuVerdict"`
+		MemVerdict     string   `json:"memVerdict"`
+		Unschedulable  bool     `json:"unschedulable"`
+		Taints         []string `json:"taints"`
+		MemoryPressure bool     `json:"memoryPressure"`
+		MetricsStale   bool     `json:"metricsStale"`
+	}
+
+	// summaryRecord totals the rows of kusa pods or deployments after filtering, before
+	// --limit. Verdict counts are by overall verdict (the worse of CPU and memory), so
+	// each row counts at most once.
+	summaryRecord struct {
+		Type                   string   `json:"type"` // "summary"
+		Context                string   `json:"context"`
+		Of                     string   `json:"of"` // "pod" or "workload"
+		Rows                   int      `json:"rows"`
+		OverRequested          int      `json:"overRequested"`
+		MassivelyOverRequested int      `json:"massivelyOverRequested"`
+		Bursting               int      `json:"bursting"`
+		NoMetrics              int      `json:"noMetrics"`
+		CPUWaste               int64    `json:"cpuWasteMillicores"`
+		MemWaste               float64  `json:"memWasteMiB"`
+		SkippedNamespaces      []string `json:"skippedNamespaces"`
+	}
+)
+
+func newPodRecord(p kube.PodInfo, contextName string, metricsAvail bool) podRecord {
+	r := podRecord{
+		Type:         "pod",
+		Context:      contextName,
+		Namespace:    p.Namespace,
+		Name:         p.Name,
+		Node:         p.NodeName,
+		WorkloadKind: p.WorkloadKind,
+		WorkloadName: p.WorkloadName,
+		CPURequest:   p.CPURequest,
+		MemRequest:   p.MemRequest,
+	}
+	if metricsAvail {
+		score := podScore(p, true)
+		r.CPUActual, r.MemActual, r.Score = &p.CPUActual, &p.MemActual, &score
+		cpuWaste, memWaste := max(p.CPURequest-p.CPUActual, 0), max(p.MemRequest-p.MemActual, 0)
+		r.CPUWaste, r.MemWaste = &cpuWaste, &memWaste
+		r.CPUVerdict = requestVerdict(float64(p.CPURequest), float64(p.CPUActual))
+		r.MemVerdict = requestVerdict(p.MemRequest, p.MemActual)
+		r.MetricsStale = p.MetricsStale
+	}
+	return r
+}
+
+func newWorkloadRecord(w kube.WorkloadInfo, contextName string, metricsAvail bool) workloadRecord {
+	r := workloadRecord{
+		Type:       "workload",
+		Context:    contextName,
+		Kind:       w.Kind,
+		Namespace:  w.Namespace,
+		Name:       w.Name,
+		Pods:       w.PodCount,
+		CPURequest: w.CPURequest,
+		MemRequest: w.MemRequest,
+		CPULimit:   w.CPULimit,
+		MemLimit:   w.MemLimit,
+	}
+	if w.DesiredKnown {
+		r.DesiredPods = &w.DesiredPods
+	}
+	if metricsAvail {
+		score := workloadScore(w, true)
+		r.CPUActual, r.MemActual, r.Score = &w.CPUActual, &w.MemActual, &score
+		cpuWaste, memWaste := max(w.CPURequest-w.CPUActual, 0), max(w.MemRequest-w.MemActual, 0)
+		r.CPUWaste, r.MemWaste = &cpuWaste, &memWaste
+		r.CPUVerdict = requestVerdict(float64(w.CPURequest), float64(w.CPUActual))
+		r.MemVerdict = requestVerdict(w.MemRequest, w.MemActual)
+		r.Overall = overallVerdictCell(float64(w.CPURequest), float64(w.CPUActual), w.MemRequest, w.MemActual, true).text
+		r.MetricsStale = w.MetricsStale
+	}
+	return r
+}
+
+func newNodeRecord(n kube.NodeInfo, contextName string, metricsAvail bool) nodeRecord {
+	r := nodeRecord{
+		Type:           "node",
+		Context:        contextName,
+		Name:           n.Name,
+		OS:             n.OS,
+		Arch:           n.Arch,
+		AllocatableCPU: n.AllocatableCPU,
+		RequestedCPU:   n.RequestedCPU,
+		LimitCPU:       n.LimitCPU,
+		AllocatableMem: n.AllocatableMem,
+		RequestedMem:   n.RequestedMem,
+		LimitMem:       n.LimitMem,
+		Unschedulable:  n.Unschedulable,
+		Taints:         make([]string, len(n.Taints)),
+		MemoryPressure: n.MemoryPressure,
+	}
+	for i, t := range n.Taints {
+		r.Taints[i] = t.ToString()
+	}
+	if metricsAvail {
+		r.ActualCPU, r.ActualMem = &n.ActualCPU, &n.ActualMem
+		r.CPUVerdict = analysis.ResourceVerdict(safePctInt(n.RequestedCPU, n.AllocatableCPU), safePctInt(n.ActualCPU, n.AllocatableCPU)).Label
+		r.MemVerdict = analysis.ResourceVerdict(safePctFloat(n.RequestedMem, n.AllocatableMem), safePctFloat(n.ActualMem, n.AllocatableMem)).Label
+		r.MetricsStale = n.MetricsStale
+	}
+	return r
+}
+
+// newSummaryRecord starts an empty summary; skipped are the namespaces the rows leave out
+// because they could not be read.
+func newSummaryRecord(contextName, of string, skipped []string) summaryRecord {
+	return summaryRecord{Type: "summary", Context: contextName, Of: of, SkippedNamespaces: append([]string{}, skipped...)}
+}
+
+// add counts one row into the summary.
+func (s *summaryRecord) add(cpuReq, cpuActual int64, memReq, memActual float64, metricsAvail bool) {
+	s.Rows++
+	if !metricsAvail {
+		s.NoMetrics++
+		return
+	}
+	s.CPUWaste += max(cpuReq-cpuActual, 0)
+	s.MemWaste += max(memReq-memActual, 0)
+	switch overallVerdictCell(float64(cpuReq), float64(cpuActual), memReq, memActual, true).text {
+	case analysis.VerdictOverRequested.Label:
+		s.OverRequested++
+	case analysis.VerdictMassivelyOverRequested.Label:
+		s.MassivelyOverRequested++
+	case analysis.VerdictBursting.Label:
+		s.Bursting++
+	}
+}
+
+// line renders the summary as one line of key=value pairs for scripts to grep, with waste
+// in cores and GiB regardless of --units and --decimals, e.g.
+// "summary: workloads=142 over_requested=37 massive=9 bursting=4 no_metrics=0 waste_cpu=86.4 waste_mem_gib=412.0".
+func (s summaryRecord) line() string {
+	return fmt.Sprintf("summary: %ss=%d over_requested=%d massive=%d bursting=%d no_metrics=%d waste_cpu=%.1f waste_mem_gib=%.1f",
+		s.Of, s.Rows, s.OverRequested, s.MassivelyOverRequested, s.Bursting, s.NoMetrics, float64(s.CPUWaste)/1000, s.MemWaste/1024)
+}
+
+// requestVerdict is the verdict label for usage against requests, "no req" without requests.
+func requestVerdict(req, actual float64) string {
+	return verdictFromRatio(req, actual, true).text
+}
@@ -0,0 +1,149 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestRenderPodsNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	prevOut, prevFormat := recordsOut, format
+	recordsOut, format = &buf, FormatNDJSON
+	t.Cleanup(func() { recordsOut, format = prevOut, prevFormat })
+
+	result := &kube.FetchPodsResult{
+		MetricsAvailable: true,
+		Pods: []kube.PodInfo{
+			{Namespace: "shop", Name: "cart-1", CPURequest: 500, CPUActual: 10, MemRequest: 512, MemActual: 100, MetricsAvailable: true},
+			{Namespace: "shop", Name: "web-1", CPURequest: 1000, MemRequest: 1024},
+			{Namespace: "kube-system", Name: "coredns-1", CPURequest: 2000, MetricsAvailable: true},
+		},
+	}
+	if err := RenderPods(result, "test", PodsOptions{Limit: 25}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 2 pods (system namespace filtered) and the summary:\n%s", len(lines), buf.String())
+	}
+
+	var records []map[string]any
+	for _, line := range lines {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		records = append(records, r)
+	}
+
+	// Sorted by CPU request, as in the table.
+	if records[0]["name"] != "web-1" || records[1]["name"] != "cart-1" {
+		t.Errorf("order = %v, %v; want web-1, cart-1", records[0]["name"], records[1]["name"])
+	}
+	if records[0]["type"] != "pod" || records[0]["context"] != "test" {
+		t.Errorf("type/context = %v/%v, want pod/test", records[0]["type"], records[0]["context"])
+	}
+	if records[0]["cpuActualMillicores"] != nil || records[0]["score"] != nil {
+		t.Errorf("pod without metrics has usage %v, score %v; want null", records[0]["cpuActualMillicores"], records[0]["score"])
+	}
+	if records[1]["cpuActualMillicores"] != float64(10) || records[1]["cpuVerdict"] != "Massively over-requested" {
+		t.Errorf("cart-1 usage/verdict = %v/%v", records[1]["cpuActualMillicores"], records[1]["cpuVerdict"])
+	}
+	if records[0]["cpuWasteMillicores"] != nil || records[1]["cpuWasteMillicores"] != float64(490) || records[1]["memWasteMiB"] != float64(412) {
+		t.Errorf("waste = %v, %v/%v; want null, 490/412", records[0]["cpuWasteMillicores"], records[1]["cpuWasteMillicores"], records[1]["memWasteMiB"])
+	}
+	if s := records[2]; s["type"] != "summary" || s["of"] != "pod" || s["rows"] != float64(2) || s["noMetrics"] != float64(1) ||
+		s["massivelyOverRequested"] != float64(1) || s["cpuWasteMillicores"] != float64(490) {
+		t.Errorf("summary = %v", s)
+	}
+}
+
+func TestSummaryRecord(t *testing.T) {
+	s := newSummaryRecord("test", "workload", []string{"payments"})
+	s.add(4000, 100, 2048, 2000, true) // CPU massively over-requested
+	s.add(1000, 700, 4096, 2048, true) // memory over-requested
+	s.add(500, 800, 512, 256, true)    // CPU bursting, memory over-requested: over-requested
+	s.add(200, 300, 256, 256, true)    // bursting
+	s.add(100, 100, 128, 128, true)    // OK
+	s.add(1000, 0, 1024, 0, false)     // no metrics
+
+	want := summaryRecord{Type: "summary", Context: "test", Of: "workload", Rows: 6, OverRequested: 2, MassivelyOverRequested: 1,
+		Bursting: 1, NoMetrics: 1, CPUWaste: 3900 + 300, MemWaste: 48 + 2048 + 256, SkippedNamespaces: []string{"payments"}}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+	if got, want := s.line(), "summary: workloads=6 over_requested=2 massive=1 bursting=1 no_metrics=1 waste_cpu=4.2 waste_mem_gib=2.3"; got != want {
+		t.Errorf("line() = %q, want %q", got, want)
+	}
+}
+
+func TestFlushRecordsTemplates(t *testing.T) {
+	records := []any{
+		workloadRecord{Type: "workload", Namespace: "shop", Name: "cart", CPURequest: 500},
+		workloadRecord{Type: "workload", Namespace: "data", Name: "etl", CPURequest: 1500},
+	}
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{`go-template={{range .items}}{{.namespace}}/{{.name}} {{.cpuRequestMillicores}}{{"\n"}}{{end}}`, "shop/cart 500\ndata/etl 1500\n"},
+		{`jsonpath={.items[*].name}`, "cart etl"},
+		{`jsonpath=.items[0].namespace`, "shop"},
+		{`jsonpath={range .items[*]}{.name}={.cpuVerdict}{"\n"}{end}`, "cart=\netl=\n"},
+		{`jsonpath={.summary.rows}`, "2"},
+	}
+
+	prevOut, prevFormat := recordsOut, format
+	t.Cleanup(func() { recordsOut, format = prevOut, prevFormat })
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			if err := SetFormat(tc.spec); err != nil {
+				t.Fatalf("SetFormat(%q): %v", tc.spec, err)
+			}
+			var buf bytes.Buffer
+			recordsOut = &buf
+			for _, r := range records {
+				emitRecord(r)
+			}
+			emitSummary(summaryRecord{Type: "summary", Of: "workload", Rows: len(records)})
+			if err := flushRecords(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("output = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFlushRecordsExecuteError(t *testing.T) {
+	prevOut, prevFormat := recordsOut, format
+	t.Cleanup(func() { recordsOut, format = prevOut, prevFormat })
+	for _, spec := range []string{`go-template={{index .items 5}}`, `jsonpath={.items[5].name}`} {
+		if err := SetFormat(spec); err != nil {
+			t.Fatalf("SetFormat(%q): %v", spec, err)
+		}
+		var buf bytes.Buffer
+		recordsOut = &buf
+		emitRecord(workloadRecord{Type: "workload", Namespace: "shop", Name: "cart"})
+		if err := flushRecords(); err == nil || buf.Len() > 0 {
+			t.Errorf("%s: err = %v, output %q; want an error and no output", spec, err, buf.String())
+		}
+	}
+}
+
+func TestSetFormatErrors(t *testing.T) {
+	prevFormat := format
+	t.Cleanup(func() { format = prevFormat })
+	for _, spec := range []string{"yaml", "ndjson=x", "go-template", "go-template={{.name", "jsonpath=", "jsonpath={.items["} {
+		if err := SetFormat(spec); err == nil {
+			t.Errorf("SetFormat(%q) succeeded, want error", spec)
+		}
+	}
+}
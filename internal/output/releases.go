@@ -0,0 +1,110 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// releaseWorkloadsShown caps the workloads named per release; the rest are counted.
+const releaseWorkloadsShown = 3
+
+// ReleasesOptions controls truncation in RenderReleases.
+type ReleasesOptions struct {
+	Limit            int  // top N releases (0 = all)
+	MetricsAvailable bool // pod metrics were fetched
+}
+
+// totalsHeaders are the headers of the totalsCells columns.
+var totalsHeaders = []string{"Pods", "CPU Req", "CPU Actual", "CPU Waste", "Mem Req", "Mem Actual", "Mem Waste", "Waste Share (CPU)"}
+
+// totalsCells renders the requests, usage and waste of a group of workloads, and its share of
+// totalWasteCPU.
+func totalsCells(t analysis.WorkloadTotals, metricsAvailable bool, totalWasteCPU int64) []cellValue {
+	cpuActual, cpuWaste, memActual, memWaste, share := naCell(), naCell(), naCell(), naCell(), naCell()
+	if metricsAvailable && t.Metered.Pods > 0 {
+		cpuActual = cv(kube.FormatCPU(t.Metered.CPUActual))
+		cpuWaste = cv(kube.FormatCPU(t.Metered.CPUWaste()))
+		memActual = cv(kube.FormatMem(t.Metered.MemActual))
+		memWaste = cv(kube.FormatMem(t.Metered.MemWaste()))
+		share = cv(formatPct(safePctInt(t.Metered.CPUWaste(), totalWasteCPU)))
+	}
+	return []cellValue{
+		cv(fmt.Sprintf("%d", t.Pods)),
+		cv(kube.FormatCPU(t.CPURequest)),
+		cpuActual,
+		cpuWaste,
+		cv(kube.FormatMem(t.MemRequest)),
+		memActual,
+		memWaste,
+		share,
+	}
+}
+
+// RenderReleases renders requests, usage and waste per Helm release to stdout and saves a
+// markdown file.
+func RenderReleases(groups []analysis.ReleaseGroup, contextName string, opts ReleasesOptions) {
+	ts := time.Now()
+
+	var (
+		totalWasteCPU int64
+		totalWasteMem float64
+		releases      int
+		unreleased    int
+	)
+	for _, g := range groups {
+		totalWasteCPU += g.Metered.CPUWaste()
+		totalWasteMem += g.Metered.MemWaste()
+		if g.Release == "" {
+			unreleased += len(g.Workloads)
+		} else {
+			releases++
+		}
+	}
+	if opts.Limit > 0 && len(groups) > opts.Limit {
+		groups = groups[:opts.Limit]
+	}
+
+	title := fmt.Sprintf("Workloads by Helm Release — %s", contextName)
+	headers := append([]string{"#", "Namespace", "Release", "Workloads"}, totalsHeaders...)
+
+	var rows [][]cellValue
+	for i, g := range groups {
+		workloads := g.Workloads
+		more := ""
+		if len(workloads) > releaseWorkloadsShown {
+			more = fmt.Sprintf(" +%d more", len(workloads)-releaseWorkloadsShown)
+			workloads = workloads[:releaseWorkloadsShown]
+		}
+		rows = append(rows, append([]cellValue{
+			cv(fmt.Sprintf("%d", i+1)),
+			cv(g.Namespace),
+			cv(orNone(g.Release)),
+			cv(strings.Join(workloads, ", ") + more),
+		}, totalsCells(g.WorkloadTotals, opts.MetricsAvailable, totalWasteCPU)...))
+	}
+
+	summary := fmt.Sprintf("%d Helm %s; %d %s not installed by Helm.",
+		releases, kube.Plural(releases, "release", "releases"), unreleased, kube.Plural(unreleased, "workload", "workloads"))
+	if opts.MetricsAvailable {
+		summary += fmt.Sprintf(" %s CPU and %s memory requested but unused.", kube.FormatCPU(totalWasteCPU), kube.FormatMem(totalWasteMem))
+	}
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	mdContent += "\n\n" + summary
+	if mermaidCharts && opts.MetricsAvailable {
+		waste := make(map[string]float64, len(groups))
+		for _, g := range groups {
+			waste[g.Namespace+"/"+orNone(g.Release)] = float64(g.Metered.CPUWaste())
+		}
+		if chart := mermaidPie("CPU requested but unused by Helm release (millicores)", waste, 10); chart != "" {
+			mdContent += "\n\n" + chart
+		}
+	}
+	saveMarkdownFile("deployments-by-release", contextName, ts, mdContent)
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// renderNodesReserved breaks down the gap between each node's capacity and allocatable:
+// what the kubelet reserves for itself (kube-reserved), for the OS (system-reserved), as
+// eviction buffer and as pre-allocated hugepages. reserved holds the kubelet config per
+// node; nodes missing from it show the total gap only.
+func renderNodesReserved(result *kube.FetchNodesResult, contextName string, reserved map[string]kube.KubeletReserved) string {
+	title := fmt.Sprintf("Reserved Capacity — %s", contextName)
+	headers := []string{
+		"Node",
+		"CPU Capacity", "CPU Allocatable", "CPU Reserved", "CPU Breakdown",
+		"Mem Capacity", "Mem Allocatable", "Mem Reserved", "Mem Breakdown",
+	}
+
+	var rows [][]cellValue
+	var capCPU, gapCPU int64
+	var capMem, gapMem float64
+	for _, node := range result.Nodes {
+		cpuGap := max(node.CapacityCPU-node.AllocatableCPU, 0)
+		memGap := max(node.CapacityMem-node.AllocatableMem, 0)
+		capCPU += node.CapacityCPU
+		gapCPU += cpuGap
+		capMem += node.CapacityMem
+		gapMem += memGap
+
+		cpuBreakdown, memBreakdown := naCell(), naCell()
+		if r, ok := reserved[node.Name]; ok {
+			cpuBreakdown = cv(reservedCPUBreakdown(r, cpuGap))
+			memBreakdown = cv(reservedMemBreakdown(r, memGap, hugePagesMem(node)))
+		}
+		rows = append(rows, []cellValue{
+			cv(node.Name),
+			cv(kube.FormatCPU(node.CapacityCPU)),
+			cv(kube.FormatCPU(node.AllocatableCPU)),
+			cv(fmt.Sprintf("%s (%s)", kube.FormatCPU(cpuGap), formatPctFine(safePctInt(cpuGap, node.CapacityCPU)))),
+			cpuBreakdown,
+			cv(kube.FormatMem(node.CapacityMem)),
+			cv(kube.FormatMem(node.AllocatableMem)),
+			cv(fmt.Sprintf("%s (%s)", kube.FormatMem(memGap), formatPctFine(safePctFloat(memGap, node.CapacityMem)))),
+			memBreakdown,
+		})
+	}
+
+	md := renderTable(title, headers, rows)
+
+	footer := fmt.Sprintf("Nodes withhold %s CPU (%s) and %s memory (%s) of capacity from pods cluster-wide.",
+		kube.FormatCPU(gapCPU), formatPctFine(safePctInt(gapCPU, capCPU)), kube.FormatMem(gapMem), formatPctFine(safePctFloat(gapMem, capMem)))
+	if len(reserved) < len(result.Nodes) {
+		footer += " The breakdown needs the kubelet config (nodes/proxy permission); N/A where it couldn't be read."
+	}
+	fmt.Println(footer)
+	return md + "\n\n" + footer
+}
+
+// hugePagesMem sums the node's pre-allocated hugepages in MiB; they count towards memory
+// capacity but not allocatable memory.
+func hugePagesMem(node kube.NodeInfo) float64 {
+	var total float64
+	for _, hp := range node.HugePages {
+		total += hp.Allocatable
+	}
+	return total
+}
+
+func reservedCPUBreakdown(r kube.KubeletReserved, gap int64) string {
+	parts := []string{
+		"kube " + kube.FormatCPU(r.KubeReservedCPU),
+		"system " + kube.FormatCPU(r.SystemReservedCPU),
+	}
+	if other := gap - r.KubeReservedCPU - r.SystemReservedCPU; other != 0 {
+		parts = append(parts, "other "+kube.FormatCPU(other))
+	}
+	return strings.Join(parts, " + ")
+}
+
+func reservedMemBreakdown(r kube.KubeletReserved, gap, hugePages float64) string {
+	parts := []string{
+		"kube " + kube.FormatMem(r.KubeReservedMem),
+		"system " + kube.FormatMem(r.SystemReservedMem),
+		"eviction " + kube.FormatMem(r.EvictionMem),
+	}
+	if hugePages > 0 {
+		parts = append(parts, "hugepages "+kube.FormatMem(hugePages))
+	}
+	// Less than 1MiB unaccounted for is rounding of the quantities.
+	if other := gap - r.KubeReservedMem - r.SystemReservedMem - r.EvictionMem - hugePages; math.Abs(other) >= 1 {
+		parts = append(parts, "other "+kube.FormatMem(other))
+	}
+	return strings.Join(parts, " + ")
+}
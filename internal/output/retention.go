@@ -0,0 +1,112 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Retention limits how many timestamped reports are kept per command and context.
+type Retention struct {
+	KeepLast int           // keep at most this many reports per command (0 = unlimited)
+	MaxAge   time.Duration // remove reports older than this (0 = unlimited)
+}
+
+// Enabled reports whether any retention rule is set.
+func (r Retention) Enabled() bool { return r.KeepLast > 0 || r.MaxAge > 0 }
+
+var retention Retention
+
+// SetRetention sets the rules applied after every saved report.
+func SetRetention(r Retention) { retention = r }
+
+// reportName matches files written by saveMarkdownFile: <command>_<YYYYMMDD_HHMMSS>.md.
+var reportName = regexp.MustCompile(`^(.+)_(\d{8}_\d{6})\.md$`)
+
+// PruneReports applies r to the reports of every context under the output directory,
+// or only to contextName when non-empty. With dryRun nothing is deleted. It returns the
+// paths of the (would-be) removed files.
+func PruneReports(contextName string, r Retention, dryRun bool) ([]string, error) {
+	var dirs []string
+	if contextName != "" {
+		dirs = []string{filepath.Join(outputDir, sanitizeContextName(contextName))}
+	} else {
+		entries, err := os.ReadDir(outputDir)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				dirs = append(dirs, filepath.Join(outputDir, e.Name()))
+			}
+		}
+	}
+
+	var removed []string
+	for _, dir := range dirs {
+		paths, err := pruneDir(dir, "", r, time.Now(), dryRun)
+		removed = append(removed, paths...)
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// pruneDir applies r to the reports in dir, per command (or only to command when non-empty).
+// Files that don't look like timestamped reports are never touched.
+func pruneDir(dir, command string, r Retention, now time.Time, dryRun bool) ([]string, error) {
+	if !r.Enabled() {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type report struct {
+		path string
+		ts   time.Time
+	}
+	byCommand := make(map[string][]report)
+	for _, e := range entries {
+		m := reportName.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil || (command != "" && m[1] != command) {
+			continue
+		}
+		ts, err := time.ParseInLocation("20060102_150405", m[2], time.Local)
+		if err != nil {
+			continue
+		}
+		byCommand[m[1]] = append(byCommand[m[1]], report{path: filepath.Join(dir, e.Name()), ts: ts})
+	}
+
+	var removed []string
+	for _, reports := range byCommand {
+		sort.Slice(reports, func(i, j int) bool { return reports[i].ts.After(reports[j].ts) })
+		for i, rep := range reports {
+			tooMany := r.KeepLast > 0 && i >= r.KeepLast
+			tooOld := r.MaxAge > 0 && now.Sub(rep.ts) > r.MaxAge
+			if !tooMany && !tooOld {
+				continue
+			}
+			if !dryRun {
+				if err := os.Remove(rep.path); err != nil {
+					return removed, fmt.Errorf("failed to remove %s: %w", rep.path, err)
+				}
+			}
+			removed = append(removed, rep.path)
+		}
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
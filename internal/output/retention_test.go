@@ -0,0 +1,60 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPruneDir(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	files := []string{
+		"nodes_20260310_110000.md", // 1h old
+		"nodes_20260309_120000.md", // 1d old
+		"nodes_20260301_120000.md", // 9d old
+		"pods_20260201_120000.md",  // 37d old
+		"nodes_latest.md",          // not a timestamped report
+		"notes.txt",
+	}
+
+	tests := []struct {
+		name      string
+		command   string
+		retention Retention
+		want      []string
+	}{
+		{"no rules keeps everything", "", Retention{}, nil},
+		{"keep last per command", "", Retention{KeepLast: 1}, []string{"nodes_20260301_120000.md", "nodes_20260309_120000.md"}},
+		{"max age", "", Retention{MaxAge: 7 * 24 * time.Hour}, []string{"nodes_20260301_120000.md", "pods_20260201_120000.md"}},
+		{"either rule removes", "", Retention{KeepLast: 2, MaxAge: 30 * 24 * time.Hour}, []string{"nodes_20260301_120000.md", "pods_20260201_120000.md"}},
+		{"only the given command", "pods", Retention{KeepLast: 0, MaxAge: time.Hour}, []string{"pods_20260201_120000.md"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range files {
+				if err := os.WriteFile(filepath.Join(dir, f), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			removed, err := pruneDir(dir, tc.command, tc.retention, now, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(removed) != len(tc.want) {
+				t.Fatalf("removed %v, want %v", removed, tc.want)
+			}
+			for i, p := range removed {
+				if filepath.Base(p) != tc.want[i] {
+					t.Errorf("removed %v, want %v", removed, tc.want)
+				}
+				if _, err := os.Stat(p); !os.IsNotExist(err) {
+					t.Errorf("%s still exists", p)
+				}
+			}
+		})
+	}
+}
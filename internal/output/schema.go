@@ -0,0 +1,14 @@
+package output
+
+import _ "embed"
+
+// SchemaVersion is the version of the structured output records. Fields may be added
+// within a version; renaming, removing or retyping one bumps it.
+const SchemaVersion = "v1"
+
+//go:embed schema/records.v1.json
+var schema []byte
+
+// Schema returns the JSON Schema (draft 2020-12) of the records written by
+// --output ndjson, go-template and jsonpath.
+func Schema() []byte { return schema }
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSchemaMatchesRecords(t *testing.T) {
+	var doc struct {
+		ID   string `json:"$id"`
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Schema(), &doc); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if !strings.Contains(doc.ID, "records."+SchemaVersion+".json") {
+		t.Errorf("$id %q does not carry SchemaVersion %s", doc.ID, SchemaVersion)
+	}
+
+	records := map[string]any{"pod": podRecord{}, "workload": workloadRecord{}, "node": nodeRecord{}, "summary": summaryRecord{}}
+	for name, record := range records {
+		def, ok := doc.Defs[name]
+		if !ok {
+			t.Errorf("schema has no $defs/%s", name)
+			continue
+		}
+		var fields []string
+		typ := reflect.TypeOf(record)
+		for i := range typ.NumField() {
+			fields = append(fields, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+		}
+		var props []string
+		for p := range def.Properties {
+			props = append(props, p)
+		}
+		slices.Sort(fields)
+		slices.Sort(props)
+		if !slices.Equal(fields, props) {
+			t.Errorf("$defs/%s properties = %v, record fields = %v", name, props, fields)
+		}
+		required := slices.Sorted(slices.Values(def.Required))
+		if !slices.Equal(fields, required) {
+			t.Errorf("$defs/%s required = %v, want every field %v", name, required, fields)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// renderNodesEphemeral renders ephemeral-storage allocatable, requests, limits and use per
+// node, with the verdict of the CPU and memory columns. storage holds the kubelet-reported
+// use per node; nodes missing from it show N/A.
+func renderNodesEphemeral(result *kube.FetchNodesResult, contextName string, storage map[string]kube.NodeStorage) string {
+	title := fmt.Sprintf("Ephemeral Storage — %s", contextName)
+	headers := []string{"Node", "Allocatable", "Requested", "Limits", "Used", "Verdict"}
+
+	var rows [][]cellValue
+	var totalAlloc, totalReq, totalUsed float64
+	for _, node := range result.Nodes {
+		reqPct := safePctFloat(node.RequestedEphemeral, node.AllocatableEphemeral)
+		totalAlloc += node.AllocatableEphemeral
+		totalReq += node.RequestedEphemeral
+
+		usedCell, verdictCell := naCell(), naCell()
+		if s, ok := storage[node.Name]; ok {
+			usedPct := safePctFloat(s.PodsEphemeralUsed, node.AllocatableEphemeral)
+			totalUsed += s.PodsEphemeralUsed
+			usedCell = withGauge(cv(fmt.Sprintf("%s (%s)", formatPct(usedPct), kube.FormatMem(s.PodsEphemeralUsed))), usedPct)
+			v := analysis.ResourceVerdict(reqPct, usedPct)
+			verdictCell = cvColored(v.Label, text.Colors{v.Color})
+		}
+		if node.AllocatableEphemeral == 0 {
+			usedCell, verdictCell = naCell(), naCell()
+		}
+		rows = append(rows, []cellValue{
+			cv(node.Name),
+			cv(kube.FormatMem(node.AllocatableEphemeral)),
+			withGauge(cv(fmt.Sprintf("%s (%s)", formatPct(reqPct), kube.FormatMem(node.RequestedEphemeral))), reqPct),
+			cv(fmt.Sprintf("%s (%s)", formatPct(safePctFloat(node.LimitEphemeral, node.AllocatableEphemeral)), kube.FormatMem(node.LimitEphemeral))),
+			usedCell,
+			verdictCell,
+		})
+	}
+
+	md := renderTable(title, headers, rows)
+	footer := fmt.Sprintf("Pods request %s (%s) of %s allocatable ephemeral storage cluster-wide",
+		kube.FormatMem(totalReq), formatPct(safePctFloat(totalReq, totalAlloc)), kube.FormatMem(totalAlloc))
+	if len(storage) > 0 {
+		footer += fmt.Sprintf(" and write %s (%s)", kube.FormatMem(totalUsed), formatPct(safePctFloat(totalUsed, totalAlloc)))
+	}
+	footer += ". Pods without a request are placed regardless of disk space and evicted first under DiskPressure."
+	if len(storage) < len(result.Nodes) {
+		footer += " Use needs the kubelet stats (nodes/proxy permission); N/A where they couldn't be read."
+	}
+	fmt.Println(footer)
+	return md + "\n\n" + footer
+}
+
+// diskEvictionMargin is how many percentage points below its eviction threshold a node
+// filesystem is flagged as close to it.
+const diskEvictionMargin = 10
+
+// diskCell shows the used share of a node's nodefs and, when separate, imagefs: red past
+// the kubelet's eviction threshold or under DiskPressure, yellow within diskEvictionMargin
+// of the threshold.
+func diskCell(node kube.NodeInfo, storage map[string]kube.NodeStorage) cellValue {
+	s, ok := storage[node.Name]
+	if !ok || s.NodeFS.Capacity == 0 {
+		if node.DiskPressure {
+			return cvColored("DiskPressure", text.Colors{text.FgRed})
+		}
+		return naCell()
+	}
+	filesystems := []kube.FilesystemUsage{s.NodeFS}
+	str := formatPct(s.NodeFS.UsedPct())
+	if s.SeparateImageFS() {
+		filesystems = append(filesystems, s.ImageFS)
+		str += fmt.Sprintf(" / %s", formatPct(s.ImageFS.UsedPct()))
+	}
+	var color text.Colors
+	for _, fs := range filesystems {
+		switch {
+		case node.DiskPressure || fs.UsedPct() >= fs.EvictionPct():
+			color = text.Colors{text.FgRed}
+		case fs.UsedPct() >= fs.EvictionPct()-diskEvictionMargin && color == nil:
+			color = text.Colors{text.FgYellow}
+		}
+	}
+	return cvColored(str, color)
+}
+
+// diskNote names the nodes under DiskPressure and, with storage, those whose filesystems
+// are past or close to the kubelet's eviction threshold.
+func diskNote(nodes []kube.NodeInfo, storage map[string]kube.NodeStorage) string {
+	var pressure, past, near []string
+	for _, n := range nodes {
+		if n.DiskPressure {
+			pressure = append(pressure, n.Name)
+			continue
+		}
+		s, ok := storage[n.Name]
+		if !ok {
+			continue
+		}
+		// A node is listed once, under its fullest filesystem's heading.
+		var pastFS, nearFS string
+		for _, fs := range []struct {
+			name  string
+			usage kube.FilesystemUsage
+		}{{"nodefs", s.NodeFS}, {"imagefs", s.ImageFS}} {
+			if fs.name == "imagefs" && !s.SeparateImageFS() {
+				continue
+			}
+			if fs.usage.Capacity == 0 {
+				continue
+			}
+			desc := fmt.Sprintf("%s (%s %s used, evicts at %s)", n.Name, fs.name, formatPct(fs.usage.UsedPct()), formatPct(fs.usage.EvictionPct()))
+			switch {
+			case fs.usage.UsedPct() >= fs.usage.EvictionPct() && pastFS == "":
+				pastFS = desc
+			case fs.usage.UsedPct() >= fs.usage.EvictionPct()-diskEvictionMargin && nearFS == "":
+				nearFS = desc
+			}
+		}
+		switch {
+		case pastFS != "":
+			past = append(past, pastFS)
+		case nearFS != "":
+			near = append(near, nearFS)
+		}
+	}
+	var parts []string
+	if len(pressure) > 0 {
+		parts = append(parts, "DiskPressure, the kubelet evicts pods and places no new ones: "+strings.Join(pressure, ", ")+
+			". Pods failing to schedule or being evicted there are short of disk, not CPU or memory.")
+	}
+	if len(past) > 0 {
+		parts = append(parts, "Past the eviction threshold, the kubelet reclaims images and evicts pods once it notices: "+strings.Join(past, "; ")+".")
+	}
+	if len(near) > 0 {
+		parts = append(parts, "Close to disk eviction: "+strings.Join(near, "; ")+".")
+	}
+	return strings.Join(parts, " ")
+}
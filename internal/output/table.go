@@ -3,13 +3,18 @@ package output
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/diag"
 	"github.com/amasotti/kusa/internal/kube"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
 var noColor bool
@@ -17,15 +22,34 @@ var noColor bool
 // SetNoColor disables ANSI color codes in console output.
 func SetNoColor(v bool) { noColor = v }
 
+var plain bool
+
+// SetPlain prints tables as markdown and leaves out the "Saved:" lines, for a stdout that
+// is piped into another program or a file rather than read in a terminal.
+func SetPlain(v bool) { plain = v }
+
 // cellValue holds a text value and optional ANSI colors for console rendering.
 type cellValue struct {
 	text   string
 	colors text.Colors
+	gauge  string // console-only prefix, see withGauge
 }
 
 func cv(s string) cellValue                       { return cellValue{text: s} }
 func cvColored(s string, c text.Colors) cellValue { return cellValue{text: s, colors: c} }
 
+// gaugeWidth is the number of block characters in a utilization gauge.
+const gaugeWidth = 5
+
+// withGauge prefixes the console rendering of c with a bar for pct (0–100, clamped),
+// e.g. "▓▓░░░ 35% (2.80)". Markdown output keeps the plain text.
+func withGauge(c cellValue, pct float64) cellValue {
+	filled := int(pct/100*gaugeWidth + 0.5)
+	filled = min(max(filled, 0), gaugeWidth)
+	c.gauge = strings.Repeat("▓", filled) + strings.Repeat("░", gaugeWidth-filled)
+	return c
+}
+
 // renderTable renders a table to stdout (with colors) and returns a markdown string.
 func renderTable(title string, headers []string, rows [][]cellValue) string {
 	headerRow := make(table.Row, len(headers))
@@ -33,6 +57,22 @@ func renderTable(title string, headers []string, rows [][]cellValue) string {
 		headerRow[i] = h
 	}
 
+	// Markdown table (plain text)
+	md := table.NewWriter()
+	md.AppendHeader(headerRow)
+	for _, row := range rows {
+		r := make(table.Row, len(row))
+		for i, cell := range row {
+			r[i] = cell.text
+		}
+		md.AppendRow(r)
+	}
+	mdContent := md.RenderMarkdown()
+	if plain {
+		fmt.Printf("## %s\n\n%s\n", title, mdContent)
+		return mdContent
+	}
+
 	// Console table
 	console := table.NewWriter()
 	console.SetOutputMirror(os.Stdout)
@@ -41,28 +81,36 @@ func renderTable(title string, headers []string, rows [][]cellValue) string {
 	for _, row := range rows {
 		r := make(table.Row, len(row))
 		for i, cell := range row {
+			s := cell.text
+			if cell.gauge != "" {
+				s = cell.gauge + " " + s
+			}
 			if !noColor && len(cell.colors) > 0 {
-				r[i] = cell.colors.Sprint(cell.text)
-			} else {
-				r[i] = cell.text
+				s = cell.colors.Sprint(s)
 			}
+			r[i] = s
 		}
 		console.AppendRow(r)
 	}
 	console.SetStyle(table.StyleRounded)
 	console.Render()
+	return mdContent
+}
 
-	// Markdown table (plain text)
-	md := table.NewWriter()
-	md.AppendHeader(headerRow)
-	for _, row := range rows {
-		r := make(table.Row, len(row))
-		for i, cell := range row {
-			r[i] = cell.text
-		}
-		md.AppendRow(r)
-	}
-	return md.RenderMarkdown()
+var pctDecimals int
+
+// SetPctDecimals sets the decimals percentages are shown with (default 0: "35%").
+func SetPctDecimals(n int) { pctDecimals = n }
+
+// formatPct formats a percentage with the configured decimals.
+func formatPct(pct float64) string {
+	return strconv.FormatFloat(pct, 'f', pctDecimals, 64) + "%"
+}
+
+// formatPctFine formats a percentage with at least one decimal, for small shares such as
+// reserved capacity where whole percent would round everything to the same value.
+func formatPctFine(pct float64) string {
+	return strconv.FormatFloat(pct, 'f', max(pctDecimals, 1), 64) + "%"
 }
 
 func safePctInt(value, total int64) float64 {
@@ -83,6 +131,14 @@ func naCell() cellValue {
 	return cvColored("N/A", text.Colors{text.Faint})
 }
 
+// actualCell shows a usage value, marked when its metrics sample is stale.
+func actualCell(s string, stale bool) cellValue {
+	if stale {
+		return cvColored(s+" (stale)", text.Colors{text.FgYellow})
+	}
+	return cv(s)
+}
+
 // meetsFactorFilter reports whether a req/actual pair satisfies a --min-factor threshold.
 //
 //	threshold == 0 → always true (filter disabled)
@@ -106,6 +162,28 @@ func meetsFactorFilter(req, actual int64, metricsAvail bool, threshold int) bool
 	return req/actual >= int64(threshold)
 }
 
+// NoLimitsModes lists the accepted values of the --no-limits filter.
+var NoLimitsModes = []string{"cpu", "memory", "any"}
+
+// meetsNoLimitsFilter reports whether a row satisfies a --no-limits mode:
+//
+//	""       → always true (filter disabled)
+//	"cpu"    → some container has no CPU limit
+//	"memory" → some container has no memory limit
+//	"any"    → some container lacks either limit
+func meetsNoLimitsFilter(missingCPU, missingMem bool, mode string) bool {
+	switch mode {
+	case "":
+		return true
+	case "cpu":
+		return missingCPU
+	case "memory":
+		return missingMem
+	default:
+		return missingCPU || missingMem
+	}
+}
+
 // verdictFromRatio computes a verdict by treating req as 100% and expressing actual as
 // a percentage of it. This makes ResourceVerdict reusable for pods and workloads where
 // there is no node-level allocatable capacity to normalise against.
@@ -120,43 +198,178 @@ func verdictFromRatio(req, actual float64, metricsAvail bool) cellValue {
 	return cvColored(v.Label, text.Colors{v.Color})
 }
 
+// overallVerdictCell combines the CPU and memory verdicts (worst of both); a dimension
+// without requests is left out.
+func overallVerdictCell(cpuReq, cpuActual, memReq, memActual float64, metricsAvail bool) cellValue {
+	if cpuReq == 0 && memReq == 0 {
+		return cvColored("no req", text.Colors{text.Faint})
+	}
+	if !metricsAvail {
+		return naCell()
+	}
+	v := analysis.VerdictOK
+	if cpuReq > 0 {
+		v = analysis.OverallVerdict(v, analysis.ResourceVerdict(100, cpuActual/cpuReq*100))
+	}
+	if memReq > 0 {
+		v = analysis.OverallVerdict(v, analysis.ResourceVerdict(100, memActual/memReq*100))
+	}
+	return cvColored(v.Label, text.Colors{v.Color})
+}
+
+// verdictReason spells out the numbers behind a row's verdicts, e.g.
+// "CPU 4 req, 180m used (5%) → 22x; mem 2Gi req, 1.9Gi used (95%) → 1x", so readers of a
+// report can check a label instead of trusting it.
+func verdictReason(cpuReq, cpuActual int64, memReq, memActual float64, metricsAvail bool) string {
+	if !metricsAvail {
+		return "no usage known, so no verdict"
+	}
+	return resourceReason("CPU", float64(cpuReq), float64(cpuActual), kube.FormatCPU(cpuReq), kube.FormatCPU(cpuActual)) + "; " +
+		resourceReason("mem", memReq, memActual, kube.FormatMem(memReq), kube.FormatMem(memActual))
+}
+
+// resourceReason is one resource of verdictReason: request, usage, usage as a share of the
+// request and, when usage is within the request, the over-request factor.
+func resourceReason(name string, req, actual float64, reqStr, actualStr string) string {
+	if req == 0 {
+		return name + " no req"
+	}
+	s := fmt.Sprintf("%s %s req, %s used (%s)", name, reqStr, actualStr, formatPct(actual/req*100))
+	if actual > 0 && actual <= req {
+		s += " → " + kube.FormatFactor(req, actual)
+	}
+	return s
+}
+
+// explainNote describes the Why column added by --explain: how its numbers map to verdicts.
+func explainNote() string {
+	return fmt.Sprintf("Why: usage as a share of the request, and the request/usage factor (rounded down). A resource is over-requested "+
+		"when its usage is more than %d points below the request, massively beyond %d points, and bursting above 100%%.",
+		analysis.OverRequestedPoints, analysis.MassivelyOverRequestedPoints)
+}
+
+// skippedNote is the errors footer of a report that left out the namespaces the user may
+// not read (see kube.FetchPodsResult.Skipped), "" when none was skipped.
+func skippedNote(skipped []string) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Errors: skipped %d %s you may not read (forbidden), not included above: %s",
+		len(skipped), kube.Plural(len(skipped), "namespace", "namespaces"), strings.Join(skipped, ", "))
+}
+
+// NodesOptions controls which tables RenderNodes produces.
+type NodesOptions struct {
+	IncludeSystem bool // include system namespaces in the pod overview
+	PodOverview   bool
+	MinFactor     int  // pod overview only; see meetsFactorFilter
+	DaemonSets    bool // per-node overhead of DaemonSet pods
+
+	Reserved        bool                            // capacity withheld from pods per node
+	KubeletReserved map[string]kube.KubeletReserved // kubelet config per node, for the breakdown
+
+	Ephemeral bool                        // ephemeral-storage allocatable, requests and use per node
+	Disk      bool                        // nodefs/imagefs use column in the nodes table
+	Storage   map[string]kube.NodeStorage // kubelet stats per node, for Ephemeral and Disk
+
+	Overcommit       bool
+	MaxCPULimitRatio float64 // tolerated CPU limits/allocatable before "Over budget"
+	MaxMemLimitRatio float64 // tolerated memory limits/allocatable before "Over budget"
+
+	// Reclaim, when set, adds the node-equivalents right-sizing would free to the summary.
+	Reclaim *analysis.ReclaimEstimate
+	// ReclaimHeadroom is the headroom Reclaim was estimated with (0.3 = +30%), for the note.
+	ReclaimHeadroom float64
+
+	// Top writes the nodes like kubectl top nodes (--compat top) instead of the tables.
+	Top bool
+}
+
 // RenderNodes renders the nodes table to stdout and saves markdown files.
-func RenderNodes(result *kube.FetchNodesResult, contextName string, includeSystem bool, podOverview bool) {
+func RenderNodes(result *kube.FetchNodesResult, contextName string, opts NodesOptions) error {
 	ts := time.Now()
+	if opts.Top {
+		if err := writeNodesTop(topOut, result); err != nil {
+			diag.Warnf("failed to write nodes: %v", err)
+		}
+		return nil
+	}
+	if structured() {
+		for _, n := range result.Nodes {
+			emitRecord(newNodeRecord(n, contextName, result.NodeMetricsAvailable && n.MetricsAvailable))
+		}
+		return flushRecords()
+	}
 
 	fmt.Println()
-	mdContent := renderNodesMain(result, contextName)
+	mdContent := renderNodesMain(result, contextName, opts)
 	saveMarkdownFile("nodes", contextName, ts, mdContent)
 
-	if podOverview {
+	if hasHugePages(result.Nodes) {
+		fmt.Println()
+		mdContent := renderNodesHugePages(result, contextName)
+		saveMarkdownFile("nodes_hugepages", contextName, ts, mdContent)
+	}
+
+	if opts.Reserved {
 		fmt.Println()
-		mdContent := renderNodesPodOverview(result, contextName, includeSystem)
+		mdContent := renderNodesReserved(result, contextName, opts.KubeletReserved)
+		saveMarkdownFile("nodes_reserved", contextName, ts, mdContent)
+	}
+
+	if opts.Ephemeral {
+		fmt.Println()
+		mdContent := renderNodesEphemeral(result, contextName, opts.Storage)
+		saveMarkdownFile("nodes_ephemeral", contextName, ts, mdContent)
+	}
+
+	if opts.DaemonSets {
+		fmt.Println()
+		mdContent := renderNodesDaemonSets(result, contextName)
+		saveMarkdownFile("nodes_daemonsets", contextName, ts, mdContent)
+	}
+
+	if opts.Overcommit {
+		fmt.Println()
+		mdContent := renderNodesOvercommit(result, contextName, opts.MaxCPULimitRatio, opts.MaxMemLimitRatio)
+		saveMarkdownFile("nodes_overcommit", contextName, ts, mdContent)
+	}
+
+	if opts.PodOverview {
+		fmt.Println()
+		mdContent := renderNodesPodOverview(result, contextName, opts.IncludeSystem, opts.MinFactor)
 		saveMarkdownFile("nodes_pod_overview", contextName, ts, mdContent)
 	}
+	return nil
 }
 
-func renderNodesMain(result *kube.FetchNodesResult, contextName string) string {
+func renderNodesMain(result *kube.FetchNodesResult, contextName string, opts NodesOptions) string {
 	title := fmt.Sprintf("Nodes — %s", contextName)
 	headers := []string{
-		"Node",
+		"Node", "OS/Arch", "Pods",
 		"CPU Actual", "CPU Requested", "CPU Verdict",
 		"Mem Actual", "Mem Requested", "Mem Verdict",
+		"Headroom (CPU/Mem)",
+	}
+	if opts.Disk {
+		headers = slices.Insert(headers, len(headers)-1, "Disk (nodefs/imagefs)")
 	}
 
 	var rows [][]cellValue
+	var maxCPU, maxMem kube.NodeInfo
 	for _, node := range result.Nodes {
 		cpuActualPct := safePctInt(node.ActualCPU, node.AllocatableCPU)
 		cpuReqPct := safePctInt(node.RequestedCPU, node.AllocatableCPU)
 		memActualPct := safePctFloat(node.ActualMem, node.AllocatableMem)
 		memReqPct := safePctFloat(node.RequestedMem, node.AllocatableMem)
 
-		cpuReqStr := fmt.Sprintf("%.0f%% (%s)", cpuReqPct, kube.FormatCPU(node.RequestedCPU))
-		memReqStr := fmt.Sprintf("%.0f%% (%s)", memReqPct, kube.FormatMem(node.RequestedMem))
+		cpuReqStr := fmt.Sprintf("%s (%s)", formatPct(cpuReqPct), kube.FormatCPU(node.RequestedCPU))
+		memReqStr := fmt.Sprintf("%s (%s)", formatPct(memReqPct), kube.FormatMem(node.RequestedMem))
 
 		var cpuActualCell, memActualCell, cpuVerdictCell, memVerdictCell cellValue
 		if result.NodeMetricsAvailable && node.MetricsAvailable {
-			cpuActualCell = cv(fmt.Sprintf("%.0f%% (%s)", cpuActualPct, kube.FormatCPU(node.ActualCPU)))
-			memActualCell = cv(fmt.Sprintf("%.0f%% (%s)", memActualPct, kube.FormatMem(node.ActualMem)))
+			cpuActualCell = withGauge(actualCell(fmt.Sprintf("%s (%s)", formatPct(cpuActualPct), kube.FormatCPU(node.ActualCPU)), node.MetricsStale), cpuActualPct)
+			memActualCell = withGauge(actualCell(fmt.Sprintf("%s (%s)", formatPct(memActualPct), kube.FormatMem(node.ActualMem)), node.MetricsStale), memActualPct)
 
 			cpuV := analysis.ResourceVerdict(cpuReqPct, cpuActualPct)
 			memV := analysis.ResourceVerdict(memReqPct, memActualPct)
@@ -169,21 +382,327 @@ func renderNodesMain(result *kube.FetchNodesResult, contextName string) string {
 			memVerdictCell = naCell()
 		}
 
-		rows = append(rows, []cellValue{
-			cv(node.Name),
+		row := []cellValue{
+			nodeNameCell(node),
+			cv(nodePlatform(node)),
+			podSlotsCell(node),
 			cpuActualCell,
-			cv(cpuReqStr),
+			withGauge(cv(cpuReqStr), cpuReqPct),
 			cpuVerdictCell,
 			memActualCell,
-			cv(memReqStr),
+			withGauge(cv(memReqStr), memReqPct),
 			memVerdictCell,
-		})
+		}
+		if opts.Disk {
+			row = append(row, diskCell(node, opts.Storage))
+		}
+		rows = append(rows, append(row, headroomCell(node)))
+
+		if node.Unschedulable || len(node.Taints) > 0 {
+			continue // new pods only land here with a toleration
+		}
+		if node.HeadroomCPU() > maxCPU.HeadroomCPU() {
+			maxCPU = node
+		}
+		if node.HeadroomMem() > maxMem.HeadroomMem() {
+			maxMem = node
+		}
+	}
+
+	md := renderTable(title, headers, rows)
+	if len(result.Nodes) > 0 {
+		footer := fmt.Sprintf("Largest schedulable pod: %s CPU (on %s), %s memory (on %s) — based on requests, not usage.",
+			kube.FormatCPU(maxCPU.HeadroomCPU()), orNone(maxCPU.Name), kube.FormatMem(maxMem.HeadroomMem()), orNone(maxMem.Name))
+		if note := unschedulableNote(result.Nodes); note != "" {
+			footer += "\n" + note
+		}
+		if note := podSlotsNote(result.Nodes); note != "" {
+			footer += "\n" + note
+		}
+		if note := diskNote(result.Nodes, opts.Storage); note != "" {
+			footer += "\n" + note
+		}
+		if age := metricsAgeNote(result.Nodes); age != "" {
+			footer += "\n" + age
+		}
+		if opts.Reclaim != nil {
+			footer += "\n" + reclaimNote(*opts.Reclaim, opts.ReclaimHeadroom)
+		}
+		fmt.Println(footer)
+		md += "\n\n" + footer
+	}
+	if platforms := renderNodesPlatforms(result, contextName); platforms != "" {
+		md += "\n\n" + platforms
+	}
+	if mermaidCharts && result.NodeMetricsAvailable {
+		md += "\n\n" + nodesCharts(result.Nodes)
+	}
+	return md
+}
+
+func hasHugePages(nodes []kube.NodeInfo) bool {
+	for _, n := range nodes {
+		if len(n.HugePages) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// renderNodesHugePages renders allocatable vs requested hugepages per node and page size.
+// Hugepages are not part of allocatable memory, so unrequested pages are stranded memory.
+func renderNodesHugePages(result *kube.FetchNodesResult, contextName string) string {
+	title := fmt.Sprintf("HugePages — %s", contextName)
+	headers := []string{"Node", "Page Size", "Allocatable", "Requested", "Unrequested"}
+
+	var rows [][]cellValue
+	var stranded float64
+	for _, node := range result.Nodes {
+		sizes := make([]string, 0, len(node.HugePages))
+		for size := range node.HugePages {
+			sizes = append(sizes, size)
+		}
+		sort.Strings(sizes)
+
+		for _, size := range sizes {
+			hp := node.HugePages[size]
+			free := max(hp.Allocatable-hp.Requested, 0)
+			stranded += free
+			freeCell := cv(fmt.Sprintf("%s (%s)", kube.FormatMem(free), formatPct(safePctFloat(free, hp.Allocatable))))
+			if hp.Allocatable > 0 && free == hp.Allocatable {
+				freeCell = cvColored(freeCell.text, text.Colors{text.FgYellow})
+			}
+			rows = append(rows, []cellValue{
+				cv(node.Name),
+				cv(size),
+				cv(kube.FormatMem(hp.Allocatable)),
+				cv(fmt.Sprintf("%s (%s)", formatPct(safePctFloat(hp.Requested, hp.Allocatable)), kube.FormatMem(hp.Requested))),
+				freeCell,
+			})
+		}
+	}
+
+	md := renderTable(title, headers, rows)
+	footer := fmt.Sprintf("%s of hugepages are allocated on nodes but requested by no pod; that memory is unavailable to regular pods.",
+		kube.FormatMem(stranded))
+	fmt.Println(footer)
+	return md + "\n\n" + footer
+}
+
+// nodesCharts renders requested (bars) vs actual (line) CPU and memory per node.
+func nodesCharts(nodes []kube.NodeInfo) string {
+	names := make([]string, len(nodes))
+	cpuReq, cpuAct := make([]float64, len(nodes)), make([]float64, len(nodes))
+	memReq, memAct := make([]float64, len(nodes)), make([]float64, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.Name
+		cpuReq[i] = safePctInt(n.RequestedCPU, n.AllocatableCPU)
+		cpuAct[i] = safePctInt(n.ActualCPU, n.AllocatableCPU)
+		memReq[i] = safePctFloat(n.RequestedMem, n.AllocatableMem)
+		memAct[i] = safePctFloat(n.ActualMem, n.AllocatableMem)
+	}
+	return mermaidBarLine("CPU requested (bars) vs actual (line)", names, cpuReq, cpuAct) + "\n\n" +
+		mermaidBarLine("Memory requested (bars) vs actual (line)", names, memReq, memAct)
+}
+
+// metricsAgeNote describes the oldest node metrics sample, e.g. "Node metrics sampled 45s
+// ago over a 30s window." It is empty when no sample carries a timestamp.
+func metricsAgeNote(nodes []kube.NodeInfo) string {
+	var oldest kube.NodeInfo
+	for _, n := range nodes {
+		if n.MetricsTimestamp.IsZero() {
+			continue
+		}
+		if oldest.MetricsTimestamp.IsZero() || n.MetricsTimestamp.Before(oldest.MetricsTimestamp) {
+			oldest = n
+		}
+	}
+	if oldest.MetricsTimestamp.IsZero() {
+		return ""
+	}
+	note := fmt.Sprintf("Node metrics sampled %s ago", duration.HumanDuration(time.Since(oldest.MetricsTimestamp)))
+	if oldest.MetricsWindow > 0 {
+		note += fmt.Sprintf(" over a %s window", oldest.MetricsWindow)
+	}
+	if oldest.MetricsStale {
+		note += " — stale, usage columns may be wrong"
+	}
+	return note + "."
+}
+
+// headroomCell shows how much CPU/memory a single new pod could request on a node.
+// Nearly full nodes (< 10% of allocatable left in either dimension) are highlighted.
+func headroomCell(node kube.NodeInfo) cellValue {
+	s := fmt.Sprintf("%s / %s", kube.FormatCPU(node.HeadroomCPU()), kube.FormatMem(node.HeadroomMem()))
+	if safePctInt(node.HeadroomCPU(), node.AllocatableCPU) < 10 || safePctFloat(node.HeadroomMem(), node.AllocatableMem) < 10 {
+		return cvColored(s, text.Colors{text.FgRed})
+	}
+	return cv(s)
+}
+
+// nodeNameCell marks nodes that take no new pods (cordoned) or only tolerating ones (tainted).
+func nodeNameCell(node kube.NodeInfo) cellValue {
+	switch {
+	case node.Unschedulable:
+		return cvColored(node.Name+" (cordoned)", text.Colors{text.FgYellow})
+	case len(node.Taints) > 0:
+		return cvColored(node.Name+" (tainted)", text.Colors{text.FgYellow})
+	default:
+		return cv(node.Name)
+	}
+}
+
+// reclaimNote states the requests right-sizing would release in node-equivalents, the
+// number that decides whether the node count can go down.
+func reclaimNote(e analysis.ReclaimEstimate, headroom float64) string {
+	bound := "memory"
+	if float64(e.CPUFreed)/float64(max(e.AvgNodeCPU, 1)) < e.MemFreed/max(e.AvgNodeMem, 1) {
+		bound = "CPU"
+	}
+	return fmt.Sprintf("Right-sizing all workloads to peak usage +%.0f%% would free %s CPU and %s memory of requests: "+
+		"~%.1f of %d nodes (bound by %s; see kusa rebalance for which).",
+		headroom*100, kube.FormatCPU(e.CPUFreed), kube.FormatMem(e.MemFreed), e.NodeEquivalents(), e.Nodes, bound)
+}
+
+// unschedulableNote lists cordoned nodes and the taints of tainted ones, whose free-looking
+// headroom is not available to ordinary pods. Returns "" when there are none.
+func unschedulableNote(nodes []kube.NodeInfo) string {
+	var cordoned, tainted []string
+	for _, n := range nodes {
+		switch {
+		case n.Unschedulable:
+			cordoned = append(cordoned, n.Name)
+		case len(n.Taints) > 0:
+			taints := make([]string, len(n.Taints))
+			for i, t := range n.Taints {
+				taints[i] = t.ToString()
+			}
+			tainted = append(tainted, fmt.Sprintf("%s (%s)", n.Name, strings.Join(taints, ", ")))
+		}
+	}
+	var parts []string
+	if len(cordoned) > 0 {
+		parts = append(parts, "Cordoned, no new pods: "+strings.Join(cordoned, ", ")+".")
+	}
+	if len(tainted) > 0 {
+		parts = append(parts, "Tainted, only for pods with matching tolerations: "+strings.Join(tainted, "; ")+".")
+	}
+	if len(parts) > 0 {
+		parts = append(parts, "Their headroom is excluded from the largest schedulable pod.")
+	}
+	return strings.Join(parts, " ")
+}
+
+// podSlotsCell shows the pods bound to a node against its pod slots, red when the node is
+// bound by them rather than by CPU or memory.
+func podSlotsCell(node kube.NodeInfo) cellValue {
+	slots := node.PodSlots()
+	if slots == 0 {
+		return cv(fmt.Sprintf("%d", node.ScheduledPods))
+	}
+	s := fmt.Sprintf("%d/%d", node.ScheduledPods, slots)
+	if analysis.PodSlotsBound(node) {
+		return cvColored(s+" (slot-bound)", text.Colors{text.FgRed})
+	}
+	return cv(s)
+}
+
+// podSlotsNote names the nodes bound by pod slots and the pod CIDRs that allow fewer pods
+// than max-pods.
+func podSlotsNote(nodes []kube.NodeInfo) string {
+	var bound, cidr []string
+	for _, n := range nodes {
+		if analysis.PodSlotsBound(n) {
+			bound = append(bound, n.Name)
+		}
+		if n.PodCIDRAddresses > 0 && n.PodSlots() < n.AllocatablePods {
+			cidr = append(cidr, fmt.Sprintf("%s (%d addresses, max-pods %d)", n.Name, n.PodCIDRAddresses, n.AllocatablePods))
+		}
+	}
+	var parts []string
+	if len(bound) > 0 {
+		parts = append(parts, "Out of pod slots while CPU and memory have room, so nothing more schedules there: "+
+			strings.Join(bound, ", ")+". Raise the kubelet's max-pods or the CNI's per-node IP limit, or spread pods.")
+	}
+	if len(cidr) > 0 {
+		parts = append(parts, "Pod CIDR smaller than max-pods, capping pods where the CNI allocates from it: "+strings.Join(cidr, "; ")+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// podNameCell marks static pods: their requests live in a manifest on the node, so
+// recommendations have to be applied there rather than through a controller.
+func podNameCell(pod kube.PodInfo) cellValue {
+	if pod.Mirror {
+		return cvColored(pod.Name+" (static)", text.Colors{text.Faint})
+	}
+	return cv(pod.Name)
+}
+
+func orNone(name string) string {
+	if name == "" {
+		return "none"
+	}
+	return name
+}
+
+// renderNodesOvercommit renders requests/allocatable and limits/allocatable ratios per node,
+// followed by a cluster-wide row summing all nodes.
+func renderNodesOvercommit(result *kube.FetchNodesResult, contextName string, maxCPURatio, maxMemRatio float64) string {
+	title := fmt.Sprintf("Overcommit — %s", contextName)
+	headers := []string{
+		"Node",
+		"CPU Req/Alloc", "CPU Lim/Alloc", "CPU Overcommit",
+		"Mem Req/Alloc", "Mem Lim/Alloc", "Mem Overcommit",
+	}
+
+	var total kube.NodeInfo
+	var rows [][]cellValue
+	for _, node := range result.Nodes {
+		rows = append(rows, overcommitRow(node.Name, node, maxCPURatio, maxMemRatio))
+
+		total.AllocatableCPU += node.AllocatableCPU
+		total.AllocatableMem += node.AllocatableMem
+		total.RequestedCPU += node.RequestedCPU
+		total.RequestedMem += node.RequestedMem
+		total.LimitCPU += node.LimitCPU
+		total.LimitMem += node.LimitMem
+	}
+	if len(result.Nodes) > 1 {
+		rows = append(rows, overcommitRow("(cluster)", total, maxCPURatio, maxMemRatio))
 	}
 
 	return renderTable(title, headers, rows)
 }
 
-func renderNodesPodOverview(result *kube.FetchNodesResult, contextName string, includeSystem bool) string {
+func overcommitRow(label string, node kube.NodeInfo, maxCPURatio, maxMemRatio float64) []cellValue {
+	cpuReqRatio := safeRatio(float64(node.RequestedCPU), float64(node.AllocatableCPU))
+	cpuLimRatio := safeRatio(float64(node.LimitCPU), float64(node.AllocatableCPU))
+	memReqRatio := safeRatio(node.RequestedMem, node.AllocatableMem)
+	memLimRatio := safeRatio(node.LimitMem, node.AllocatableMem)
+
+	cpuV := analysis.OvercommitVerdict(cpuLimRatio, maxCPURatio)
+	memV := analysis.OvercommitVerdict(memLimRatio, maxMemRatio)
+
+	return []cellValue{
+		cv(label),
+		cv(fmt.Sprintf("%.2fx (%s)", cpuReqRatio, kube.FormatCPU(node.RequestedCPU))),
+		cv(fmt.Sprintf("%.2fx (%s)", cpuLimRatio, kube.FormatCPU(node.LimitCPU))),
+		cvColored(cpuV.Label, text.Colors{cpuV.Color}),
+		cv(fmt.Sprintf("%.2fx (%s)", memReqRatio, kube.FormatMem(node.RequestedMem))),
+		cv(fmt.Sprintf("%.2fx (%s)", memLimRatio, kube.FormatMem(node.LimitMem))),
+		cvColored(memV.Label, text.Colors{memV.Color}),
+	}
+}
+
+func safeRatio(value, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return value / total
+}
+
+func renderNodesPodOverview(result *kube.FetchNodesResult, contextName string, includeSystem bool, minFactor int) string {
 	headers := []string{
 		"Namespace", "Pod",
 		"CPU Req", "CPU Limit", "CPU Actual", "Over-req",
@@ -195,10 +714,11 @@ func renderNodesPodOverview(result *kube.FetchNodesResult, contextName string, i
 
 	for _, node := range result.Nodes {
 		pods := node.Pods
-		if !includeSystem {
+		if !includeSystem || minFactor != 0 {
 			filtered := pods[:0]
 			for _, p := range pods {
-				if !kube.SystemNamespaces[p.Namespace] {
+				if (includeSystem || !kube.SystemNamespaces[p.Namespace]) &&
+					meetsFactorFilter(p.CPURequest, p.CPUActual, result.PodMetricsAvailable && p.MetricsAvailable, minFactor) {
 					filtered = append(filtered, p)
 				}
 			}
@@ -231,8 +751,8 @@ func renderNodesPodOverview(result *kube.FetchNodesResult, contextName string, i
 
 			var cpuActualCell, memActualCell cellValue
 			if result.PodMetricsAvailable && pod.MetricsAvailable {
-				cpuActualCell = cv(kube.FormatCPU(pod.CPUActual))
-				memActualCell = cv(kube.FormatMem(pod.MemActual))
+				cpuActualCell = actualCell(kube.FormatCPU(pod.CPUActual), pod.MetricsStale)
+				memActualCell = actualCell(kube.FormatMem(pod.MemActual), pod.MetricsStale)
 			} else {
 				cpuActualCell = naCell()
 				memActualCell = naCell()
@@ -240,7 +760,7 @@ func renderNodesPodOverview(result *kube.FetchNodesResult, contextName string, i
 
 			rows = append(rows, []cellValue{
 				cv(pod.Namespace),
-				cv(pod.Name),
+				podNameCell(pod),
 				cv(kube.FormatCPU(pod.CPURequest)),
 				cv(cpuLimitStr),
 				cpuActualCell,
@@ -262,36 +782,93 @@ func renderNodesPodOverview(result *kube.FetchNodesResult, contextName string, i
 	return allMd
 }
 
+// DeploymentsOptions controls filtering and truncation in RenderDeployments.
+type DeploymentsOptions struct {
+	Limit       int    // top N workloads (0 = all)
+	MinFactor   int    // see meetsFactorFilter
+	NoLimits    string // see meetsNoLimitsFilter
+	SortByScore bool   // sort by severity score instead of over-request factor
+
+	// SortByMemFactor sorts by the memory over-request factor instead of the CPU one, for
+	// clusters that run out of memory first.
+	SortByMemFactor bool
+
+	ExcludeDaemonSets bool // leave out DaemonSets: per-node overhead, not scalable
+
+	// ShowVPA adds a column with the VerticalPodAutoscaler in VPAs targeting each workload
+	// and how often it recently resized the workload's pods. VPAErr is why they could not
+	// be read; the column then shows N/A.
+	ShowVPA bool
+	VPAs    []kube.VPAInfo
+	VPAErr  error
+
+	Explain bool // add a Why column with the numbers behind each row's verdicts
+}
+
 // RenderDeployments renders workloads grouped by controller to stdout and saves a markdown file.
-// Results are sorted by CPU over-request factor descending (worst first).
-func RenderDeployments(result *kube.FetchWorkloadsResult, contextName string, limit int, minFactor int) {
+// Results are sorted by CPU over-request factor (or memory factor, or severity score)
+// descending, worst first.
+func RenderDeployments(result *kube.FetchWorkloadsResult, contextName string, opts DeploymentsOptions) error {
 	ts := time.Now()
 
 	workloads := make([]kube.WorkloadInfo, len(result.Workloads))
 	copy(workloads, result.Workloads)
 
-	// Filter by over-request factor
-	if minFactor != 0 {
+	// Filter by over-request factor, missing limits and kind
+	if opts.MinFactor != 0 || opts.NoLimits != "" || opts.ExcludeDaemonSets {
 		filtered := workloads[:0]
 		for _, w := range workloads {
-			if meetsFactorFilter(w.CPURequest, w.CPUActual, result.MetricsAvailable && w.MetricsAvailable, minFactor) {
+			if !(opts.ExcludeDaemonSets && w.Kind == "DaemonSet") &&
+				meetsFactorFilter(w.CPURequest, w.CPUActual, result.MetricsAvailable && w.MetricsAvailable, opts.MinFactor) &&
+				meetsNoLimitsFilter(w.MissingCPULimit, w.MissingMemLimit, opts.NoLimits) {
 				filtered = append(filtered, w)
 			}
 		}
 		workloads = filtered
 	}
 
-	sort.Slice(workloads, func(i, j int) bool {
-		return workloadSortFactor(workloads[i]) > workloadSortFactor(workloads[j])
-	})
-	if limit > 0 && len(workloads) > limit {
-		workloads = workloads[:limit]
+	if opts.SortByScore {
+		metricsAvail := func(w kube.WorkloadInfo) bool { return result.MetricsAvailable && w.MetricsAvailable }
+		sort.SliceStable(workloads, func(i, j int) bool {
+			return workloadScore(workloads[i], metricsAvail(workloads[i])) > workloadScore(workloads[j], metricsAvail(workloads[j]))
+		})
+	} else if opts.SortByMemFactor {
+		sort.Slice(workloads, func(i, j int) bool {
+			return workloadSortFactor(workloads[i].MemRequest, workloads[i].MemActual, workloads[i].MetricsAvailable) >
+				workloadSortFactor(workloads[j].MemRequest, workloads[j].MemActual, workloads[j].MetricsAvailable)
+		})
+	} else {
+		sort.Slice(workloads, func(i, j int) bool {
+			return workloadSortFactor(float64(workloads[i].CPURequest), float64(workloads[i].CPUActual), workloads[i].MetricsAvailable) >
+				workloadSortFactor(float64(workloads[j].CPURequest), float64(workloads[j].CPUActual), workloads[j].MetricsAvailable)
+		})
+	}
+	summary := newSummaryRecord(contextName, "workload", result.Skipped)
+	for _, w := range workloads {
+		summary.add(w.CPURequest, w.CPUActual, w.MemRequest, w.MemActual, result.MetricsAvailable && w.MetricsAvailable)
+	}
+	if opts.Limit > 0 && len(workloads) > opts.Limit {
+		workloads = workloads[:opts.Limit]
+	}
+	if structured() {
+		for _, w := range workloads {
+			emitRecord(newWorkloadRecord(w, contextName, result.MetricsAvailable && w.MetricsAvailable))
+		}
+		emitSummary(summary)
+		return flushRecords()
 	}
 
 	title := fmt.Sprintf("Deployments — %s", contextName)
-	headers := []string{"#", "Kind", "Namespace", "Workload", "Pods", "CPU Req", "CPU Actual", "Over-req", "CPU Verdict", "Mem Req", "Mem Actual", "Mem Verdict"}
+	headers := []string{"#", "Kind", "Namespace", "Workload", "Pods", "CPU Req", "CPU Limit", "CPU Actual", "CPU Waste", "Over-req", "Score", "CPU Verdict", "Mem Req", "Mem Limit", "Mem Actual", "Mem Waste", "Mem Over-req", "Mem Verdict", "Overall"}
+	if opts.ShowVPA {
+		headers = append(headers, "VPA")
+	}
+	if opts.Explain {
+		headers = append(headers, "Why")
+	}
 
 	var rows [][]cellValue
+	managed := 0
 	for i, w := range workloads {
 		factorStr := kube.FormatFactor(w.CPURequest, w.CPUActual)
 		factorColors := analysis.FactorColors(w.CPURequest, w.CPUActual)
@@ -299,56 +876,242 @@ func RenderDeployments(result *kube.FetchWorkloadsResult, contextName string, li
 		metricsAvail := result.MetricsAvailable && w.MetricsAvailable
 		var cpuActualCell, memActualCell cellValue
 		if metricsAvail {
-			cpuActualCell = cv(kube.FormatCPU(w.CPUActual))
-			memActualCell = cv(kube.FormatMem(w.MemActual))
+			cpuActualCell = actualCell(kube.FormatCPU(w.CPUActual), w.MetricsStale)
+			memActualCell = actualCell(kube.FormatMem(w.MemActual), w.MetricsStale)
 		} else {
 			cpuActualCell = naCell()
 			memActualCell = naCell()
 		}
 
-		rows = append(rows, []cellValue{
+		row := []cellValue{
 			cv(fmt.Sprintf("%d", i+1)),
 			cv(w.Kind),
 			cv(w.Namespace),
 			cv(w.Name),
-			cv(fmt.Sprintf("%d", w.PodCount)),
+			podCountCell(w),
 			cv(kube.FormatCPU(w.CPURequest)),
+			workloadLimitCell(kube.FormatCPU(w.CPULimit), float64(w.CPULimit), float64(w.CPURequest), w.MissingCPULimit),
 			cpuActualCell,
+			cpuWasteCell(w.CPURequest, w.CPUActual, metricsAvail),
 			cvColored(factorStr, factorColors),
+			scoreCell(workloadScore(w, metricsAvail), metricsAvail),
 			verdictFromRatio(float64(w.CPURequest), float64(w.CPUActual), metricsAvail),
 			cv(kube.FormatMem(w.MemRequest)),
+			workloadLimitCell(kube.FormatMem(w.MemLimit), w.MemLimit, w.MemRequest, w.MissingMemLimit),
 			memActualCell,
+			memWasteCell(w.MemRequest, w.MemActual, metricsAvail),
+			cvColored(kube.FormatFactor(w.MemRequest, w.MemActual), analysis.FactorColors(w.MemRequest, w.MemActual)),
 			verdictFromRatio(w.MemRequest, w.MemActual, metricsAvail),
-		})
+			overallVerdictCell(float64(w.CPURequest), float64(w.CPUActual), w.MemRequest, w.MemActual, metricsAvail),
+		}
+		if opts.ShowVPA && opts.VPAErr != nil {
+			row = append(row, naCell())
+		} else if opts.ShowVPA {
+			vpa := workloadVPA(w, opts.VPAs)
+			if vpa != nil && vpa.Updating() {
+				managed++
+			}
+			row = append(row, vpaCell(vpa, ts))
+		}
+		if opts.Explain {
+			row = append(row, cv(verdictReason(w.CPURequest, w.CPUActual, w.MemRequest, w.MemActual, metricsAvail)))
+		}
+		rows = append(rows, row)
 	}
 
 	fmt.Println()
 	mdContent := renderTable(title, headers, rows)
+	if opts.ShowVPA && opts.VPAErr == nil {
+		note := vpaNote(len(opts.VPAs), managed)
+		fmt.Println(note)
+		mdContent += "\n\n" + note
+	}
+	if opts.Explain {
+		fmt.Println(explainNote())
+		mdContent += "\n\n" + explainNote()
+	}
+	fmt.Println(summary.line())
+	mdContent += "\n\n" + summary.line()
+	if note := skippedNote(result.Skipped); note != "" {
+		fmt.Println(note)
+		mdContent += "\n\n" + note
+	}
 	saveMarkdownFile("deployments", contextName, ts, mdContent)
+	return nil
+}
+
+// workloadVPA returns the VerticalPodAutoscaler in vpas targeting w, nil when there is none.
+func workloadVPA(w kube.WorkloadInfo, vpas []kube.VPAInfo) *kube.VPAInfo {
+	for i, v := range vpas {
+		if v.Namespace == w.Namespace && v.TargetKind == w.Kind && v.TargetName == w.Name {
+			return &vpas[i]
+		}
+	}
+	return nil
+}
+
+// vpaCell shows the update mode of a workload's VPA and, when it changes running pods, how
+// many it recently resized: yellow when it did, as the pods restart or change under load.
+func vpaCell(v *kube.VPAInfo, now time.Time) cellValue {
+	switch {
+	case v == nil:
+		return cv("-")
+	case !v.Updating():
+		return cvColored(v.UpdateMode+" (recommends only)", text.Colors{text.Faint})
+	case v.Resizes() == 0:
+		return cv(v.UpdateMode + ", no recent resizes")
+	}
+	var parts []string
+	if v.Evictions > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", v.Evictions, kube.Plural(int(v.Evictions), "eviction", "evictions")))
+	}
+	if v.InPlaceUpdates > 0 {
+		parts = append(parts, fmt.Sprintf("%d in place", v.InPlaceUpdates))
+	}
+	s := fmt.Sprintf("%s: %s, last %s ago", v.UpdateMode, strings.Join(parts, ", "), duration.HumanDuration(now.Sub(v.LastUpdate)))
+	return cvColored(s, text.Colors{text.FgYellow})
+}
+
+// vpaNote summarises the VPA column: workloads an updating VPA manages get their requests
+// from it, so a right-sizing change belongs in the VPA's bounds, not the manifest.
+func vpaNote(vpas, managed int) string {
+	if vpas == 0 {
+		return "No VerticalPodAutoscalers found (or the autoscaling.k8s.io CRDs are not installed)."
+	}
+	if managed == 0 {
+		return fmt.Sprintf("%d %s found; none resizes the pods of the workloads shown.", vpas, kube.Plural(vpas, "VerticalPodAutoscaler", "VerticalPodAutoscalers"))
+	}
+	return fmt.Sprintf("%d of the workloads shown %s resized by a VPA: change the VPA's resource policy rather than the requests "+
+		"in the manifest. Resizes are counted from events, which the API server keeps for an hour by default.",
+		managed, kube.Plural(managed, "is", "are"))
+}
+
+// podCountCell shows running/desired pods when the controller is known, highlighting
+// workloads that run fewer pods than desired (crash-looping, Pending, quota-blocked).
+func podCountCell(w kube.WorkloadInfo) cellValue {
+	if !w.DesiredKnown {
+		return cv(fmt.Sprintf("%d", w.PodCount))
+	}
+	s := fmt.Sprintf("%d/%d", w.PodCount, w.DesiredPods)
+	if w.PodCount < w.DesiredPods {
+		return cvColored(s, text.Colors{text.FgYellow})
+	}
+	return cv(s)
+}
+
+// workloadLimitCell renders the summed limits of a workload with their ratio to its
+// requests when higher (the burst it may take from its nodes). Workloads without any limit
+// show "none" and those where only some containers set one "(partial)", both in yellow:
+// their pods can grow until the node runs out. A workload scaled to zero whose containers
+// all set limits sums none and shows "-".
+func workloadLimitCell(limitStr string, limit, request float64, missing bool) cellValue {
+	if limit == 0 && !missing {
+		return cv("-")
+	}
+	if limit == 0 {
+		return cvColored("none", text.Colors{text.FgYellow})
+	}
+	s := limitStr
+	if request > 0 && limit > request {
+		s += fmt.Sprintf(" (%.1fx)", limit/request)
+	}
+	if missing {
+		return cvColored(s+" (partial)", text.Colors{text.FgYellow})
+	}
+	return cv(s)
+}
+
+// workloadScore returns the severity score of w, or -1 without metrics so such rows sort last.
+func workloadScore(w kube.WorkloadInfo, metricsAvail bool) int {
+	if !metricsAvail {
+		return -1
+	}
+	return analysis.SeverityScore(w.CPURequest, w.CPUActual, w.MemRequest, w.MemActual, w.PodCount)
+}
+
+// podScore returns the severity score of p, or -1 without metrics so such rows sort last.
+func podScore(p kube.PodInfo, metricsAvail bool) int {
+	if !metricsAvail {
+		return -1
+	}
+	return analysis.SeverityScore(p.CPURequest, p.CPUActual, p.MemRequest, p.MemActual, 1)
+}
+
+// cpuWasteCell renders CPU requested but unused (request minus usage, floored at 0):
+// the absolute capacity a row would give back, which the over-request ratio hides.
+func cpuWasteCell(req, actual int64, metricsAvail bool) cellValue {
+	if !metricsAvail {
+		return naCell()
+	}
+	return cv(kube.FormatCPU(max(req-actual, 0)))
+}
+
+// memWasteCell is cpuWasteCell for memory.
+func memWasteCell(req, actual float64, metricsAvail bool) cellValue {
+	if !metricsAvail {
+		return naCell()
+	}
+	return cv(kube.FormatMem(max(req-actual, 0)))
+}
+
+// scoreCell renders a 0–100 severity score: red from 70, yellow from 40.
+func scoreCell(score int, metricsAvail bool) cellValue {
+	if !metricsAvail {
+		return naCell()
+	}
+	s := fmt.Sprintf("%d", score)
+	switch {
+	case score >= 70:
+		return cvColored(s, text.Colors{text.FgRed})
+	case score >= 40:
+		return cvColored(s, text.Colors{text.FgYellow})
+	default:
+		return cv(s)
+	}
 }
 
-// workloadSortFactor returns a float64 key for sorting workloads by CPU over-request severity.
+// workloadSortFactor returns a float64 key for sorting workloads by CPU or memory over-request severity.
 // Higher = worse. Unknowns and no-request workloads sort to the bottom.
-func workloadSortFactor(w kube.WorkloadInfo) float64 {
-	if w.CPURequest == 0 {
+func workloadSortFactor(req, actual float64, metricsAvailable bool) float64 {
+	if req == 0 {
 		return -1 // no requests set → least interesting
 	}
-	if !w.MetricsAvailable {
+	if !metricsAvailable {
 		return -0.5 // can't compare without metrics
 	}
-	if w.CPUActual == 0 {
+	if actual == 0 {
 		return 1e15 // requesting but consuming nothing → worst case
 	}
-	return float64(w.CPURequest) / float64(w.CPUActual)
+	return req / actual
+}
+
+// PodsOptions controls filtering and truncation in RenderPods.
+type PodsOptions struct {
+	IncludeSystem bool
+	Limit         int    // top N pods (0 = all)
+	MinFactor     int    // see meetsFactorFilter
+	NoLimits      string // see meetsNoLimitsFilter
+	SortByScore   bool   // sort by severity score instead of CPU request
+
+	ExcludeDaemonSets bool // leave out DaemonSet pods: per-node overhead, not scalable
+
+	// Containers lists one row per container instead of per pod; the filters above then
+	// apply per container.
+	Containers bool
+
+	// Top writes the pods like kubectl top pods -A (--compat top) instead of the table.
+	Top bool
+
+	Explain bool // add a Why column with the numbers behind each row's verdicts
 }
 
 // RenderPods renders the pods table to stdout and saves a markdown file.
-func RenderPods(result *kube.FetchPodsResult, contextName string, includeSystem bool, limit int, minFactor int) {
+func RenderPods(result *kube.FetchPodsResult, contextName string, opts PodsOptions) error {
 	ts := time.Now()
 
 	// Filter system namespaces
 	pods := result.Pods
-	if !includeSystem {
+	if !opts.IncludeSystem {
 		filtered := pods[:0]
 		for _, p := range pods {
 			if !kube.SystemNamespaces[p.Namespace] {
@@ -357,30 +1120,74 @@ func RenderPods(result *kube.FetchPodsResult, contextName string, includeSystem
 		}
 		pods = filtered
 	}
+	if opts.Containers {
+		renderPodContainers(pods, result.MetricsAvailable, contextName, opts)
+		return nil
+	}
 
-	// Filter by over-request factor
-	if minFactor != 0 {
+	// Filter by over-request factor, missing limits and owner
+	if opts.MinFactor != 0 || opts.NoLimits != "" || opts.ExcludeDaemonSets {
 		filtered := pods[:0]
 		for _, p := range pods {
-			if meetsFactorFilter(p.CPURequest, p.CPUActual, result.MetricsAvailable && p.MetricsAvailable, minFactor) {
+			if !(opts.ExcludeDaemonSets && p.WorkloadKind == "DaemonSet") &&
+				meetsFactorFilter(p.CPURequest, p.CPUActual, result.MetricsAvailable && p.MetricsAvailable, opts.MinFactor) &&
+				meetsNoLimitsFilter(p.MissingCPULimit, p.MissingMemLimit, opts.NoLimits) {
 				filtered = append(filtered, p)
 			}
 		}
 		pods = filtered
 	}
 
-	// Sort by CPU request descending
+	// Sort by CPU request (or severity score) descending
 	sort.Slice(pods, func(i, j int) bool {
 		return pods[i].CPURequest > pods[j].CPURequest
 	})
+	if opts.SortByScore {
+		metricsAvail := func(p kube.PodInfo) bool { return result.MetricsAvailable && p.MetricsAvailable }
+		sort.SliceStable(pods, func(i, j int) bool {
+			return podScore(pods[i], metricsAvail(pods[i])) > podScore(pods[j], metricsAvail(pods[j]))
+		})
+	}
+
+	var chart string
+	if mermaidCharts && result.MetricsAvailable {
+		waste := make(map[string]float64)
+		for _, p := range pods {
+			if p.MetricsAvailable {
+				waste[p.Namespace] += float64(max(p.CPURequest-p.CPUActual, 0))
+			}
+		}
+		chart = mermaidPie("CPU requested but unused by namespace (millicores)", waste, 10)
+	}
+
+	summary := newSummaryRecord(contextName, "pod", result.Skipped)
+	for _, p := range pods {
+		summary.add(p.CPURequest, p.CPUActual, p.MemRequest, p.MemActual, result.MetricsAvailable && p.MetricsAvailable)
+	}
 
 	// Take top N
-	if limit > 0 && len(pods) > limit {
-		pods = pods[:limit]
+	if opts.Limit > 0 && len(pods) > opts.Limit {
+		pods = pods[:opts.Limit]
+	}
+	if opts.Top {
+		if err := writePodsTop(topOut, pods, result.MetricsAvailable); err != nil {
+			diag.Warnf("failed to write pods: %v", err)
+		}
+		return nil
+	}
+	if structured() {
+		for _, p := range pods {
+			emitRecord(newPodRecord(p, contextName, result.MetricsAvailable && p.MetricsAvailable))
+		}
+		emitSummary(summary)
+		return flushRecords()
 	}
 
 	title := fmt.Sprintf("Top Pods — %s", contextName)
-	headers := []string{"#", "Namespace", "Pod", "Node", "CPU Req", "CPU Actual", "Over-req", "CPU Verdict", "Mem Req", "Mem Actual", "Mem Verdict"}
+	headers := []string{"#", "Namespace", "Pod", "Node", "CPU Req", "CPU Actual", "CPU Waste", "Over-req", "Score", "CPU Verdict", "Mem Req", "Mem Actual", "Mem Waste", "Mem Verdict"}
+	if opts.Explain {
+		headers = append(headers, "Why")
+	}
 
 	var rows [][]cellValue
 	for i, pod := range pods {
@@ -390,29 +1197,50 @@ func RenderPods(result *kube.FetchPodsResult, contextName string, includeSystem
 		metricsAvail := result.MetricsAvailable && pod.MetricsAvailable
 		var cpuActualCell, memActualCell cellValue
 		if metricsAvail {
-			cpuActualCell = cv(kube.FormatCPU(pod.CPUActual))
-			memActualCell = cv(kube.FormatMem(pod.MemActual))
+			cpuActualCell = actualCell(kube.FormatCPU(pod.CPUActual), pod.MetricsStale)
+			memActualCell = actualCell(kube.FormatMem(pod.MemActual), pod.MetricsStale)
 		} else {
 			cpuActualCell = naCell()
 			memActualCell = naCell()
 		}
 
-		rows = append(rows, []cellValue{
+		row := []cellValue{
 			cv(fmt.Sprintf("%d", i+1)),
 			cv(pod.Namespace),
-			cv(pod.Name),
+			podNameCell(pod),
 			cv(pod.NodeName),
 			cv(kube.FormatCPU(pod.CPURequest)),
 			cpuActualCell,
+			cpuWasteCell(pod.CPURequest, pod.CPUActual, metricsAvail),
 			cvColored(factorStr, factorColors),
+			scoreCell(podScore(pod, metricsAvail), metricsAvail),
 			verdictFromRatio(float64(pod.CPURequest), float64(pod.CPUActual), metricsAvail),
 			cv(kube.FormatMem(pod.MemRequest)),
 			memActualCell,
+			memWasteCell(pod.MemRequest, pod.MemActual, metricsAvail),
 			verdictFromRatio(pod.MemRequest, pod.MemActual, metricsAvail),
-		})
+		}
+		if opts.Explain {
+			row = append(row, cv(verdictReason(pod.CPURequest, pod.CPUActual, pod.MemRequest, pod.MemActual, metricsAvail)))
+		}
+		rows = append(rows, row)
 	}
 
 	fmt.Println()
 	mdContent := renderTable(title, headers, rows)
+	if opts.Explain {
+		fmt.Println(explainNote())
+		mdContent += "\n\n" + explainNote()
+	}
+	fmt.Println(summary.line())
+	mdContent += "\n\n" + summary.line()
+	if note := skippedNote(result.Skipped); note != "" {
+		fmt.Println(note)
+		mdContent += "\n\n" + note
+	}
+	if chart != "" {
+		mdContent += "\n\n" + chart
+	}
 	saveMarkdownFile("pods", contextName, ts, mdContent)
+	return nil
 }
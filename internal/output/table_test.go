@@ -1,6 +1,15 @@
 package output
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	corev1 "k8s.io/api/core/v1"
+)
 
 func TestMeetsFactorFilter(t *testing.T) {
 	tests := []struct {
@@ -42,3 +51,238 @@ func TestMeetsFactorFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestMeetsNoLimitsFilter(t *testing.T) {
+	tests := []struct {
+		name                   string
+		missingCPU, missingMem bool
+		mode                   string
+		want                   bool
+	}{
+		{"disabled passes fully limited", false, false, "", true},
+		{"disabled passes unlimited", true, true, "", true},
+
+		{"memory mode matches missing mem limit", false, true, "memory", true},
+		{"memory mode ignores missing cpu limit", true, false, "memory", false},
+
+		{"cpu mode matches missing cpu limit", true, false, "cpu", true},
+		{"cpu mode ignores missing mem limit", false, true, "cpu", false},
+
+		{"any mode matches either", true, false, "any", true},
+		{"any mode matches both", true, true, "any", true},
+		{"any mode excludes fully limited", false, false, "any", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := meetsNoLimitsFilter(tc.missingCPU, tc.missingMem, tc.mode)
+			if got != tc.want {
+				t.Errorf("meetsNoLimitsFilter(cpu=%v, mem=%v, mode=%q) = %v, want %v",
+					tc.missingCPU, tc.missingMem, tc.mode, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWithGauge(t *testing.T) {
+	tests := []struct {
+		pct  float64
+		want string
+	}{
+		{0, "░░░░░"},
+		{9, "░░░░░"},
+		{10, "▓░░░░"},
+		{35, "▓▓░░░"},
+		{100, "▓▓▓▓▓"},
+		{180, "▓▓▓▓▓"},
+		{-5, "░░░░░"},
+	}
+	for _, tc := range tests {
+		if got := withGauge(cv("x"), tc.pct); got.gauge != tc.want || got.text != "x" {
+			t.Errorf("withGauge(%g) = %q/%q, want %q with text unchanged", tc.pct, got.gauge, got.text, tc.want)
+		}
+	}
+}
+
+func TestWorkloadLimitCell(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit, request float64
+		missing        bool
+		want           string
+		warn           bool
+	}{
+		{name: "no limits", request: 500, missing: true, want: "none", warn: true},
+		{name: "twice the request", limit: 1000, request: 500, want: "1000 (2.0x)"},
+		{name: "equal to the request", limit: 500, request: 500, want: "500"},
+		{name: "partial", limit: 500, request: 1000, missing: true, want: "500 (partial)", warn: true},
+		{name: "scaled to zero with limits", want: "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := workloadLimitCell(fmt.Sprintf("%.0f", tt.limit), tt.limit, tt.request, tt.missing)
+			if got.text != tt.want || (got.colors != nil) != tt.warn {
+				t.Errorf("got %q (colors %v), want %q (warn %v)", got.text, got.colors, tt.want, tt.warn)
+			}
+		})
+	}
+}
+
+func TestVPACell(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		vpa  *kube.VPAInfo
+		want string
+	}{
+		{name: "none", want: "-"},
+		{name: "off", vpa: &kube.VPAInfo{UpdateMode: "Off"}, want: "Off (recommends only)"},
+		{name: "idle", vpa: &kube.VPAInfo{UpdateMode: "Auto"}, want: "Auto, no recent resizes"},
+		{
+			name: "resizing",
+			vpa:  &kube.VPAInfo{UpdateMode: "InPlaceOrRecreate", Evictions: 1, InPlaceUpdates: 3, LastUpdate: now.Add(-12 * time.Minute)},
+			want: "InPlaceOrRecreate: 1 eviction, 3 in place, last 12m ago",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vpaCell(tt.vpa, now); got.text != tt.want {
+				t.Errorf("got %q, want %q", got.text, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerdictReason(t *testing.T) {
+	tests := []struct {
+		name              string
+		cpuReq, cpuActual int64
+		memReq, memActual float64
+		metricsAvail      bool
+		want              string
+	}{
+		{name: "over-requested", cpuReq: 4000, cpuActual: 180, memReq: 2048, memActual: 1946, metricsAvail: true,
+			want: "CPU 4 req, 180m used (4%) → 22x; mem 2Gi req, 1.9Gi used (95%) → 1x"},
+		{name: "bursting and no request", cpuReq: 500, cpuActual: 800, memActual: 100, metricsAvail: true,
+			want: "CPU 500m req, 800m used (160%); mem no req"},
+		{name: "idle", cpuReq: 500, memReq: 512, memActual: 0, metricsAvail: true,
+			want: "CPU 500m req, 0 used (0%); mem 512Mi req, 0Mi used (0%)"},
+		{name: "no metrics", cpuReq: 500, want: "no usage known, so no verdict"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verdictReason(tt.cpuReq, tt.cpuActual, tt.memReq, tt.memActual, tt.metricsAvail); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPct(t *testing.T) {
+	defer SetPctDecimals(0)
+	tests := []struct {
+		decimals       int
+		pct            float64
+		want, wantFine string
+	}{
+		{0, 35.44, "35%", "35.4%"},
+		{1, 35.44, "35.4%", "35.4%"},
+		{2, 0.5, "0.50%", "0.50%"},
+	}
+	for _, tc := range tests {
+		SetPctDecimals(tc.decimals)
+		if got := formatPct(tc.pct); got != tc.want {
+			t.Errorf("decimals %d: formatPct(%g) = %q, want %q", tc.decimals, tc.pct, got, tc.want)
+		}
+		if got := formatPctFine(tc.pct); got != tc.wantFine {
+			t.Errorf("decimals %d: formatPctFine(%g) = %q, want %q", tc.decimals, tc.pct, got, tc.wantFine)
+		}
+	}
+}
+
+func TestUnschedulableNote(t *testing.T) {
+	gpu := corev1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule}
+	tests := []struct {
+		name  string
+		nodes []kube.NodeInfo
+		want  string
+	}{
+		{"all schedulable", []kube.NodeInfo{{Name: "a"}}, ""},
+		{"cordoned", []kube.NodeInfo{{Name: "a", Unschedulable: true}, {Name: "b"}},
+			"Cordoned, no new pods: a. Their headroom is excluded from the largest schedulable pod."},
+		{"cordoned and tainted", []kube.NodeInfo{{Name: "a", Unschedulable: true, Taints: []corev1.Taint{gpu}}, {Name: "gpu-1", Taints: []corev1.Taint{gpu}}},
+			"Cordoned, no new pods: a. Tainted, only for pods with matching tolerations: gpu-1 (nvidia.com/gpu=present:NoSchedule). Their headroom is excluded from the largest schedulable pod."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := unschedulableNote(tc.nodes); got != tc.want {
+				t.Errorf("unschedulableNote() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSkippedNote(t *testing.T) {
+	tests := []struct {
+		name    string
+		skipped []string
+		want    string
+	}{
+		{"none skipped", nil, ""},
+		{"one", []string{"payments"}, "Errors: skipped 1 namespace you may not read (forbidden), not included above: payments"},
+		{"several", []string{"payments", "vault"}, "Errors: skipped 2 namespaces you may not read (forbidden), not included above: payments, vault"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := skippedNote(tc.skipped); got != tc.want {
+				t.Errorf("skippedNote() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDiskNote(t *testing.T) {
+	fs := func(usedPct float64) kube.FilesystemUsage {
+		return kube.FilesystemUsage{Capacity: 1000, Available: 1000 - usedPct*10, EvictionAvailable: 100}
+	}
+	storage := map[string]kube.NodeStorage{
+		"roomy":  {NodeFS: fs(50), ImageFS: fs(50)},
+		"full":   {NodeFS: fs(84), ImageFS: fs(84)},
+		"images": {NodeFS: fs(30), ImageFS: kube.FilesystemUsage{Capacity: 2000, Available: 200, EvictionAvailable: 300}},
+		"both":   {NodeFS: fs(86), ImageFS: kube.FilesystemUsage{Capacity: 2000, Available: 100, EvictionAvailable: 200}},
+	}
+	tests := []struct {
+		name  string
+		nodes []kube.NodeInfo
+		want  string
+	}{
+		{"roomy", []kube.NodeInfo{{Name: "roomy"}}, ""},
+		{"close to eviction", []kube.NodeInfo{{Name: "roomy"}, {Name: "full"}},
+			"Close to disk eviction: full (nodefs 84% used, evicts at 90%)."},
+		{"past the threshold", []kube.NodeInfo{{Name: "roomy"}, {Name: "full"}, {Name: "images"}, {Name: "both"}},
+			"Past the eviction threshold, the kubelet reclaims images and evicts pods once it notices: images (imagefs 90% used, evicts at 85%); both (imagefs 95% used, evicts at 90%). " +
+				"Close to disk eviction: full (nodefs 84% used, evicts at 90%)."},
+		{"disk pressure without stats", []kube.NodeInfo{{Name: "other", DiskPressure: true}},
+			"DiskPressure, the kubelet evicts pods and places no new ones: other. Pods failing to schedule or being evicted there are short of disk, not CPU or memory."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := diskNote(tc.nodes, storage); got != tc.want {
+				t.Errorf("diskNote() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReclaimNote(t *testing.T) {
+	e := analysis.ReclaimEstimate{Nodes: 40, CPUFreed: 48000, MemFreed: 100 * 1024, AvgNodeCPU: 8000, AvgNodeMem: 32 * 1024}
+	got := reclaimNote(e, 0.3)
+	for _, want := range []string{"+30%", "48 CPU", "100Gi", "~3.1 of 40 nodes", "bound by memory"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("reclaimNote() = %q, missing %q", got, want)
+		}
+	}
+}
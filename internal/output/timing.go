@@ -0,0 +1,46 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// RenderTimings writes a per-call API diagnostics report (see --timing) to w.
+func RenderTimings(w io.Writer, calls []kube.CallTiming) {
+	if len(calls) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "API call timings:")
+
+	var slowest time.Duration
+	throttled := false
+	for _, c := range calls {
+		status := ""
+		if c.Err != nil {
+			status = "  (failed)"
+		}
+		fmt.Fprintf(w, "  %-20s %8s  %6d objects  %2d %s%s\n",
+			c.Name, c.Duration.Round(time.Millisecond), c.Objects, c.Requests, kube.Plural(c.Requests, "request", "requests"), status)
+
+		if c.ThrottleWait > 0 {
+			throttled = true
+			fmt.Fprintf(w, "  %-20s client-side throttling: waited %s for rate limiter\n", "", c.ThrottleWait.Round(time.Millisecond))
+		}
+		if c.Throttled429 > 0 {
+			throttled = true
+			fmt.Fprintf(w, "  %-20s server-side throttling: %d × HTTP 429 (API Priority and Fairness)\n", "", c.Throttled429)
+		}
+		slowest = max(slowest, c.Duration)
+	}
+
+	// Calls run concurrently, so the slowest call bounds the fetch phase.
+	fmt.Fprintf(w, "  %-20s %8s\n", "slowest (wall time)", slowest.Round(time.Millisecond))
+	if !throttled {
+		fmt.Fprintln(w, "  no client- or server-side throttling detected")
+	}
+}
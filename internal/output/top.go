@@ -0,0 +1,71 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+// CompatTop is the --compat value that lays results out like kubectl top.
+const CompatTop = "top"
+
+// topOut receives --compat top output; tests replace it.
+var topOut io.Writer = os.Stdout
+
+// topUnknown is what kubectl top shows for usage it has no metrics for.
+const topUnknown = "<unknown>"
+
+// newTopWriter returns a tabwriter padded like kubectl's printers.
+func newTopWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 10, 4, 3, ' ', 0)
+}
+
+func topCPU(millicores int64) string { return fmt.Sprintf("%dm", millicores) }
+func topMem(mib float64) string      { return fmt.Sprintf("%.0fMi", mib) }
+
+// writeNodesTop writes nodes in the columns of kubectl top nodes, followed by their
+// requests as a share of allocatable and the worse of the CPU and memory verdicts.
+func writeNodesTop(w io.Writer, result *kube.FetchNodesResult) error {
+	tw := newTopWriter(w)
+	fmt.Fprintln(tw, "NAME\tCPU(cores)\tCPU(%)\tMEMORY(bytes)\tMEMORY(%)\tCPU-REQUESTS(%)\tMEMORY-REQUESTS(%)\tVERDICT")
+	for _, n := range result.Nodes {
+		cpuReqPct := safePctInt(n.RequestedCPU, n.AllocatableCPU)
+		memReqPct := safePctFloat(n.RequestedMem, n.AllocatableMem)
+		cells := []string{n.Name, topUnknown, topUnknown, topUnknown, topUnknown}
+		verdict := topUnknown
+		if result.NodeMetricsAvailable && n.MetricsAvailable {
+			cpuPct := safePctInt(n.ActualCPU, n.AllocatableCPU)
+			memPct := safePctFloat(n.ActualMem, n.AllocatableMem)
+			cells = []string{n.Name, topCPU(n.ActualCPU), fmt.Sprintf("%.0f%%", cpuPct), topMem(n.ActualMem), fmt.Sprintf("%.0f%%", memPct)}
+			verdict = analysis.OverallVerdict(analysis.ResourceVerdict(cpuReqPct, cpuPct), analysis.ResourceVerdict(memReqPct, memPct)).Label
+		}
+		cells = append(cells, fmt.Sprintf("%.0f%%", cpuReqPct), fmt.Sprintf("%.0f%%", memReqPct), verdict)
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writePodsTop writes pods in the columns of kubectl top pods -A, followed by their
+// requests and the worse of the CPU and memory verdicts.
+func writePodsTop(w io.Writer, pods []kube.PodInfo, metricsAvailable bool) error {
+	tw := newTopWriter(w)
+	fmt.Fprintln(tw, "NAMESPACE\tNAME\tCPU(cores)\tMEMORY(bytes)\tCPU-REQUESTS(cores)\tMEMORY-REQUESTS(bytes)\tVERDICT")
+	for _, p := range pods {
+		avail := metricsAvailable && p.MetricsAvailable
+		cpu, mem := topUnknown, topUnknown
+		if avail {
+			cpu, mem = topCPU(p.CPUActual), topMem(p.MemActual)
+		}
+		verdict := overallVerdictCell(float64(p.CPURequest), float64(p.CPUActual), p.MemRequest, p.MemActual, avail).text
+		if !avail && (p.CPURequest > 0 || p.MemRequest > 0) {
+			verdict = topUnknown
+		}
+		fmt.Fprintln(tw, strings.Join([]string{p.Namespace, p.Name, cpu, mem, topCPU(p.CPURequest), topMem(p.MemRequest), verdict}, "\t"))
+	}
+	return tw.Flush()
+}
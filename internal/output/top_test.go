@@ -0,0 +1,49 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/amasotti/kusa/internal/kube"
+)
+
+func TestWriteNodesTop(t *testing.T) {
+	result := &kube.FetchNodesResult{
+		NodeMetricsAvailable: true,
+		Nodes: []kube.NodeInfo{
+			{Name: "a", AllocatableCPU: 4000, AllocatableMem: 8192, RequestedCPU: 3000, RequestedMem: 4096, ActualCPU: 250, ActualMem: 1024, MetricsAvailable: true},
+			{Name: "b", AllocatableCPU: 2000, AllocatableMem: 4096, RequestedCPU: 1000, RequestedMem: 1024},
+		},
+	}
+	var b bytes.Buffer
+	if err := writeNodesTop(&b, result); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"NAME      CPU(cores)   CPU(%)      MEMORY(bytes)   MEMORY(%)   CPU-REQUESTS(%)   MEMORY-REQUESTS(%)   VERDICT\n" +
+		"a         250m         6%          1024Mi          12%         75%               50%                  Massively over-requested\n" +
+		"b         <unknown>    <unknown>   <unknown>       <unknown>   50%               25%                  <unknown>\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWritePodsTop(t *testing.T) {
+	pods := []kube.PodInfo{
+		{Namespace: "shop", Name: "web", CPURequest: 500, MemRequest: 512, CPUActual: 450, MemActual: 480, MetricsAvailable: true},
+		{Namespace: "shop", Name: "cron", MetricsAvailable: true},
+		{Namespace: "dev", Name: "new", CPURequest: 100, MemRequest: 128},
+	}
+	var b bytes.Buffer
+	if err := writePodsTop(&b, pods, true); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"NAMESPACE   NAME      CPU(cores)   MEMORY(bytes)   CPU-REQUESTS(cores)   MEMORY-REQUESTS(bytes)   VERDICT\n" +
+		"shop        web       450m         480Mi           500m                  512Mi                    OK\n" +
+		"shop        cron      0m           0Mi             0m                    0Mi                      no req\n" +
+		"dev         new       <unknown>    <unknown>       100m                  128Mi                    <unknown>\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
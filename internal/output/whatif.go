@@ -0,0 +1,101 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+	"github.com/amasotti/kusa/internal/kube"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// RenderWhatIf renders node request utilization before and after a hypothetical
+// workload change to stdout and saves a markdown file.
+func RenderWhatIf(result analysis.WhatIfResult, change analysis.WhatIfChange, contextName string) {
+	ts := time.Now()
+
+	target := fmt.Sprintf("%s/%s/%s", change.Namespace, change.Kind, change.Name)
+	title := fmt.Sprintf("What-if: %s × %d @ %s / %s — %s",
+		target, change.Replicas, kube.FormatCPU(change.CPURequest), kube.FormatMem(change.MemRequest), contextName)
+	if change.Kind == "DaemonSet" {
+		title = fmt.Sprintf("What-if: %s @ %s / %s — %s",
+			target, kube.FormatCPU(change.CPURequest), kube.FormatMem(change.MemRequest), contextName)
+	}
+	headers := []string{"Node", "CPU Req % (before → after)", "Mem Req % (before → after)", "Workload Pods"}
+
+	var (
+		rows                          [][]cellValue
+		allocCPU, cpuBefore, cpuAfter int64
+		allocMem, memBefore, memAfter float64
+		absorbing                     int
+	)
+	for _, n := range result.Nodes {
+		allocCPU += n.AllocatableCPU
+		allocMem += n.AllocatableMem
+		cpuBefore += n.CPUBefore
+		cpuAfter += n.CPUAfter
+		memBefore += n.MemBefore
+		memAfter += n.MemAfter
+
+		name := n.Name
+		switch {
+		case n.Unschedulable:
+			name += " (cordoned)"
+		case n.Untolerated:
+			name += " (tainted)"
+		case n.Unselected:
+			name += " (not selected)"
+		}
+		podsCell := cv(fmt.Sprintf("%d", n.PodsAfter))
+		if n.PodsAfter != n.PodsBefore {
+			podsCell = cvColored(fmt.Sprintf("%d → %d", n.PodsBefore, n.PodsAfter), text.Colors{text.FgCyan})
+		}
+		if n.PodsAfter > n.PodsBefore {
+			absorbing++
+		}
+
+		rows = append(rows, []cellValue{
+			cv(name),
+			whatIfPctCell(float64(n.CPUBefore), float64(n.CPUAfter), float64(n.AllocatableCPU)),
+			whatIfPctCell(n.MemBefore, n.MemAfter, n.AllocatableMem),
+			podsCell,
+		})
+	}
+
+	clusterLine := fmt.Sprintf("Cluster requests: CPU %s → %s, memory %s → %s.",
+		formatPct(safeRatio(float64(cpuBefore), float64(allocCPU))*100), formatPct(safeRatio(float64(cpuAfter), float64(allocCPU))*100),
+		formatPct(safeRatio(memBefore, allocMem)*100), formatPct(safeRatio(memAfter, allocMem)*100))
+
+	verdict := fmt.Sprintf("Fits: %d %s absorb new pods.", absorbing, kube.Plural(absorbing, "node would", "nodes would"))
+	switch {
+	case result.Unplaced > 0:
+		verdict = fmt.Sprintf("Does NOT fit: %d %s no node with request headroom.",
+			result.Unplaced, kube.Plural(result.Unplaced, "pod has", "pods have"))
+	case absorbing == 0:
+		verdict = "Fits: no node needs room for additional pods."
+	}
+	summary := clusterLine + "\n" + verdict
+
+	fmt.Println()
+	mdContent := renderTable(title, headers, rows)
+	fmt.Println(summary)
+	saveMarkdownFile("whatif", contextName, ts, mdContent+"\n\n"+summary)
+}
+
+// whatIfPctCell shows requested/allocatable before and after; increases are yellow,
+// anything above allocatable is red.
+func whatIfPctCell(before, after, allocatable float64) cellValue {
+	b, a := safeRatio(before, allocatable)*100, safeRatio(after, allocatable)*100
+	if before == after {
+		return cv(formatPct(a))
+	}
+	s := fmt.Sprintf("%s → %s", formatPct(b), formatPct(a))
+	switch {
+	case a > 100:
+		return cvColored(s, text.Colors{text.FgRed})
+	case a > b:
+		return cvColored(s, text.Colors{text.FgYellow})
+	default:
+		return cvColored(s, text.Colors{text.FgGreen})
+	}
+}
@@ -0,0 +1,160 @@
+package kusa
+
+import (
+	"time"
+
+	"github.com/amasotti/kusa/internal/analysis"
+)
+
+// Verdict is a labelled, colored health verdict.
+type Verdict = analysis.Verdict
+
+// Verdicts returned by the functions below.
+var (
+	VerdictMassivelyOverRequested = analysis.VerdictMassivelyOverRequested
+	VerdictOverRequested          = analysis.VerdictOverRequested
+	VerdictBursting               = analysis.VerdictBursting
+	VerdictOK                     = analysis.VerdictOK
+
+	VerdictOverBudget    = analysis.VerdictOverBudget
+	VerdictOvercommitted = analysis.VerdictOvercommitted
+
+	VerdictEvictionHigh   = analysis.VerdictEvictionHigh
+	VerdictEvictionMedium = analysis.VerdictEvictionMedium
+	VerdictEvictionLow    = analysis.VerdictEvictionLow
+
+	VerdictPDBBlocks = analysis.VerdictPDBBlocks
+	VerdictPDBSlows  = analysis.VerdictPDBSlows
+
+	VerdictOOMHigh   = analysis.VerdictOOMHigh
+	VerdictOOMMedium = analysis.VerdictOOMMedium
+	VerdictOOMLow    = analysis.VerdictOOMLow
+
+	VerdictConfidenceHigh   = analysis.VerdictConfidenceHigh
+	VerdictConfidenceMedium = analysis.VerdictConfidenceMedium
+	VerdictConfidenceLow    = analysis.VerdictConfidenceLow
+)
+
+// ResourceVerdict compares requested% and actual% of a node's capacity.
+func ResourceVerdict(requestedPct, actualPct float64) Verdict {
+	return analysis.ResourceVerdict(requestedPct, actualPct)
+}
+
+// OvercommitVerdict rates a limits/allocatable ratio against the tolerated maximum.
+func OvercommitVerdict(limitRatio, maxRatio float64) Verdict {
+	return analysis.OvercommitVerdict(limitRatio, maxRatio)
+}
+
+// EvictionRisk estimates how early a pod is evicted when its node runs out of memory.
+func EvictionRisk(qosClass string, memReq, memLimit, memActual float64, nodePressure bool) (Verdict, string) {
+	return analysis.EvictionRisk(qosClass, memReq, memLimit, memActual, nodePressure)
+}
+
+// PodEvictionRisk is EvictionRisk for a pod, ignoring its memory limit when a container
+// sets none.
+func PodEvictionRisk(p PodInfo, nodePressure bool) (Verdict, string) {
+	return analysis.PodEvictionRisk(p, nodePressure)
+}
+
+// Efficiency and baselines.
+type (
+	Efficiency = analysis.Efficiency
+	Baseline   = analysis.Baseline
+	Regression = analysis.Regression
+)
+
+// MeasureEfficiency sums requests and usage of the pods that report metrics.
+func MeasureEfficiency(pods []PodInfo) Efficiency { return analysis.MeasureEfficiency(pods) }
+
+// CompareEfficiency returns efficiencies that dropped more than tolerance percentage points.
+func CompareEfficiency(baseline, current Efficiency, tolerance float64) []Regression {
+	return analysis.CompareEfficiency(baseline, current, tolerance)
+}
+
+// LoadBaseline reads a baseline file.
+func LoadBaseline(path string) (Baseline, error) { return analysis.LoadBaseline(path) }
+
+// SaveBaseline writes a baseline file.
+func SaveBaseline(path string, b Baseline) error { return analysis.SaveBaseline(path, b) }
+
+// Simulations.
+type (
+	RebalanceOptions = analysis.RebalanceOptions
+	RebalanceAction  = analysis.RebalanceAction
+	RebalancePlan    = analysis.RebalancePlan
+	ReclaimEstimate  = analysis.ReclaimEstimate
+	DrainCheck       = analysis.DrainCheck
+	DrainMove        = analysis.DrainMove
+	PDBImpact        = analysis.PDBImpact
+	WhatIfChange     = analysis.WhatIfChange
+	WhatIfNode       = analysis.WhatIfNode
+	WhatIfResult     = analysis.WhatIfResult
+)
+
+// Rebalance action kinds.
+const (
+	ActionDownsize = analysis.ActionDownsize
+	ActionDrain    = analysis.ActionDrain
+)
+
+// PlanRebalance suggests workload downsizes and node drains that consolidate capacity.
+func PlanRebalance(nodes []NodeInfo, opts RebalanceOptions) RebalancePlan {
+	return analysis.PlanRebalance(nodes, opts)
+}
+
+// EstimateReclaimable sums the requests right-sizing every workload would release.
+func EstimateReclaimable(nodes []NodeInfo, headroom float64, skipNamespaces map[string]bool) ReclaimEstimate {
+	return analysis.EstimateReclaimable(nodes, headroom, skipNamespaces)
+}
+
+// CheckDrain simulates cordoning and draining a node.
+func CheckDrain(nodes []NodeInfo, nodeName string, pdbs []PDBInfo, maxUtil float64) DrainCheck {
+	return analysis.CheckDrain(nodes, nodeName, pdbs, maxUtil)
+}
+
+// WorkloadPods returns the running pods of a workload ("" kind = any kind).
+func WorkloadPods(nodes []NodeInfo, namespace, kind, name string) []PodInfo {
+	return analysis.WorkloadPods(nodes, namespace, kind, name)
+}
+
+// SimulateWhatIf applies a hypothetical replica or request change to a workload.
+func SimulateWhatIf(nodes []NodeInfo, change WhatIfChange) WhatIfResult {
+	return analysis.SimulateWhatIf(nodes, change)
+}
+
+// OOMRisk is the OOM-kill risk of one container.
+type OOMRisk = analysis.OOMRisk
+
+// OOMRiskScore rates from 0 to 100 how likely a container is to be OOM-killed.
+func OOMRiskScore(memLimit, memActual float64, recentlyOOMKilled bool, restarts int32) int {
+	return analysis.OOMRiskScore(memLimit, memActual, recentlyOOMKilled, restarts)
+}
+
+// OOMRiskVerdict returns the verdict for an OOMRiskScore.
+func OOMRiskVerdict(score int) Verdict { return analysis.OOMRiskVerdict(score) }
+
+// RankOOMRisk scores every container of pods, counting OOM kills after since as recent.
+func RankOOMRisk(pods []PodInfo, since time.Time) []OOMRisk {
+	return analysis.RankOOMRisk(pods, since)
+}
+
+// Recommendations.
+type (
+	RecommendOptions        = analysis.RecommendOptions
+	Recommendation          = analysis.Recommendation
+	ContainerRecommendation = analysis.ContainerRecommendation
+	ThrottlingOptions       = analysis.ThrottlingOptions
+	LimitRemoval            = analysis.LimitRemoval
+)
+
+// Recommend right-sizes the container requests of each workload to its peak usage plus
+// headroom, lowering the limits of Guaranteed workloads with them.
+func Recommend(pods []PodInfo, opts RecommendOptions) []Recommendation {
+	return analysis.Recommend(pods, opts)
+}
+
+// SuggestLimitRemovals finds workload containers throttled at their CPU limit on nodes with
+// CPU to spare.
+func SuggestLimitRemovals(pods []PodInfo, throttling map[ContainerKey]CPUThrottling, nodes []NodeInfo, opts ThrottlingOptions) []LimitRemoval {
+	return analysis.SuggestLimitRemovals(pods, throttling, nodes, opts)
+}
@@ -0,0 +1,16 @@
+// Package kusa is the public Go API of kusa, the Kubernetes Usage Analyzer.
+//
+// It exposes the same collection, verdict and rendering logic the kusa CLI uses, so
+// other tools (operators, internal portals, custom reports) can embed it:
+//
+//   - Clients and NewClients connect to a cluster through a kubeconfig.
+//   - The Fetch* functions list nodes, pods, workloads and metrics and aggregate
+//     requests, limits and actual usage.
+//   - Verdicts, efficiency measurement and the rebalance, drain and what-if
+//     simulations work on the fetched data without further API calls.
+//   - The Render* functions print tables to stdout and save markdown reports under
+//     output/<context>/, exactly like the CLI.
+//
+// Types are aliases of kusa's internal types, so values can be passed freely between
+// the functions of this package. CPU values are in millicores and memory values in MiB.
+package kusa
@@ -0,0 +1,26 @@
+package kusa_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/amasotti/kusa/pkg/kusa"
+)
+
+// Lists nodes whose requests leave less than 10% of CPU schedulable.
+func ExampleFetchNodes() {
+	clients, err := kusa.NewClients("", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	result, err := kusa.FetchNodes(context.Background(), clients, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, n := range result.Nodes {
+		if n.HeadroomCPU()*10 < n.AllocatableCPU {
+			fmt.Printf("%s: only %s CPU schedulable\n", n.Name, kusa.FormatCPU(n.HeadroomCPU()))
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package kusa
+
+import (
+	"context"
+
+	"github.com/amasotti/kusa/internal/diag"
+	"github.com/amasotti/kusa/internal/kube"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Clients holds the Kubernetes and metrics clients plus per-call timings.
+type Clients = kube.Clients
+
+// Sampling sets how often fetchers poll the metrics API and how samples are combined;
+// assign it to Clients.Sampling.
+type Sampling = kube.Sampling
+
+// ResponseCache caches the responses of list calls on disk for NewCachedClients.
+type ResponseCache = kube.ResponseCache
+
+// Snapshot holds the objects and metrics of a cluster for rendering without it.
+type Snapshot = kube.Snapshot
+
+// CallTiming records diagnostics for a single logical API call.
+type CallTiming = kube.CallTiming
+
+// NodeInfo holds per-node allocatable, requested, limit and actual resources.
+type NodeInfo = kube.NodeInfo
+
+// PodInfo holds per-pod requests, limits, actual usage and owning workload.
+type PodInfo = kube.PodInfo
+
+// ContainerInfo holds the resources of one container of a pod.
+type ContainerInfo = kube.ContainerInfo
+
+// WorkloadInfo holds resources aggregated per owning controller.
+type WorkloadInfo = kube.WorkloadInfo
+
+// LintFinding is a workload container missing requests (or limits).
+type LintFinding = kube.LintFinding
+
+// PDBInfo is a PodDisruptionBudget reduced to what decides whether an eviction is allowed.
+type PDBInfo = kube.PDBInfo
+
+// CPUThrottling counts a container's CFS periods and how many of them were throttled.
+type CPUThrottling = kube.CPUThrottling
+
+// ContainerKey identifies a container of a pod.
+type ContainerKey = kube.ContainerKey
+
+// Fetch results.
+type (
+	FetchNodesResult     = kube.FetchNodesResult
+	FetchPodsResult      = kube.FetchPodsResult
+	FetchWorkloadsResult = kube.FetchWorkloadsResult
+	FetchLintResult      = kube.FetchLintResult
+)
+
+// SystemNamespaces lists the namespaces the CLI excludes by default.
+var SystemNamespaces = kube.SystemNamespaces
+
+// NewClients builds clients from a kubeconfig path ("" = default loading rules) and an
+// optional context override ("" = current context).
+func NewClients(kubeconfig, contextName string) (*Clients, error) {
+	return kube.NewClients(kubeconfig, contextName)
+}
+
+// NewCachedClients is NewClients with list responses reused from disk for cache.TTL.
+func NewCachedClients(kubeconfig, contextName string, cache ResponseCache) (*Clients, error) {
+	return kube.NewCachedClients(kubeconfig, contextName, cache)
+}
+
+// NewSnapshotClients returns clients that read from s instead of a cluster.
+func NewSnapshotClients(s *Snapshot) *Clients {
+	return kube.NewSnapshotClients(s)
+}
+
+// TakeSnapshot lists the objects and metrics of the whole cluster.
+func TakeSnapshot(ctx context.Context, clients *Clients) (*Snapshot, error) {
+	return kube.TakeSnapshot(ctx, clients)
+}
+
+// LoadSnapshot reads a snapshot file written by SaveSnapshot or kusa snapshot.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	return kube.LoadSnapshot(path)
+}
+
+// SaveSnapshot writes s to path as JSON.
+func SaveSnapshot(path string, s *Snapshot) error {
+	return kube.SaveSnapshot(path, s)
+}
+
+// NewClientsFrom wraps existing clientsets (e.g. fakes, or clients built by the caller).
+func NewClientsFrom(core kubernetes.Interface, metrics metricsclient.Interface, contextName string) *Clients {
+	return kube.NewClientsFrom(core, metrics, contextName)
+}
+
+// ContextNames returns the context names defined in the kubeconfig.
+func ContextNames(kubeconfig string) ([]string, error) {
+	return kube.ContextNames(kubeconfig)
+}
+
+// FetchNodes fetches nodes with their running pods and metrics; withPodMetrics adds
+// per-pod usage and owning workloads.
+func FetchNodes(ctx context.Context, clients *Clients, withPodMetrics bool) (*FetchNodesResult, error) {
+	return kube.FetchNodes(ctx, clients, withPodMetrics)
+}
+
+// FetchPods fetches running pods and their metrics ("" namespace = cluster-wide).
+func FetchPods(ctx context.Context, clients *Clients, namespace string) (*FetchPodsResult, error) {
+	return kube.FetchPods(ctx, clients, namespace)
+}
+
+// FetchNodePods fetches the running pods on one node and their metrics.
+func FetchNodePods(ctx context.Context, clients *Clients, namespace, node string) (*FetchPodsResult, error) {
+	return kube.FetchNodePods(ctx, clients, namespace, node)
+}
+
+// FetchWorkloads aggregates pods per owning Deployment, StatefulSet, DaemonSet or Job.
+func FetchWorkloads(ctx context.Context, clients *Clients, namespace string, includeSystem bool) (*FetchWorkloadsResult, error) {
+	return kube.FetchWorkloads(ctx, clients, namespace, includeSystem)
+}
+
+// FetchLint reports containers without requests (and, with checkLimits, without limits).
+func FetchLint(ctx context.Context, clients *Clients, namespace string, includeSystem, checkLimits bool) (*FetchLintResult, error) {
+	return kube.FetchLint(ctx, clients, namespace, includeSystem, checkLimits)
+}
+
+// FetchPDBs lists PodDisruptionBudgets in all namespaces.
+func FetchPDBs(ctx context.Context, clients *Clients) ([]PDBInfo, error) {
+	return kube.FetchPDBs(ctx, clients)
+}
+
+// FetchCPUThrottling reads the CFS throttling counters of the containers on the given nodes
+// from their kubelets (needs the nodes/proxy permission).
+func FetchCPUThrottling(ctx context.Context, clients *Clients, nodes []string) (map[ContainerKey]CPUThrottling, error) {
+	return kube.FetchCPUThrottling(ctx, clients, nodes)
+}
+
+// ListNamespaceNames returns the sorted names of all namespaces.
+func ListNamespaceNames(ctx context.Context, clients *Clients) ([]string, error) {
+	return kube.ListNamespaceNames(ctx, clients)
+}
+
+// ListNodeNames returns the sorted names of all nodes.
+func ListNodeNames(ctx context.Context, clients *Clients) ([]string, error) {
+	return kube.ListNodeNames(ctx, clients)
+}
+
+// MillicoresFromQuantity converts a CPU quantity to millicores.
+func MillicoresFromQuantity(q resource.Quantity) int64 { return kube.MillicoresFromQuantity(q) }
+
+// MiBFromQuantity converts a memory quantity to MiB.
+func MiBFromQuantity(q resource.Quantity) float64 { return kube.MiBFromQuantity(q) }
+
+// FormatCPU formats millicores as "250m" or cores ("1.5").
+func FormatCPU(millicores int64) string { return kube.FormatCPU(millicores) }
+
+// FormatMem formats MiB as "512Mi" or "1.5Gi".
+func FormatMem(mib float64) string { return kube.FormatMem(mib) }
+
+// FormatFactor formats the request/actual over-request factor ("42x", "N/A", "no req") of
+// millicores or MiB.
+func FormatFactor[T int64 | float64](req, actual T) string { return kube.FormatFactor(req, actual) }
+
+// HelmRelease returns the Helm release an object's labels or annotations name, or "" for
+// objects outside one.
+func HelmRelease(labels, annotations map[string]string) string {
+	return kube.HelmRelease(labels, annotations)
+}
+
+// ArgoCDApp returns the Argo CD Application an object's tracking label or annotation names,
+// or "" for objects Argo CD does not manage.
+func ArgoCDApp(labels, annotations map[string]string) string {
+	return kube.ArgoCDApp(labels, annotations)
+}
+
+// FlushWarnings writes the warnings fetchers collected (unreadable or stale metrics) to
+// stderr and clears them. Call it once results are rendered.
+func FlushWarnings() { diag.Flush() }
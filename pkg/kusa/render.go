@@ -0,0 +1,82 @@
+package kusa
+
+import (
+	"io"
+
+	"github.com/amasotti/kusa/internal/output"
+)
+
+// Render options.
+type (
+	NodesOptions       = output.NodesOptions
+	PodsOptions        = output.PodsOptions
+	DeploymentsOptions = output.DeploymentsOptions
+	IdleOptions        = output.IdleOptions
+	OrphansOptions     = output.OrphansOptions
+	EvictionOptions    = output.EvictionOptions
+)
+
+// SetNoColor disables ANSI colors in rendered tables.
+func SetNoColor(v bool) { output.SetNoColor(v) }
+
+// RenderNodes renders the node overview (and optional breakdowns) and saves a report. It
+// fails when a go-template or jsonpath output format cannot be executed, as do RenderPods
+// and RenderDeployments.
+func RenderNodes(result *FetchNodesResult, contextName string, opts NodesOptions) error {
+	return output.RenderNodes(result, contextName, opts)
+}
+
+// RenderPods renders the top pods by CPU request and saves a report.
+func RenderPods(result *FetchPodsResult, contextName string, opts PodsOptions) error {
+	return output.RenderPods(result, contextName, opts)
+}
+
+// RenderDeployments renders workloads by over-request factor and saves a report.
+func RenderDeployments(result *FetchWorkloadsResult, contextName string, opts DeploymentsOptions) error {
+	return output.RenderDeployments(result, contextName, opts)
+}
+
+// RenderIdle renders idle workloads and saves a report.
+func RenderIdle(result *FetchWorkloadsResult, contextName string, opts IdleOptions) {
+	output.RenderIdle(result, contextName, opts)
+}
+
+// RenderOrphans renders standalone pods and saves a report.
+func RenderOrphans(result *FetchPodsResult, contextName string, opts OrphansOptions) {
+	output.RenderOrphans(result, contextName, opts)
+}
+
+// RenderEviction renders pods ranked by memory eviction risk and saves a report.
+func RenderEviction(result *FetchNodesResult, contextName string, opts EvictionOptions) {
+	output.RenderEviction(result, contextName, opts)
+}
+
+// RenderLint renders containers missing requests/limits and saves a report.
+func RenderLint(result *FetchLintResult, contextName string, checkLimits bool) {
+	output.RenderLint(result, contextName, checkLimits)
+}
+
+// RenderRebalance renders a rebalance plan and saves a report.
+func RenderRebalance(plan RebalancePlan, contextName string) {
+	output.RenderRebalance(plan, contextName)
+}
+
+// RenderDrainCheck renders a drain simulation and saves a report.
+func RenderDrainCheck(check DrainCheck, contextName string) {
+	output.RenderDrainCheck(check, contextName)
+}
+
+// RenderWhatIf renders a what-if simulation and saves a report.
+func RenderWhatIf(result WhatIfResult, change WhatIfChange, contextName string) {
+	output.RenderWhatIf(result, change, contextName)
+}
+
+// RenderCheck renders efficiency against a baseline and saves a report.
+func RenderCheck(baseline Baseline, current Efficiency, regressions []Regression, contextName string, tolerance float64) {
+	output.RenderCheck(baseline, current, regressions, contextName, tolerance)
+}
+
+// RenderTimings writes the per-API-call timing report to w.
+func RenderTimings(w io.Writer, calls []CallTiming) {
+	output.RenderTimings(w, calls)
+}